@@ -0,0 +1,152 @@
+// app.go
+package helper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+)
+
+type App struct {
+	configFile      string
+	trustFile       string
+	shutdownTimeout time.Duration
+	signals         []os.Signal
+	logger          core.Logger
+}
+
+type Option func(*App)
+
+func WithConfigFile(filename string) Option {
+	return func(a *App) {
+		a.configFile = filename
+	}
+}
+
+// WithTrustFile verifies config bundles against the operator keys in
+// path; see config.SetTrustFile.
+func WithTrustFile(path string) Option {
+	return func(a *App) {
+		a.trustFile = path
+	}
+}
+
+// WithShutdownTimeout overrides config.shutdown_timeout.
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(a *App) {
+		a.shutdownTimeout = timeout
+	}
+}
+
+// WithSignals sets the signals that stop Run; pass none to rely on ctx only.
+func WithSignals(signals ...os.Signal) Option {
+	return func(a *App) {
+		a.signals = signals
+	}
+}
+
+// WithLoggerFactory routes all helper logging through loggers built by fn,
+// e.g. core.SlogFactory(slog.Default()); see core.SetLoggerFactory.
+func WithLoggerFactory(fn func(name string) core.Logger) Option {
+	return func(a *App) {
+		core.SetLoggerFactory(fn)
+	}
+}
+
+func New(opts ...Option) *App {
+	a := &App{
+		signals: []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.logger = core.GetLogger("app")
+	return a
+}
+
+// RegisterComponent adds component to the ones Run initializes; see
+// core.Register for opts.
+func (a *App) RegisterComponent(component interface{}, opts ...core.RegisterOption) {
+	core.Register(component, opts...)
+}
+
+// Run initializes all registered components, blocks until ctx is cancelled
+// or a stop signal arrives, then shuts everything down in reverse order.
+func (a *App) Run(ctx context.Context) error {
+	if err := a.start(ctx); err != nil {
+		return err
+	}
+
+	if len(a.signals) > 0 {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, a.signals...)
+		defer stop()
+	}
+
+	<-ctx.Done()
+	a.logger.Info("Shutting down...")
+
+	if err := a.Stop(); err != nil {
+		return err
+	}
+
+	a.logger.Info("Shutdown complete")
+	return nil
+}
+
+func (a *App) Start() error {
+	return a.start(context.Background())
+}
+
+func (a *App) start(ctx context.Context) error {
+	if a.configFile != "" {
+		config.SetConfigFile(a.configFile)
+	}
+	if a.trustFile != "" {
+		config.SetTrustFile(a.trustFile)
+	}
+
+	if err := core.InitializeContext(ctx); err != nil {
+		var initErr *core.InitError
+		if errors.As(err, &initErr) && initErr.Diagnostic != nil {
+			a.writeDiagnostic(initErr.Diagnostic)
+		}
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	a.logger.Info("System initialized: %v", core.GetInitOrder())
+	return nil
+}
+
+// writeDiagnostic prints the startup diagnostic as a single JSON line on
+// stderr so supervisors and CI can parse it.
+func (a *App) writeDiagnostic(diag *core.StartupDiagnostic) {
+	line, err := json.Marshal(map[string]interface{}{"startup_failure": diag})
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, string(line))
+}
+
+func (a *App) Stop() error {
+	timeout := a.shutdownTimeout
+	if timeout == 0 {
+		timeout = config.Get().GetDuration("config", "shutdown_timeout")
+	}
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	if err := core.GracefulShutdown(timeout); err != nil {
+		return fmt.Errorf("failed to shutdown: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,262 @@
+// cmd/helper/bench.go
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/polkadot-go/helper"
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+// benchResult summarizes one benchmark. Latencies cover successful and
+// failed operations alike.
+type benchResult struct {
+	Name        string        `json:"name"`
+	Concurrency int           `json:"concurrency"`
+	Duration    time.Duration `json:"duration"`
+	Ops         int64         `json:"ops"`
+	Errors      int64         `json:"errors"`
+	OpsPerSec   float64       `json:"ops_per_sec"`
+	P50         time.Duration `json:"p50"`
+	P90         time.Duration `json:"p90"`
+	P99         time.Duration `json:"p99"`
+	Max         time.Duration `json:"max"`
+	FirstError  string        `json:"first_error,omitempty"`
+}
+
+type benchReport struct {
+	Started time.Time     `json:"started"`
+	Host    benchHost     `json:"host"`
+	Results []benchResult `json:"results"`
+}
+
+type benchHost struct {
+	OS   string `json:"os"`
+	Arch string `json:"arch"`
+	CPUs int    `json:"cpus"`
+	Go   string `json:"go"`
+}
+
+// bench runs the "bench" subcommand: it starts the helper from a config
+// file like a normal run, exercises the selected subsystems, prints a
+// report and shuts down again. Give the bench config an empty
+// admin.address when a helper already runs on the same host.
+func bench(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	configFile := fs.String("config", "", "config file to start the helper with")
+	storeName := fs.String("store", "", "component name of the store to run kv benchmarks against")
+	rpcURLs := fs.String("rpc", "", "comma-separated JSON-RPC HTTP endpoints to measure query latency against")
+	rpcMethod := fs.String("rpc-method", "system_health", "JSON-RPC method called by the rpc benchmark")
+	concurrency := fs.Int("concurrency", 8, "concurrent workers per benchmark")
+	duration := fs.Duration("duration", 10*time.Second, "how long each benchmark runs")
+	keys := fs.Int("keys", 10000, "distinct keys the kv benchmarks cycle through")
+	valueSize := fs.Int("value-size", 256, "bytes per value written by the kv benchmark")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Parse(args)
+
+	if *storeName == "" && *rpcURLs == "" {
+		return fmt.Errorf("nothing to benchmark: pass -store and/or -rpc")
+	}
+	if *concurrency < 1 || *keys < 1 {
+		return fmt.Errorf("-concurrency and -keys must be at least 1")
+	}
+
+	report := benchReport{
+		Started: time.Now(),
+		Host:    benchHost{OS: runtime.GOOS, Arch: runtime.GOARCH, CPUs: runtime.NumCPU(), Go: runtime.Version()},
+	}
+
+	if *storeName != "" {
+		var opts []helper.Option
+		if *configFile != "" {
+			opts = append(opts, helper.WithConfigFile(*configFile))
+		}
+		app := helper.New(opts...)
+		if err := app.Start(); err != nil {
+			return err
+		}
+		results, err := benchStore(*storeName, *concurrency, *duration, *keys, *valueSize)
+		stopErr := app.Stop()
+		if err != nil {
+			return err
+		}
+		if stopErr != nil {
+			return stopErr
+		}
+		report.Results = append(report.Results, results...)
+	}
+
+	if *rpcURLs != "" {
+		for _, url := range strings.Split(*rpcURLs, ",") {
+			if url = strings.TrimSpace(url); url != "" {
+				report.Results = append(report.Results, benchRPC(url, *rpcMethod, *concurrency, *duration))
+			}
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printBench(report)
+	return nil
+}
+
+func benchStore(name string, concurrency int, duration time.Duration, keys, valueSize int) ([]benchResult, error) {
+	provider, ok := core.GetComponent(name).(data.StoreProvider)
+	if !ok || !core.IsInitialized(name) || provider.Store() == nil {
+		return nil, fmt.Errorf("store %s is not registered or not initialized", name)
+	}
+	store := provider.Store()
+
+	run := make([]byte, 4)
+	rand.Read(run)
+	prefix := "bench:" + hex.EncodeToString(run) + ":"
+	value := strings.Repeat("x", valueSize)
+	key := func(i int) string { return fmt.Sprintf("%s%d", prefix, i%keys) }
+
+	set := runBench(name+" set", concurrency, duration, func(ctx context.Context, i int) error {
+		return store.Set(ctx, key(i), value)
+	})
+	get := runBench(name+" get", concurrency, duration, func(ctx context.Context, i int) error {
+		_, err := store.Get(ctx, key(i))
+		return err
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	// Operations cut short by the deadline may have written too
+	for i := 0; i < min(int(set.Ops)+concurrency, keys); i++ {
+		if err := store.Delete(ctx, key(i)); err != nil {
+			return nil, fmt.Errorf("removing benchmark keys: %w", err)
+		}
+	}
+	return []benchResult{set, get}, nil
+}
+
+func benchRPC(url, method string, concurrency int, duration time.Duration) benchResult {
+	client := &http.Client{Timeout: 10 * time.Second}
+	return runBench("rpc "+url, concurrency, duration, func(ctx context.Context, i int) error {
+		body := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":[]}`, i, method)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBufferString(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		var out struct {
+			Error *struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if resp.StatusCode != http.StatusOK {
+			io.Copy(io.Discard, resp.Body)
+			return fmt.Errorf("endpoint returned %s", resp.Status)
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+			return err
+		}
+		if out.Error != nil {
+			return fmt.Errorf("rpc error: %s", out.Error.Message)
+		}
+		return nil
+	})
+}
+
+// runBench calls op from concurrency workers until duration has passed.
+// i counts operations across all workers.
+func runBench(name string, concurrency int, duration time.Duration, op func(ctx context.Context, i int) error) benchResult {
+	ctx, cancel := context.WithTimeout(context.Background(), duration)
+	defer cancel()
+
+	var (
+		next     atomic.Int64
+		errs     atomic.Int64
+		firstErr atomic.Value
+		wg       sync.WaitGroup
+	)
+	latencies := make([][]time.Duration, concurrency)
+
+	start := time.Now()
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1) - 1)
+				opStart := time.Now()
+				err := op(ctx, i)
+				if ctx.Err() != nil {
+					// Cut short by the deadline, not a real result
+					return
+				}
+				latencies[w] = append(latencies[w], time.Since(opStart))
+				if err != nil {
+					errs.Add(1)
+					firstErr.CompareAndSwap(nil, err.Error())
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	var all []time.Duration
+	for _, l := range latencies {
+		all = append(all, l...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+
+	result := benchResult{
+		Name:        name,
+		Concurrency: concurrency,
+		Duration:    elapsed,
+		Ops:         int64(len(all)),
+		Errors:      errs.Load(),
+		OpsPerSec:   float64(len(all)) / elapsed.Seconds(),
+	}
+	if msg, ok := firstErr.Load().(string); ok {
+		result.FirstError = msg
+	}
+	if n := len(all); n > 0 {
+		result.P50 = all[n*50/100]
+		result.P90 = all[n*90/100]
+		result.P99 = all[n*99/100]
+		result.Max = all[n-1]
+	}
+	return result
+}
+
+func printBench(report benchReport) {
+	fmt.Printf("%s/%s, %d CPUs, %s\n\n", report.Host.OS, report.Host.Arch, report.Host.CPUs, report.Host.Go)
+	fmt.Printf("%-40s %10s %8s %12s %10s %10s %10s %10s\n", "benchmark", "ops", "errors", "ops/s", "p50", "p90", "p99", "max")
+	for _, r := range report.Results {
+		fmt.Printf("%-40s %10d %8d %12.1f %10s %10s %10s %10s\n", r.Name, r.Ops, r.Errors, r.OpsPerSec,
+			r.P50.Round(time.Microsecond), r.P90.Round(time.Microsecond), r.P99.Round(time.Microsecond), r.Max.Round(time.Microsecond))
+		if r.FirstError != "" {
+			fmt.Printf("  first error: %s\n", r.FirstError)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+// cmd/helper/main.go
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/polkadot-go/helper"
+	"github.com/polkadot-go/helper/data/mysql"
+	"github.com/polkadot-go/helper/managers/admin"
+	"github.com/polkadot-go/helper/managers/annotations"
+	"github.com/polkadot-go/helper/managers/fleet"
+	"github.com/polkadot-go/helper/managers/metrics"
+	"github.com/polkadot-go/helper/managers/network"
+	"github.com/polkadot-go/helper/managers/telemetry"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "config-usage" {
+		address := "127.0.0.1:8080"
+		if len(os.Args) > 2 {
+			address = os.Args[2]
+		}
+		if err := configUsage(address); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		if err := setup(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "bench" {
+		if err := bench(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	var opts []helper.Option
+
+	// Set config file if needed
+	if len(os.Args) > 1 {
+		opts = append(opts, helper.WithConfigFile(os.Args[1]))
+	}
+
+	app := helper.New(opts...)
+	mysql.Register(app)
+	admin.Register(app)
+	annotations.Register(app)
+	fleet.Register(app)
+	metrics.Register(app)
+	network.Register(app)
+	telemetry.Register(app)
+
+	if err := app.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
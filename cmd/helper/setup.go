@@ -0,0 +1,354 @@
+// cmd/helper/setup.go
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data/mysql"
+)
+
+// connectivityTest checks that the values entered for a section reach a
+// live service. fields are offered for editing when the test fails.
+type connectivityTest struct {
+	section string
+	fields  []string
+	run     func(ctx context.Context) error
+}
+
+var connectivityTests = []connectivityTest{
+	{
+		section: "mysql",
+		fields:  []string{"host", "port", "user", "password", "database"},
+		run: func(ctx context.Context) error {
+			store := mysql.New(sectionConfig{section: "mysql"})
+			if err := store.Connect(ctx); err != nil {
+				return err
+			}
+			return store.Close()
+		},
+	},
+}
+
+// wizard holds the answers of a "helper init" session.
+type wizard struct {
+	in      *bufio.Reader
+	out     io.Writer
+	schemas map[string]config.Schema
+	values  map[string]map[string]interface{}
+}
+
+// setup runs the "init" subcommand: it asks for config values field by
+// field, checks connectivity with them and writes the config file.
+func setup(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	configFile := fs.String("config", "config.json", "config file to write")
+	all := fs.Bool("all", false, "ask for every field, not only required ones")
+	fs.Parse(args)
+
+	w := &wizard{
+		in:      bufio.NewReader(os.Stdin),
+		out:     os.Stdout,
+		schemas: config.Schemas(),
+	}
+	if err := w.loadExisting(*configFile); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w.out, "Writing %s. Press enter to keep the value in brackets.\n", *configFile)
+	for _, section := range sortedKeys(w.schemas) {
+		var fields []string
+		for _, field := range sortedKeys(w.schemas[section]) {
+			if *all || w.schemas[section][field].Required {
+				fields = append(fields, field)
+			}
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		fmt.Fprintf(w.out, "\n[%s]\n", section)
+		if err := w.askFields(section, fields); err != nil {
+			return err
+		}
+	}
+
+	// The candidate is loaded through the config package so that
+	// references are resolved and every validator and binding runs exactly
+	// as on startup
+	candidate := *configFile + ".init"
+	defer os.Remove(candidate)
+	for {
+		if err := w.write(candidate); err != nil {
+			return err
+		}
+		if err := config.Load(candidate); err != nil {
+			return fmt.Errorf("config does not validate: %w", err)
+		}
+
+		retest, err := w.testConnectivity()
+		if err != nil {
+			return err
+		}
+		if !retest {
+			break
+		}
+	}
+
+	if err := os.Rename(candidate, *configFile); err != nil {
+		return err
+	}
+	fmt.Fprintf(w.out, "\nWrote %s. Start the helper with: helper %s\n", *configFile, *configFile)
+	return nil
+}
+
+// loadExisting starts from the defaults overlaid with filename, if it
+// exists, so running init again edits the current config.
+func (w *wizard) loadExisting(filename string) error {
+	w.values = make(map[string]map[string]interface{}, len(w.schemas))
+	for section, schema := range w.schemas {
+		w.values[section] = make(map[string]interface{}, len(schema))
+		for field, def := range schema {
+			w.values[section][field] = def.Default
+		}
+	}
+
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var raw map[string]map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("reading existing %s: %w", filename, err)
+	}
+	for section, values := range raw {
+		if _, ok := w.values[section]; !ok {
+			w.values[section] = make(map[string]interface{}, len(values))
+		}
+		for field, value := range values {
+			w.values[section][field] = value
+		}
+	}
+	fmt.Fprintf(w.out, "Editing existing %s.\n", filename)
+	return nil
+}
+
+func (w *wizard) askFields(section string, fields []string) error {
+	for _, field := range fields {
+		if err := w.askField(section, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// askField prompts until the answer parses as the field's type and passes
+// its validator.
+func (w *wizard) askField(section, field string) error {
+	def := w.schemas[section][field]
+	current := w.values[section][field]
+
+	fmt.Fprintf(w.out, "%s.%s: %s\n", section, field, def.Description)
+	if def.Secret {
+		fmt.Fprintln(w.out, "  Input is shown as typed; env:NAME or file:/path keeps the secret out of the file.")
+	}
+	for {
+		fmt.Fprintf(w.out, "  %s [%s]: ", field, display(def, current))
+		line, err := w.in.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			return fmt.Errorf("reading input: %w", err)
+		}
+		line = strings.TrimSpace(line)
+
+		value := current
+		if line != "" {
+			value, err = parseAnswer(line, def.Default)
+			if err != nil {
+				fmt.Fprintf(w.out, "  %v\n", err)
+				continue
+			}
+		}
+		if def.Required && (value == nil || value == "") {
+			fmt.Fprintln(w.out, "  A value is required.")
+			continue
+		}
+		if def.Validator != nil && value != nil {
+			if err := def.Validator(value); err != nil {
+				fmt.Fprintf(w.out, "  %v\n", err)
+				continue
+			}
+		}
+		w.values[section][field] = value
+		return nil
+	}
+}
+
+// testConnectivity runs the connectivity tests and reports whether the
+// user edited values, so the config must be validated and tested again.
+func (w *wizard) testConnectivity() (bool, error) {
+	for _, test := range connectivityTests {
+		if _, ok := w.schemas[test.section]; !ok {
+			continue
+		}
+		ok, err := w.confirm(fmt.Sprintf("\nTest the %s connection?", test.section), true)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		err = test.run(ctx)
+		cancel()
+		if err == nil {
+			fmt.Fprintf(w.out, "  %s: connected\n", test.section)
+			continue
+		}
+
+		fmt.Fprintf(w.out, "  %s: %v\n", test.section, err)
+		edit, err := w.confirm(fmt.Sprintf("Edit the %s settings?", test.section), true)
+		if err != nil || !edit {
+			return false, err
+		}
+		if err := w.askFields(test.section, test.fields); err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func (w *wizard) confirm(question string, yes bool) (bool, error) {
+	hint := "[Y/n]"
+	if !yes {
+		hint = "[y/N]"
+	}
+	for {
+		fmt.Fprintf(w.out, "%s %s ", question, hint)
+		line, err := w.in.ReadString('\n')
+		if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+			return false, fmt.Errorf("reading input: %w", err)
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "":
+			return yes, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+	}
+}
+
+// write saves the answers readable by the owner only, since they may
+// include passwords.
+func (w *wizard) write(filename string) error {
+	data, err := json.MarshalIndent(w.values, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0600)
+}
+
+// parseAnswer converts typed input to the type of the field's default, so
+// that "3306" stays a number and durations are checked as they are typed.
+func parseAnswer(input string, def interface{}) (interface{}, error) {
+	switch d := def.(type) {
+	case int, int64:
+		n, err := strconv.Atoi(input)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a whole number", input)
+		}
+		return n, nil
+	case float64:
+		n, err := strconv.ParseFloat(input, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", input)
+		}
+		return n, nil
+	case bool:
+		b, err := strconv.ParseBool(input)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", input)
+		}
+		return b, nil
+	case string:
+		if _, err := time.ParseDuration(d); err == nil && d != "" {
+			if _, err := time.ParseDuration(input); err != nil {
+				return nil, fmt.Errorf("%q is not a duration such as 30s or 5m", input)
+			}
+		}
+		return input, nil
+	case nil:
+		return input, nil
+	default:
+		var v interface{}
+		if err := json.Unmarshal([]byte(input), &v); err != nil {
+			return nil, fmt.Errorf("enter JSON: %v", err)
+		}
+		return v, nil
+	}
+}
+
+func display(def config.Field, value interface{}) string {
+	if s, ok := value.(string); ok && def.Secret && s != "" && !config.IsReference(s) {
+		return "******"
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(value)
+		return string(data)
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// sectionConfig reads a section of the loaded config for a store's
+// constructor.
+type sectionConfig struct {
+	section string
+}
+
+func (s sectionConfig) GetString(key string) string {
+	return config.Get().GetString(s.section, key)
+}
+
+func (s sectionConfig) GetInt(key string) int {
+	return config.Get().GetInt(s.section, key)
+}
+
+func (s sectionConfig) GetBool(key string) bool {
+	return config.Get().GetBool(s.section, key)
+}
+
+func (s sectionConfig) GetDuration(key string) time.Duration {
+	return config.Get().GetDuration(s.section, key)
+}
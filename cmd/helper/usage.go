@@ -0,0 +1,43 @@
+// cmd/helper/usage.go
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/polkadot-go/helper/core/config"
+)
+
+// configUsage prints the config usage report of a running helper, read
+// from its admin server.
+func configUsage(adminAddress string) error {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get("http://" + adminAddress + "/config/usage")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("admin server returned %s", resp.Status)
+	}
+
+	var report config.UsageReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return err
+	}
+
+	printKeys("Unused keys (set but never read)", report.Unused)
+	printKeys("Undeclared keys (read but not in any schema)", report.Undeclared)
+	printKeys("Unknown keys (set but not in any schema)", report.Unknown)
+	return nil
+}
+
+func printKeys(title string, keys []string) {
+	fmt.Printf("%s: %d\n", title, len(keys))
+	for _, key := range keys {
+		fmt.Printf("  %s\n", key)
+	}
+}
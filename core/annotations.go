@@ -0,0 +1,53 @@
+package core
+
+import (
+	"sync"
+	"time"
+)
+
+// Annotation is an operator note attached to a piece of runtime state,
+// e.g. a component, an RPC endpoint or an account, identified by Kind and
+// Name.
+type Annotation struct {
+	ID      string    `json:"id"`
+	Kind    string    `json:"kind"`
+	Name    string    `json:"name"`
+	Note    string    `json:"note"`
+	Author  string    `json:"author,omitempty"`
+	Created time.Time `json:"created"`
+	Expires time.Time `json:"expires,omitzero"`
+}
+
+// Expired reports whether the annotation had an expiry and it has passed.
+func (a Annotation) Expired(now time.Time) bool {
+	return !a.Expires.IsZero() && !now.Before(a.Expires)
+}
+
+var annotations struct {
+	mu  sync.RWMutex
+	all []Annotation
+}
+
+// SetAnnotations replaces the annotations shown alongside component status
+// and health. The annotations component keeps them in sync with its store.
+func SetAnnotations(all []Annotation) {
+	annotations.mu.Lock()
+	defer annotations.mu.Unlock()
+	annotations.all = append([]Annotation(nil), all...)
+}
+
+// AnnotationsFor returns the unexpired annotations on kind/name, oldest
+// first. An empty kind or name matches any.
+func AnnotationsFor(kind, name string) []Annotation {
+	annotations.mu.RLock()
+	defer annotations.mu.RUnlock()
+
+	now := time.Now()
+	var result []Annotation
+	for _, a := range annotations.all {
+		if (kind == "" || a.Kind == kind) && (name == "" || a.Name == name) && !a.Expired(now) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Budget accumulates how a request's time is spent across categories
+// (config, store, rpc) and endpoints. Attach one to a context with
+// WithBudget; TrackSpan calls made with that context feed into it.
+type Budget struct {
+	name  string
+	start time.Time
+	limit time.Duration
+	spans map[spanKey]*SpanSummary
+	mu    sync.Mutex
+}
+
+type spanKey struct {
+	category string
+	endpoint string
+}
+
+type SpanSummary struct {
+	Category string
+	Endpoint string
+	Count    int
+	Total    time.Duration
+}
+
+type budgetKey struct{}
+
+func WithBudget(ctx context.Context, name string) (context.Context, *Budget) {
+	b := &Budget{
+		name:  name,
+		start: time.Now(),
+		spans: make(map[spanKey]*SpanSummary),
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		b.limit = time.Until(deadline)
+	}
+	return context.WithValue(ctx, budgetKey{}, b), b
+}
+
+func BudgetFromContext(ctx context.Context) *Budget {
+	b, _ := ctx.Value(budgetKey{}).(*Budget)
+	return b
+}
+
+// TrackSpan times a unit of work and returns the function that ends it:
+//
+//	defer core.TrackSpan(ctx, "store", "mysql")()
+//
+// The span is always recorded in metrics; it is also attributed to the
+// context's Budget when one is present.
+func TrackSpan(ctx context.Context, category, endpoint string) func() {
+	start := time.Now()
+	return func() {
+		elapsed := time.Since(start)
+		RecordValueWithLabels("budget.span", map[string]string{"category": category, "endpoint": endpoint},
+			float64(elapsed.Microseconds()))
+
+		if b := BudgetFromContext(ctx); b != nil {
+			b.add(category, endpoint, elapsed)
+		}
+	}
+}
+
+func (b *Budget) add(category, endpoint string, elapsed time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := spanKey{category: category, endpoint: endpoint}
+	s, ok := b.spans[key]
+	if !ok {
+		s = &SpanSummary{Category: category, Endpoint: endpoint}
+		b.spans[key] = s
+	}
+	s.Count++
+	s.Total += elapsed
+}
+
+func (b *Budget) Elapsed() time.Duration {
+	return time.Since(b.start)
+}
+
+// Remaining reports time left before the context deadline the budget was
+// created with, or zero when there was no deadline.
+func (b *Budget) Remaining() time.Duration {
+	if b.limit == 0 {
+		return 0
+	}
+	return b.limit - b.Elapsed()
+}
+
+// Breakdown returns the accumulated spans, largest total first.
+func (b *Budget) Breakdown() []SpanSummary {
+	b.mu.Lock()
+	result := make([]SpanSummary, 0, len(b.spans))
+	for _, s := range b.spans {
+		result = append(result, *s)
+	}
+	b.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Total > result[j].Total
+	})
+	return result
+}
+
+// Attributes flattens the breakdown into fields suitable for
+// Logger.With or trace span attributes.
+func (b *Budget) Attributes() map[string]interface{} {
+	elapsed := b.Elapsed()
+	attrs := map[string]interface{}{
+		"budget.name":       b.name,
+		"budget.elapsed_ms": elapsed.Milliseconds(),
+	}
+	if b.limit > 0 {
+		attrs["budget.limit_ms"] = b.limit.Milliseconds()
+	}
+
+	var accounted time.Duration
+	for _, s := range b.Breakdown() {
+		attrs[fmt.Sprintf("budget.%s.%s_ms", s.Category, s.Endpoint)] = s.Total.Milliseconds()
+		accounted += s.Total
+	}
+	attrs["budget.unaccounted_ms"] = (elapsed - accounted).Milliseconds()
+	return attrs
+}
+
+// Finish records the share of the request spent in each category and
+// endpoint, so "where did my 500ms go" is answerable from metrics alone.
+func (b *Budget) Finish() {
+	elapsed := b.Elapsed()
+	RecordValueWithLabels("budget.request", map[string]string{"name": b.name}, float64(elapsed.Microseconds()))
+	if elapsed <= 0 {
+		return
+	}
+
+	for _, s := range b.Breakdown() {
+		share := float64(s.Total) / float64(elapsed) * 100
+		RecordValueWithLabels("budget.share", map[string]string{
+			"name":     b.name,
+			"category": s.Category,
+			"endpoint": s.Endpoint,
+		}, share)
+	}
+}
@@ -0,0 +1,291 @@
+// config/bind.go
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+type binding struct {
+	section string
+	// current returns the bound struct, store replaces it
+	current func() reflect.Value
+	store   func(reflect.Value)
+}
+
+var (
+	bindings     []binding
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// Bound is a config section decoded into a struct of type T.
+type Bound[T any] struct {
+	value atomic.Pointer[T]
+}
+
+// Load returns the section as of the last successful reload. Reloads
+// decode into a new T rather than this one, so it can be read without
+// locking but must not be modified.
+func (b *Bound[T]) Load() *T {
+	return b.value.Load()
+}
+
+// Bind decodes a config section into a struct of type T and keeps it
+// bound, so Load returns a fresh copy after every successful reload.
+// Fields are matched by their `config` tag, falling back to the lowercased
+// field name; a tag of "-" skips the field.
+func Bind[T any](section string) (*Bound[T], error) {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("bind type for %s must be a struct, got %s", section, t)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	initial := new(T)
+	if err := Get().bindSection(section, reflect.ValueOf(initial).Elem()); err != nil {
+		return nil, err
+	}
+	b := &Bound[T]{}
+	b.value.Store(initial)
+	bindings = append(bindings, binding{
+		section: section,
+		current: func() reflect.Value { return reflect.ValueOf(b.value.Load()).Elem() },
+		store:   func(v reflect.Value) { b.value.Store(v.Interface().(*T)) },
+	})
+	return b, nil
+}
+
+func (c *Config) bindSection(section string, v reflect.Value) error {
+	data, ok := c.data[section]
+	if !ok {
+		return fmt.Errorf("unknown config section: %s", section)
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if key, ok := fieldKey(t.Field(i)); ok {
+			recordRead(section, key)
+		}
+	}
+	return decodeStruct(data, v, section)
+}
+
+// fieldKey returns the config key a struct field binds to.
+func fieldKey(field reflect.StructField) (string, bool) {
+	if field.PkgPath != "" {
+		return "", false
+	}
+	key := field.Tag.Get("config")
+	if key == "-" {
+		return "", false
+	}
+	if key == "" {
+		key = strings.ToLower(field.Name)
+	}
+	return key, true
+}
+
+// rebind decodes every bound section into a copy of its struct and swaps
+// the copy in. Decoding replaces slices, maps and pointers instead of
+// writing through them, so the copy shares nothing a reader could see
+// change.
+func (c *Config) rebind() error {
+	for _, b := range bindings {
+		next := copyBound(b)
+		if err := c.bindSection(b.section, next.Elem()); err != nil {
+			return err
+		}
+		b.store(next)
+	}
+	return nil
+}
+
+// checkBindings decodes data into scratch copies of every bound struct, so
+// a reload can be rejected before anything is applied.
+func checkBindings(data map[string]map[string]interface{}) error {
+	for _, b := range bindings {
+		if err := decodeStruct(data[b.section], copyBound(b).Elem(), b.section); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyBound returns a pointer to a shallow copy of b's struct.
+func copyBound(b binding) reflect.Value {
+	current := b.current()
+	next := reflect.New(current.Type())
+	next.Elem().Set(current)
+	return next
+}
+
+func decodeStruct(data map[string]interface{}, v reflect.Value, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key, ok := fieldKey(t.Field(i))
+		if !ok {
+			continue
+		}
+
+		raw, ok := data[key]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(raw, v.Field(i)); err != nil {
+			return fmt.Errorf("binding %s.%s: %w", path, key, err)
+		}
+	}
+	return nil
+}
+
+func decodeValue(raw interface{}, v reflect.Value) error {
+	if raw == nil {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	if v.Type() == durationType {
+		d, err := toDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(fmt.Sprintf("%v", raw))
+	case reflect.Bool:
+		b, ok := raw.(bool)
+		if !ok {
+			return fmt.Errorf("expected bool, got %T", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := toFloat(raw)
+		if err != nil {
+			return err
+		}
+		if v.OverflowInt(int64(n)) {
+			return fmt.Errorf("value %v overflows %s", raw, v.Type())
+		}
+		v.SetInt(int64(n))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := toFloat(raw)
+		if err != nil {
+			return err
+		}
+		if n < 0 || v.OverflowUint(uint64(n)) {
+			return fmt.Errorf("value %v overflows %s", raw, v.Type())
+		}
+		v.SetUint(uint64(n))
+	case reflect.Float32, reflect.Float64:
+		n, err := toFloat(raw)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		return decodeSlice(raw, v)
+	case reflect.Map:
+		return decodeMap(raw, v)
+	case reflect.Struct:
+		m, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("expected object, got %T", raw)
+		}
+		return decodeStruct(m, v, v.Type().Name())
+	case reflect.Ptr:
+		elem := reflect.New(v.Type().Elem())
+		if err := decodeValue(raw, elem.Elem()); err != nil {
+			return err
+		}
+		v.Set(elem)
+	case reflect.Interface:
+		v.Set(reflect.ValueOf(raw))
+	default:
+		return fmt.Errorf("unsupported field type %s", v.Type())
+	}
+	return nil
+}
+
+func decodeSlice(raw interface{}, v reflect.Value) error {
+	var items []interface{}
+	switch val := raw.(type) {
+	case []interface{}:
+		items = val
+	case []string:
+		for _, s := range val {
+			items = append(items, s)
+		}
+	case string:
+		for _, s := range strings.Split(val, ",") {
+			items = append(items, s)
+		}
+	default:
+		return fmt.Errorf("expected array, got %T", raw)
+	}
+
+	slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+	for i, item := range items {
+		if err := decodeValue(item, slice.Index(i)); err != nil {
+			return fmt.Errorf("index %d: %w", i, err)
+		}
+	}
+	v.Set(slice)
+	return nil
+}
+
+func decodeMap(raw interface{}, v reflect.Value) error {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected object, got %T", raw)
+	}
+	if v.Type().Key().Kind() != reflect.String {
+		return fmt.Errorf("unsupported map key type %s", v.Type().Key())
+	}
+
+	result := reflect.MakeMapWithSize(v.Type(), len(m))
+	for key, item := range m {
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := decodeValue(item, elem); err != nil {
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+		result.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+	}
+	v.Set(result)
+	return nil
+}
+
+func toFloat(raw interface{}) (float64, error) {
+	switch val := raw.(type) {
+	case int:
+		return float64(val), nil
+	case int64:
+		return float64(val), nil
+	case float64:
+		return val, nil
+	case float32:
+		return float64(val), nil
+	}
+	return 0, fmt.Errorf("expected number, got %T", raw)
+}
+
+func toDuration(raw interface{}) (time.Duration, error) {
+	switch val := raw.(type) {
+	case string:
+		return time.ParseDuration(val)
+	case int:
+		return time.Duration(val) * time.Second, nil
+	case int64:
+		return time.Duration(val) * time.Second, nil
+	case float64:
+		return time.Duration(val * float64(time.Second)), nil
+	}
+	return 0, fmt.Errorf("expected duration, got %T", raw)
+}
@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+type bindTest struct {
+	Name  string            `config:"name"`
+	Peers []string          `config:"peers"`
+	Tags  map[string]string `config:"tags"`
+}
+
+// TestBindReload reads a bound section while it is reloaded. Run with
+// -race to catch reloads writing into a struct readers hold.
+func TestBindReload(t *testing.T) {
+	Register("bind_test", Schema{
+		"name":  Field{Default: "a"},
+		"peers": Field{Default: []interface{}{}},
+		"tags":  Field{Default: map[string]interface{}{}},
+	})
+
+	file := filepath.Join(t.TempDir(), "config.json")
+	write := func(i int) {
+		content := fmt.Sprintf(`{"bind_test": {"name": "n%d", "peers": ["p%d"], "tags": {"k": "v%d"}}}`, i, i, i)
+		if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(0)
+	if err := Load(file); err != nil {
+		t.Fatal(err)
+	}
+
+	bound, err := Bind[bindTest]("bind_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := bound.Load(); got.Name != "n0" || got.Peers[0] != "p0" || got.Tags["k"] != "v0" {
+		t.Fatalf("initial bind = %+v", got)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				v := bound.Load()
+				// Each snapshot comes from a single reload
+				if "n"+v.Peers[0][1:] != v.Name || "v"+v.Tags["k"][1:] != "v"+v.Name[1:] {
+					t.Errorf("torn read: %+v", v)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 1; i <= 50; i++ {
+		write(i)
+		if err := Load(file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	if got := bound.Load(); got.Name != "n50" || got.Peers[0] != "p50" || got.Tags["k"] != "v50" {
+		t.Fatalf("after reloads = %+v", got)
+	}
+}
+
+func TestBindRejectsNonStruct(t *testing.T) {
+	if _, err := Bind[string]("bind_test"); err == nil {
+		t.Fatal("Bind accepted a non-struct type")
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"reflect"
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/polkadot-go/helper/core"
 )
 
 type Field struct {
@@ -15,6 +18,9 @@ type Field struct {
 	Required    bool
 	Description string
 	Validator   func(interface{}) error
+	// Secret masks the value in templates, the admin /config endpoint and
+	// logs.
+	Secret bool
 }
 
 type Schema map[string]Field
@@ -30,7 +36,12 @@ type Config struct {
 	data      map[string]map[string]interface{}
 	loaded    bool
 	filename  string
-	listeners []func(string, string, interface{})
+	listeners []*listener
+	watchStop chan struct{}
+	refs      map[string]map[string]string
+	// overrides holds ephemeral patches, reapplied over the file on every
+	// reload until the process exits
+	overrides map[string]interface{}
 }
 
 func Register(section string, schema Schema) {
@@ -39,11 +50,27 @@ func Register(section string, schema Schema) {
 	registry[section] = schema
 }
 
+// Schemas returns a copy of the registered schemas by section.
+func Schemas() map[string]Schema {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	schemas := make(map[string]Schema, len(registry))
+	for section, schema := range registry {
+		copied := make(Schema, len(schema))
+		for field, def := range schema {
+			copied[field] = def
+		}
+		schemas[section] = copied
+	}
+	return schemas
+}
+
 func Get() *Config {
 	once.Do(func() {
 		instance = &Config{
 			data:      make(map[string]map[string]interface{}),
-			listeners: make([]func(string, string, interface{}), 0),
+			listeners: make([]*listener, 0),
 		}
 	})
 	return instance
@@ -53,53 +80,145 @@ func Load(filename string) error {
 	return Get().LoadFile(filename)
 }
 
+// LoadFile reads, validates and applies a config file. The new config is
+// built and checked in full before it replaces the current one, so a
+// broken file leaves the running config untouched.
 func (c *Config) LoadFile(filename string) error {
 	mu.Lock()
-	defer mu.Unlock()
 
 	c.filename = filename
+	reloading := c.loaded
+	next, refs, bundleSigner, err := c.prepare(filename, c.overrides)
+	if err != nil {
+		mu.Unlock()
+		if reloading {
+			core.IncrCounterWithLabels("config.reloads", map[string]string{"result": "rejected"})
+		}
+		return err
+	}
+
+	old, changed := c.swap(next, refs)
+	notice := newReloadNotice(changed, old, next)
+
+	if trustFile != "" {
+		signer = bundleSigner
+		core.GetLogger("audit").With(map[string]interface{}{
+			"file":   filename,
+			"signer": bundleSigner,
+		}).Info("Config bundle applied")
+	}
+
+	c.loaded = true
+	mu.Unlock()
+
+	if reloading {
+		core.IncrCounterWithLabels("config.reloads", map[string]string{"result": "applied"})
+		notice.notify()
+	}
+	return nil
+}
+
+// swap replaces the running config with next, which prepare has already
+// decoded against every binding, and returns the old data and the sections
+// that changed. It must be called with mu held.
+func (c *Config) swap(next map[string]map[string]interface{}, refs map[string]map[string]string) (map[string]map[string]interface{}, map[string]bool) {
+	old := c.data
+	c.data = next
+	c.refs = refs
+	c.rebind()
+
+	changed := make(map[string]bool)
+	for section, values := range next {
+		for key, value := range values {
+			if previous, ok := old[section][key]; !ok || !reflect.DeepEqual(previous, value) {
+				changed[section] = true
+				c.notifyListeners(section, key, previous, value)
+			}
+		}
+		for key := range old[section] {
+			if _, ok := values[key]; !ok {
+				changed[section] = true
+			}
+		}
+	}
+	return old, changed
+}
+
+// prepare builds the config a file would produce without touching c: the
+// registered defaults overlaid with the file and then with overrides, with
+// env: and file: references resolved, validated, and decoded into every
+// bound struct.
+func (c *Config) prepare(filename string, overrides map[string]interface{}) (map[string]map[string]interface{}, map[string]map[string]string, string, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
-		if os.IsNotExist(err) {
-			c.loadDefaults()
-			c.loaded = true
-			return nil
+		if !os.IsNotExist(err) {
+			return nil, nil, "", fmt.Errorf("reading config file: %w", err)
 		}
-		return fmt.Errorf("reading config file: %w", err)
+		data = nil
 	}
+	return c.build(filename, data, overrides)
+}
+
+// build is prepare for file contents already read; nil data stands for a
+// missing file.
+func (c *Config) build(filename string, data []byte, overrides map[string]interface{}) (map[string]map[string]interface{}, map[string]map[string]string, string, error) {
+	next := defaultData()
 
-	rawData := make(map[string]interface{})
-	if err := json.Unmarshal(data, &rawData); err != nil {
-		return fmt.Errorf("parsing json: %w", err)
+	if data == nil && len(overrides) == 0 {
+		refs, err := resolveReferences(next)
+		return next, refs, "", err
 	}
 
-	c.loadDefaults()
-	if err := c.overlayData(rawData); err != nil {
-		return err
+	var bundleSigner string
+	if data != nil {
+		rawData := make(map[string]interface{})
+		if err := json.Unmarshal(data, &rawData); err != nil {
+			return nil, nil, "", fmt.Errorf("parsing json: %w", err)
+		}
+
+		var err error
+		bundleSigner, err = c.verifyBundle(filename, data, rawData)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("verifying config bundle: %w", err)
+		}
+
+		if err := overlayData(next, rawData); err != nil {
+			return nil, nil, "", err
+		}
 	}
 
-	if err := c.validate(); err != nil {
-		return err
+	applyOverrides(next, overrides)
+
+	refs, err := resolveReferences(next)
+	if err != nil {
+		return nil, nil, "", err
 	}
 
-	c.loaded = true
-	return nil
+	if err := validate(next); err != nil {
+		return nil, nil, "", err
+	}
+
+	if err := checkBindings(next); err != nil {
+		return nil, nil, "", err
+	}
+
+	return next, refs, bundleSigner, nil
 }
 
-func (c *Config) loadDefaults() {
+func defaultData() map[string]map[string]interface{} {
+	data := make(map[string]map[string]interface{}, len(registry))
 	for section, schema := range registry {
-		if c.data[section] == nil {
-			c.data[section] = make(map[string]interface{})
-		}
+		data[section] = make(map[string]interface{}, len(schema))
 		for field, def := range schema {
 			if def.Default != nil {
-				c.data[section][field] = def.Default
+				data[section][field] = def.Default
 			}
 		}
 	}
+	return data
 }
 
-func (c *Config) overlayData(rawData map[string]interface{}) error {
+func overlayData(data map[string]map[string]interface{}, rawData map[string]interface{}) error {
 	for section, sectionData := range rawData {
 		if _, ok := registry[section]; !ok {
 			continue
@@ -110,23 +229,32 @@ func (c *Config) overlayData(rawData map[string]interface{}) error {
 			return fmt.Errorf("section %s must be an object", section)
 		}
 
-		if c.data[section] == nil {
-			c.data[section] = make(map[string]interface{})
-		}
-
 		for field, value := range sectionMap {
-			old := c.data[section][field]
-			c.data[section][field] = value
-			c.notifyListeners(section, field, old, value)
+			data[section][field] = value
 		}
 	}
 	return nil
 }
 
-func (c *Config) validate() error {
+// ValidationError reports a field its Validator rejected.
+type ValidationError struct {
+	Section string
+	Field   string
+	Err     error
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed for %s.%s: %v", e.Section, e.Field, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+func validate(data map[string]map[string]interface{}) error {
 	for section, schema := range registry {
 		for field, def := range schema {
-			value := c.data[section][field]
+			value := data[section][field]
 
 			if def.Required && (value == nil || value == "") {
 				return fmt.Errorf("required field missing: %s.%s", section, field)
@@ -134,7 +262,7 @@ func (c *Config) validate() error {
 
 			if def.Validator != nil && value != nil {
 				if err := def.Validator(value); err != nil {
-					return fmt.Errorf("validation failed for %s.%s: %w", section, field, err)
+					return &ValidationError{Section: section, Field: field, Err: err}
 				}
 			}
 		}
@@ -143,6 +271,8 @@ func (c *Config) validate() error {
 }
 
 func (c *Config) Get(section, key string) interface{} {
+	recordRead(section, key)
+
 	mu.RLock()
 	defer mu.RUnlock()
 
@@ -214,6 +344,7 @@ func (c *Config) GetSection(section string) map[string]interface{} {
 		copy := make(map[string]interface{})
 		for k, v := range s {
 			copy[k] = v
+			recordRead(section, k)
 		}
 		return copy
 	}
@@ -271,12 +402,15 @@ func (c *Config) Set(section, key string, value interface{}) {
 func (c *Config) AddListener(listener func(section, key string, value interface{})) {
 	mu.Lock()
 	defer mu.Unlock()
-	c.listeners = append(c.listeners, listener)
+	c.listeners = append(c.listeners, newListener(listener))
 }
 
+// notifyListeners queues the change for every listener; callbacks run
+// asynchronously, after the caller has released mu.
 func (c *Config) notifyListeners(section, key string, oldValue, newValue interface{}) {
-	for _, listener := range c.listeners {
-		listener(section, key, newValue)
+	n := notification{section: section, key: key, value: newValue}
+	for _, l := range c.listeners {
+		l.notify(n)
 	}
 }
 
@@ -287,27 +421,6 @@ func (c *Config) Reload() error {
 	return c.LoadFile(c.filename)
 }
 
-func (c *Config) Watch(interval time.Duration) {
-	if c.filename == "" {
-		return
-	}
-
-	go func() {
-		lastMod := time.Now()
-		for {
-			time.Sleep(interval)
-			stat, err := os.Stat(c.filename)
-			if err != nil {
-				continue
-			}
-			if stat.ModTime().After(lastMod) {
-				lastMod = stat.ModTime()
-				c.Reload()
-			}
-		}
-	}()
-}
-
 func GenerateTemplate() ([]byte, error) {
 	mu.RLock()
 	defer mu.RUnlock()
@@ -317,7 +430,13 @@ func GenerateTemplate() ([]byte, error) {
 	for section, schema := range registry {
 		template[section] = make(map[string]interface{})
 		for field, def := range schema {
-			template[section][field] = def.Default
+			value := def.Default
+			if def.Secret && value != nil && value != "" {
+				// Left blank rather than masked, since the template is
+				// loaded as-is on first run
+				value = ""
+			}
+			template[section][field] = value
 		}
 	}
 
@@ -347,6 +466,8 @@ func (c *Config) MustGet(section, key string) interface{} {
 }
 
 func (c *Config) Exists(section, key string) bool {
+	recordRead(section, key)
+
 	mu.RLock()
 	defer mu.RUnlock()
 
@@ -356,3 +477,32 @@ func (c *Config) Exists(section, key string) bool {
 	}
 	return false
 }
+
+var sensitiveKeyParts = []string{"password", "secret", "token", "seed", "private", "mnemonic"}
+
+// Redacted returns a copy of all sections with secret values masked and
+// resolved references shown as written, suitable for exposing over an
+// admin endpoint or in logs.
+func (c *Config) Redacted() map[string]map[string]interface{} {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	result := make(map[string]map[string]interface{}, len(c.data))
+	for section, values := range c.data {
+		result[section] = make(map[string]interface{}, len(values))
+		for key, value := range values {
+			result[section][key] = c.redact(section, key, value)
+		}
+	}
+	return result
+}
+
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(lower, part) {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/polkadot-go/helper/core"
 )
@@ -40,33 +41,121 @@ func (c *configComponent) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+func (c *configComponent) Get(section, key string) interface{} {
+	return Get().Get(section, key)
+}
+
+func (c *configComponent) GetString(section, key string) string {
+	return Get().GetString(section, key)
+}
+
+func (c *configComponent) GetInt(section, key string) int {
+	return Get().GetInt(section, key)
+}
+
+func (c *configComponent) GetDuration(section, key string) time.Duration {
+	return Get().GetDuration(section, key)
+}
+
+func (c *configComponent) RedactedSection(section string) map[string]interface{} {
+	return Get().Redacted()[section]
+}
+
 var component = &configComponent{}
 
+func validLogLevel(level string) error {
+	switch level {
+	case "debug", "info", "warn", "error":
+		return nil
+	}
+	return fmt.Errorf("invalid log_level: %s", level)
+}
+
 func init() {
 	Register("config", Schema{
 		"log_level": Field{
 			Default:     "info",
 			Required:    false,
-			Description: "Logging level",
+			Description: "Logging level, or levels by logger name with \"*\" for the rest, e.g. {\"mysql\": \"debug\", \"*\": \"info\"}",
+			Validator: func(v interface{}) error {
+				switch val := v.(type) {
+				case string:
+					return validLogLevel(val)
+				case map[string]interface{}:
+					for name, level := range val {
+						s, ok := level.(string)
+						if !ok {
+							return fmt.Errorf("log_level.%s must be string", name)
+						}
+						if err := validLogLevel(s); err != nil {
+							return fmt.Errorf("log_level.%s: %w", name, err)
+						}
+					}
+					return nil
+				}
+				return fmt.Errorf("log_level must be string or object")
+			},
+		},
+		"log_format": Field{
+			Default:     "text",
+			Required:    false,
+			Description: "Log output format (text or json)",
 			Validator: func(v interface{}) error {
-				level, ok := v.(string)
+				format, ok := v.(string)
 				if !ok {
-					return fmt.Errorf("log_level must be string")
+					return fmt.Errorf("log_format must be string")
 				}
-				valid := []string{"debug", "info", "warn", "error"}
-				for _, vl := range valid {
-					if level == vl {
-						return nil
-					}
+				if format != "text" && format != "json" {
+					return fmt.Errorf("invalid log_format: %s", format)
 				}
-				return fmt.Errorf("invalid log_level: %s", level)
+				return nil
 			},
 		},
+		"log_outputs": Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "Log sinks (stdout, stderr, syslog[:tag], file:<path>) as a list, or an object mapping logger names (\"*\" for all) to lists",
+		},
+		"log_file_max_size": Field{
+			Default:     100,
+			Required:    false,
+			Description: "Rotate file sinks after this many megabytes (0 disables)",
+		},
+		"log_file_max_age": Field{
+			Default:     "0s",
+			Required:    false,
+			Description: "Rotate file sinks after this duration (0 disables)",
+		},
+		"log_file_max_backups": Field{
+			Default:     7,
+			Required:    false,
+			Description: "Rotated log files to keep (0 keeps all)",
+		},
+		"health_interval": Field{
+			Default:     "15s",
+			Required:    false,
+			Description: "Background health check interval (0 disables the monitor)",
+		},
+		"health_timeout": Field{
+			Default:     "5s",
+			Required:    false,
+			Description: "Per-check timeout for background health checks",
+		},
 		"environment": Field{
 			Default:     "development",
 			Required:    false,
 			Description: "Environment",
 		},
+		"init_timeout": Field{
+			Default:     "30s",
+			Required:    false,
+			Description: "Per-component initialization timeout (0 disables)",
+		},
+		"init_timeouts": Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Initialization timeout overrides keyed by component name",
+		},
 		"shutdown_timeout": Field{
 			Default:     "30s",
 			Required:    false,
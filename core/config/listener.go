@@ -0,0 +1,57 @@
+// config/listener.go
+package config
+
+import (
+	"github.com/polkadot-go/helper/core"
+)
+
+const listenerQueueSize = 64
+
+type notification struct {
+	section string
+	key     string
+	value   interface{}
+}
+
+// listener delivers notifications to its callback from its own goroutine,
+// so callbacks may call back into Config and a slow or panicking callback
+// cannot stall writers or other listeners.
+type listener struct {
+	fn    func(section, key string, value interface{})
+	queue chan notification
+}
+
+func newListener(fn func(section, key string, value interface{})) *listener {
+	l := &listener{
+		fn:    fn,
+		queue: make(chan notification, listenerQueueSize),
+	}
+	go l.run()
+	return l
+}
+
+func (l *listener) run() {
+	for n := range l.queue {
+		l.deliver(n)
+	}
+}
+
+func (l *listener) deliver(n notification) {
+	defer func() {
+		if r := recover(); r != nil {
+			core.IncrCounter("config.listener_panics")
+			core.GetLogger("config").Error("Config listener panicked on %s.%s: %v", n.section, n.key, r)
+		}
+	}()
+	l.fn(n.section, n.key, n.value)
+}
+
+// notify never blocks; when the queue is full the notification is dropped
+// and counted.
+func (l *listener) notify(n notification) {
+	select {
+	case l.queue <- n:
+	default:
+		core.IncrCounter("config.listener_dropped")
+	}
+}
@@ -0,0 +1,329 @@
+// config/patch.go
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+var ErrPatchRefused = errors.New("config patch refused")
+
+// PatchResult describes an applied patch.
+type PatchResult struct {
+	Changed   []string `json:"changed"`
+	Persisted bool     `json:"persisted"`
+}
+
+// ApplyPatch applies a JSON merge patch (RFC 7396) of sections to the
+// running config. A null removes a key or section from the config, so it
+// falls back to its default. The result is validated in full first; a
+// patch that fails leaves everything as it was.
+//
+// With persist the patch is merged into the config file, which is
+// rewritten as plain indented JSON. Otherwise the patch is ephemeral: it
+// survives reloads of the file but not a restart. Patches are refused
+// while config bundles are signed, since they would bypass the signature.
+func (c *Config) ApplyPatch(patch map[string]interface{}, persist bool) (*PatchResult, error) {
+	mu.Lock()
+
+	if err := checkPatch(patch); err != nil {
+		mu.Unlock()
+		return nil, err
+	}
+	if trustFile != "" {
+		mu.Unlock()
+		return nil, fmt.Errorf("%w: config bundles must be signed", ErrPatchRefused)
+	}
+	if persist && c.filename == "" {
+		mu.Unlock()
+		return nil, fmt.Errorf("%w: no config file to persist to", ErrPatchRefused)
+	}
+
+	var (
+		overrides map[string]interface{}
+		data      []byte
+		err       error
+	)
+	if persist {
+		// The file now holds these keys, so ephemeral overrides of them
+		// would only hide the persisted values
+		overrides = withoutPatched(c.overrides, patch)
+		data, err = patchedFile(c.filename, patch)
+	} else {
+		overrides, _ = composePatch(c.overrides, patch).(map[string]interface{})
+		data, err = os.ReadFile(c.filename)
+		if os.IsNotExist(err) {
+			data, err = nil, nil
+		}
+	}
+	if err != nil {
+		mu.Unlock()
+		return nil, err
+	}
+
+	next, refs, _, err := c.build(c.filename, data, overrides)
+	if err != nil {
+		mu.Unlock()
+		core.IncrCounterWithLabels("config.patches", map[string]string{"result": "rejected"})
+		// Validator messages may quote the value, which the caller should
+		// not be able to read back through a patch
+		var invalid *ValidationError
+		if errors.As(err, &invalid) {
+			return nil, fmt.Errorf("validation failed for %s.%s", invalid.Section, invalid.Field)
+		}
+		return nil, err
+	}
+	if persist {
+		if err := writeFileAtomic(c.filename, data); err != nil {
+			mu.Unlock()
+			return nil, fmt.Errorf("persisting config: %w", err)
+		}
+	}
+
+	c.overrides = overrides
+	old, changed := c.swap(next, refs)
+	notice := newReloadNotice(changed, old, next)
+	redacted := c.redactPatch(patch)
+	mu.Unlock()
+
+	result := &PatchResult{Changed: make([]string, 0, len(changed)), Persisted: persist}
+	for section := range changed {
+		result.Changed = append(result.Changed, section)
+	}
+	sort.Strings(result.Changed)
+
+	core.IncrCounterWithLabels("config.patches", map[string]string{"result": "applied"})
+	core.PublishEvent("config.patched", map[string]interface{}{
+		"sections":  result.Changed,
+		"persisted": persist,
+		"patch":     redacted,
+	})
+	notice.notify()
+	return result, nil
+}
+
+// Overrides returns the ephemeral patches in effect, merged into one, with
+// secret values masked.
+func (c *Config) Overrides() map[string]interface{} {
+	mu.RLock()
+	defer mu.RUnlock()
+	return c.redactPatch(c.overrides)
+}
+
+// ClearOverrides drops every ephemeral patch and reloads the file.
+func (c *Config) ClearOverrides() error {
+	mu.Lock()
+	c.overrides = nil
+	filename := c.filename
+	mu.Unlock()
+	return c.LoadFile(filename)
+}
+
+// CheckPatch reports whether ApplyPatch would accept the shape of patch,
+// without validating its values.
+func (c *Config) CheckPatch(patch map[string]interface{}) error {
+	mu.RLock()
+	defer mu.RUnlock()
+	return checkPatch(patch)
+}
+
+// RedactPatch returns patch with secret values masked.
+func (c *Config) RedactPatch(patch map[string]interface{}) map[string]interface{} {
+	mu.RLock()
+	defer mu.RUnlock()
+	return c.redactPatch(patch)
+}
+
+// checkPatch only accepts declared sections and fields, and refuses
+// secret references: resolving them on behalf of whoever sent the patch
+// would let them read files, environment variables or Vault secrets. It
+// must be called with mu held.
+func checkPatch(patch map[string]interface{}) error {
+	if len(patch) == 0 {
+		return fmt.Errorf("%w: empty patch", ErrPatchRefused)
+	}
+	for section, value := range patch {
+		schema, ok := registry[section]
+		if !ok {
+			return fmt.Errorf("%w: unknown section %s", ErrPatchRefused, section)
+		}
+		if value == nil {
+			continue
+		}
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: section %s must be an object or null", ErrPatchRefused, section)
+		}
+		for field, v := range fields {
+			if _, ok := schema[field]; !ok {
+				return fmt.Errorf("%w: unknown field %s.%s", ErrPatchRefused, section, field)
+			}
+			if s, ok := v.(string); ok && IsReference(s) {
+				return fmt.Errorf("%w: %s.%s is a secret reference", ErrPatchRefused, section, field)
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Config) redactPatch(patch map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(patch))
+	for section, value := range patch {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			result[section] = value
+			continue
+		}
+		masked := make(map[string]interface{}, len(fields))
+		for field, v := range fields {
+			if isSecret(section, field) && v != nil && v != "" {
+				v = redactedValue
+			}
+			masked[field] = v
+		}
+		result[section] = masked
+	}
+	return result
+}
+
+// applyOverrides merges overrides into data as a merge patch, restoring
+// defaults for removed fields.
+func applyOverrides(data map[string]map[string]interface{}, overrides map[string]interface{}) {
+	for section, value := range overrides {
+		merged, _ := mergePatch(data[section], value).(map[string]interface{})
+		if merged == nil {
+			merged = make(map[string]interface{})
+		}
+		for field, def := range registry[section] {
+			if _, ok := merged[field]; !ok && def.Default != nil {
+				merged[field] = def.Default
+			}
+		}
+		data[section] = merged
+	}
+}
+
+// patchedFile returns the config file's contents with patch merged in.
+func patchedFile(filename string, patch map[string]interface{}) ([]byte, error) {
+	raw := make(map[string]interface{})
+	data, err := os.ReadFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("parsing json: %w", err)
+		}
+	}
+	return json.MarshalIndent(mergePatch(raw, patch), "", "  ")
+}
+
+func writeFileAtomic(filename string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// mergePatch applies patch to target per RFC 7396 without modifying
+// either.
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, _ := target.(map[string]interface{})
+	result := make(map[string]interface{}, len(t)+len(p))
+	for k, v := range t {
+		result[k] = v
+	}
+	for k, v := range p {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		result[k] = mergePatch(result[k], v)
+	}
+	return result
+}
+
+// composePatch combines two merge patches into one with the effect of
+// applying a and then b. Unlike mergePatch it keeps nulls.
+func composePatch(a, b interface{}) interface{} {
+	bm, ok := b.(map[string]interface{})
+	if !ok {
+		return b
+	}
+	am, _ := a.(map[string]interface{})
+	result := make(map[string]interface{}, len(am)+len(bm))
+	for k, v := range am {
+		result[k] = v
+	}
+	for k, v := range bm {
+		if prev, ok := result[k].(map[string]interface{}); ok && v != nil {
+			result[k] = composePatch(prev, v)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
+
+// withoutPatched returns overrides minus the fields patch sets.
+func withoutPatched(overrides, patch map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(overrides))
+	for section, value := range overrides {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			result[section] = value
+			continue
+		}
+		kept := make(map[string]interface{}, len(fields))
+		for field, v := range fields {
+			kept[field] = v
+		}
+		result[section] = kept
+	}
+
+	for section, value := range patch {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			delete(result, section)
+			continue
+		}
+		if kept, ok := result[section].(map[string]interface{}); ok {
+			for field := range fields {
+				delete(kept, field)
+			}
+		}
+	}
+	return result
+}
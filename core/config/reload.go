@@ -0,0 +1,88 @@
+// config/reload.go
+package config
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// ReloadFunc receives a section's values before and after a reload that
+// changed it. The maps are copies and may be kept.
+type ReloadFunc func(old, new map[string]interface{})
+
+var (
+	reloadMu       sync.Mutex
+	reloadHandlers = make(map[string][]ReloadFunc)
+)
+
+// OnReload registers fn to run after each successful reload that changes
+// section. Handlers run in registration order on the reloading goroutine,
+// after the new config is in place, so they can read it through Get.
+func OnReload(section string, fn ReloadFunc) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHandlers[section] = append(reloadHandlers[section], fn)
+}
+
+// reloadNotice holds copies of the sections a reload changed. It is taken
+// with mu held, since once mu is released Set may write to the live
+// section maps, and delivered after.
+type reloadNotice struct {
+	sections  []string
+	old, next map[string]map[string]interface{}
+}
+
+// newReloadNotice must be called with mu held.
+func newReloadNotice(changed map[string]bool, old, next map[string]map[string]interface{}) *reloadNotice {
+	n := &reloadNotice{
+		sections: make([]string, 0, len(changed)),
+		old:      make(map[string]map[string]interface{}, len(changed)),
+		next:     make(map[string]map[string]interface{}, len(changed)),
+	}
+	for section := range changed {
+		n.sections = append(n.sections, section)
+		n.old[section] = copySection(old[section])
+		n.next[section] = copySection(next[section])
+	}
+	sort.Strings(n.sections)
+	return n
+}
+
+// notify runs the reload handlers. Called without mu held.
+func (n *reloadNotice) notify() {
+	if len(n.sections) > 0 {
+		core.GetLogger("config").Info("Config reloaded, changed sections: %v", n.sections)
+		core.ConfigReloaded.Publish(core.ConfigReload{Sections: n.sections})
+	}
+
+	for _, section := range n.sections {
+		reloadMu.Lock()
+		handlers := append([]ReloadFunc{}, reloadHandlers[section]...)
+		reloadMu.Unlock()
+
+		// Each handler gets its own copies, as it may keep them
+		for _, fn := range handlers {
+			runReloadHandler(section, fn, copySection(n.old[section]), copySection(n.next[section]))
+		}
+	}
+}
+
+func runReloadHandler(section string, fn ReloadFunc, old, new map[string]interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			core.IncrCounter("config.reload_handler_panics")
+			core.GetLogger("config").Error("Reload handler for %s panicked: %v", section, r)
+		}
+	}()
+	fn(old, new)
+}
+
+func copySection(values map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		result[k] = v
+	}
+	return result
+}
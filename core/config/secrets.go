@@ -0,0 +1,117 @@
+// config/secrets.go
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+const redactedValue = "******"
+
+// Resolver returns the value a "<scheme>:<ref>" config string refers to.
+type Resolver func(ref string) (string, error)
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = map[string]Resolver{
+		"env":  resolveEnv,
+		"file": resolveFile,
+	}
+)
+
+// RegisterResolver adds a reference scheme, such as "vault", alongside the
+// built-in env: and file:.
+func RegisterResolver(scheme string, r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	resolvers[scheme] = r
+}
+
+// IsReference reports whether s has the form "<scheme>:<ref>" for a
+// registered scheme.
+func IsReference(s string) bool {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	scheme, _, ok := strings.Cut(s, ":")
+	return ok && resolvers[scheme] != nil
+}
+
+func resolveEnv(name string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return v, nil
+}
+
+func resolveFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// resolveReferences replaces top-level string values of the form
+// "<scheme>:<ref>" for a registered scheme with what they refer to. It
+// returns the references it resolved so they can be shown in place of the
+// secrets they point to.
+func resolveReferences(data map[string]map[string]interface{}) (map[string]map[string]string, error) {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+
+	refs := make(map[string]map[string]string)
+	for section, values := range data {
+		for key, value := range values {
+			s, ok := value.(string)
+			if !ok {
+				continue
+			}
+			scheme, ref, ok := strings.Cut(s, ":")
+			resolve := resolvers[scheme]
+			if !ok || resolve == nil {
+				continue
+			}
+
+			resolved, err := resolve(ref)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", section, key, err)
+			}
+			values[key] = resolved
+			if refs[section] == nil {
+				refs[section] = make(map[string]string)
+			}
+			refs[section][key] = s
+		}
+	}
+	return refs, nil
+}
+
+// isSecret reports fields declared Secret, and undeclared ones whose names
+// look sensitive.
+func isSecret(section, key string) bool {
+	if field, ok := registry[section][key]; ok && field.Secret {
+		return true
+	}
+	return isSensitiveKey(key)
+}
+
+// RedactValue masks value if section.key is secret, for callers that log
+// or display individual config values.
+func (c *Config) RedactValue(section, key string, value interface{}) interface{} {
+	mu.RLock()
+	defer mu.RUnlock()
+	return c.redact(section, key, value)
+}
+
+func (c *Config) redact(section, key string, value interface{}) interface{} {
+	if ref, ok := c.refs[section][key]; ok {
+		return ref
+	}
+	if isSecret(section, key) && value != nil && value != "" {
+		return redactedValue
+	}
+	return value
+}
@@ -0,0 +1,142 @@
+// config/signing.go
+package config
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// A signed bundle is a config file accompanied by <file>.sig holding the
+// base64 ed25519 signature of the file's exact bytes. The trust file is a
+// JSON object mapping operator names to base64 ed25519 public keys.
+
+var (
+	ErrUnsignedConfig   = errors.New("config bundle is not signed")
+	ErrInvalidSignature = errors.New("config bundle signature does not match any trusted key")
+)
+
+var (
+	trustFile     string
+	requireSigned bool
+	signer        string
+)
+
+// SetTrustFile enables signature verification of config bundles against
+// the operator keys in path.
+func SetTrustFile(path string) {
+	mu.Lock()
+	defer mu.Unlock()
+	trustFile = path
+}
+
+// RequireSignedConfig refuses unsigned bundles regardless of environment.
+// Unsigned bundles are always refused in production once a trust file is
+// set.
+func RequireSignedConfig(required bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	requireSigned = required
+}
+
+// Signer returns the operator that signed the loaded config, or "" when it
+// was unsigned.
+func (c *Config) Signer() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return signer
+}
+
+func SignatureFile(filename string) string {
+	return filename + ".sig"
+}
+
+// SignBundle writes the signature file for filename using key.
+func SignBundle(filename string, key ed25519.PrivateKey) error {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(key, content))
+	return os.WriteFile(SignatureFile(filename), []byte(sig+"\n"), 0644)
+}
+
+func loadTrustedKeys(path string) (map[string]ed25519.PublicKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading trust file: %w", err)
+	}
+
+	raw := make(map[string]string)
+	if err := json.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("parsing trust file: %w", err)
+	}
+
+	keys := make(map[string]ed25519.PublicKey, len(raw))
+	for name, encoded := range raw {
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("trust file: invalid key for %s", name)
+		}
+		keys[name] = ed25519.PublicKey(key)
+	}
+	return keys, nil
+}
+
+// verifyBundle checks the signature of content when a trust file is set
+// and returns the signer. Called with mu held.
+func (c *Config) verifyBundle(filename string, content []byte, rawData map[string]interface{}) (string, error) {
+	if trustFile == "" {
+		return "", nil
+	}
+
+	keys, err := loadTrustedKeys(trustFile)
+	if err != nil {
+		return "", err
+	}
+
+	sigData, err := os.ReadFile(SignatureFile(filename))
+	if os.IsNotExist(err) {
+		if requireSigned || c.isProduction(rawData) {
+			return "", ErrUnsignedConfig
+		}
+		core.GetLogger("config").Warn("Applying unsigned config %s", filename)
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading signature: %w", err)
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sigData)))
+	if err != nil {
+		return "", fmt.Errorf("decoding signature: %w", err)
+	}
+
+	for name, key := range keys {
+		if ed25519.Verify(key, content, sig) {
+			return name, nil
+		}
+	}
+	return "", ErrInvalidSignature
+}
+
+// isProduction reports whether either the running or the incoming config
+// declares the production environment, so an unsigned bundle cannot
+// downgrade the environment to slip past verification.
+func (c *Config) isProduction(rawData map[string]interface{}) bool {
+	if env, _ := c.data["config"]["environment"].(string); env == "production" {
+		return true
+	}
+	if section, ok := rawData["config"].(map[string]interface{}); ok {
+		if env, _ := section["environment"].(string); env == "production" {
+			return true
+		}
+	}
+	return false
+}
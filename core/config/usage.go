@@ -0,0 +1,76 @@
+// config/usage.go
+package config
+
+import (
+	"sort"
+	"sync"
+)
+
+var (
+	usageMu sync.Mutex
+	reads   = make(map[string]int)
+)
+
+func recordRead(section, key string) {
+	usageMu.Lock()
+	reads[section+"."+key]++
+	usageMu.Unlock()
+}
+
+// UsageReport describes how the running process has used its config.
+// Keys are written as section.key.
+type UsageReport struct {
+	Reads      map[string]int `json:"reads"`
+	Unused     []string       `json:"unused"`
+	Undeclared []string       `json:"undeclared"`
+	Unknown    []string       `json:"unknown"`
+}
+
+// Usage reports which keys have been read, which loaded keys were never
+// read (unused), which reads targeted keys no schema declares
+// (undeclared), and which keys are set but not declared (unknown).
+func (c *Config) Usage() UsageReport {
+	mu.RLock()
+	declared := make(map[string]bool)
+	for section, schema := range registry {
+		for field := range schema {
+			declared[section+"."+field] = true
+		}
+	}
+	loaded := make(map[string]bool)
+	for section, values := range c.data {
+		for key := range values {
+			loaded[section+"."+key] = true
+		}
+	}
+	mu.RUnlock()
+
+	usageMu.Lock()
+	report := UsageReport{
+		Reads:      make(map[string]int, len(reads)),
+		Unused:     []string{},
+		Undeclared: []string{},
+		Unknown:    []string{},
+	}
+	for key, n := range reads {
+		report.Reads[key] = n
+		if !declared[key] {
+			report.Undeclared = append(report.Undeclared, key)
+		}
+	}
+	usageMu.Unlock()
+
+	for key := range loaded {
+		if report.Reads[key] == 0 {
+			report.Unused = append(report.Unused, key)
+		}
+		if !declared[key] {
+			report.Unknown = append(report.Unknown, key)
+		}
+	}
+
+	sort.Strings(report.Unused)
+	sort.Strings(report.Undeclared)
+	sort.Strings(report.Unknown)
+	return report
+}
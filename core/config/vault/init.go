@@ -0,0 +1,107 @@
+// core/config/vault/init.go
+package vault
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+)
+
+var (
+	instance *Client
+	once     sync.Once
+	initErr  error
+)
+
+// Get returns the client built from the environment, or nil when VAULT_ADDR
+// is not set.
+func Get() *Client {
+	client, _ := defaultClient()
+	return client
+}
+
+func defaultClient() (*Client, error) {
+	once.Do(func() {
+		if os.Getenv("VAULT_ADDR") == "" {
+			return
+		}
+		instance, initErr = FromEnv()
+	})
+	return instance, initErr
+}
+
+// resolve backs "vault:<path>#<field>" config references. The client is
+// configured from the environment, since references are resolved while the
+// config file itself is loading.
+func resolve(ref string) (string, error) {
+	client, err := defaultClient()
+	if err != nil {
+		return "", err
+	}
+	if client == nil {
+		return "", fmt.Errorf("vault reference %q but VAULT_ADDR is not set", ref)
+	}
+	return client.Resolve(ref)
+}
+
+type vaultComponent struct{}
+
+func (c *vaultComponent) Name() string {
+	return "vault"
+}
+
+func (c *vaultComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *vaultComponent) Init() error {
+	client, err := defaultClient()
+	if err != nil || client == nil {
+		return err
+	}
+
+	cfg := config.Get()
+	if interval := cfg.GetDuration("vault", "refresh_interval"); interval > 0 {
+		client.opts.RefreshInterval = interval
+	}
+	if cfg.GetBool("vault", "reload_on_rotate") {
+		// Reloading resolves the references again from the refreshed cache
+		// and runs config.OnReload handlers for the sections that changed
+		client.OnRotate(func(paths []string) {
+			if err := config.Get().Reload(); err != nil {
+				client.logger.Error("Reloading config after Vault rotation: %v", err)
+			}
+		})
+	}
+	client.Start()
+	return nil
+}
+
+func (c *vaultComponent) Shutdown(ctx context.Context) error {
+	if instance != nil {
+		instance.Stop()
+	}
+	return nil
+}
+
+func init() {
+	config.Register("vault", config.Schema{
+		"refresh_interval": config.Field{
+			Default:     "5m",
+			Required:    false,
+			Description: "How often unleased Vault secrets, such as KV entries, are re-read to detect rotation",
+		},
+		"reload_on_rotate": config.Field{
+			Default:     true,
+			Required:    false,
+			Description: "Reload the config when a referenced Vault secret rotates, so components reconnect with new credentials",
+		},
+	})
+
+	config.RegisterResolver("vault", resolve)
+	core.Register(&vaultComponent{})
+}
@@ -0,0 +1,520 @@
+// core/config/vault/vault.go
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// Options configures a Client. Token takes precedence; otherwise RoleID and
+// SecretID log in through AppRole.
+type Options struct {
+	Address   string
+	Namespace string
+	Token     string
+	RoleID    string
+	SecretID  string
+	// AppRoleMount is the AppRole auth mount, "approle" by default
+	AppRoleMount string
+	// RefreshInterval is how often unleased secrets, such as KV entries,
+	// are re-read to detect rotation
+	RefreshInterval time.Duration
+	Timeout         time.Duration
+}
+
+// Client reads secrets from the Vault HTTP API, keeps its token and the
+// leases of secrets it has read alive, and reports rotated secrets.
+type Client struct {
+	opts   Options
+	http   *http.Client
+	logger core.Logger
+
+	mu         sync.Mutex
+	token      string
+	tokenTTL   time.Duration
+	renewable  bool
+	tokenSince time.Time
+	secrets    map[string]*secret
+	onRotate   []func(paths []string)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+type secret struct {
+	data      map[string]interface{}
+	leaseID   string
+	leaseTTL  time.Duration
+	renewable bool
+	fetched   time.Time
+}
+
+type response struct {
+	LeaseID       string                 `json:"lease_id"`
+	LeaseDuration int                    `json:"lease_duration"`
+	Renewable     bool                   `json:"renewable"`
+	Data          map[string]interface{} `json:"data"`
+	Auth          *struct {
+		ClientToken   string `json:"client_token"`
+		LeaseDuration int    `json:"lease_duration"`
+		Renewable     bool   `json:"renewable"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+func New(opts Options) (*Client, error) {
+	if opts.Address == "" {
+		return nil, fmt.Errorf("vault address is required")
+	}
+	if opts.Token == "" && (opts.RoleID == "" || opts.SecretID == "") {
+		return nil, fmt.Errorf("vault token or AppRole role_id and secret_id are required")
+	}
+	if opts.AppRoleMount == "" {
+		opts.AppRoleMount = "approle"
+	}
+	if opts.RefreshInterval <= 0 {
+		opts.RefreshInterval = 5 * time.Minute
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = 10 * time.Second
+	}
+	opts.Address = strings.TrimRight(opts.Address, "/")
+
+	return &Client{
+		opts:    opts,
+		http:    &http.Client{Timeout: opts.Timeout},
+		logger:  core.GetLogger("vault"),
+		token:   opts.Token,
+		secrets: make(map[string]*secret),
+	}, nil
+}
+
+// FromEnv builds a client from the standard VAULT_ADDR, VAULT_NAMESPACE and
+// VAULT_TOKEN variables, or VAULT_ROLE_ID and VAULT_SECRET_ID for AppRole.
+// VAULT_TOKEN_FILE and VAULT_SECRET_ID_FILE read those from files instead.
+func FromEnv() (*Client, error) {
+	token, err := envOrFile("VAULT_TOKEN")
+	if err != nil {
+		return nil, err
+	}
+	secretID, err := envOrFile("VAULT_SECRET_ID")
+	if err != nil {
+		return nil, err
+	}
+	return New(Options{
+		Address:      os.Getenv("VAULT_ADDR"),
+		Namespace:    os.Getenv("VAULT_NAMESPACE"),
+		Token:        token,
+		RoleID:       os.Getenv("VAULT_ROLE_ID"),
+		SecretID:     secretID,
+		AppRoleMount: os.Getenv("VAULT_APPROLE_MOUNT"),
+	})
+}
+
+func envOrFile(name string) (string, error) {
+	if v := os.Getenv(name); v != "" {
+		return v, nil
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		return "", nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("reading %s_FILE: %w", name, err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// Resolve returns one field of a secret, given as "<path>#<field>", e.g.
+// "secret/data/mysql#password" for KV version 2. Secrets are cached, so
+// every reference to the same path sees the same version.
+func (c *Client) Resolve(ref string) (string, error) {
+	path, field, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || field == "" {
+		return "", fmt.Errorf("vault reference %q must be <path>#<field>", ref)
+	}
+
+	c.mu.Lock()
+	s := c.secrets[path]
+	c.mu.Unlock()
+
+	if s == nil {
+		var err error
+		if s, err = c.read(context.Background(), path); err != nil {
+			return "", err
+		}
+		c.mu.Lock()
+		if cached := c.secrets[path]; cached != nil {
+			s = cached
+		} else {
+			c.secrets[path] = s
+		}
+		c.mu.Unlock()
+	}
+
+	value, ok := s.data[field]
+	if !ok {
+		return "", fmt.Errorf("vault secret %s has no field %s", path, field)
+	}
+	if str, ok := value.(string); ok {
+		return str, nil
+	}
+	return fmt.Sprint(value), nil
+}
+
+// OnRotate registers fn to run with the paths whose data changed, after the
+// cache holds the new values.
+func (c *Client) OnRotate(fn func(paths []string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onRotate = append(c.onRotate, fn)
+}
+
+// Start renews the token and secret leases in the background and re-reads
+// secrets when their leases cannot be renewed or, for unleased secrets,
+// every RefreshInterval.
+func (c *Client) Start() {
+	c.mu.Lock()
+	if c.stop != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+	c.mu.Unlock()
+
+	go func() {
+		defer close(c.done)
+		core.Supervise("vault", c.stop, c.loop)
+	}()
+}
+
+func (c *Client) Stop() {
+	c.mu.Lock()
+	stop, done := c.stop, c.done
+	c.stop = nil
+	c.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
+func (c *Client) loop() {
+	c.mu.Lock()
+	stop := c.stop
+	c.mu.Unlock()
+
+	ticker := time.NewTicker(c.tick())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), c.opts.Timeout*4)
+			c.maintain(ctx)
+			cancel()
+		}
+	}
+}
+
+// tick is frequent enough to renew the shortest lease at two thirds of its
+// duration.
+func (c *Client) tick() time.Duration {
+	interval := c.opts.RefreshInterval
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	shorten := func(ttl time.Duration) {
+		if ttl > 0 && ttl/3 < interval {
+			interval = ttl / 3
+		}
+	}
+	shorten(c.tokenTTL)
+	for _, s := range c.secrets {
+		shorten(s.leaseTTL)
+	}
+	if interval < time.Second {
+		interval = time.Second
+	}
+	return interval
+}
+
+func (c *Client) maintain(ctx context.Context) {
+	if err := c.renewToken(ctx); err != nil {
+		core.IncrCounterWithLabels("vault.errors", map[string]string{"op": "renew_token"})
+		c.logger.Warn("Renewing Vault token: %v", err)
+	}
+
+	c.mu.Lock()
+	paths := make([]string, 0, len(c.secrets))
+	for path := range c.secrets {
+		paths = append(paths, path)
+	}
+	c.mu.Unlock()
+	sort.Strings(paths)
+
+	var rotated []string
+	for _, path := range paths {
+		changed, err := c.refresh(ctx, path)
+		if err != nil {
+			core.IncrCounterWithLabels("vault.errors", map[string]string{"op": "refresh"})
+			c.logger.Warn("Refreshing Vault secret %s: %v", path, err)
+			continue
+		}
+		if changed {
+			rotated = append(rotated, path)
+		}
+	}
+
+	if len(rotated) == 0 {
+		return
+	}
+	core.IncrCounter("vault.rotations")
+	c.logger.Info("Vault secrets rotated: %v", rotated)
+
+	c.mu.Lock()
+	handlers := append([]func([]string){}, c.onRotate...)
+	c.mu.Unlock()
+	for _, fn := range handlers {
+		fn(rotated)
+	}
+}
+
+// refresh renews the secret's lease when it is due, or re-reads the secret
+// when it has no lease, the lease cannot be renewed, or it is near expiry.
+func (c *Client) refresh(ctx context.Context, path string) (bool, error) {
+	c.mu.Lock()
+	s := c.secrets[path]
+	c.mu.Unlock()
+	if s == nil {
+		return false, nil
+	}
+
+	age := time.Since(s.fetched)
+	if s.leaseID == "" {
+		if age < c.opts.RefreshInterval {
+			return false, nil
+		}
+	} else if age < s.leaseTTL*2/3 {
+		return false, nil
+	} else if s.renewable {
+		ttl, err := c.renewLease(ctx, s.leaseID, s.leaseTTL)
+		if err == nil && ttl > 0 {
+			c.mu.Lock()
+			s.leaseTTL = ttl
+			s.fetched = time.Now()
+			c.mu.Unlock()
+			return false, nil
+		}
+		if err != nil {
+			c.logger.Warn("Renewing lease for %s, reading it again: %v", path, err)
+		}
+	}
+
+	next, err := c.read(ctx, path)
+	if err != nil {
+		return false, err
+	}
+	c.mu.Lock()
+	c.secrets[path] = next
+	c.mu.Unlock()
+	return !reflect.DeepEqual(s.data, next.data), nil
+}
+
+func (c *Client) read(ctx context.Context, path string) (*secret, error) {
+	if err := c.ensureToken(ctx); err != nil {
+		return nil, err
+	}
+
+	var resp response
+	if err := c.do(ctx, http.MethodGet, "/v1/"+strings.TrimPrefix(path, "/"), nil, &resp); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	core.IncrCounter("vault.reads")
+
+	data := resp.Data
+	// KV version 2 nests the fields under data, beside the metadata
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, versioned := data["metadata"]; versioned {
+			data = inner
+		}
+	}
+	return &secret{
+		data:      data,
+		leaseID:   resp.LeaseID,
+		leaseTTL:  time.Duration(resp.LeaseDuration) * time.Second,
+		renewable: resp.Renewable,
+		fetched:   time.Now(),
+	}, nil
+}
+
+func (c *Client) renewLease(ctx context.Context, leaseID string, increment time.Duration) (time.Duration, error) {
+	body := map[string]interface{}{"lease_id": leaseID, "increment": int(increment.Seconds())}
+	var resp response
+	if err := c.do(ctx, http.MethodPut, "/v1/sys/leases/renew", body, &resp); err != nil {
+		return 0, err
+	}
+	return time.Duration(resp.LeaseDuration) * time.Second, nil
+}
+
+// ensureToken logs in through AppRole when there is no token yet.
+func (c *Client) ensureToken(ctx context.Context) error {
+	c.mu.Lock()
+	token := c.token
+	c.mu.Unlock()
+	if token != "" {
+		return nil
+	}
+	return c.login(ctx)
+}
+
+func (c *Client) login(ctx context.Context) error {
+	if c.opts.RoleID == "" {
+		return fmt.Errorf("no Vault token and no AppRole credentials")
+	}
+
+	body := map[string]string{"role_id": c.opts.RoleID, "secret_id": c.opts.SecretID}
+	var resp response
+	if err := c.doWithToken(ctx, "", http.MethodPost, "/v1/auth/"+c.opts.AppRoleMount+"/login", body, &resp); err != nil {
+		return fmt.Errorf("AppRole login: %w", err)
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return fmt.Errorf("AppRole login returned no token")
+	}
+
+	c.mu.Lock()
+	c.token = resp.Auth.ClientToken
+	c.tokenTTL = time.Duration(resp.Auth.LeaseDuration) * time.Second
+	c.renewable = resp.Auth.Renewable
+	c.tokenSince = time.Now()
+	c.mu.Unlock()
+
+	core.IncrCounter("vault.logins")
+	return nil
+}
+
+// renewToken extends the token at two thirds of its TTL. AppRole tokens
+// that cannot be renewed are replaced by logging in again.
+func (c *Client) renewToken(ctx context.Context) error {
+	c.mu.Lock()
+	token, ttl, renewable, since := c.token, c.tokenTTL, c.renewable, c.tokenSince
+	c.mu.Unlock()
+
+	if token == "" {
+		return c.login(ctx)
+	}
+	if ttl == 0 {
+		if since.IsZero() {
+			// A static token: look up its TTL once
+			return c.lookupToken(ctx)
+		}
+		return nil
+	}
+	if time.Since(since) < ttl*2/3 {
+		return nil
+	}
+
+	if renewable {
+		var resp response
+		err := c.do(ctx, http.MethodPost, "/v1/auth/token/renew-self", map[string]string{}, &resp)
+		if err == nil && resp.Auth != nil {
+			c.mu.Lock()
+			c.tokenTTL = time.Duration(resp.Auth.LeaseDuration) * time.Second
+			c.renewable = resp.Auth.Renewable
+			c.tokenSince = time.Now()
+			c.mu.Unlock()
+			return nil
+		}
+		if c.opts.RoleID == "" {
+			return err
+		}
+	}
+	return c.login(ctx)
+}
+
+func (c *Client) lookupToken(ctx context.Context) error {
+	var resp response
+	if err := c.do(ctx, http.MethodGet, "/v1/auth/token/lookup-self", nil, &resp); err != nil {
+		return err
+	}
+	ttl, _ := resp.Data["ttl"].(float64)
+	renewable, _ := resp.Data["renewable"].(bool)
+
+	c.mu.Lock()
+	c.tokenTTL = time.Duration(ttl) * time.Second
+	c.renewable = renewable
+	c.tokenSince = time.Now()
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body interface{}, out *response) error {
+	c.mu.Lock()
+	token := c.token
+	c.mu.Unlock()
+	return c.doWithToken(ctx, token, method, path, body, out)
+}
+
+func (c *Client) doWithToken(ctx context.Context, token, method, path string, body interface{}, out *response) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.opts.Address+path, reader)
+	if err != nil {
+		return err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if c.opts.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.opts.Namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var decoded response
+	if resp.StatusCode != http.StatusNoContent {
+		if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&decoded); err != nil && err != io.EOF {
+			return fmt.Errorf("decoding Vault response: %w", err)
+		}
+	}
+	if resp.StatusCode >= 300 {
+		if len(decoded.Errors) > 0 {
+			return fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.Join(decoded.Errors, "; "))
+		}
+		return fmt.Errorf("vault returned %d", resp.StatusCode)
+	}
+	if out != nil {
+		*out = decoded
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+// config/watch.go
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// watchDebounce groups the burst of events an editor save or a ConfigMap
+// update produces into one reload.
+const watchDebounce = 250 * time.Millisecond
+
+// defaultWatchInterval is the polling interval used when Watch is given
+// none.
+const defaultWatchInterval = 5 * time.Second
+
+// Watch reloads the config file when its content changes. It watches the
+// file's directory rather than the file, so atomic renames by editors and
+// Kubernetes ConfigMap symlink swaps are seen; where file notifications
+// are unavailable it polls every interval instead, or every 5s when
+// interval is zero or less. A reload that fails is logged and the running
+// config is kept; it is retried on the next change in the directory, such
+// as a bundle's .sig arriving after the config it signs.
+func (c *Config) Watch(interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if c.filename == "" || c.watchStop != nil {
+		return
+	}
+	c.watchStop = make(chan struct{})
+	go c.watch(c.filename, interval, c.watchStop)
+}
+
+func (c *Config) StopWatch() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if c.watchStop != nil {
+		close(c.watchStop)
+		c.watchStop = nil
+	}
+}
+
+func (c *Config) watch(filename string, interval time.Duration, stop <-chan struct{}) {
+	logger := core.GetLogger("config")
+	last := fingerprint(filename)
+	// The content of the last rejected reload, so it is logged only once
+	var rejected string
+
+	var (
+		events <-chan struct{}
+		poll   <-chan time.Time
+	)
+	watcher, err := newDirWatcher(filepath.Dir(filename))
+	if err != nil {
+		logger.Warn("File notifications unavailable, polling %s every %s: %v", filename, interval, err)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		poll = ticker.C
+	} else {
+		defer watcher.Close()
+		events = watcher.Events()
+	}
+
+	debounce := time.NewTimer(watchDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+
+	check := func() {
+		// The file can be briefly missing mid-swap; the next event retries
+		fp := fingerprint(filename)
+		if fp == "" || fp == last {
+			return
+		}
+		if err := c.Reload(); err != nil {
+			if fp != rejected {
+				logger.Error("Config reload rejected, keeping current config: %v", err)
+			}
+			rejected = fp
+			return
+		}
+		last = fp
+		rejected = ""
+	}
+
+	for {
+		select {
+		case <-events:
+			debounce.Reset(watchDebounce)
+		case <-debounce.C:
+			check()
+		case <-poll:
+			check()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// fingerprint hashes the file's content, following symlinks, so reloads
+// happen on real changes and not on touches or unrelated directory
+// events.
+func fingerprint(filename string) string {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
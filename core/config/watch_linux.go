@@ -0,0 +1,59 @@
+//go:build linux
+
+package config
+
+import (
+	"os"
+	"syscall"
+)
+
+// dirWatcher reports inotify activity in a directory. The fd is
+// non-blocking and wrapped in an os.File so reads go through the runtime
+// poller and Close unblocks them.
+type dirWatcher struct {
+	file   *os.File
+	events chan struct{}
+}
+
+func newDirWatcher(dir string) (*dirWatcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, os.NewSyscallError("inotify_init1", err)
+	}
+
+	const mask = syscall.IN_CREATE | syscall.IN_MOVED_TO | syscall.IN_MOVED_FROM |
+		syscall.IN_CLOSE_WRITE | syscall.IN_MODIFY | syscall.IN_DELETE | syscall.IN_ATTRIB
+	if _, err := syscall.InotifyAddWatch(fd, dir, mask); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("inotify_add_watch", err)
+	}
+
+	w := &dirWatcher{
+		file:   os.NewFile(uintptr(fd), "inotify"),
+		events: make(chan struct{}, 1),
+	}
+	go w.run()
+	return w, nil
+}
+
+func (w *dirWatcher) run() {
+	buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+	for {
+		if _, err := w.file.Read(buf); err != nil {
+			return
+		}
+		// Which file changed does not matter: the watcher compares content
+		select {
+		case w.events <- struct{}{}:
+		default:
+		}
+	}
+}
+
+func (w *dirWatcher) Events() <-chan struct{} {
+	return w.events
+}
+
+func (w *dirWatcher) Close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,19 @@
+//go:build !linux
+
+package config
+
+import "errors"
+
+type dirWatcher struct{}
+
+func newDirWatcher(dir string) (*dirWatcher, error) {
+	return nil, errors.New("not supported on this platform")
+}
+
+func (w *dirWatcher) Events() <-chan struct{} {
+	return nil
+}
+
+func (w *dirWatcher) Close() error {
+	return nil
+}
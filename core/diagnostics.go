@@ -0,0 +1,148 @@
+// core/diagnostics.go
+package core
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+// StartupDiagnostic is the machine-readable account of a failed
+// Initialize, attached to the returned *InitError. Config holds the
+// redacted config sections of the failed component, by section name.
+type StartupDiagnostic struct {
+	Component       string                 `json:"component"`
+	DependencyChain []string               `json:"dependency_chain"`
+	Config          map[string]interface{} `json:"config,omitempty"`
+	Error           string                 `json:"error"`
+	Class           string                 `json:"class"`
+	Remediation     string                 `json:"remediation,omitempty"`
+	RolledBack      []string               `json:"rolled_back,omitempty"`
+}
+
+// ErrorClassifier recognises errors it knows about and returns a short
+// class and a remediation hint. Packages register classifiers for their
+// own error types, e.g. driver authentication failures.
+type ErrorClassifier func(err error) (class, remediation string, ok bool)
+
+var (
+	classifiersMu sync.Mutex
+	classifiers   []ErrorClassifier
+)
+
+func RegisterErrorClassifier(classifier ErrorClassifier) {
+	classifiersMu.Lock()
+	defer classifiersMu.Unlock()
+	classifiers = append(classifiers, classifier)
+}
+
+// ConfigSectioner is implemented by components whose config is not the
+// section named after the component, e.g. network_manager reading network.
+type ConfigSectioner interface {
+	ConfigSections() []string
+}
+
+// redactedSource is satisfied by the config component.
+type redactedSource interface {
+	RedactedSection(section string) map[string]interface{}
+}
+
+// ClassifyError runs registered classifiers first, then falls back to
+// generic network, timeout and panic checks.
+func ClassifyError(err error) (string, string) {
+	classifiersMu.Lock()
+	registered := append([]ErrorClassifier{}, classifiers...)
+	classifiersMu.Unlock()
+
+	for _, classify := range registered {
+		if class, remediation, ok := classify(err); ok {
+			return class, remediation
+		}
+	}
+
+	var panicErr *PanicError
+	var dnsErr *net.DNSError
+	switch {
+	case errors.As(err, &panicErr):
+		return "panic", "Report the panic with the stack trace from the debug log"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout", "Check the service is reachable or raise config.init_timeouts for this component"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection_refused", "Check the service is running and the configured host and port"
+	case errors.As(err, &dnsErr):
+		return "dns", "Check the configured host name resolves"
+	case strings.Contains(err.Error(), "required field missing"),
+		strings.Contains(err.Error(), "validation failed"):
+		return "config", "Fix the reported config field"
+	}
+	return "unknown", ""
+}
+
+// diagnose builds the diagnostic for a failed component. Called without
+// mu held.
+func (r *Registry) diagnose(failed string, cause error) *StartupDiagnostic {
+	class, remediation := ClassifyError(cause)
+	diag := &StartupDiagnostic{
+		Component:   failed,
+		Error:       cause.Error(),
+		Class:       class,
+		Remediation: remediation,
+	}
+
+	r.mu.Lock()
+	closure := make(map[string]bool)
+	var collect func(string)
+	collect = func(name string) {
+		for _, dep := range r.dependencies(name) {
+			if !closure[dep] {
+				closure[dep] = true
+				collect(dep)
+			}
+		}
+	}
+	collect(failed)
+	for _, name := range r.initOrder {
+		if closure[name] {
+			diag.DependencyChain = append(diag.DependencyChain, name)
+		}
+	}
+	// A failed preflight is attributed to the components whose checks failed
+	owners := []string{failed}
+	var preflightErr *PreflightError
+	if failed == "preflight" {
+		owners = []string{"config"}
+		if errors.As(cause, &preflightErr) {
+			owners = nil
+			for _, result := range preflightErr.Report.Results {
+				if !result.Passed {
+					owners = append(owners, result.Component)
+				}
+			}
+		}
+	}
+	var sections []string
+	for _, name := range owners {
+		if c, ok := r.components[name].(ConfigSectioner); ok {
+			sections = append(sections, c.ConfigSections()...)
+		} else {
+			sections = append(sections, name)
+		}
+	}
+	cfg := r.components["config"]
+	r.mu.Unlock()
+
+	if source, ok := cfg.(redactedSource); ok {
+		for _, section := range sections {
+			if values := source.RedactedSection(section); values != nil {
+				if diag.Config == nil {
+					diag.Config = make(map[string]interface{})
+				}
+				diag.Config[section] = values
+			}
+		}
+	}
+	return diag
+}
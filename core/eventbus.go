@@ -0,0 +1,111 @@
+// core/eventbus.go
+package core
+
+import "sync"
+
+// Subscriber calls a handler for the events of a subscription on a
+// goroutine of its own, so a slow handler delays only itself. Events
+// arriving while its buffer is full are dropped as for any subscription.
+type Subscriber struct {
+	name string
+	sub  *EventSubscription
+	done chan struct{}
+	once sync.Once
+}
+
+// Subscribe calls fn for every future event whose topic matches one of
+// filters. A panic in fn is logged and counted, and delivery continues
+// with the next event. name identifies the subscriber in logs and metrics.
+func Subscribe(name string, filters []string, buffer int, fn func(Event)) *Subscriber {
+	s := &Subscriber{
+		name: name,
+		sub:  SubscribeEvents(filters, buffer),
+		done: make(chan struct{}),
+	}
+	go s.deliver(fn)
+	return s
+}
+
+func (s *Subscriber) deliver(fn func(Event)) {
+	defer close(s.done)
+	labels := map[string]string{"subscriber": s.name}
+	for e := range s.sub.C {
+		if err := runProtected(func() { fn(e) }); err != nil {
+			IncrCounterWithLabels("events.handler_panics", labels)
+			GetLogger("events").Error("Subscriber %s panicked handling %s: %v", s.name, e.Topic, err)
+			GetLogger("events").Debug("%s", err.(*PanicError).Stack)
+		}
+	}
+}
+
+// Dropped counts events missed because the buffer was full.
+func (s *Subscriber) Dropped() uint64 {
+	return s.sub.Dropped()
+}
+
+// Close stops delivery and waits for the handler to return from the event
+// it is handling, if any. Buffered events are still delivered first.
+// Close must not be called from the handler itself.
+func (s *Subscriber) Close() {
+	s.once.Do(func() {
+		s.sub.Close()
+		<-s.done
+	})
+}
+
+// Topic is an event topic whose payloads are of type T, so publishers and
+// subscribers agree on the payload at compile time:
+//
+//	var BlockImported = core.NewTopic[Block]("chain.block_imported")
+//
+//	BlockImported.Publish(block)
+//	sub := BlockImported.Subscribe("indexer", 64, func(b Block) { ... })
+type Topic[T any] struct {
+	name string
+}
+
+func NewTopic[T any](name string) Topic[T] {
+	return Topic[T]{name: name}
+}
+
+func (t Topic[T]) Name() string {
+	return t.name
+}
+
+func (t Topic[T]) Publish(data T) Event {
+	return PublishEvent(t.name, data)
+}
+
+// Subscribe calls fn with the payload of each future event on the topic.
+// Events published on the topic name with another payload type, through
+// PublishEvent, are skipped and counted.
+func (t Topic[T]) Subscribe(name string, buffer int, fn func(T)) *Subscriber {
+	return Subscribe(name, []string{t.name}, buffer, func(e Event) {
+		data, ok := e.Data.(T)
+		if !ok {
+			IncrCounterWithLabels("events.type_mismatches", map[string]string{"topic": t.name})
+			return
+		}
+		fn(data)
+	})
+}
+
+// Topics published by core and core/config, declared here so that
+// components can subscribe without importing each other.
+var (
+	ConfigReloaded = NewTopic[ConfigReload]("config.reloaded")
+	HealthChanged  = NewTopic[HealthChange]("health.changed")
+)
+
+// ConfigReload lists the config sections a reload changed.
+type ConfigReload struct {
+	Sections []string `json:"sections"`
+}
+
+// HealthChange is a health check moving from one status to another.
+type HealthChange struct {
+	Check    string `json:"check"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+	Error    string `json:"error,omitempty"`
+}
@@ -0,0 +1,171 @@
+// core/events.go
+package core
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event is a helper event published on a topic. Seq increases by one per
+// published event across all topics, so clients can resume after the last
+// sequence they saw.
+type Event struct {
+	Seq   uint64      `json:"seq"`
+	Topic string      `json:"topic"`
+	Time  time.Time   `json:"time"`
+	Data  interface{} `json:"data"`
+}
+
+const eventHistorySize = 1024
+
+type EventSubscription struct {
+	C       <-chan Event
+	ch      chan Event
+	filters []string
+	once    sync.Once
+	dropped atomic.Uint64
+}
+
+type eventHub struct {
+	mu      sync.Mutex
+	seq     uint64
+	history []Event
+	subs    map[*EventSubscription]struct{}
+	waiters []chan struct{}
+}
+
+var events = &eventHub{subs: make(map[*EventSubscription]struct{})}
+
+// PublishEvent records an event and delivers it to matching subscribers.
+// Subscribers that fall behind miss events rather than block publishers;
+// they can catch up from EventsSince.
+func PublishEvent(topic string, data interface{}) Event {
+	events.mu.Lock()
+	events.seq++
+	e := Event{Seq: events.seq, Topic: topic, Time: time.Now(), Data: data}
+
+	events.history = append(events.history, e)
+	if len(events.history) > eventHistorySize {
+		events.history = events.history[len(events.history)-eventHistorySize:]
+	}
+
+	for sub := range events.subs {
+		if !MatchTopic(sub.filters, topic) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+			sub.dropped.Add(1)
+			IncrCounterWithLabels("events.dropped", map[string]string{"topic": topic})
+		}
+	}
+
+	waiters := events.waiters
+	events.waiters = nil
+	events.mu.Unlock()
+
+	for _, w := range waiters {
+		close(w)
+	}
+	IncrCounterWithLabels("events.published", map[string]string{"topic": topic})
+	return e
+}
+
+// SubscribeEvents delivers future events whose topic matches one of
+// filters; no filters matches everything.
+func SubscribeEvents(filters []string, buffer int) *EventSubscription {
+	ch := make(chan Event, buffer)
+	sub := &EventSubscription{C: ch, ch: ch, filters: filters}
+
+	events.mu.Lock()
+	events.subs[sub] = struct{}{}
+	events.mu.Unlock()
+	return sub
+}
+
+func (s *EventSubscription) Close() {
+	s.once.Do(func() {
+		events.mu.Lock()
+		delete(events.subs, s)
+		events.mu.Unlock()
+		close(s.ch)
+	})
+}
+
+// Dropped counts events this subscription missed because its buffer was
+// full. Consumers that need every event can re-read them with EventsSince
+// when it changes.
+func (s *EventSubscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// EventsSince returns retained events after seq that match filters. The
+// bool is false when events after seq have already been evicted from the
+// history.
+func EventsSince(seq uint64, filters []string) ([]Event, bool) {
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	return events.since(seq, filters)
+}
+
+func (h *eventHub) since(seq uint64, filters []string) ([]Event, bool) {
+	complete := len(h.history) == 0 || h.history[0].Seq <= seq+1
+	var result []Event
+	for _, e := range h.history {
+		if e.Seq > seq && MatchTopic(filters, e.Topic) {
+			result = append(result, e)
+		}
+	}
+	return result, complete
+}
+
+// WaitEvents long-polls: it returns as soon as there are matching events
+// after seq, or an empty slice once timeout passes.
+func WaitEvents(seq uint64, filters []string, timeout time.Duration) ([]Event, bool) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		events.mu.Lock()
+		result, complete := events.since(seq, filters)
+		if len(result) > 0 {
+			events.mu.Unlock()
+			return result, complete
+		}
+		wake := make(chan struct{})
+		events.waiters = append(events.waiters, wake)
+		events.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-deadline.C:
+			return nil, complete
+		}
+	}
+}
+
+func LastEventSeq() uint64 {
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	return events.seq
+}
+
+// MatchTopic reports whether topic matches any filter. A filter is an
+// exact topic, "*", or a prefix ending in ".*".
+func MatchTopic(filters []string, topic string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		switch {
+		case f == "*" || f == topic:
+			return true
+		case strings.HasSuffix(f, ".*") && strings.HasPrefix(topic, f[:len(f)-1]):
+			return true
+		}
+	}
+	return false
+}
@@ -15,36 +15,78 @@ const (
 	HealthUnhealthy
 )
 
+// HealthCheckKind classifies a check. Liveness checks answer "should this
+// process be restarted", readiness checks "should it receive traffic".
+type HealthCheckKind int
+
+const (
+	ReadinessCheck HealthCheckKind = 1 << iota
+	LivenessCheck
+)
+
 type HealthChecker interface {
 	HealthCheck(ctx context.Context) (HealthStatus, error)
 }
 
+type registeredCheck struct {
+	checker HealthChecker
+	kind    HealthCheckKind
+}
+
 type HealthRegistry struct {
 	mu       sync.RWMutex
-	checkers map[string]HealthChecker
+	checkers map[string]registeredCheck
 }
 
 var healthRegistry = &HealthRegistry{
-	checkers: make(map[string]HealthChecker),
+	checkers: make(map[string]registeredCheck),
 }
 
+// RegisterHealthCheck registers a readiness check: a failing dependency
+// takes the instance out of rotation without getting it restarted.
 func RegisterHealthCheck(name string, checker HealthChecker) {
+	RegisterHealthCheckKind(name, checker, ReadinessCheck)
+}
+
+func RegisterHealthCheckKind(name string, checker HealthChecker, kind HealthCheckKind) {
+	healthRegistry.mu.Lock()
+	defer healthRegistry.mu.Unlock()
+	healthRegistry.checkers[name] = registeredCheck{checker: checker, kind: kind}
+}
+
+func UnregisterHealthCheck(name string) {
 	healthRegistry.mu.Lock()
 	defer healthRegistry.mu.Unlock()
-	healthRegistry.checkers[name] = checker
+	delete(healthRegistry.checkers, name)
 }
 
 func CheckHealth(ctx context.Context) map[string]HealthResult {
+	return runHealthChecks(ctx, ReadinessCheck|LivenessCheck)
+}
+
+func CheckLiveness(ctx context.Context) map[string]HealthResult {
+	return runHealthChecks(ctx, LivenessCheck)
+}
+
+func CheckReadiness(ctx context.Context) map[string]HealthResult {
+	return runHealthChecks(ctx, ReadinessCheck)
+}
+
+func runHealthChecks(ctx context.Context, kind HealthCheckKind) map[string]HealthResult {
 	healthRegistry.mu.RLock()
 	defer healthRegistry.mu.RUnlock()
 
 	results := make(map[string]HealthResult)
-	for name, checker := range healthRegistry.checkers {
-		status, err := checker.HealthCheck(ctx)
+	for name, check := range healthRegistry.checkers {
+		if check.kind&kind == 0 {
+			continue
+		}
+		status, err := check.checker.HealthCheck(ctx)
 		results[name] = HealthResult{
 			Status: status,
 			Error:  err,
 			Time:   time.Now(),
+			Kind:   check.kind,
 		}
 	}
 	return results
@@ -54,4 +96,27 @@ type HealthResult struct {
 	Status HealthStatus
 	Error  error
 	Time   time.Time
+	Kind   HealthCheckKind
+}
+
+func FilterHealthResults(results map[string]HealthResult, kind HealthCheckKind) map[string]HealthResult {
+	filtered := make(map[string]HealthResult, len(results))
+	for name, result := range results {
+		if result.Kind&kind != 0 {
+			filtered[name] = result
+		}
+	}
+	return filtered
+}
+
+func (s HealthStatus) String() string {
+	switch s {
+	case HealthHealthy:
+		return "healthy"
+	case HealthDegraded:
+		return "degraded"
+	case HealthUnhealthy:
+		return "unhealthy"
+	}
+	return "unknown"
 }
@@ -0,0 +1,40 @@
+// core/health_init.go
+package core
+
+import (
+	"context"
+)
+
+type healthComponent struct{}
+
+func (h *healthComponent) Name() string {
+	return "health"
+}
+
+func (h *healthComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (h *healthComponent) Init() error {
+	cfg, ok := GetComponent("config").(configSource)
+	if !ok {
+		return nil
+	}
+
+	interval := cfg.GetDuration("config", "health_interval")
+	if interval <= 0 {
+		return nil
+	}
+
+	StartHealthMonitor(interval, cfg.GetDuration("config", "health_timeout"))
+	return nil
+}
+
+func (h *healthComponent) Shutdown(ctx context.Context) error {
+	StopHealthMonitor()
+	return nil
+}
+
+func init() {
+	Register(&healthComponent{})
+}
@@ -0,0 +1,226 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type HealthChangeFunc func(name string, previous, current HealthResult)
+
+// HealthMonitor runs registered checks in the background and caches the
+// latest results, so readers never trigger synchronous checks.
+type HealthMonitor struct {
+	interval  time.Duration
+	timeout   time.Duration
+	mu        sync.RWMutex
+	last      map[string]HealthResult
+	callbacks []HealthChangeFunc
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	logger    Logger
+}
+
+var (
+	healthMonitor   *HealthMonitor
+	healthMonitorMu sync.Mutex
+	healthCallbacks []HealthChangeFunc
+)
+
+func NewHealthMonitor(interval, timeout time.Duration) *HealthMonitor {
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &HealthMonitor{
+		interval: interval,
+		timeout:  timeout,
+		last:     make(map[string]HealthResult),
+		stopCh:   make(chan struct{}),
+		logger:   GetLogger("health"),
+	}
+}
+
+// StartHealthMonitor starts the process-wide monitor. Callbacks registered
+// with OnHealthChange before or after the start are all honored.
+func StartHealthMonitor(interval, timeout time.Duration) *HealthMonitor {
+	healthMonitorMu.Lock()
+	defer healthMonitorMu.Unlock()
+
+	if healthMonitor != nil {
+		healthMonitor.Stop()
+	}
+
+	m := NewHealthMonitor(interval, timeout)
+	m.callbacks = append(m.callbacks, healthCallbacks...)
+	m.Start()
+	healthMonitor = m
+	return m
+}
+
+func StopHealthMonitor() {
+	healthMonitorMu.Lock()
+	defer healthMonitorMu.Unlock()
+
+	if healthMonitor != nil {
+		healthMonitor.Stop()
+		healthMonitor = nil
+	}
+}
+
+// GetHealthMonitor returns the running process-wide monitor, or nil.
+func GetHealthMonitor() *HealthMonitor {
+	healthMonitorMu.Lock()
+	defer healthMonitorMu.Unlock()
+	return healthMonitor
+}
+
+func OnHealthChange(callback HealthChangeFunc) {
+	healthMonitorMu.Lock()
+	defer healthMonitorMu.Unlock()
+
+	healthCallbacks = append(healthCallbacks, callback)
+	if healthMonitor != nil {
+		healthMonitor.OnStatusChange(callback)
+	}
+}
+
+func (m *HealthMonitor) OnStatusChange(callback HealthChangeFunc) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callbacks = append(m.callbacks, callback)
+}
+
+func (m *HealthMonitor) Start() {
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+		Supervise("health_monitor", m.stopCh, m.run)
+	}()
+}
+
+func (m *HealthMonitor) Stop() {
+	close(m.stopCh)
+	m.wg.Wait()
+}
+
+func (m *HealthMonitor) LastResults() map[string]HealthResult {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := make(map[string]HealthResult, len(m.last))
+	for name, result := range m.last {
+		results[name] = result
+	}
+	return results
+}
+
+func (m *HealthMonitor) run() {
+	m.checkAll()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.checkAll()
+		case <-m.stopCh:
+			return
+		}
+	}
+}
+
+func (m *HealthMonitor) checkAll() {
+	healthRegistry.mu.RLock()
+	checks := make(map[string]registeredCheck, len(healthRegistry.checkers))
+	for name, check := range healthRegistry.checkers {
+		checks[name] = check
+	}
+	healthRegistry.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	var resultsMu sync.Mutex
+	results := make(map[string]HealthResult, len(checks))
+
+	for name, check := range checks {
+		wg.Add(1)
+		go func(name string, check registeredCheck) {
+			defer wg.Done()
+			result := m.checkOne(check)
+			resultsMu.Lock()
+			results[name] = result
+			resultsMu.Unlock()
+		}(name, check)
+	}
+	wg.Wait()
+
+	m.mu.Lock()
+	previous := m.last
+	m.last = results
+	callbacks := append([]HealthChangeFunc{}, m.callbacks...)
+	m.mu.Unlock()
+
+	for name, current := range results {
+		prev := previous[name]
+		SetGaugeWithLabels("health.status", map[string]string{"check": name}, int64(current.Status))
+		if prev.Status == current.Status {
+			continue
+		}
+		m.logger.Info("Health of %s changed: %s -> %s", name, prev.Status, current.Status)
+		HealthChanged.Publish(healthChange(name, prev, current))
+		for _, callback := range callbacks {
+			m.notify(callback, name, prev, current)
+		}
+	}
+}
+
+// checkOne bounds a check by the monitor timeout even if the checker
+// ignores its context.
+func (m *HealthMonitor) checkOne(check registeredCheck) HealthResult {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	done := make(chan HealthResult, 1)
+	go func() {
+		var result HealthResult
+		if err := runProtected(func() {
+			status, err := check.checker.HealthCheck(ctx)
+			result = HealthResult{Status: status, Error: err}
+		}); err != nil {
+			result = HealthResult{Status: HealthUnhealthy, Error: err}
+		}
+		done <- result
+	}()
+
+	var result HealthResult
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = HealthResult{
+			Status: HealthUnhealthy,
+			Error:  fmt.Errorf("health check timed out after %s", m.timeout),
+		}
+	}
+	result.Time = time.Now()
+	result.Kind = check.kind
+	return result
+}
+
+func (m *HealthMonitor) notify(callback HealthChangeFunc, name string, previous, current HealthResult) {
+	if err := runProtected(func() { callback(name, previous, current) }); err != nil {
+		m.logger.Error("Health change callback for %s failed: %v", name, err)
+	}
+}
+
+func healthChange(name string, previous, current HealthResult) HealthChange {
+	change := HealthChange{
+		Check:    name,
+		Previous: previous.Status.String(),
+		Current:  current.Status.String(),
+	}
+	if current.Error != nil {
+		change.Error = current.Error.Error()
+	}
+	return change
+}
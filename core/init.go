@@ -14,6 +14,20 @@ type Initializer interface {
 	Init() error
 }
 
+// InitializerCtx is the context-aware form of Initializer. Its Init runs
+// under the component's init timeout and should return once ctx is done.
+type InitializerCtx interface {
+	Name() string
+	Dependencies() []string
+	Init(ctx context.Context) error
+}
+
+// initializer is the part shared by Initializer and InitializerCtx.
+type initializer interface {
+	Name() string
+	Dependencies() []string
+}
+
 type Shutdowner interface {
 	Shutdown(ctx context.Context) error
 }
@@ -24,56 +38,182 @@ type Component interface {
 }
 
 type Registry struct {
-	mu            sync.Mutex
-	components    map[string]interface{}
-	initialized   map[string]bool
-	initOrder     []string
+	mu          sync.Mutex
+	components  map[string]interface{}
+	initialized map[string]bool
+	initOrder   []string
+	extraDeps   map[string][]string
+	disabled    map[string]bool
+	// pinned names were registered with WithReplace or WithDisabled and
+	// ignore plain registrations
+	pinned        map[string]bool
 	shutdownHooks []func(context.Context) error
+	preShutdown   []func(context.Context) error
+
+	// lifecycleMu serializes Initialize and Shutdown. Component hooks run
+	// with only this lock held, so they may call back into the registry.
+	lifecycleMu sync.Mutex
 }
 
 var (
 	registry = &Registry{
 		components:  make(map[string]interface{}),
 		initialized: make(map[string]bool),
+		extraDeps:   make(map[string][]string),
+		disabled:    make(map[string]bool),
+		pinned:      make(map[string]bool),
 	}
 )
 
-func Register(component interface{}) {
-	registry.mu.Lock()
-	defer registry.mu.Unlock()
-
-	if init, ok := component.(Initializer); ok {
-		registry.components[init.Name()] = component
+// Register adds a component under its Name(), replacing one registered
+// earlier under the same name unless that one used WithReplace or
+// WithDisabled.
+func Register(component interface{}, opts ...RegisterOption) {
+	switch component.(type) {
+	case Initializer, InitializerCtx:
+		registry.register(component, opts)
 	}
 }
 
 func Initialize() error {
-	registry.mu.Lock()
-	defer registry.mu.Unlock()
+	return InitializeContext(context.Background())
+}
+
+// InitializeContext initializes all registered components in dependency
+// order. Each component's Init is bounded by config.init_timeout, or its
+// entry in config.init_timeouts, and by ctx.
+func InitializeContext(ctx context.Context) error {
+	registry.lifecycleMu.Lock()
+	defer registry.lifecycleMu.Unlock()
 
+	registry.mu.Lock()
 	order, err := registry.topologicalSort()
+	if err == nil {
+		registry.initOrder = order
+	}
+	registry.mu.Unlock()
 	if err != nil {
 		return err
 	}
-	registry.initOrder = order
 
+	registry.mu.Lock()
+	alreadyUp := make(map[string]bool, len(registry.initialized))
+	for name, done := range registry.initialized {
+		alreadyUp[name] = done
+	}
+	registry.mu.Unlock()
+
+	pending := make(map[string]bool, len(order))
 	for _, name := range order {
-		if err := registry.initOne(name); err != nil {
-			return fmt.Errorf("initializing %s: %w", name, err)
+		pending[name] = !alreadyUp[name]
+	}
+	if _, err := registry.preflight(ctx, pending); err != nil {
+		return registry.rollback(ctx, order, alreadyUp, "preflight", err)
+	}
+
+	for _, name := range order {
+		if err := registry.initOne(ctx, name); err != nil {
+			return registry.rollback(ctx, order, alreadyUp, name, err)
 		}
 	}
 
 	return nil
 }
 
+// InitError reports a failed Initialize and which components were shut
+// down again because of it.
+type InitError struct {
+	Component      string
+	Err            error
+	RolledBack     []string
+	RollbackErrors map[string]error
+	Diagnostic     *StartupDiagnostic
+}
+
+func (e *InitError) Error() string {
+	msg := fmt.Sprintf("initializing %s: %v", e.Component, e.Err)
+	if len(e.RolledBack) > 0 {
+		msg += fmt.Sprintf(" (rolled back %v)", e.RolledBack)
+	}
+	if len(e.RollbackErrors) > 0 {
+		msg += fmt.Sprintf(" (%d rollback errors)", len(e.RollbackErrors))
+	}
+	return msg
+}
+
+func (e *InitError) Unwrap() error {
+	return e.Err
+}
+
+// rollback shuts down, in reverse order, the components this Initialize
+// call brought up before the failure. Components that were already
+// running beforehand are left alone.
+func (r *Registry) rollback(ctx context.Context, order []string, alreadyUp map[string]bool, failed string, cause error) error {
+	initErr := &InitError{Component: failed, Err: cause}
+
+	r.mu.Lock()
+	var started []string
+	for _, name := range order {
+		if r.initialized[name] && !alreadyUp[name] {
+			started = append(started, name)
+		}
+	}
+	components := make(map[string]interface{}, len(started))
+	for _, name := range started {
+		components[name] = r.components[name]
+	}
+	r.mu.Unlock()
+
+	// The init context may be what failed, so give shutdown its own budget
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), defaultInitTimeout)
+	defer cancel()
+
+	for i := len(started) - 1; i >= 0; i-- {
+		name := started[i]
+		if s, ok := components[name].(Shutdowner); ok {
+			if err := s.Shutdown(ctx); err != nil {
+				if initErr.RollbackErrors == nil {
+					initErr.RollbackErrors = make(map[string]error)
+				}
+				initErr.RollbackErrors[name] = err
+			}
+		}
+		r.mu.Lock()
+		r.initialized[name] = false
+		r.mu.Unlock()
+		initErr.RolledBack = append(initErr.RolledBack, name)
+	}
+
+	initErr.Diagnostic = r.diagnose(failed, cause)
+	initErr.Diagnostic.RolledBack = initErr.RolledBack
+	return initErr
+}
+
 func Shutdown(ctx context.Context) error {
+	registry.lifecycleMu.Lock()
+	defer registry.lifecycleMu.Unlock()
+
 	registry.mu.Lock()
-	defer registry.mu.Unlock()
+	order := append([]string{}, registry.initOrder...)
+	components := make(map[string]interface{}, len(registry.components))
+	for name, comp := range registry.components {
+		components[name] = comp
+	}
+	hooks := append([]func(context.Context) error{}, registry.shutdownHooks...)
+	preHooks := append([]func(context.Context) error{}, registry.preShutdown...)
+	registry.mu.Unlock()
+
+	// Pre-shutdown hooks see every component still running
+	for _, hook := range preHooks {
+		if err := hook(ctx); err != nil {
+			return err
+		}
+	}
 
 	// Shutdown in reverse order
-	for i := len(registry.initOrder) - 1; i >= 0; i-- {
-		name := registry.initOrder[i]
-		if comp, ok := registry.components[name]; ok {
+	for i := len(order) - 1; i >= 0; i-- {
+		name := order[i]
+		if comp, ok := components[name]; ok {
 			if s, ok := comp.(Shutdowner); ok {
 				if err := s.Shutdown(ctx); err != nil {
 					return fmt.Errorf("shutting down %s: %w", name, err)
@@ -83,7 +223,7 @@ func Shutdown(ctx context.Context) error {
 	}
 
 	// Run additional shutdown hooks
-	for _, hook := range registry.shutdownHooks {
+	for _, hook := range hooks {
 		if err := hook(ctx); err != nil {
 			return err
 		}
@@ -98,37 +238,120 @@ func RegisterShutdownHook(hook func(context.Context) error) {
 	registry.shutdownHooks = append(registry.shutdownHooks, hook)
 }
 
-func (r *Registry) initOne(name string) error {
-	if r.initialized[name] {
-		return nil
-	}
+func RegisterPreShutdownHook(hook func(context.Context) error) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.preShutdown = append(registry.preShutdown, hook)
+}
 
+func (r *Registry) initOne(ctx context.Context, name string) error {
+	r.mu.Lock()
+	done := r.initialized[name]
 	comp, ok := r.components[name]
+	disabled := r.disabled[name]
+	deps := r.dependencies(name)
+	r.mu.Unlock()
+
+	if done {
+		return nil
+	}
+	if disabled {
+		return fmt.Errorf("component %s is disabled", name)
+	}
 	if !ok {
 		return fmt.Errorf("unknown component: %s", name)
 	}
 
-	init, ok := comp.(Initializer)
-	if !ok {
+	if _, ok := comp.(initializer); !ok {
 		return fmt.Errorf("%s does not implement Initializer", name)
 	}
 
-	for _, dep := range init.Dependencies() {
-		if !r.initialized[dep] {
-			if err := r.initOne(dep); err != nil {
-				return err
-			}
+	for _, dep := range deps {
+		if err := r.initOne(ctx, dep); err != nil {
+			return err
 		}
 	}
 
-	if err := init.Init(); err != nil {
+	if err := runInit(ctx, name, comp); err != nil {
 		return err
 	}
 
+	r.mu.Lock()
 	r.initialized[name] = true
+	r.mu.Unlock()
 	return nil
 }
 
+// runInit calls the component's Init in its own goroutine so that a plain
+// Initializer which ignores cancellation still cannot hang startup past its
+// timeout; such an Init is abandoned, not stopped.
+func runInit(ctx context.Context, name string, comp interface{}) error {
+	if timeout := initTimeout(name); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		var initErr error
+		err := runProtected(func() {
+			switch c := comp.(type) {
+			case InitializerCtx:
+				initErr = c.Init(ctx)
+			case Initializer:
+				initErr = c.Init()
+			}
+		})
+		if err == nil {
+			err = initErr
+		}
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("init did not complete: %w", ctx.Err())
+	}
+}
+
+const defaultInitTimeout = 30 * time.Second
+
+// initTimeout reads the timeout for name from config once the config
+// component is loaded; components initialized before that, including config
+// itself, get the default.
+func initTimeout(name string) time.Duration {
+	cfg, ok := GetComponent("config").(configSource)
+	if !ok || !IsInitialized("config") {
+		return defaultInitTimeout
+	}
+
+	if overrides, ok := cfg.Get("config", "init_timeouts").(map[string]interface{}); ok {
+		if d, ok := parseTimeout(overrides[name]); ok {
+			return d
+		}
+	}
+	if d, ok := parseTimeout(cfg.Get("config", "init_timeout")); ok {
+		return d
+	}
+	return defaultInitTimeout
+}
+
+func parseTimeout(v interface{}) (time.Duration, bool) {
+	switch val := v.(type) {
+	case string:
+		d, err := time.ParseDuration(val)
+		return d, err == nil
+	case float64:
+		return time.Duration(val * float64(time.Second)), true
+	case int:
+		return time.Duration(val) * time.Second, true
+	}
+	return 0, false
+}
+
 func (r *Registry) topologicalSort() ([]string, error) {
 	var order []string
 	visited := make(map[string]bool)
@@ -145,13 +368,12 @@ func (r *Registry) topologicalSort() ([]string, error) {
 
 		visiting[name] = true
 
-		if comp, ok := r.components[name]; ok {
-			if init, ok := comp.(Initializer); ok {
-				for _, dep := range init.Dependencies() {
-					if err := visit(dep); err != nil {
-						return err
-					}
-				}
+		for _, dep := range r.dependencies(name) {
+			if r.disabled[dep] {
+				return fmt.Errorf("%s depends on disabled component %s", name, dep)
+			}
+			if err := visit(dep); err != nil {
+				return err
 			}
 		}
 
@@ -205,3 +427,50 @@ func GracefulShutdown(timeout time.Duration) error {
 	defer cancel()
 	return Shutdown(ctx)
 }
+
+type ComponentStatus struct {
+	Name         string       `json:"name"`
+	Dependencies []string     `json:"dependencies,omitempty"`
+	Initialized  bool         `json:"initialized"`
+	Degraded     bool         `json:"degraded"`
+	Disabled     bool         `json:"disabled,omitempty"`
+	Annotations  []Annotation `json:"annotations,omitempty"`
+}
+
+// GetComponentStatus lists registered components in init order, followed by
+// any that have not been ordered yet.
+func GetComponentStatus() []ComponentStatus {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	seen := make(map[string]bool)
+	names := append([]string{}, registry.initOrder...)
+	for _, name := range names {
+		seen[name] = true
+	}
+	for _, name := range sortedKeys(registry.components) {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+
+	result := make([]ComponentStatus, 0, len(names))
+	for _, name := range names {
+		status := ComponentStatus{
+			Name:        name,
+			Initialized: registry.initialized[name],
+			Degraded:    IsDegraded(name),
+		}
+		status.Dependencies = registry.dependencies(name)
+		status.Annotations = AnnotationsFor("component", name)
+		result = append(result, status)
+	}
+	for _, name := range sortedKeys(registry.disabled) {
+		result = append(result, ComponentStatus{
+			Name:        name,
+			Disabled:    true,
+			Annotations: AnnotationsFor("component", name),
+		})
+	}
+	return result
+}
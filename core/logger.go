@@ -1,11 +1,16 @@
 package core
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
+
+	"github.com/polkadot-go/helper/core/pool"
 )
 
 type LogLevel int
@@ -17,20 +22,69 @@ const (
 	LogError
 )
 
-type Logger struct {
+type LogFormat int
+
+const (
+	FormatText LogFormat = iota
+	FormatJSON
+)
+
+// Logger is what the helper logs through. GetLogger returns the built-in
+// StdLogger unless an application installs its own with SetLoggerFactory.
+type Logger interface {
+	Debug(format string, args ...interface{})
+	Info(format string, args ...interface{})
+	Warn(format string, args ...interface{})
+	Error(format string, args ...interface{})
+	// With returns a logger that attaches fields to every line
+	With(fields map[string]interface{}) Logger
+}
+
+// StdLogger is the built-in Logger, writing text or JSON lines to the
+// configured sinks at per-logger levels.
+type StdLogger struct {
 	level  LogLevel
+	name   string
 	prefix string
+	fields map[string]interface{}
+	parent *StdLogger
 	mu     sync.Mutex
 }
 
 var (
-	loggers    = make(map[string]*Logger)
+	loggers    = make(map[string]*StdLogger)
 	loggersMu  sync.RWMutex
-	rootLogger = &Logger{level: LogInfo}
+	rootLogger = &StdLogger{level: LogInfo}
+	logFormat  = FormatText
+	// levelOverrides holds per-logger levels; others use rootLogger's
+	levelOverrides = make(map[string]LogLevel)
+
+	factory       func(name string) Logger
+	customLoggers = make(map[string]Logger)
 )
 
-func GetLogger(name string) *Logger {
+// SetLoggerFactory makes GetLogger return loggers built by fn, e.g. with
+// SlogFactory, instead of StdLogger. Components keep the logger they got at
+// construction, so call it before Initialize. Levels, formats and sinks
+// from config only apply to StdLogger. A nil fn restores the built-in
+// loggers.
+func SetLoggerFactory(fn func(name string) Logger) {
+	loggersMu.Lock()
+	defer loggersMu.Unlock()
+	factory = fn
+	customLoggers = make(map[string]Logger)
+}
+
+func GetLogger(name string) Logger {
 	loggersMu.RLock()
+	if factory != nil {
+		if l, ok := customLoggers[name]; ok {
+			loggersMu.RUnlock()
+			return l
+		}
+		loggersMu.RUnlock()
+		return customLogger(name)
+	}
 	if l, ok := loggers[name]; ok {
 		loggersMu.RUnlock()
 		return l
@@ -42,38 +96,123 @@ func GetLogger(name string) *Logger {
 	if l, ok := loggers[name]; ok {
 		return l
 	}
-	l := &Logger{
-		level:  rootLogger.level,
+	l := &StdLogger{
+		level:  levelFor(name),
+		name:   name,
 		prefix: fmt.Sprintf("[%s] ", name),
 	}
 	loggers[name] = l
 	return l
 }
 
-func SetLogLevel(level string) {
-	var l LogLevel
+func customLogger(name string) Logger {
+	loggersMu.Lock()
+	if l, ok := customLoggers[name]; ok {
+		loggersMu.Unlock()
+		return l
+	}
+	if factory == nil {
+		// The factory was removed since GetLogger checked
+		loggersMu.Unlock()
+		return GetLogger(name)
+	}
+	l := factory(name)
+	customLoggers[name] = l
+	loggersMu.Unlock()
+	return l
+}
+
+// levelFor must be called with loggersMu held.
+func levelFor(name string) LogLevel {
+	if level, ok := levelOverrides[name]; ok {
+		return level
+	}
+	return rootLogger.level
+}
+
+func parseLogLevel(level string) LogLevel {
 	switch level {
 	case "debug":
-		l = LogDebug
-	case "info":
-		l = LogInfo
+		return LogDebug
 	case "warn":
-		l = LogWarn
+		return LogWarn
 	case "error":
-		l = LogError
-	default:
-		l = LogInfo
+		return LogError
 	}
-	rootLogger.level = l
+	return LogInfo
+}
+
+// SetLogLevel sets every logger to level, clearing per-logger levels.
+func SetLogLevel(level string) {
+	SetLogLevels(map[string]string{"*": level})
+}
+
+// SetLogLevels sets levels by logger name. "*" applies to loggers not
+// named, including ones created later, and defaults to info.
+func SetLogLevels(levels map[string]string) {
+	def := LogInfo
+	overrides := make(map[string]LogLevel, len(levels))
+	for name, level := range levels {
+		if name == "*" {
+			def = parseLogLevel(level)
+		} else {
+			overrides[name] = parseLogLevel(level)
+		}
+	}
+
 	loggersMu.Lock()
-	for _, logger := range loggers {
-		logger.level = l
+	defer loggersMu.Unlock()
+	rootLogger.level = def
+	levelOverrides = overrides
+	for name, logger := range loggers {
+		logger.level = levelFor(name)
 	}
-	loggersMu.Unlock()
 }
 
-func (l *Logger) log(level LogLevel, format string, args ...interface{}) {
-	if level < l.level {
+func SetLogFormat(format string) {
+	loggersMu.Lock()
+	defer loggersMu.Unlock()
+	switch format {
+	case "json":
+		logFormat = FormatJSON
+	default:
+		logFormat = FormatText
+	}
+}
+
+// With returns a child logger that attaches fields to every line. The
+// child shares its parent's level.
+func (l *StdLogger) With(fields map[string]interface{}) Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+
+	root := l
+	if l.parent != nil {
+		root = l.parent
+	}
+
+	return &StdLogger{
+		name:   l.name,
+		prefix: l.prefix,
+		fields: merged,
+		parent: root,
+	}
+}
+
+func (l *StdLogger) effectiveLevel() LogLevel {
+	if l.parent != nil {
+		return l.parent.level
+	}
+	return l.level
+}
+
+func (l *StdLogger) log(level LogLevel, format string, args ...interface{}) {
+	if level < l.effectiveLevel() {
 		return
 	}
 	l.mu.Lock()
@@ -92,26 +231,106 @@ func (l *Logger) log(level LogLevel, format string, args ...interface{}) {
 	}
 
 	msg := fmt.Sprintf(format, args...)
-	log.Printf("%s %s%s %s", time.Now().Format("2006-01-02 15:04:05"), l.prefix, levelStr, msg)
+
+	loggersMu.RLock()
+	f := logFormat
+	loggersMu.RUnlock()
+
+	buf := pool.GetBuffer(256)
+	defer pool.PutBuffer(buf)
+
+	line := (*buf)[:0]
+	if f == FormatJSON {
+		line = append(line, l.formatJSON(levelStr, msg)...)
+	} else {
+		line = time.Now().AppendFormat(line, "2006-01-02 15:04:05")
+		line = append(line, ' ')
+		line = append(line, l.prefix...)
+		line = append(line, levelStr...)
+		line = append(line, ' ')
+		line = append(line, msg...)
+		line = append(line, l.textFields()...)
+	}
+	defer func() { *buf = line[:0] }()
+
+	sinks := sinksFor(l.name)
+	if len(sinks) == 0 {
+		if f == FormatJSON {
+			log.Writer().Write(append(line, '\n'))
+		} else {
+			log.Print(string(line))
+		}
+		return
+	}
+
+	line = append(line, '\n')
+	for _, sink := range sinks {
+		sink.Write(line)
+	}
+}
+
+func (l *StdLogger) formatJSON(level, msg string) []byte {
+	entry := make(map[string]interface{}, len(l.fields)+4)
+	for k, v := range l.fields {
+		if err, ok := v.(error); ok {
+			v = err.Error()
+		}
+		entry[k] = v
+	}
+	entry["time"] = time.Now().Format(time.RFC3339Nano)
+	entry["level"] = level
+	entry["msg"] = msg
+	if l.name != "" {
+		entry["logger"] = l.name
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		line, _ = json.Marshal(map[string]interface{}{
+			"time":  entry["time"],
+			"level": level,
+			"msg":   msg,
+			"error": fmt.Sprintf("marshaling fields: %v", err),
+		})
+	}
+	return line
+}
+
+func (l *StdLogger) textFields() string {
+	if len(l.fields) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(l.fields))
+	for k := range l.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, " %s=%v", k, l.fields[k])
+	}
+	return b.String()
 }
 
-func (l *Logger) Debug(format string, args ...interface{}) {
+func (l *StdLogger) Debug(format string, args ...interface{}) {
 	l.log(LogDebug, format, args...)
 }
 
-func (l *Logger) Info(format string, args ...interface{}) {
+func (l *StdLogger) Info(format string, args ...interface{}) {
 	l.log(LogInfo, format, args...)
 }
 
-func (l *Logger) Warn(format string, args ...interface{}) {
+func (l *StdLogger) Warn(format string, args ...interface{}) {
 	l.log(LogWarn, format, args...)
 }
 
-func (l *Logger) Error(format string, args ...interface{}) {
+func (l *StdLogger) Error(format string, args ...interface{}) {
 	l.log(LogError, format, args...)
 }
 
-func (l *Logger) Fatal(format string, args ...interface{}) {
+func (l *StdLogger) Fatal(format string, args ...interface{}) {
 	l.log(LogError, format, args...)
 	os.Exit(1)
 }
@@ -0,0 +1,71 @@
+// core/logger_context.go
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// CorrelationHeader carries a correlation ID across HTTP hops. Servers
+// reuse an incoming value and otherwise generate one.
+const CorrelationHeader = "X-Correlation-ID"
+
+// CorrelationField is the log field correlation IDs are written under.
+const CorrelationField = "correlation_id"
+
+type loggerKey struct{}
+
+type correlationKey struct{}
+
+// NewCorrelationID returns a random 16 byte ID in hex.
+func NewCorrelationID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// WithCorrelationID attaches id to ctx, generating one when id is empty. A
+// logger already on ctx is replaced by one that logs the ID.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		id = NewCorrelationID()
+	}
+	ctx = context.WithValue(ctx, correlationKey{}, id)
+	if l, ok := ctx.Value(loggerKey{}).(Logger); ok {
+		ctx = context.WithValue(ctx, loggerKey{}, l.With(map[string]interface{}{CorrelationField: id}))
+	}
+	return ctx
+}
+
+// CorrelationID returns the correlation ID on ctx, or "".
+func CorrelationID(ctx context.Context) string {
+	id, _ := ctx.Value(correlationKey{}).(string)
+	return id
+}
+
+// ContextWithLogger attaches l to ctx for LoggerFromContext, adding the
+// correlation ID field when ctx has one.
+func ContextWithLogger(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, ContextLogger(ctx, l))
+}
+
+// LoggerFromContext returns the logger attached to ctx, or the "app" logger
+// carrying ctx's correlation ID.
+func LoggerFromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(loggerKey{}).(Logger); ok {
+		return l
+	}
+	return ContextLogger(ctx, GetLogger("app"))
+}
+
+// ContextLogger returns l with ctx's correlation ID attached, so a
+// component can keep its own logger and still tag request-scoped lines:
+//
+//	core.ContextLogger(ctx, m.logger).Warn("Query failed: %v", err)
+func ContextLogger(ctx context.Context, l Logger) Logger {
+	if id := CorrelationID(ctx); id != "" {
+		return l.With(map[string]interface{}{CorrelationField: id})
+	}
+	return l
+}
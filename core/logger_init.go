@@ -3,33 +3,124 @@ package core
 
 import (
 	"context"
+	"fmt"
+	"time"
 )
 
-type loggerComponent struct{}
+// configSource is satisfied by the config component; core cannot import
+// the config package without creating a cycle.
+type configSource interface {
+	Get(section, key string) interface{}
+	GetString(section, key string) string
+	GetInt(section, key string) int
+	GetDuration(section, key string) time.Duration
+}
+
+type loggerComponent struct {
+	reloads *Subscriber
+	// outputs is the log_outputs and rotation settings last applied, so a
+	// reload only reopens sinks when they change
+	outputs string
+}
 
 func (l *loggerComponent) Name() string {
 	return "logger"
 }
 
+func (l *loggerComponent) ConfigSections() []string {
+	return []string{"config"}
+}
+
 func (l *loggerComponent) Dependencies() []string {
 	return []string{"config"}
 }
 
 func (l *loggerComponent) Init() error {
 	// Get config component directly
-	if comp := GetComponent("config"); comp != nil {
-		// We'll set log level after config is loaded
-		// For now, default to info
-		SetLogLevel("info")
+	cfg, ok := GetComponent("config").(configSource)
+	if !ok {
+		return nil
 	}
 
+	l.outputs = ""
+	if err := l.apply(cfg); err != nil {
+		return err
+	}
+	l.watchReloads()
 	return nil
 }
 
-func (l *loggerComponent) Shutdown(ctx context.Context) error {
+// apply sets level, format and outputs from config. Sinks are replaced only
+// once the new ones have all opened.
+func (l *loggerComponent) apply(cfg configSource) error {
+	applyLogLevel(cfg.Get("config", "log_level"))
+	SetLogFormat(cfg.GetString("config", "log_format"))
+
+	rotation := FileRotation{
+		MaxSize:    int64(cfg.GetInt("config", "log_file_max_size")) << 20,
+		MaxAge:     cfg.GetDuration("config", "log_file_max_age"),
+		MaxBackups: cfg.GetInt("config", "log_file_max_backups"),
+	}
+	outputs := cfg.Get("config", "log_outputs")
+	fingerprint := fmt.Sprintf("%v|%+v", outputs, rotation)
+	if fingerprint == l.outputs {
+		return nil
+	}
+	if err := ConfigureLogOutputs(outputs, rotation); err != nil {
+		return err
+	}
+	l.outputs = fingerprint
 	return nil
 }
 
+// applyLogLevel takes config.log_level as either one level or a map of
+// logger name to level.
+func applyLogLevel(v interface{}) {
+	switch val := v.(type) {
+	case string:
+		SetLogLevel(val)
+	case map[string]interface{}:
+		levels := make(map[string]string, len(val))
+		for name, level := range val {
+			if s, ok := level.(string); ok {
+				levels[name] = s
+			}
+		}
+		SetLogLevels(levels)
+	}
+}
+
+// watchReloads reapplies the logging settings when a config reload changes
+// the config section.
+func (l *loggerComponent) watchReloads() {
+	if l.reloads != nil {
+		return
+	}
+	l.reloads = ConfigReloaded.Subscribe("logger", 8, func(reload ConfigReload) {
+		for _, section := range reload.Sections {
+			if section != "config" {
+				continue
+			}
+			cfg, ok := GetComponent("config").(configSource)
+			if !ok {
+				continue
+			}
+			if err := l.apply(cfg); err != nil {
+				GetLogger("logger").Error("Applying reloaded log settings, keeping previous outputs: %v", err)
+			}
+		}
+	})
+}
+
+func (l *loggerComponent) Shutdown(ctx context.Context) error {
+	if l.reloads != nil {
+		l.reloads.Close()
+		l.reloads = nil
+	}
+	l.outputs = ""
+	return CloseLogSinks()
+}
+
 func init() {
 	Register(&loggerComponent{})
 }
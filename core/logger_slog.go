@@ -0,0 +1,65 @@
+// core/logger_slog.go
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+// SlogLogger adapts a *slog.Logger to Logger. Messages are formatted before
+// being handed to slog, and fields become attributes.
+type SlogLogger struct {
+	logger *slog.Logger
+}
+
+func NewSlogLogger(l *slog.Logger) *SlogLogger {
+	return &SlogLogger{logger: l}
+}
+
+// SlogFactory returns a factory for SetLoggerFactory that logs through l,
+// with each logger's name in a "logger" attribute.
+func SlogFactory(l *slog.Logger) func(name string) Logger {
+	return func(name string) Logger {
+		return NewSlogLogger(l.With("logger", name))
+	}
+}
+
+func (l *SlogLogger) Debug(format string, args ...interface{}) {
+	l.log(slog.LevelDebug, format, args)
+}
+
+func (l *SlogLogger) Info(format string, args ...interface{}) {
+	l.log(slog.LevelInfo, format, args)
+}
+
+func (l *SlogLogger) Warn(format string, args ...interface{}) {
+	l.log(slog.LevelWarn, format, args)
+}
+
+func (l *SlogLogger) Error(format string, args ...interface{}) {
+	l.log(slog.LevelError, format, args)
+}
+
+func (l *SlogLogger) With(fields map[string]interface{}) Logger {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	attrs := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		attrs = append(attrs, slog.Any(k, fields[k]))
+	}
+	return &SlogLogger{logger: l.logger.With(attrs...)}
+}
+
+func (l *SlogLogger) log(level slog.Level, format string, args []interface{}) {
+	ctx := context.Background()
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+	l.logger.Log(ctx, level, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,335 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type LogSink interface {
+	io.Writer
+	Close() error
+}
+
+type FileRotation struct {
+	MaxSize    int64         // bytes; 0 disables size-based rotation
+	MaxAge     time.Duration // 0 disables age-based rotation
+	MaxBackups int           // rotated files to keep; 0 keeps all
+}
+
+var (
+	sinksMu     sync.RWMutex
+	globalSinks []LogSink
+	namedSinks  = make(map[string][]LogSink)
+)
+
+// SetLogSinks routes every logger without its own sinks to the given sinks.
+// With no sinks configured, output goes through the standard log package.
+func SetLogSinks(sinks ...LogSink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	globalSinks = sinks
+}
+
+func SetLoggerSinks(name string, sinks ...LogSink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	if len(sinks) == 0 {
+		delete(namedSinks, name)
+		return
+	}
+	namedSinks[name] = sinks
+}
+
+// CloseLogSinks closes every configured sink once and resets output to the
+// standard log package.
+func CloseLogSinks() error {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+
+	closed := make(map[LogSink]bool)
+	var firstErr error
+	closeAll := func(sinks []LogSink) {
+		for _, s := range sinks {
+			if closed[s] {
+				continue
+			}
+			closed[s] = true
+			if err := s.Close(); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	closeAll(globalSinks)
+	for _, sinks := range namedSinks {
+		closeAll(sinks)
+	}
+
+	globalSinks = nil
+	namedSinks = make(map[string][]LogSink)
+	return firstErr
+}
+
+func sinksFor(name string) []LogSink {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	if sinks, ok := namedSinks[name]; ok {
+		return sinks
+	}
+	return globalSinks
+}
+
+// OpenLogSink builds a sink from a spec string: "stdout", "stderr",
+// "syslog", "syslog:<tag>" or "file:<path>".
+func OpenLogSink(spec string, rotation FileRotation) (LogSink, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "stdout":
+		return nopCloser{os.Stdout}, nil
+	case "stderr":
+		return nopCloser{os.Stderr}, nil
+	case "syslog":
+		if arg == "" {
+			arg = filepath.Base(os.Args[0])
+		}
+		return NewSyslogSink(arg)
+	case "file":
+		if arg == "" {
+			return nil, fmt.Errorf("file log sink requires a path")
+		}
+		return NewFileSink(arg, rotation)
+	}
+	return nil, fmt.Errorf("unknown log sink: %s", spec)
+}
+
+// ConfigureLogOutputs replaces all sinks from a config value: either a list
+// of sink specs applied to every logger, or an object mapping logger names
+// to spec lists, where "*" sets the global sinks.
+func ConfigureLogOutputs(outputs interface{}, rotation FileRotation) error {
+	specs, err := parseLogOutputs(outputs)
+	if err != nil {
+		return err
+	}
+
+	// Specs shared between loggers reuse one sink so a file is opened once
+	opened := make(map[string]LogSink)
+	build := func(list []string) ([]LogSink, error) {
+		var sinks []LogSink
+		for _, spec := range list {
+			sink, ok := opened[spec]
+			if !ok {
+				var err error
+				if sink, err = OpenLogSink(spec, rotation); err != nil {
+					return nil, err
+				}
+				opened[spec] = sink
+			}
+			sinks = append(sinks, sink)
+		}
+		return sinks, nil
+	}
+
+	global, err := build(specs["*"])
+	named := make(map[string][]LogSink)
+	for name, list := range specs {
+		if err != nil {
+			break
+		}
+		if name != "*" {
+			named[name], err = build(list)
+		}
+	}
+	if err != nil {
+		for _, sink := range opened {
+			sink.Close()
+		}
+		return err
+	}
+
+	CloseLogSinks()
+
+	sinksMu.Lock()
+	globalSinks = global
+	namedSinks = named
+	sinksMu.Unlock()
+	return nil
+}
+
+func parseLogOutputs(outputs interface{}) (map[string][]string, error) {
+	specs := make(map[string][]string)
+	switch val := outputs.(type) {
+	case nil:
+	case []string:
+		specs["*"] = val
+	case []interface{}:
+		list, err := toStringList(val)
+		if err != nil {
+			return nil, err
+		}
+		specs["*"] = list
+	case map[string]interface{}:
+		for name, v := range val {
+			items, ok := v.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("log outputs for %s must be a list", name)
+			}
+			list, err := toStringList(items)
+			if err != nil {
+				return nil, err
+			}
+			specs[name] = list
+		}
+	default:
+		return nil, fmt.Errorf("log outputs must be a list or an object, got %T", outputs)
+	}
+	return specs, nil
+}
+
+func toStringList(items []interface{}) ([]string, error) {
+	list := make([]string, len(items))
+	for i, item := range items {
+		s, ok := item.(string)
+		if !ok {
+			return nil, fmt.Errorf("log output must be string, got %T", item)
+		}
+		list[i] = s
+	}
+	return list, nil
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+type FileSink struct {
+	path     string
+	rotation FileRotation
+	file     *os.File
+	size     int64
+	opened   time.Time
+	mu       sync.Mutex
+}
+
+func NewFileSink(path string, rotation FileRotation) (*FileSink, error) {
+	s := &FileSink{path: path, rotation: rotation}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("creating log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	s.file = f
+	s.size = info.Size()
+	s.opened = time.Now()
+	return nil
+}
+
+func (s *FileSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if s.shouldRotate(int64(len(p))) {
+		if err := s.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := s.file.Write(p)
+	s.size += int64(n)
+	return n, err
+}
+
+func (s *FileSink) shouldRotate(next int64) bool {
+	if s.rotation.MaxSize > 0 && s.size > 0 && s.size+next > s.rotation.MaxSize {
+		return true
+	}
+	return s.rotation.MaxAge > 0 && time.Since(s.opened) > s.rotation.MaxAge
+}
+
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return err
+	}
+	s.file = nil
+
+	backup := fmt.Sprintf("%s.%s", s.path, time.Now().Format("20060102-150405.000"))
+	for i := 1; fileExists(backup); i++ {
+		backup = fmt.Sprintf("%s.%s-%d", s.path, time.Now().Format("20060102-150405.000"), i)
+	}
+	if err := os.Rename(s.path, backup); err != nil {
+		// Keep writing to the current file rather than losing output
+		s.open()
+		return fmt.Errorf("rotating log file: %w", err)
+	}
+
+	if err := s.open(); err != nil {
+		return err
+	}
+
+	s.pruneBackups()
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func (s *FileSink) pruneBackups() {
+	if s.rotation.MaxBackups <= 0 {
+		return
+	}
+
+	backups, err := filepath.Glob(s.path + ".*")
+	if err != nil || len(backups) <= s.rotation.MaxBackups {
+		return
+	}
+
+	// Timestamp suffixes sort chronologically
+	sort.Strings(backups)
+	for _, old := range backups[:len(backups)-s.rotation.MaxBackups] {
+		os.Remove(old)
+	}
+}
+
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
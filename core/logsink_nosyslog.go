@@ -0,0 +1,11 @@
+//go:build windows || plan9
+
+package core
+
+import (
+	"fmt"
+)
+
+func NewSyslogSink(tag string) (LogSink, error) {
+	return nil, fmt.Errorf("syslog is not supported on this platform")
+}
@@ -0,0 +1,11 @@
+//go:build !windows && !plan9
+
+package core
+
+import (
+	"log/syslog"
+)
+
+func NewSyslogSink(tag string) (LogSink, error) {
+	return syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+}
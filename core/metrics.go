@@ -1,6 +1,10 @@
 package core
 
 import (
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -9,76 +13,258 @@ import (
 type Metrics struct {
 	counters   map[string]*int64
 	gauges     map[string]*int64
+	floats     map[string]*uint64
 	histograms map[string]*Histogram
+	series     map[string]metricSeries
 	mu         sync.RWMutex
 }
 
+// metricSeries identifies a stored metric: its base name plus the label
+// set it was recorded with. Series are keyed as name{k="v",...}.
+type metricSeries struct {
+	name   string
+	labels string
+}
+
+// reservoirSize bounds the number of samples kept per histogram for
+// quantile estimation; count, sum, min and max are tracked exactly.
+const reservoirSize = 1024
+
 type Histogram struct {
-	values []float64
-	mu     sync.Mutex
+	reservoir []float64
+	count     int64
+	sum       float64
+	min       float64
+	max       float64
+	mu        sync.Mutex
+}
+
+type HistogramSnapshot struct {
+	Count int64
+	Sum   float64
+	Min   float64
+	Max   float64
+	P50   float64
+	P90   float64
+	P95   float64
+	P99   float64
+}
+
+func (h *Histogram) observe(value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.count++
+	h.sum += value
+	if h.count == 1 || value < h.min {
+		h.min = value
+	}
+	if h.count == 1 || value > h.max {
+		h.max = value
+	}
+
+	// Reservoir sampling keeps a uniform sample of everything observed
+	if len(h.reservoir) < reservoirSize {
+		h.reservoir = append(h.reservoir, value)
+	} else if j := rand.Int64N(h.count); j < reservoirSize {
+		h.reservoir[j] = value
+	}
+}
+
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	sorted := append([]float64(nil), h.reservoir...)
+	snap := HistogramSnapshot{
+		Count: h.count,
+		Sum:   h.sum,
+		Min:   h.min,
+		Max:   h.max,
+	}
+	h.mu.Unlock()
+
+	sort.Float64s(sorted)
+	snap.P50 = quantile(sorted, 0.50)
+	snap.P90 = quantile(sorted, 0.90)
+	snap.P95 = quantile(sorted, 0.95)
+	snap.P99 = quantile(sorted, 0.99)
+	return snap
+}
+
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
 }
 
 var metrics = &Metrics{
 	counters:   make(map[string]*int64),
 	gauges:     make(map[string]*int64),
+	floats:     make(map[string]*uint64),
 	histograms: make(map[string]*Histogram),
+	series:     make(map[string]metricSeries),
 }
 
 func IncrCounter(name string) {
+	IncrCounterWithLabels(name, nil)
+}
+
+func IncrCounterWithLabels(name string, labels map[string]string) {
+	atomic.AddInt64(counterFor(name, labels), 1)
+}
+
+// AddCounter increases a counter by delta, for work counted in batches.
+func AddCounter(name string, delta int64) {
+	atomic.AddInt64(counterFor(name, nil), delta)
+}
+
+// SetCounterWithLabels sets a counter to a cumulative total kept
+// elsewhere, such as the pool package's own counters or sql.DBStats.
+func SetCounterWithLabels(name string, labels map[string]string, value int64) {
+	atomic.StoreInt64(counterFor(name, labels), value)
+}
+
+func counterFor(name string, labels map[string]string) *int64 {
+	key := seriesKey(name, labels)
+
 	metrics.mu.RLock()
-	counter, ok := metrics.counters[name]
+	counter, ok := metrics.counters[key]
 	metrics.mu.RUnlock()
 
 	if !ok {
 		metrics.mu.Lock()
-		counter = new(int64)
-		metrics.counters[name] = counter
+		if counter, ok = metrics.counters[key]; !ok {
+			counter = new(int64)
+			metrics.counters[key] = counter
+			metrics.series[key] = metricSeries{name: name, labels: formatLabels(labels)}
+		}
 		metrics.mu.Unlock()
 	}
 
-	atomic.AddInt64(counter, 1)
+	return counter
 }
 
 func SetGauge(name string, value int64) {
+	SetGaugeWithLabels(name, nil, value)
+}
+
+func SetGaugeWithLabels(name string, labels map[string]string, value int64) {
+	key := seriesKey(name, labels)
+
 	metrics.mu.RLock()
-	gauge, ok := metrics.gauges[name]
+	gauge, ok := metrics.gauges[key]
 	metrics.mu.RUnlock()
 
 	if !ok {
 		metrics.mu.Lock()
-		gauge = new(int64)
-		metrics.gauges[name] = gauge
+		if gauge, ok = metrics.gauges[key]; !ok {
+			gauge = new(int64)
+			metrics.gauges[key] = gauge
+			metrics.series[key] = metricSeries{name: name, labels: formatLabels(labels)}
+		}
 		metrics.mu.Unlock()
 	}
 
 	atomic.StoreInt64(gauge, value)
 }
 
+// SetFloatGauge sets a gauge with a fractional value, such as a ratio.
+func SetFloatGauge(name string, value float64) {
+	SetFloatGaugeWithLabels(name, nil, value)
+}
+
+func SetFloatGaugeWithLabels(name string, labels map[string]string, value float64) {
+	key := seriesKey(name, labels)
+
+	metrics.mu.RLock()
+	gauge, ok := metrics.floats[key]
+	metrics.mu.RUnlock()
+
+	if !ok {
+		metrics.mu.Lock()
+		if gauge, ok = metrics.floats[key]; !ok {
+			gauge = new(uint64)
+			metrics.floats[key] = gauge
+			metrics.series[key] = metricSeries{name: name, labels: formatLabels(labels)}
+		}
+		metrics.mu.Unlock()
+	}
+
+	atomic.StoreUint64(gauge, math.Float64bits(value))
+}
+
 func RecordDuration(name string, start time.Time) {
 	RecordValue(name, float64(time.Since(start).Microseconds()))
 }
 
+func RecordDurationWithLabels(name string, labels map[string]string, start time.Time) {
+	RecordValueWithLabels(name, labels, float64(time.Since(start).Microseconds()))
+}
+
 func RecordValue(name string, value float64) {
+	RecordValueWithLabels(name, nil, value)
+}
+
+func RecordValueWithLabels(name string, labels map[string]string, value float64) {
+	key := seriesKey(name, labels)
+
 	metrics.mu.RLock()
-	hist, ok := metrics.histograms[name]
+	hist, ok := metrics.histograms[key]
 	metrics.mu.RUnlock()
 
 	if !ok {
 		metrics.mu.Lock()
-		hist = &Histogram{}
-		metrics.histograms[name] = hist
+		if hist, ok = metrics.histograms[key]; !ok {
+			hist = &Histogram{}
+			metrics.histograms[key] = hist
+			metrics.series[key] = metricSeries{name: name, labels: formatLabels(labels)}
+		}
 		metrics.mu.Unlock()
 	}
 
-	hist.mu.Lock()
-	hist.values = append(hist.values, value)
-	if len(hist.values) > 10000 {
-		hist.values = hist.values[1:]
+	hist.observe(value)
+}
+
+func seriesKey(name string, labels map[string]string) string {
+	if len(labels) == 0 {
+		return name
 	}
-	hist.mu.Unlock()
+	return name + "{" + formatLabels(labels) + "}"
 }
 
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(promName(k))
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(labels[k]))
+		b.WriteByte('"')
+	}
+	return b.String()
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
 func GetMetrics() map[string]interface{} {
+	collectPoolMetrics()
+
 	metrics.mu.RLock()
 	defer metrics.mu.RUnlock()
 
@@ -92,17 +278,23 @@ func GetMetrics() map[string]interface{} {
 		result["gauge."+name] = atomic.LoadInt64(gauge)
 	}
 
+	for name, gauge := range metrics.floats {
+		result["gauge."+name] = math.Float64frombits(atomic.LoadUint64(gauge))
+	}
+
 	for name, hist := range metrics.histograms {
-		hist.mu.Lock()
-		if len(hist.values) > 0 {
-			sum := 0.0
-			for _, v := range hist.values {
-				sum += v
-			}
-			result["histogram."+name+".avg"] = sum / float64(len(hist.values))
-			result["histogram."+name+".count"] = len(hist.values)
+		snap := hist.Snapshot()
+		if snap.Count > 0 {
+			prefix := "histogram." + name
+			result[prefix+".avg"] = snap.Sum / float64(snap.Count)
+			result[prefix+".count"] = snap.Count
+			result[prefix+".min"] = snap.Min
+			result[prefix+".max"] = snap.Max
+			result[prefix+".p50"] = snap.P50
+			result[prefix+".p90"] = snap.P90
+			result[prefix+".p95"] = snap.P95
+			result[prefix+".p99"] = snap.P99
 		}
-		hist.mu.Unlock()
 	}
 
 	return result
@@ -0,0 +1,376 @@
+// core/pipeline/pipeline.go
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// Pipeline runs stages connected by bounded channels. A slow stage fills
+// the channel in front of it and so slows every stage upstream, rather than
+// letting work pile up in memory. The first stage error cancels the
+// pipeline's context, every stage drains out, and Wait returns that error.
+//
+//	p, ctx := pipeline.New(ctx, "indexer")
+//	blocks := pipeline.Source(p, "fetch", 16, fetchBlocks)
+//	decoded := pipeline.MapOrdered(p, "decode", blocks, 8, 16, decode)
+//	pipeline.Sink(p, "store", decoded, store)
+//	err := p.Wait()
+type Pipeline struct {
+	name   string
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	errOnce sync.Once
+	err     error
+
+	mu     sync.Mutex
+	stages []*stage
+}
+
+type stage struct {
+	name    string
+	labels  map[string]string
+	in      atomic.Int64
+	out     atomic.Int64
+	errors  atomic.Int64
+	blocked atomic.Int64
+	queue   func() int
+}
+
+// StageStats describes one stage. Blocked is the time spent waiting for
+// the next stage to accept output: a stage that is blocked a lot sits
+// upstream of the bottleneck, and the stage whose Queued input stays full
+// is the bottleneck.
+type StageStats struct {
+	Name    string        `json:"name"`
+	In      int64         `json:"in"`
+	Out     int64         `json:"out"`
+	Errors  int64         `json:"errors"`
+	Blocked time.Duration `json:"blocked"`
+	Queued  int           `json:"queued"`
+}
+
+func New(ctx context.Context, name string) (*Pipeline, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Pipeline{name: name, ctx: ctx, cancel: cancel}, ctx
+}
+
+// Wait blocks until every stage has returned and reports the first error.
+func (p *Pipeline) Wait() error {
+	p.wg.Wait()
+	p.cancel()
+	return p.err
+}
+
+// Stats returns each stage's counters in the order stages were added.
+func (p *Pipeline) Stats() []StageStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := make([]StageStats, 0, len(p.stages))
+	for _, s := range p.stages {
+		st := StageStats{
+			Name:    s.name,
+			In:      s.in.Load(),
+			Out:     s.out.Load(),
+			Errors:  s.errors.Load(),
+			Blocked: time.Duration(s.blocked.Load()),
+		}
+		if s.queue != nil {
+			st.Queued = s.queue()
+		}
+		stats = append(stats, st)
+	}
+	return stats
+}
+
+func (p *Pipeline) fail(s *stage, err error) {
+	s.errors.Add(1)
+	core.IncrCounterWithLabels("pipeline.errors", s.labels)
+	p.errOnce.Do(func() {
+		p.err = fmt.Errorf("pipeline %s, stage %s: %w", p.name, s.name, err)
+		p.cancel()
+	})
+}
+
+func (p *Pipeline) addStage(name string, queue func() int) *stage {
+	s := &stage{
+		name:   name,
+		labels: map[string]string{"pipeline": p.name, "stage": name},
+		queue:  queue,
+	}
+	p.mu.Lock()
+	p.stages = append(p.stages, s)
+	p.mu.Unlock()
+	return s
+}
+
+func (p *Pipeline) run(s *stage, fn func()) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				p.fail(s, fmt.Errorf("panic: %v", r))
+			}
+		}()
+		fn()
+	}()
+}
+
+// send delivers v unless the pipeline is cancelled first, accounting the
+// time spent waiting as backpressure.
+func send[T any](p *Pipeline, s *stage, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+	default:
+		start := time.Now()
+		select {
+		case out <- v:
+		case <-p.ctx.Done():
+			return false
+		}
+		waited := time.Since(start)
+		s.blocked.Add(int64(waited))
+		core.RecordValueWithLabels("pipeline.blocked_us", s.labels, float64(waited.Microseconds()))
+	}
+	s.out.Add(1)
+	core.IncrCounterWithLabels("pipeline.items", s.labels)
+	return true
+}
+
+// Source runs fn, which calls emit for each item it produces. emit returns
+// false once the pipeline is cancelled, and fn should then return.
+func Source[T any](p *Pipeline, name string, buffer int, fn func(ctx context.Context, emit func(T) bool) error) <-chan T {
+	out := make(chan T, buffer)
+	s := p.addStage(name, nil)
+
+	p.run(s, func() {
+		defer close(out)
+		emit := func(v T) bool { return send(p, s, out, v) }
+		if err := fn(p.ctx, emit); err != nil && p.ctx.Err() == nil {
+			p.fail(s, err)
+		}
+	})
+	return out
+}
+
+// Map applies fn to items from in on workers goroutines. Output order is
+// not preserved; use MapOrdered when it matters.
+func Map[In, Out any](p *Pipeline, name string, in <-chan In, workers, buffer int, fn func(context.Context, In) (Out, error)) <-chan Out {
+	out := make(chan Out, buffer)
+	s := p.addStage(name, func() int { return len(in) })
+	workers = max(workers, 1)
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		p.run(s, func() {
+			defer wg.Done()
+			for v := range recv(p, in) {
+				s.in.Add(1)
+				result, err := fn(p.ctx, v)
+				if err != nil {
+					p.fail(s, err)
+					return
+				}
+				if !send(p, s, out, result) {
+					return
+				}
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// MapOrdered is Map with output in input order. At most workers+buffer
+// items are in flight, so one slow item holds back the ones behind it
+// instead of letting them accumulate.
+func MapOrdered[In, Out any](p *Pipeline, name string, in <-chan In, workers, buffer int, fn func(context.Context, In) (Out, error)) <-chan Out {
+	type job struct {
+		seq int
+		v   In
+	}
+	type result struct {
+		seq int
+		v   Out
+	}
+
+	out := make(chan Out, buffer)
+	s := p.addStage(name, func() int { return len(in) })
+	workers = max(workers, 1)
+
+	jobs := make(chan job)
+	results := make(chan result, workers)
+	slots := make(chan struct{}, workers+max(buffer, 0))
+
+	p.run(s, func() {
+		defer close(jobs)
+		seq := 0
+		for v := range recv(p, in) {
+			select {
+			case slots <- struct{}{}:
+			case <-p.ctx.Done():
+				return
+			}
+			s.in.Add(1)
+			select {
+			case jobs <- job{seq: seq, v: v}:
+			case <-p.ctx.Done():
+				return
+			}
+			seq++
+		}
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		p.run(s, func() {
+			defer wg.Done()
+			for j := range jobs {
+				v, err := fn(p.ctx, j.v)
+				if err != nil {
+					p.fail(s, err)
+					return
+				}
+				select {
+				case results <- result{seq: j.seq, v: v}:
+				case <-p.ctx.Done():
+					return
+				}
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	p.run(s, func() {
+		defer close(out)
+		pending := make(map[int]Out)
+		next := 0
+		for r := range results {
+			pending[r.seq] = r.v
+			for {
+				v, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				if !send(p, s, out, v) {
+					return
+				}
+				<-slots
+			}
+		}
+	})
+	return out
+}
+
+// Merge fans several channels into one, in no particular order.
+func Merge[T any](p *Pipeline, name string, buffer int, ins ...<-chan T) <-chan T {
+	out := make(chan T, buffer)
+	s := p.addStage(name, func() int {
+		n := 0
+		for _, in := range ins {
+			n += len(in)
+		}
+		return n
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(len(ins))
+	for _, in := range ins {
+		p.run(s, func() {
+			defer wg.Done()
+			for v := range recv(p, in) {
+				s.in.Add(1)
+				if !send(p, s, out, v) {
+					return
+				}
+			}
+		})
+	}
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// FanOut copies every item from in to n outputs. The slowest consumer sets
+// the pace for all of them.
+func FanOut[T any](p *Pipeline, name string, in <-chan T, n, buffer int) []<-chan T {
+	outs := make([]chan T, n)
+	result := make([]<-chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T, buffer)
+		result[i] = outs[i]
+	}
+	s := p.addStage(name, func() int { return len(in) })
+
+	p.run(s, func() {
+		defer func() {
+			for _, out := range outs {
+				close(out)
+			}
+		}()
+		for v := range recv(p, in) {
+			s.in.Add(1)
+			for _, out := range outs {
+				if !send(p, s, out, v) {
+					return
+				}
+			}
+		}
+	})
+	return result
+}
+
+// Sink consumes in with fn. Wait returns once it has drained.
+func Sink[T any](p *Pipeline, name string, in <-chan T, fn func(context.Context, T) error) {
+	s := p.addStage(name, func() int { return len(in) })
+
+	p.run(s, func() {
+		for v := range recv(p, in) {
+			s.in.Add(1)
+			if err := fn(p.ctx, v); err != nil {
+				p.fail(s, err)
+				return
+			}
+			s.out.Add(1)
+			core.IncrCounterWithLabels("pipeline.items", s.labels)
+		}
+	})
+}
+
+// recv yields items from in until it is closed or the pipeline is
+// cancelled.
+func recv[T any](p *Pipeline, in <-chan T) func(yield func(T) bool) {
+	return func(yield func(T) bool) {
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok || !yield(v) {
+					return
+				}
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	}
+}
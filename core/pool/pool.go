@@ -0,0 +1,266 @@
+// core/pool/pool.go
+package pool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Package pool must not import core: core's logger uses it. Core reads
+// Snapshot when metrics are collected instead.
+
+var ErrTimeout = errors.New("timed out waiting for a pooled object")
+
+// Stats are the cumulative counters of one pool.
+type Stats struct {
+	Name     string
+	Gets     int64
+	Puts     int64
+	News     int64
+	Waits    int64
+	Timeouts int64
+	Dropped  int64
+	InUse    int64
+}
+
+type counters struct {
+	name     string
+	gets     atomic.Int64
+	puts     atomic.Int64
+	news     atomic.Int64
+	waits    atomic.Int64
+	timeouts atomic.Int64
+	dropped  atomic.Int64
+	inUse    atomic.Int64
+}
+
+func (c *counters) stats() Stats {
+	return Stats{
+		Name:     c.name,
+		Gets:     c.gets.Load(),
+		Puts:     c.puts.Load(),
+		News:     c.news.Load(),
+		Waits:    c.waits.Load(),
+		Timeouts: c.timeouts.Load(),
+		Dropped:  c.dropped.Load(),
+		InUse:    c.inUse.Load(),
+	}
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = make(map[string]*counters)
+)
+
+// register returns the counters for name, shared by pools of the same
+// name so recreating a pool keeps its history.
+func register(name string) *counters {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	c, ok := registry[name]
+	if !ok {
+		c = &counters{name: name}
+		registry[name] = c
+	}
+	return c
+}
+
+// Snapshot returns the stats of every pool, sorted by name.
+func Snapshot() []Stats {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	result := make([]Stats, 0, len(registry))
+	for _, c := range registry {
+		result = append(result, c.stats())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Pool is a typed sync.Pool with counters. The garbage collector may free
+// idle objects at any time, so it never pins memory.
+type Pool[T any] struct {
+	pool  sync.Pool
+	reset func(T)
+	stats *counters
+}
+
+// New creates a pool; reset, if not nil, clears objects as they are put
+// back.
+func New[T any](name string, newFn func() T, reset func(T)) *Pool[T] {
+	p := &Pool[T]{reset: reset, stats: register(name)}
+	p.pool.New = func() interface{} {
+		p.stats.news.Add(1)
+		return newFn()
+	}
+	return p
+}
+
+func (p *Pool[T]) Get() T {
+	p.stats.gets.Add(1)
+	return p.pool.Get().(T)
+}
+
+func (p *Pool[T]) Put(v T) {
+	if p.reset != nil {
+		p.reset(v)
+	}
+	p.stats.puts.Add(1)
+	p.pool.Put(v)
+}
+
+// BufferPool hands out byte slices from power-of-two size classes between
+// a minimum and maximum. Larger requests are allocated directly and never
+// retained.
+type BufferPool struct {
+	minShift int
+	maxShift int
+	classes  []sync.Pool
+	stats    *counters
+}
+
+func NewBufferPool(name string, minSize, maxSize int) *BufferPool {
+	minShift := bits.Len(uint(minSize - 1))
+	maxShift := bits.Len(uint(maxSize - 1))
+	b := &BufferPool{
+		minShift: minShift,
+		maxShift: maxShift,
+		classes:  make([]sync.Pool, maxShift-minShift+1),
+		stats:    register(name),
+	}
+	for i := range b.classes {
+		size := 1 << (minShift + i)
+		b.classes[i].New = func() interface{} {
+			b.stats.news.Add(1)
+			buf := make([]byte, 0, size)
+			return &buf
+		}
+	}
+	return b
+}
+
+// Get returns an empty buffer with capacity of at least size.
+func (b *BufferPool) Get(size int) *[]byte {
+	b.stats.gets.Add(1)
+	shift := max(bits.Len(uint(size-1)), b.minShift)
+	if size <= 0 {
+		shift = b.minShift
+	}
+	if shift > b.maxShift {
+		b.stats.news.Add(1)
+		buf := make([]byte, 0, size)
+		return &buf
+	}
+	buf := b.classes[shift-b.minShift].Get().(*[]byte)
+	*buf = (*buf)[:0]
+	return buf
+}
+
+// Put returns a buffer to the class its capacity fills. Buffers that grew
+// past the largest class are dropped so one large message cannot pin
+// memory.
+func (b *BufferPool) Put(buf *[]byte) {
+	c := cap(*buf)
+	shift := bits.Len(uint(c)) - 1
+	if shift < b.minShift || shift > b.maxShift {
+		b.stats.dropped.Add(1)
+		return
+	}
+	b.stats.puts.Add(1)
+	b.classes[shift-b.minShift].Put(buf)
+}
+
+// Buffers is the shared byte buffer pool, from 256 bytes to 64KiB.
+var Buffers = NewBufferPool("bytes", 256, 64<<10)
+
+func GetBuffer(size int) *[]byte {
+	return Buffers.Get(size)
+}
+
+func PutBuffer(buf *[]byte) {
+	Buffers.Put(buf)
+}
+
+// Bounded holds at most max objects, created on demand. Acquire waits for
+// a free slot when all are in use, which caps both memory and, for pooled
+// connections or workers, concurrency.
+type Bounded[T any] struct {
+	newFn func() (T, error)
+	slots chan struct{}
+	idle  chan T
+	stats *counters
+}
+
+func NewBounded[T any](name string, max int, newFn func() (T, error)) *Bounded[T] {
+	return &Bounded[T]{
+		newFn: newFn,
+		slots: make(chan struct{}, max),
+		idle:  make(chan T, max),
+		stats: register(name),
+	}
+}
+
+// Acquire returns an idle object or creates one, waiting until ctx is done
+// if the pool is exhausted.
+func (b *Bounded[T]) Acquire(ctx context.Context) (T, error) {
+	var zero T
+	b.stats.gets.Add(1)
+
+	select {
+	case b.slots <- struct{}{}:
+	default:
+		b.stats.waits.Add(1)
+		select {
+		case b.slots <- struct{}{}:
+		case <-ctx.Done():
+			b.stats.timeouts.Add(1)
+			return zero, fmt.Errorf("%w: %v", ErrTimeout, ctx.Err())
+		}
+	}
+
+	select {
+	case v := <-b.idle:
+		b.stats.inUse.Add(1)
+		return v, nil
+	default:
+	}
+
+	v, err := b.newFn()
+	if err != nil {
+		<-b.slots
+		return zero, err
+	}
+	b.stats.news.Add(1)
+	b.stats.inUse.Add(1)
+	return v, nil
+}
+
+func (b *Bounded[T]) AcquireTimeout(timeout time.Duration) (T, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return b.Acquire(ctx)
+}
+
+// Release returns v for reuse.
+func (b *Bounded[T]) Release(v T) {
+	b.stats.puts.Add(1)
+	b.stats.inUse.Add(-1)
+	b.idle <- v
+	<-b.slots
+}
+
+// Discard frees v's slot without keeping v, for objects that broke while
+// in use.
+func (b *Bounded[T]) Discard(v T) {
+	b.stats.dropped.Add(1)
+	b.stats.inUse.Add(-1)
+	<-b.slots
+}
@@ -0,0 +1,19 @@
+// core/pool_metrics.go
+package core
+
+import "github.com/polkadot-go/helper/core/pool"
+
+// collectPoolMetrics mirrors the pool package's counters into the metrics
+// registry each time metrics are read.
+func collectPoolMetrics() {
+	for _, s := range pool.Snapshot() {
+		labels := map[string]string{"pool": s.Name}
+		SetCounterWithLabels("pool.gets", labels, s.Gets)
+		SetCounterWithLabels("pool.puts", labels, s.Puts)
+		SetCounterWithLabels("pool.news", labels, s.News)
+		SetCounterWithLabels("pool.waits", labels, s.Waits)
+		SetCounterWithLabels("pool.timeouts", labels, s.Timeouts)
+		SetCounterWithLabels("pool.dropped", labels, s.Dropped)
+		SetGaugeWithLabels("pool.in_use", labels, s.InUse)
+	}
+}
@@ -0,0 +1,233 @@
+// core/preflight.go
+package core
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PreflightFunc is a fast environment check, such as a host name resolving
+// or a port being free. It runs after config is loaded and before any other
+// component initializes.
+type PreflightFunc func(ctx context.Context) error
+
+type preflightCheck struct {
+	component string
+	name      string
+	fn        PreflightFunc
+}
+
+// PreflightResult is the outcome of one check.
+type PreflightResult struct {
+	Component string        `json:"component"`
+	Check     string        `json:"check"`
+	Passed    bool          `json:"passed"`
+	Error     string        `json:"error,omitempty"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// PreflightReport holds every check run before an Initialize.
+type PreflightReport struct {
+	Passed  bool              `json:"passed"`
+	Results []PreflightResult `json:"results"`
+}
+
+// PreflightError fails Initialize when any check fails, before components
+// other than config have started.
+type PreflightError struct {
+	Report *PreflightReport
+}
+
+func (e *PreflightError) Error() string {
+	var failed []string
+	for _, r := range e.Report.Results {
+		if !r.Passed {
+			failed = append(failed, fmt.Sprintf("%s/%s: %s", r.Component, r.Check, r.Error))
+		}
+	}
+	return fmt.Sprintf("preflight failed: %s", strings.Join(failed, "; "))
+}
+
+const preflightTimeout = 5 * time.Second
+
+var (
+	preflightMu   sync.Mutex
+	preflights    []preflightCheck
+	lastPreflight *PreflightReport
+)
+
+// RegisterPreflight adds a check for component. Checks only run when the
+// component is registered and about to be initialized.
+func RegisterPreflight(component, name string, fn PreflightFunc) {
+	preflightMu.Lock()
+	defer preflightMu.Unlock()
+	preflights = append(preflights, preflightCheck{component: component, name: name, fn: fn})
+}
+
+// LastPreflight returns the report of the most recent preflight run, or nil.
+func LastPreflight() *PreflightReport {
+	preflightMu.Lock()
+	defer preflightMu.Unlock()
+	return lastPreflight
+}
+
+// preflight runs the checks of the components in pending, bringing config
+// up first so checks can read their settings. Called with lifecycleMu held.
+func (r *Registry) preflight(ctx context.Context, pending map[string]bool) (*PreflightReport, error) {
+	preflightMu.Lock()
+	var checks []preflightCheck
+	for _, c := range preflights {
+		if pending[c.component] {
+			checks = append(checks, c)
+		}
+	}
+	preflightMu.Unlock()
+
+	if len(checks) == 0 {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	_, hasConfig := r.components["config"]
+	r.mu.Unlock()
+	if hasConfig {
+		if err := r.initOne(ctx, "config"); err != nil {
+			return nil, err
+		}
+	}
+
+	report := runPreflight(ctx, checks)
+
+	preflightMu.Lock()
+	lastPreflight = report
+	preflightMu.Unlock()
+
+	logger := GetLogger("preflight")
+	for _, result := range report.Results {
+		if result.Passed {
+			logger.Debug("PASS %s/%s (%s)", result.Component, result.Check, result.Duration)
+		} else {
+			IncrCounterWithLabels("preflight.failures", map[string]string{"component": result.Component})
+			logger.Error("FAIL %s/%s: %s", result.Component, result.Check, result.Error)
+		}
+	}
+	if !report.Passed {
+		return report, &PreflightError{Report: report}
+	}
+	logger.Info("Preflight passed, %d checks", len(report.Results))
+	return report, nil
+}
+
+// runPreflight runs checks concurrently, each under its own timeout, and
+// reports them sorted by component and check name.
+func runPreflight(ctx context.Context, checks []preflightCheck) *PreflightReport {
+	results := make([]PreflightResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, preflightTimeout)
+			defer cancel()
+
+			start := time.Now()
+			var checkErr error
+			if err := runProtected(func() { checkErr = c.fn(checkCtx) }); err != nil {
+				checkErr = err
+			}
+			results[i] = PreflightResult{
+				Component: c.component,
+				Check:     c.name,
+				Passed:    checkErr == nil,
+				Duration:  time.Since(start),
+			}
+			if checkErr != nil {
+				results[i].Error = checkErr.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Component != results[j].Component {
+			return results[i].Component < results[j].Component
+		}
+		return results[i].Check < results[j].Check
+	})
+
+	report := &PreflightReport{Passed: true, Results: results}
+	for _, r := range results {
+		if !r.Passed {
+			report.Passed = false
+		}
+	}
+	return report
+}
+
+// CheckDNS verifies host resolves. IP addresses and empty hosts pass.
+func CheckDNS(ctx context.Context, host string) error {
+	if host == "" || net.ParseIP(host) != nil {
+		return nil
+	}
+	if _, err := net.DefaultResolver.LookupHost(ctx, host); err != nil {
+		return err
+	}
+	return nil
+}
+
+// CheckPortFree verifies nothing else is listening on address. An empty
+// address passes, since it usually means the listener is disabled.
+func CheckPortFree(address string) error {
+	if address == "" {
+		return nil
+	}
+	ln, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	return ln.Close()
+}
+
+// CheckWritable verifies dir exists and files can be created in it.
+func CheckWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".preflight-*")
+	if err != nil {
+		return err
+	}
+	name := f.Name()
+	f.Close()
+	return os.Remove(name)
+}
+
+// CheckFilePermissions verifies path exists and grants nothing outside
+// allowed, e.g. 0o600 for a file holding secrets.
+func CheckFilePermissions(path string, allowed os.FileMode) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if extra := info.Mode().Perm() &^ allowed; extra != 0 {
+		return fmt.Errorf("%s has mode %s, wider than %s", path, info.Mode().Perm(), allowed)
+	}
+	return nil
+}
+
+// CheckDiskSpace verifies the filesystem holding path has at least
+// minFree bytes available.
+func CheckDiskSpace(path string, minFree uint64) error {
+	free, ok, err := diskFree(filepath.Clean(path))
+	if err != nil || !ok {
+		return err
+	}
+	if free < minFree {
+		return fmt.Errorf("%s has %d MiB free, want %d MiB", path, free>>20, minFree>>20)
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+//go:build linux || darwin
+
+package core
+
+import "syscall"
+
+func diskFree(path string) (uint64, bool, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, false, err
+	}
+	return uint64(st.Bavail) * uint64(st.Bsize), true, nil
+}
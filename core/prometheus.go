@@ -0,0 +1,136 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+func WritePrometheus(w io.Writer) error {
+	collectPoolMetrics()
+
+	metrics.mu.RLock()
+	defer metrics.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+
+	var last string
+	for _, key := range sortedSeries(metrics, metrics.counters) {
+		s := metrics.series[key]
+		pn := promName(s.name)
+		if pn != last {
+			fmt.Fprintf(bw, "# TYPE %s counter\n", pn)
+			last = pn
+		}
+		fmt.Fprintf(bw, "%s%s %d\n", pn, promLabels(s.labels, ""), atomic.LoadInt64(metrics.counters[key]))
+	}
+
+	last = ""
+	for _, key := range sortedSeries(metrics, metrics.gauges) {
+		s := metrics.series[key]
+		pn := promName(s.name)
+		if pn != last {
+			fmt.Fprintf(bw, "# TYPE %s gauge\n", pn)
+			last = pn
+		}
+		fmt.Fprintf(bw, "%s%s %d\n", pn, promLabels(s.labels, ""), atomic.LoadInt64(metrics.gauges[key]))
+	}
+
+	last = ""
+	for _, key := range sortedSeries(metrics, metrics.floats) {
+		s := metrics.series[key]
+		pn := promName(s.name)
+		if pn != last {
+			fmt.Fprintf(bw, "# TYPE %s gauge\n", pn)
+			last = pn
+		}
+		fmt.Fprintf(bw, "%s%s %g\n", pn, promLabels(s.labels, ""), math.Float64frombits(atomic.LoadUint64(metrics.floats[key])))
+	}
+
+	last = ""
+	for _, key := range sortedSeries(metrics, metrics.histograms) {
+		s := metrics.series[key]
+		snap := metrics.histograms[key].Snapshot()
+
+		pn := promName(s.name)
+		if pn != last {
+			fmt.Fprintf(bw, "# TYPE %s summary\n", pn)
+			last = pn
+		}
+		fmt.Fprintf(bw, "%s%s %g\n", pn, promLabels(s.labels, `quantile="0.5"`), snap.P50)
+		fmt.Fprintf(bw, "%s%s %g\n", pn, promLabels(s.labels, `quantile="0.9"`), snap.P90)
+		fmt.Fprintf(bw, "%s%s %g\n", pn, promLabels(s.labels, `quantile="0.95"`), snap.P95)
+		fmt.Fprintf(bw, "%s%s %g\n", pn, promLabels(s.labels, `quantile="0.99"`), snap.P99)
+		fmt.Fprintf(bw, "%s_sum%s %g\n", pn, promLabels(s.labels, ""), snap.Sum)
+		fmt.Fprintf(bw, "%s_count%s %d\n", pn, promLabels(s.labels, ""), snap.Count)
+	}
+
+	return bw.Flush()
+}
+
+func MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := WritePrometheus(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// sortedSeries orders series keys by metric name, then label set, so all
+// series of one metric are written together under a single TYPE line.
+func sortedSeries[V any](m *Metrics, set map[string]V) []string {
+	keys := sortedKeys(set)
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := m.series[keys[i]], m.series[keys[j]]
+		if a.name != b.name {
+			return a.name < b.name
+		}
+		return a.labels < b.labels
+	})
+	return keys
+}
+
+func promLabels(labels, extra string) string {
+	switch {
+	case labels == "" && extra == "":
+		return ""
+	case labels == "":
+		return "{" + extra + "}"
+	case extra == "":
+		return "{" + labels + "}"
+	}
+	return "{" + labels + "," + extra + "}"
+}
+
+func promName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_', r == ':':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,92 @@
+package core
+
+// RegisterOption adjusts how Register records a component.
+type RegisterOption func(*registration)
+
+type registration struct {
+	name     string
+	replace  bool
+	disabled bool
+	deps     []string
+}
+
+// WithReplace makes the component take the place of any other registered
+// under the same name, whichever registers first. Later plain Register
+// calls for the name are ignored, so an embedder can swap out a built-in
+// component regardless of package init order.
+func WithReplace() RegisterOption {
+	return func(r *registration) { r.replace = true }
+}
+
+// WithName registers the component under name instead of its Name(), e.g.
+// to run a second instance of a built-in store.
+func WithName(name string) RegisterOption {
+	return func(r *registration) { r.name = name }
+}
+
+// WithDependencies adds to the dependencies the component declares itself.
+func WithDependencies(deps ...string) RegisterOption {
+	return func(r *registration) { r.deps = append(r.deps, deps...) }
+}
+
+// WithDisabled keeps the component from being initialized. Like
+// WithReplace it wins over plain registrations of the name; a component
+// depending on a disabled one fails startup.
+func WithDisabled() RegisterOption {
+	return func(r *registration) { r.disabled = true }
+}
+
+// Disable disables the component registered as name, or the one that will
+// be, so that it is never initialized.
+func Disable(name string) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	delete(registry.components, name)
+	registry.disabled[name] = true
+	registry.pinned[name] = true
+}
+
+func (r *Registry) register(component interface{}, opts []RegisterOption) {
+	reg := registration{name: component.(initializer).Name()}
+	for _, opt := range opts {
+		opt(&reg)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	explicit := reg.replace || reg.disabled
+	if r.pinned[reg.name] && !explicit {
+		return
+	}
+	if explicit {
+		r.pinned[reg.name] = true
+	}
+
+	if reg.disabled {
+		delete(r.components, reg.name)
+		r.disabled[reg.name] = true
+	} else {
+		r.components[reg.name] = component
+		delete(r.disabled, reg.name)
+	}
+	if len(reg.deps) > 0 {
+		r.extraDeps[reg.name] = reg.deps
+	} else {
+		delete(r.extraDeps, reg.name)
+	}
+}
+
+// dependencies returns what the component registered as name declares
+// plus any added with WithDependencies. Called with mu held.
+func (r *Registry) dependencies(name string) []string {
+	init, ok := r.components[name].(initializer)
+	if !ok {
+		return nil
+	}
+	deps := init.Dependencies()
+	if extra := r.extraDeps[name]; len(extra) > 0 {
+		deps = append(append([]string{}, deps...), extra...)
+	}
+	return deps
+}
@@ -0,0 +1,262 @@
+// core/resilience/breaker.go
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State int
+
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case HalfOpen:
+		return "half_open"
+	case Open:
+		return "open"
+	}
+	return "unknown"
+}
+
+// StateChange is published on BreakerChanged whenever a breaker moves
+// between states.
+type StateChange struct {
+	Breaker  string `json:"breaker"`
+	Previous string `json:"previous"`
+	Current  string `json:"current"`
+	Error    string `json:"error,omitempty"`
+}
+
+var BreakerChanged = core.NewTopic[StateChange]("resilience.breaker_changed")
+
+// Options configures a breaker. Zero fields take the defaults noted.
+type Options struct {
+	// FailureThreshold consecutive failures open the breaker; default 5
+	FailureThreshold int
+	// OpenTimeout is how long the breaker stays open before letting probe
+	// calls through; default 30s
+	OpenTimeout time.Duration
+	// HalfOpenProbes is how many probe calls may run at once while half
+	// open; default 1
+	HalfOpenProbes int
+	// SuccessThreshold successful probes close the breaker again; default 1
+	SuccessThreshold int
+	// IsFailure decides which errors count against the dependency. By
+	// default every error does except context cancellation, which is the
+	// caller giving up.
+	IsFailure func(error) bool
+}
+
+func (o Options) withDefaults() Options {
+	if o.FailureThreshold <= 0 {
+		o.FailureThreshold = 5
+	}
+	if o.OpenTimeout <= 0 {
+		o.OpenTimeout = 30 * time.Second
+	}
+	if o.HalfOpenProbes <= 0 {
+		o.HalfOpenProbes = 1
+	}
+	if o.SuccessThreshold <= 0 {
+		o.SuccessThreshold = 1
+	}
+	if o.IsFailure == nil {
+		o.IsFailure = func(err error) bool {
+			return err != nil && !errors.Is(err, context.Canceled)
+		}
+	}
+	return o
+}
+
+// Breaker is a circuit breaker. While closed, calls go through and
+// consecutive failures are counted; reaching the threshold opens it. While
+// open, calls fail fast with ErrOpen. After OpenTimeout it is half open: a
+// limited number of probe calls go through, and enough successes close it
+// while any failure opens it again.
+//
+// A nil *Breaker lets every call through, so callers need not check
+// whether one is configured.
+type Breaker struct {
+	name   string
+	opts   Options
+	labels map[string]string
+	logger core.Logger
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+	lastErr   error
+}
+
+func New(name string, opts Options) *Breaker {
+	b := &Breaker{
+		name:   name,
+		opts:   opts.withDefaults(),
+		labels: map[string]string{"breaker": name},
+		logger: core.GetLogger("resilience"),
+	}
+	core.SetGaugeWithLabels("resilience.breaker_state", b.labels, int64(Closed))
+	return b
+}
+
+// Allow asks to make a call. When it returns nil, the caller must make the
+// call and pass its result to done.
+func (b *Breaker) Allow() (done func(error), err error) {
+	if b == nil {
+		return func(error) {}, nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open {
+		if time.Since(b.openedAt) < b.opts.OpenTimeout {
+			core.IncrCounterWithLabels("resilience.breaker_rejected", b.labels)
+			return nil, fmt.Errorf("%w: %s", ErrOpen, b.name)
+		}
+		b.transition(HalfOpen)
+	}
+
+	probe := b.state == HalfOpen
+	if probe {
+		if b.probes >= b.opts.HalfOpenProbes {
+			core.IncrCounterWithLabels("resilience.breaker_rejected", b.labels)
+			return nil, fmt.Errorf("%w: %s (probing)", ErrOpen, b.name)
+		}
+		b.probes++
+	}
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { b.record(probe, err) })
+	}, nil
+}
+
+// Do runs fn if the breaker allows it and records its result.
+func (b *Breaker) Do(fn func() error) error {
+	done, err := b.Allow()
+	if err != nil {
+		return err
+	}
+	err = fn()
+	done(err)
+	return err
+}
+
+func (b *Breaker) State() State {
+	if b == nil {
+		return Closed
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && time.Since(b.openedAt) >= b.opts.OpenTimeout {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// HealthCheck reports unhealthy while the breaker is open and degraded
+// while it is probing.
+func (b *Breaker) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
+	if b == nil {
+		return core.HealthHealthy, nil
+	}
+	b.mu.Lock()
+	lastErr := b.lastErr
+	b.mu.Unlock()
+
+	switch b.State() {
+	case Open:
+		return core.HealthUnhealthy, fmt.Errorf("circuit open after: %v", lastErr)
+	case HalfOpen:
+		return core.HealthDegraded, fmt.Errorf("circuit half open after: %v", lastErr)
+	}
+	return core.HealthHealthy, nil
+}
+
+func (b *Breaker) record(probe bool, err error) {
+	failed := b.opts.IsFailure(err)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probes--
+	}
+	if failed {
+		b.lastErr = err
+	}
+
+	switch b.state {
+	case Closed:
+		if !failed {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.opts.FailureThreshold {
+			b.transition(Open)
+		}
+	case HalfOpen:
+		// Results of calls started before the breaker opened say nothing
+		// about the dependency now
+		if !probe {
+			return
+		}
+		if failed {
+			b.transition(Open)
+			return
+		}
+		b.successes++
+		if b.successes >= b.opts.SuccessThreshold {
+			b.transition(Closed)
+		}
+	case Open:
+		// Late results of calls started before the breaker opened
+	}
+}
+
+// transition must be called with mu held.
+func (b *Breaker) transition(to State) {
+	from := b.state
+	b.state = to
+	b.failures, b.successes = 0, 0
+	if to == Open {
+		b.openedAt = time.Now()
+	}
+
+	change := StateChange{Breaker: b.name, Previous: from.String(), Current: to.String()}
+	if b.lastErr != nil && to != Closed {
+		change.Error = b.lastErr.Error()
+	}
+	if to == Closed {
+		b.lastErr = nil
+	}
+
+	core.SetGaugeWithLabels("resilience.breaker_state", b.labels, int64(to))
+	core.IncrCounterWithLabels("resilience.breaker_transitions", map[string]string{"breaker": b.name, "to": to.String()})
+	if to == Open {
+		b.logger.Warn("Circuit %s opened: %s", b.name, change.Error)
+	} else {
+		b.logger.Info("Circuit %s %s -> %s", b.name, from, to)
+	}
+	BreakerChanged.Publish(change)
+}
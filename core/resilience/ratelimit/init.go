@@ -0,0 +1,98 @@
+// core/resilience/ratelimit/init.go
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+)
+
+type ratelimitComponent struct{}
+
+func (c *ratelimitComponent) Name() string {
+	return "ratelimit"
+}
+
+func (c *ratelimitComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *ratelimitComponent) Init() error {
+	raw, _ := config.Get().Get("ratelimit", "limiters").(map[string]interface{})
+	limits, err := parseLimits(raw)
+	if err != nil {
+		return err
+	}
+	configure(limits)
+	return nil
+}
+
+func (c *ratelimitComponent) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// parseLimits reads ratelimit.limiters: limiter names mapped to objects
+// with rate, burst, per_key_rate and per_key_burst.
+func parseLimits(raw map[string]interface{}) (map[string]Options, error) {
+	limits := make(map[string]Options, len(raw))
+	for name, v := range raw {
+		fields, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("ratelimit.limiters.%s must be an object", name)
+		}
+		var opts Options
+		for key, value := range fields {
+			n, ok := value.(float64)
+			if !ok || n < 0 {
+				return nil, fmt.Errorf("ratelimit.limiters.%s.%s must be a non-negative number", name, key)
+			}
+			switch key {
+			case "rate":
+				opts.Rate = n
+			case "burst":
+				opts.Burst = int(n)
+			case "per_key_rate":
+				opts.PerKeyRate = n
+			case "per_key_burst":
+				opts.PerKeyBurst = int(n)
+			default:
+				return nil, fmt.Errorf("ratelimit.limiters.%s: unknown option %s", name, key)
+			}
+		}
+		limits[name] = opts
+	}
+	return limits, nil
+}
+
+func validateLimits(v interface{}) error {
+	raw, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("must be an object")
+	}
+	_, err := parseLimits(raw)
+	return err
+}
+
+func init() {
+	config.Register("ratelimit", config.Schema{
+		"limiters": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Limits by limiter name: rate and burst for all calls, per_key_rate and per_key_burst for each key (rates per second; 0 turns a limit off)",
+			Validator:   validateLimits,
+		},
+	})
+
+	config.OnReload("ratelimit", func(old, new map[string]interface{}) {
+		raw, _ := new["limiters"].(map[string]interface{})
+		limits, err := parseLimits(raw)
+		if err != nil {
+			core.GetLogger("ratelimit").Error("Keeping previous limits: %v", err)
+			return
+		}
+		configure(limits)
+	})
+	core.Register(&ratelimitComponent{})
+}
@@ -0,0 +1,270 @@
+// core/resilience/ratelimit/ratelimit.go
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// Bucket is a token bucket: it holds up to burst tokens and refills at
+// rate tokens per second. A rate of zero or less never limits.
+type Bucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func NewBucket(rate float64, burst int) *Bucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &Bucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: time.Now()}
+}
+
+// Allow takes a token if one is available.
+func (b *Bucket) Allow() bool {
+	if b.rate <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(time.Now())
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// Wait takes a token, waiting for one to become available. The token is
+// returned if ctx ends first.
+func (b *Bucket) Wait(ctx context.Context) error {
+	if b.rate <= 0 {
+		return nil
+	}
+	b.mu.Lock()
+	b.refill(time.Now())
+	// Taking the token now, even into debt, queues waiters fairly
+	b.tokens--
+	wait := time.Duration(-b.tokens / b.rate * float64(time.Second))
+	b.mu.Unlock()
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// RetryAfter is how long until a token is available.
+func (b *Bucket) RetryAfter() time.Duration {
+	if b.rate <= 0 {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(time.Now())
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// full reports whether the bucket has refilled completely, so it behaves
+// like a new one and can be dropped.
+func (b *Bucket) full(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	return b.tokens >= b.burst
+}
+
+// refill must be called with mu held.
+func (b *Bucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// Options configures a limiter. Either limit may be left at zero to turn it
+// off.
+type Options struct {
+	// Rate and Burst limit all calls together
+	Rate  float64
+	Burst int
+	// PerKeyRate and PerKeyBurst limit the calls of each key, such as a
+	// client address or an endpoint
+	PerKeyRate  float64
+	PerKeyBurst int
+}
+
+// Limiter combines a global bucket with a bucket per key. A call must get
+// a token from both. Buckets of keys that have been idle long enough to
+// refill are dropped, so keys do not accumulate.
+//
+// A nil *Limiter never limits, so callers need not check whether one is
+// configured.
+type Limiter struct {
+	name   string
+	labels map[string]string
+
+	mu        sync.Mutex
+	opts      Options
+	global    *Bucket
+	keys      map[string]*Bucket
+	lastSweep time.Time
+}
+
+// sweepInterval is how often idle key buckets are dropped.
+const sweepInterval = time.Minute
+
+var unlimited = NewBucket(0, 1)
+
+func NewLimiter(name string, opts Options) *Limiter {
+	l := &Limiter{name: name, labels: map[string]string{"limiter": name}}
+	l.SetOptions(opts)
+	return l
+}
+
+// SetOptions replaces the limits. Buckets start full again.
+func (l *Limiter) SetOptions(opts Options) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.opts = opts
+	l.global = NewBucket(opts.Rate, opts.Burst)
+	l.keys = make(map[string]*Bucket)
+	l.lastSweep = time.Now()
+}
+
+// Allow takes a token for key without waiting.
+func (l *Limiter) Allow(key string) bool {
+	if l == nil {
+		return true
+	}
+	perKey, global := l.buckets(key)
+	if !perKey.Allow() || !global.Allow() {
+		core.IncrCounterWithLabels("ratelimit.rejected", l.labels)
+		return false
+	}
+	return true
+}
+
+// Wait takes a token for key, waiting until one is available or ctx ends.
+func (l *Limiter) Wait(ctx context.Context, key string) error {
+	if l == nil {
+		return nil
+	}
+	perKey, global := l.buckets(key)
+	start := time.Now()
+	if err := perKey.Wait(ctx); err != nil {
+		return err
+	}
+	if err := global.Wait(ctx); err != nil {
+		return err
+	}
+	core.RecordDurationWithLabels("ratelimit.wait", l.labels, start)
+	return nil
+}
+
+// RetryAfter is how long until key may make a call.
+func (l *Limiter) RetryAfter(key string) time.Duration {
+	if l == nil {
+		return 0
+	}
+	perKey, global := l.buckets(key)
+	return max(perKey.RetryAfter(), global.RetryAfter())
+}
+
+func (l *Limiter) buckets(key string) (*Bucket, *Bucket) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		for k, b := range l.keys {
+			if b.full(now) {
+				delete(l.keys, k)
+			}
+		}
+		l.lastSweep = now
+		core.SetGaugeWithLabels("ratelimit.keys", l.labels, int64(len(l.keys)))
+	}
+
+	if l.opts.PerKeyRate <= 0 {
+		return unlimited, l.global
+	}
+	b, ok := l.keys[key]
+	if !ok {
+		b = NewBucket(l.opts.PerKeyRate, l.opts.PerKeyBurst)
+		l.keys[key] = b
+	}
+	return b, l.global
+}
+
+var (
+	mu       sync.RWMutex
+	limiters = make(map[string]*Limiter)
+	// registered holds the limits given in code, restored when a limiter is
+	// removed from the config
+	registered = make(map[string]Options)
+	configured = make(map[string]bool)
+)
+
+// Get returns the named limiter, or nil when none is registered or
+// configured.
+func Get(name string) *Limiter {
+	mu.RLock()
+	defer mu.RUnlock()
+	return limiters[name]
+}
+
+// Register returns the named limiter, creating it with opts unless it
+// exists. Limits under ratelimit.limiters in the config take precedence
+// over opts.
+func Register(name string, opts Options) *Limiter {
+	mu.Lock()
+	defer mu.Unlock()
+	registered[name] = opts
+	if l, ok := limiters[name]; ok {
+		return l
+	}
+	l := NewLimiter(name, opts)
+	limiters[name] = l
+	return l
+}
+
+// configure applies the configured limits, updating limiters in place so
+// that callers holding them see the change.
+func configure(limits map[string]Options) {
+	mu.Lock()
+	defer mu.Unlock()
+	for name := range configured {
+		if _, ok := limits[name]; !ok {
+			limiters[name].SetOptions(registered[name])
+		}
+	}
+	configured = make(map[string]bool, len(limits))
+	for name, opts := range limits {
+		configured[name] = true
+		if l, ok := limiters[name]; ok {
+			l.SetOptions(opts)
+			continue
+		}
+		limiters[name] = NewLimiter(name, opts)
+	}
+}
@@ -0,0 +1,118 @@
+// core/resilience/retry.go
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+type Jitter int
+
+const (
+	// FullJitter waits anywhere from zero to the backoff, which spreads out
+	// callers that failed together the most
+	FullJitter Jitter = iota
+	// EqualJitter waits at least half the backoff
+	EqualJitter
+	// NoJitter waits exactly the backoff
+	NoJitter
+)
+
+// RetryPolicy controls Retry. The wait before each retry grows from
+// Backoff by Multiplier up to MaxBackoff. Zero fields take the defaults
+// noted.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt; default 3, 1 disables retries
+	MaxAttempts int
+	// Backoff is the wait before the first retry; default 100ms
+	Backoff time.Duration
+	// MaxBackoff caps the wait; default 30s
+	MaxBackoff time.Duration
+	// Multiplier grows the wait per attempt; default 2
+	Multiplier float64
+	Jitter     Jitter
+	// Retryable decides which errors are worth another attempt. By default
+	// every error is except the context ending.
+	Retryable func(error) bool
+	// OnRetry, if set, is called before each wait, e.g. to log or count
+	// retries
+	OnRetry func(attempt int, err error, wait time.Duration)
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = 3
+	}
+	if p.Backoff <= 0 {
+		p.Backoff = 100 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = max(30*time.Second, p.Backoff)
+	}
+	if p.Multiplier < 1 {
+		p.Multiplier = 2
+	}
+	if p.Retryable == nil {
+		p.Retryable = func(err error) bool {
+			return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+		}
+	}
+	return p
+}
+
+// Delay returns the wait after the given failed attempt, counting from 1,
+// with jitter applied.
+func (p RetryPolicy) Delay(attempt int) time.Duration {
+	p = p.withDefaults()
+
+	d := float64(p.Backoff)
+	for i := 1; i < attempt && d < float64(p.MaxBackoff); i++ {
+		d *= p.Multiplier
+	}
+	wait := min(time.Duration(d), p.MaxBackoff)
+
+	switch p.Jitter {
+	case FullJitter:
+		return time.Duration(rand.Int63n(int64(wait) + 1))
+	case EqualJitter:
+		return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+	}
+	return wait
+}
+
+// Retry calls fn until it succeeds, returns an error the policy does not
+// retry, or runs out of attempts. It stops waiting when ctx ends and
+// returns ctx's error. When more than one attempt was made, the last
+// error is wrapped with the number of attempts.
+func Retry(ctx context.Context, policy RetryPolicy, fn func(ctx context.Context) error) error {
+	policy = policy.withDefaults()
+
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= policy.MaxAttempts || !policy.Retryable(err) {
+			if attempt > 1 {
+				return fmt.Errorf("failed after %d attempts: %w", attempt, err)
+			}
+			return err
+		}
+
+		wait := policy.Delay(attempt)
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,140 @@
+// core/restart.go
+package core
+
+import (
+	"context"
+	"fmt"
+)
+
+// Restart shuts down name and every initialized component that depends on
+// it, directly or transitively, in reverse init order, then initializes
+// them again in init order.
+func Restart(ctx context.Context, name string) error {
+	registry.lifecycleMu.Lock()
+	defer registry.lifecycleMu.Unlock()
+
+	return registry.restart(ctx, name, nil)
+}
+
+// Replace swaps the implementation registered under name. If the current
+// one is running, it and its dependents are restarted with the new one in
+// place, e.g. to reconnect a store with new credentials after a reload.
+func Replace(name string, component interface{}) error {
+	init, ok := component.(initializer)
+	if !ok {
+		return fmt.Errorf("%s does not implement Initializer", name)
+	}
+
+	registry.lifecycleMu.Lock()
+	defer registry.lifecycleMu.Unlock()
+
+	registry.mu.Lock()
+	current, exists := registry.components[name]
+	if !exists {
+		registry.mu.Unlock()
+		return fmt.Errorf("unknown component: %s", name)
+	}
+	// A component registered WithName may be replaced by one of the same
+	// kind, which still reports its own Name()
+	if cur, ok := current.(initializer); (!ok || cur.Name() == name) && init.Name() != name {
+		registry.mu.Unlock()
+		return fmt.Errorf("replacement for %s is named %s", name, init.Name())
+	}
+	running := registry.initialized[name]
+	if !running {
+		registry.components[name] = component
+	}
+	registry.mu.Unlock()
+
+	if !running {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultInitTimeout)
+	defer cancel()
+	return registry.restart(ctx, name, component)
+}
+
+// restart must be called with lifecycleMu held. A non-nil replacement is
+// installed under name between shutdown and re-initialization.
+func (r *Registry) restart(ctx context.Context, name string, replacement interface{}) error {
+	r.mu.Lock()
+	if _, ok := r.components[name]; !ok {
+		r.mu.Unlock()
+		return fmt.Errorf("unknown component: %s", name)
+	}
+	affected := r.dependents(name)
+	var order []string
+	for _, n := range r.initOrder {
+		if affected[n] && r.initialized[n] {
+			order = append(order, n)
+		}
+	}
+	components := make(map[string]interface{}, len(order))
+	for _, n := range order {
+		components[n] = r.components[n]
+	}
+	r.mu.Unlock()
+
+	logger := GetLogger("core")
+	logger.Info("Restarting %v", order)
+
+	for i := len(order) - 1; i >= 0; i-- {
+		n := order[i]
+		if s, ok := components[n].(Shutdowner); ok {
+			if err := s.Shutdown(ctx); err != nil {
+				return fmt.Errorf("shutting down %s: %w", n, err)
+			}
+		}
+		r.mu.Lock()
+		r.initialized[n] = false
+		r.mu.Unlock()
+	}
+
+	if replacement != nil {
+		r.mu.Lock()
+		r.components[name] = replacement
+		newOrder, err := r.topologicalSort()
+		if err == nil {
+			r.initOrder = newOrder
+		}
+		r.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, n := range order {
+		if err := r.initOne(ctx, n); err != nil {
+			return fmt.Errorf("initializing %s: %w", n, err)
+		}
+	}
+
+	IncrCounterWithLabels("core.restarts", map[string]string{"component": name})
+	return nil
+}
+
+// dependents returns name and every registered component that depends on
+// it. Called with mu held.
+func (r *Registry) dependents(name string) map[string]bool {
+	result := map[string]bool{name: true}
+	for changed := true; changed; {
+		changed = false
+		for n, comp := range r.components {
+			if result[n] {
+				continue
+			}
+			if _, ok := comp.(initializer); !ok {
+				continue
+			}
+			for _, dep := range r.dependencies(n) {
+				if result[dep] {
+					result[n] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+)
+
+var (
+	runID     = newRunID()
+	startedAt = time.Now()
+)
+
+// RunID identifies this process invocation, e.g. to tie telemetry written
+// by short-lived job runs back to a single execution.
+func RunID() string {
+	return runID
+}
+
+func StartedAt() time.Time {
+	return startedAt
+}
+
+func newRunID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
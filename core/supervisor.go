@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Escalation int
+
+const (
+	// EscalateDegrade isolates the component: it is not restarted again and
+	// reports HealthDegraded, while the rest of the process keeps running.
+	EscalateDegrade Escalation = iota
+	// EscalateExit terminates the process once restarts are exhausted.
+	EscalateExit
+)
+
+type SupervisionPolicy struct {
+	MaxRestarts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+	Escalation  Escalation
+}
+
+var DefaultSupervisionPolicy = SupervisionPolicy{
+	MaxRestarts: 3,
+	Backoff:     time.Second,
+	MaxBackoff:  30 * time.Second,
+	Escalation:  EscalateDegrade,
+}
+
+type supervisor struct {
+	mu       sync.Mutex
+	policies map[string]SupervisionPolicy
+	degraded map[string]error
+}
+
+var supervision = &supervisor{
+	policies: make(map[string]SupervisionPolicy),
+	degraded: make(map[string]error),
+}
+
+func SetSupervisionPolicy(name string, policy SupervisionPolicy) {
+	supervision.mu.Lock()
+	defer supervision.mu.Unlock()
+	supervision.policies[name] = policy
+}
+
+// Supervise runs fn on behalf of the named component, restarting it with
+// backoff when it panics. It blocks until fn returns normally, the policy
+// gives up, or stop is closed while waiting to restart.
+func Supervise(name string, stop <-chan struct{}, fn func()) {
+	policy := supervision.policy(name)
+	logger := GetLogger("supervisor")
+	backoff := policy.Backoff
+
+	for restarts := 0; ; restarts++ {
+		err := runProtected(fn)
+		if err == nil {
+			return
+		}
+
+		IncrCounterWithLabels("supervisor.panics", map[string]string{"component": name})
+		logger.Error("Component %s panicked: %v", name, err)
+		logger.Debug("%s", err.(*PanicError).Stack)
+
+		if restarts >= policy.MaxRestarts {
+			supervision.escalate(name, policy, fmt.Errorf("gave up after %d restarts: %w", restarts, err))
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-stop:
+			return
+		}
+
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+		IncrCounterWithLabels("supervisor.restarts", map[string]string{"component": name})
+		logger.Warn("Restarting %s (attempt %d/%d)", name, restarts+1, policy.MaxRestarts)
+	}
+}
+
+func IsDegraded(name string) bool {
+	supervision.mu.Lock()
+	defer supervision.mu.Unlock()
+	_, ok := supervision.degraded[name]
+	return ok
+}
+
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+func runProtected(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	fn()
+	return nil
+}
+
+func (s *supervisor) policy(name string) SupervisionPolicy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if p, ok := s.policies[name]; ok {
+		return p
+	}
+	return DefaultSupervisionPolicy
+}
+
+func (s *supervisor) escalate(name string, policy SupervisionPolicy, err error) {
+	logger := GetLogger("supervisor")
+
+	if policy.Escalation == EscalateExit {
+		logger.Error("Component %s failed permanently, exiting: %v", name, err)
+		os.Exit(1)
+	}
+
+	s.mu.Lock()
+	s.degraded[name] = err
+	s.mu.Unlock()
+
+	SetGaugeWithLabels("supervisor.degraded", map[string]string{"component": name}, 1)
+	logger.Error("Component %s isolated: %v", name, err)
+}
+
+func (s *supervisor) HealthCheck(ctx context.Context) (HealthStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.degraded) == 0 {
+		return HealthHealthy, nil
+	}
+	names := sortedKeys(s.degraded)
+	return HealthDegraded, fmt.Errorf("isolated components: %s", strings.Join(names, ", "))
+}
+
+func init() {
+	RegisterHealthCheckKind("supervisor", supervision, ReadinessCheck|LivenessCheck)
+}
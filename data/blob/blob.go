@@ -0,0 +1,280 @@
+// data/blob/blob.go
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+var (
+	ErrNotFound = errors.New("blob not found")
+	ErrCorrupt  = errors.New("blob content does not match its hash")
+)
+
+type entry struct {
+	Size       int       `json:"size"`
+	Refs       int       `json:"refs"`
+	Created    time.Time `json:"created"`
+	ReleasedAt time.Time `json:"released_at,omitempty"`
+}
+
+// Store keeps large values in an underlying data.Store keyed by their
+// SHA-256, so identical content (runtime blobs, metadata snapshots, report
+// artifacts) is written once and shared by reference count.
+//
+// Each blob's reference count lives in a key of its own next to its
+// content, so operations on one blob touch only its keys. Updates are
+// guarded by an in-process mutex; a Store must not be shared by several
+// processes writing to the same prefix. GC lists the reference keys and
+// needs a data.KVStore.
+type Store struct {
+	store  data.Store
+	prefix string
+	logger core.Logger
+	mu     sync.Mutex
+}
+
+func New(store data.Store, prefix string) *Store {
+	return &Store{
+		store:  store,
+		prefix: prefix,
+		logger: core.GetLogger("blob"),
+	}
+}
+
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Store) dataKey(hash string) string {
+	return s.prefix + "data/" + hash
+}
+
+func (s *Store) refKey(hash string) string {
+	return s.prefix + "ref/" + hash
+}
+
+// Put stores content if it is not already present and takes a reference
+// to it. Every Put must be balanced by a Release.
+func (s *Store) Put(ctx context.Context, content []byte) (string, error) {
+	hash := Hash(content)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.loadEntry(ctx, hash)
+	if err != nil {
+		return "", err
+	}
+
+	if e != nil {
+		core.IncrCounter("blob.dedup")
+	} else {
+		encoded := base64.StdEncoding.EncodeToString(content)
+		if err := s.store.Set(ctx, s.dataKey(hash), encoded); err != nil {
+			return "", fmt.Errorf("writing blob %s: %w", hash, err)
+		}
+		e = &entry{Size: len(content), Created: time.Now()}
+		core.IncrCounter("blob.writes")
+		core.RecordValue("blob.size", float64(len(content)))
+	}
+
+	e.Refs++
+	e.ReleasedAt = time.Time{}
+	if err := s.saveEntry(ctx, hash, e); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+func (s *Store) Get(ctx context.Context, hash string) ([]byte, error) {
+	value, err := s.store.Get(ctx, s.dataKey(hash))
+	if err != nil {
+		return nil, err
+	}
+	if value == nil {
+		return nil, ErrNotFound
+	}
+
+	encoded, ok := value.(string)
+	if !ok {
+		if b, isBytes := value.([]byte); isBytes {
+			encoded = string(b)
+		} else {
+			return nil, fmt.Errorf("blob %s: unexpected value type %T", hash, value)
+		}
+	}
+
+	content, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("decoding blob %s: %w", hash, err)
+	}
+	if Hash(content) != hash {
+		return nil, fmt.Errorf("%w: %s", ErrCorrupt, hash)
+	}
+	return content, nil
+}
+
+// Retain takes an additional reference to an existing blob.
+func (s *Store) Retain(ctx context.Context, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.loadEntry(ctx, hash)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return ErrNotFound
+	}
+	e.Refs++
+	e.ReleasedAt = time.Time{}
+	return s.saveEntry(ctx, hash, e)
+}
+
+// Release drops a reference. Unreferenced blobs stay readable until GC
+// removes them.
+func (s *Store) Release(ctx context.Context, hash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.loadEntry(ctx, hash)
+	if err != nil {
+		return err
+	}
+	if e == nil {
+		return ErrNotFound
+	}
+	if e.Refs > 0 {
+		e.Refs--
+	}
+	if e.Refs == 0 {
+		e.ReleasedAt = time.Now()
+	}
+	return s.saveEntry(ctx, hash, e)
+}
+
+func (s *Store) Refs(ctx context.Context, hash string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, err := s.loadEntry(ctx, hash)
+	if err != nil {
+		return 0, err
+	}
+	if e == nil {
+		return 0, ErrNotFound
+	}
+	return e.Refs, nil
+}
+
+// GC deletes blobs that have been unreferenced for at least grace and
+// returns how many were removed.
+func (s *Store) GC(ctx context.Context, grace time.Duration) (int, error) {
+	kv, ok := s.store.(data.KVStore)
+	if !ok {
+		return 0, fmt.Errorf("blob GC needs a store that can list its keys")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Collected first, so nothing is deleted while the scan is open
+	var (
+		candidates []string
+		total      int64
+	)
+	refPrefix := s.refKey("")
+	err := kv.Scan(ctx, refPrefix, func(key string) error {
+		total++
+		candidates = append(candidates, strings.TrimPrefix(key, refPrefix))
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("listing blobs: %w", err)
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-grace)
+	for _, hash := range candidates {
+		e, err := s.loadEntry(ctx, hash)
+		if err != nil {
+			return removed, err
+		}
+		if e == nil || e.Refs > 0 || e.ReleasedAt.After(cutoff) {
+			continue
+		}
+		if err := s.store.Delete(ctx, s.dataKey(hash)); err != nil {
+			s.logger.Warn("Deleting blob %s: %v", hash, err)
+			continue
+		}
+		if err := s.store.Delete(ctx, s.refKey(hash)); err != nil {
+			return removed, fmt.Errorf("deleting blob entry %s: %w", hash, err)
+		}
+		removed++
+		core.IncrCounter("blob.gc_removed")
+	}
+	core.SetGauge("blob.count", total-int64(removed))
+
+	if removed > 0 {
+		s.logger.Info("Garbage collected %d unreferenced blobs", removed)
+	}
+	return removed, nil
+}
+
+// loadEntry returns hash's entry, or nil if there is none. Called with mu
+// held.
+func (s *Store) loadEntry(ctx context.Context, hash string) (*entry, error) {
+	value, err := s.store.Get(ctx, s.refKey(hash))
+	if err != nil {
+		return nil, fmt.Errorf("reading blob entry %s: %w", hash, err)
+	}
+	raw, err := rawJSON(value)
+	if err != nil {
+		return nil, fmt.Errorf("blob entry %s: %w", hash, err)
+	}
+	if raw == nil {
+		return nil, nil
+	}
+
+	var e entry
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return nil, fmt.Errorf("parsing blob entry %s: %w", hash, err)
+	}
+	return &e, nil
+}
+
+func (s *Store) saveEntry(ctx context.Context, hash string, e *entry) error {
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Set(ctx, s.refKey(hash), string(raw)); err != nil {
+		return fmt.Errorf("writing blob entry %s: %w", hash, err)
+	}
+	return nil
+}
+
+func rawJSON(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	}
+	return nil, fmt.Errorf("unexpected value type %T", value)
+}
@@ -0,0 +1,357 @@
+// data/cache/cache.go
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+// Broadcaster carries invalidations between helper processes that share a
+// remote store. A remote CacheStore that implements it, such as one backed
+// by Redis pub/sub, is used automatically.
+type Broadcaster interface {
+	Publish(ctx context.Context, channel string, message []byte) error
+	Subscribe(ctx context.Context, channel string, handler func([]byte)) (unsubscribe func(), err error)
+}
+
+type Options struct {
+	// LocalSize caps the number of entries held in process.
+	LocalSize int
+	// LocalTTL bounds how long a value is served locally without going
+	// back to the remote store. Zero keeps entries until evicted or
+	// invalidated.
+	LocalTTL time.Duration
+	// Channel is the broadcast channel for invalidations.
+	Channel string
+}
+
+// Tiered is a CacheStore that serves reads from an in-process LRU, falls
+// through to a remote CacheStore on a miss, and writes through to the
+// remote store. Reads whose context asks for data.Strong consistency go to
+// the remote store directly. Writes invalidate the key in other processes when the
+// remote store can broadcast.
+type Tiered struct {
+	opts    Options
+	local   *lru
+	resolve func() (data.CacheStore, error)
+	logger  core.Logger
+
+	mu          sync.Mutex
+	remote      data.CacheStore
+	unsubscribe func()
+}
+
+type invalidation struct {
+	Origin string `json:"origin"`
+	Kind   string `json:"kind"`
+	Value  string `json:"value"`
+}
+
+var instance *Tiered
+
+func Get() *Tiered {
+	return instance
+}
+
+func New(remote data.CacheStore, opts Options) *Tiered {
+	return NewLazy(func() (data.CacheStore, error) { return remote, nil }, opts)
+}
+
+// NewLazy defers locating the remote store to first use, for remotes owned
+// by components that may initialize after the cache.
+func NewLazy(resolve func() (data.CacheStore, error), opts Options) *Tiered {
+	return &Tiered{
+		opts:    opts,
+		local:   newLRU(opts.LocalSize),
+		resolve: resolve,
+		logger:  core.GetLogger("cache"),
+	}
+}
+
+func (t *Tiered) remoteStore() (data.CacheStore, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.remote != nil {
+		return t.remote, nil
+	}
+	remote, err := t.resolve()
+	if err != nil {
+		return nil, err
+	}
+	if remote == nil {
+		return nil, fmt.Errorf("remote cache store is not available")
+	}
+	t.remote = remote
+
+	if b, ok := remote.(Broadcaster); ok && t.opts.Channel != "" {
+		unsubscribe, err := b.Subscribe(context.Background(), t.opts.Channel, t.handleInvalidation)
+		if err != nil {
+			t.logger.Error("Subscribing to cache invalidations: %v", err)
+		} else {
+			t.unsubscribe = unsubscribe
+		}
+	}
+	return remote, nil
+}
+
+func (t *Tiered) Connect(ctx context.Context) error {
+	_, err := t.remoteStore()
+	return err
+}
+
+// Close stops listening for invalidations. The remote store belongs to its
+// own component and is left open.
+func (t *Tiered) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.unsubscribe != nil {
+		t.unsubscribe()
+		t.unsubscribe = nil
+	}
+	return nil
+}
+
+// cached returns key from the local tier. Reads with Strong consistency
+// skip it, since it may lag behind writes from other processes until the
+// invalidation arrives.
+func (t *Tiered) cached(ctx context.Context, key string) (interface{}, bool) {
+	if data.ConsistencyFrom(ctx) == data.Strong {
+		return nil, false
+	}
+	v, ok := t.local.get(key)
+	if ok {
+		t.record("local", "hit")
+	} else {
+		t.record("local", "miss")
+	}
+	return v, ok
+}
+
+func (t *Tiered) Get(ctx context.Context, key string) (interface{}, error) {
+	if v, ok := t.cached(ctx, key); ok {
+		return v, nil
+	}
+
+	remote, err := t.remoteStore()
+	if err != nil {
+		return nil, err
+	}
+	v, err := remote.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		t.record("remote", "miss")
+		return nil, nil
+	}
+	t.record("remote", "hit")
+	t.local.set(key, v, t.opts.LocalTTL)
+	return v, nil
+}
+
+func (t *Tiered) Set(ctx context.Context, key string, value interface{}) error {
+	remote, err := t.remoteStore()
+	if err != nil {
+		return err
+	}
+	if err := remote.Set(ctx, key, value); err != nil {
+		return err
+	}
+	t.local.set(key, value, t.opts.LocalTTL)
+	t.broadcast(ctx, "key", key)
+	return nil
+}
+
+func (t *Tiered) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	remote, err := t.remoteStore()
+	if err != nil {
+		return err
+	}
+	if err := remote.SetWithTTL(ctx, key, value, ttl); err != nil {
+		return err
+	}
+
+	localTTL := t.opts.LocalTTL
+	if ttl > 0 && (localTTL <= 0 || ttl < localTTL) {
+		localTTL = ttl
+	}
+	t.local.set(key, value, localTTL)
+	t.broadcast(ctx, "key", key)
+	return nil
+}
+
+func (t *Tiered) Delete(ctx context.Context, key string) error {
+	remote, err := t.remoteStore()
+	if err != nil {
+		return err
+	}
+	if err := remote.Delete(ctx, key); err != nil {
+		return err
+	}
+	t.local.remove(key)
+	t.broadcast(ctx, "key", key)
+	return nil
+}
+
+func (t *Tiered) Exists(ctx context.Context, key string) (bool, error) {
+	if _, ok := t.cached(ctx, key); ok {
+		return true, nil
+	}
+	remote, err := t.remoteStore()
+	if err != nil {
+		return false, err
+	}
+	return remote.Exists(ctx, key)
+}
+
+// GetMulti serves what it can locally and fetches the rest in one remote
+// call. With Strong consistency every key is fetched.
+func (t *Tiered) GetMulti(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	result := make(map[string]interface{}, len(keys))
+	var missing []string
+	for _, key := range keys {
+		if v, ok := t.cached(ctx, key); ok {
+			result[key] = v
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) == 0 {
+		return result, nil
+	}
+
+	remote, err := t.remoteStore()
+	if err != nil {
+		return nil, err
+	}
+	fetched, err := remote.GetMulti(ctx, missing)
+	if err != nil {
+		return nil, err
+	}
+	for key, v := range fetched {
+		result[key] = v
+		t.local.set(key, v, t.opts.LocalTTL)
+	}
+	return result, nil
+}
+
+// Increment is applied remotely only; counters change too often to be
+// worth holding locally.
+func (t *Tiered) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	remote, err := t.remoteStore()
+	if err != nil {
+		return 0, err
+	}
+	n, err := remote.Increment(ctx, key, delta)
+	if err != nil {
+		return 0, err
+	}
+	t.local.remove(key)
+	t.broadcast(ctx, "key", key)
+	return n, nil
+}
+
+func (t *Tiered) Decrement(ctx context.Context, key string, delta int64) (int64, error) {
+	return t.Increment(ctx, key, -delta)
+}
+
+func (t *Tiered) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
+	remote, err := t.remoteStore()
+	if err != nil {
+		return 0, err
+	}
+	n, err := remote.DeleteByPrefix(ctx, prefix)
+	if err != nil {
+		return n, err
+	}
+	t.local.removeMatching(func(key string) bool { return strings.HasPrefix(key, prefix) })
+	t.broadcast(ctx, "prefix", prefix)
+	return n, nil
+}
+
+func (t *Tiered) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	re, err := data.CompilePattern(pattern)
+	if err != nil {
+		return 0, err
+	}
+	remote, err := t.remoteStore()
+	if err != nil {
+		return 0, err
+	}
+	n, err := remote.DeleteByPattern(ctx, pattern)
+	if err != nil {
+		return n, err
+	}
+	t.local.removeMatching(re.MatchString)
+	t.broadcast(ctx, "pattern", pattern)
+	return n, nil
+}
+
+func (t *Tiered) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	remote, err := t.remoteStore()
+	if err != nil {
+		return 0, err
+	}
+	return remote.GetTTL(ctx, key)
+}
+
+// Invalidate drops key from the local tier only, for callers that learn
+// about remote changes some other way.
+func (t *Tiered) Invalidate(key string) {
+	t.local.remove(key)
+}
+
+func (t *Tiered) broadcast(ctx context.Context, kind, value string) {
+	t.mu.Lock()
+	b, ok := t.remote.(Broadcaster)
+	t.mu.Unlock()
+	if !ok || t.opts.Channel == "" {
+		return
+	}
+
+	msg, _ := json.Marshal(invalidation{Origin: core.RunID(), Kind: kind, Value: value})
+	if err := b.Publish(ctx, t.opts.Channel, msg); err != nil {
+		// Other processes serve the stale value until LocalTTL passes
+		core.IncrCounter("cache.broadcast_errors")
+		t.logger.Warn("Broadcasting cache invalidation for %s %s: %v", kind, value, err)
+	}
+}
+
+func (t *Tiered) handleInvalidation(msg []byte) {
+	var inv invalidation
+	if err := json.Unmarshal(msg, &inv); err != nil || inv.Origin == core.RunID() {
+		return
+	}
+
+	core.IncrCounterWithLabels("cache.invalidations", map[string]string{"kind": inv.Kind})
+	switch inv.Kind {
+	case "key":
+		t.local.remove(inv.Value)
+	case "prefix":
+		t.local.removeMatching(func(key string) bool { return strings.HasPrefix(key, inv.Value) })
+	case "pattern":
+		if re, err := data.CompilePattern(inv.Value); err == nil {
+			t.local.removeMatching(re.MatchString)
+		}
+	}
+}
+
+func (t *Tiered) record(tier, result string) {
+	core.IncrCounterWithLabels("cache.requests", map[string]string{"tier": tier, "result": result})
+}
+
+func (t *Tiered) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
+	core.SetGauge("cache.local_entries", int64(t.local.len()))
+	if _, err := t.remoteStore(); err != nil {
+		return core.HealthDegraded, err
+	}
+	return core.HealthHealthy, nil
+}
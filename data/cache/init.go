@@ -0,0 +1,96 @@
+// data/cache/init.go
+package cache
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data"
+)
+
+type cacheComponent struct{}
+
+func (c *cacheComponent) Name() string {
+	return "cache"
+}
+
+func (c *cacheComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *cacheComponent) Init() error {
+	cfg := config.Get()
+
+	remoteName := cfg.GetString("cache", "remote")
+	if remoteName == "" {
+		return nil
+	}
+
+	// The remote store's component may initialize after this one, so it is
+	// looked up on first use.
+	resolve := func() (data.CacheStore, error) {
+		if !core.IsInitialized(remoteName) {
+			return nil, fmt.Errorf("cache remote %s is not initialized", remoteName)
+		}
+		provider, ok := core.GetComponent(remoteName).(data.StoreProvider)
+		if !ok || provider.Store() == nil {
+			return nil, fmt.Errorf("cache remote %s does not provide a store", remoteName)
+		}
+		store, ok := provider.Store().(data.CacheStore)
+		if !ok {
+			return nil, fmt.Errorf("cache remote %s is not a CacheStore", remoteName)
+		}
+		return store, nil
+	}
+
+	instance = NewLazy(resolve, Options{
+		LocalSize: cfg.GetInt("cache", "local_size"),
+		LocalTTL:  cfg.GetDuration("cache", "local_ttl"),
+		Channel:   cfg.GetString("cache", "channel"),
+	})
+	core.RegisterHealthCheck("cache", instance)
+	return nil
+}
+
+func (c *cacheComponent) Shutdown(ctx context.Context) error {
+	if instance != nil {
+		return instance.Close()
+	}
+	return nil
+}
+
+func (c *cacheComponent) Store() data.Store {
+	if instance == nil {
+		return nil
+	}
+	return instance
+}
+
+func init() {
+	config.Register("cache", config.Schema{
+		"remote": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Component name of the remote CacheStore behind the local tier (empty disables the cache)",
+		},
+		"local_size": config.Field{
+			Default:     10000,
+			Required:    false,
+			Description: "Maximum entries held in the in-process LRU",
+		},
+		"local_ttl": config.Field{
+			Default:     "1m",
+			Required:    false,
+			Description: "Longest a value is served locally before rereading the remote store",
+		},
+		"channel": config.Field{
+			Default:     "helper:cache:invalidate",
+			Required:    false,
+			Description: "Pub/sub channel for invalidations, when the remote store supports broadcasting",
+		},
+	})
+
+	core.Register(&cacheComponent{})
+}
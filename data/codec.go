@@ -0,0 +1,83 @@
+// data/codec.go
+package data
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// EncodeValue is called by stores that persist values as text before
+// validating and encrypting them. Strings and byte slices are stored as
+// they are; any other value is stored as its JSON encoding.
+func EncodeValue(value interface{}) (interface{}, error) {
+	switch value.(type) {
+	case nil, string, []byte:
+		return value, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("encoding value: %w", err)
+	}
+	return string(b), nil
+}
+
+// DecodeValue stores value, as returned by a store's Get, in the value
+// target points to. Text is decoded as JSON unless target is a *string or
+// *[]byte. Values a store keeps as they are, as the memory store does, are
+// assigned directly when their type allows it, and converted through JSON
+// otherwise.
+func DecodeValue(value interface{}, target interface{}) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("decoding value: target must be a non-nil pointer, not %T", target)
+	}
+
+	var raw []byte
+	switch v := value.(type) {
+	case string:
+		raw = []byte(v)
+	case []byte:
+		raw = v
+	default:
+		if value != nil && reflect.TypeOf(value).AssignableTo(rv.Elem().Type()) {
+			rv.Elem().Set(reflect.ValueOf(value))
+			return nil
+		}
+		b, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("decoding value: %w", err)
+		}
+		raw = b
+	}
+
+	switch t := target.(type) {
+	case *string:
+		*t = string(raw)
+		return nil
+	case *[]byte:
+		*t = append([]byte(nil), raw...)
+		return nil
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return fmt.Errorf("decoding value: %w", err)
+	}
+	return nil
+}
+
+// GetInto reads key from store into target, a pointer, decoding values
+// Set stored as JSON. It returns ErrKeyNotFound when key is missing.
+func GetInto(ctx context.Context, store Store, key string, target interface{}) error {
+	value, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return ErrKeyNotFound
+	}
+	if err := DecodeValue(value, target); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+// data/context.go
+package data
+
+import (
+	"context"
+	"errors"
+)
+
+type Consistency int
+
+const (
+	// Eventual allows reads from replicas or caches that may lag behind.
+	Eventual Consistency = iota
+	// Strong requires reads to observe all previously committed writes.
+	Strong
+)
+
+var ErrReadOnlyContext = errors.New("write attempted with read-only context")
+
+type readOnlyKey struct{}
+
+type consistencyKey struct{}
+
+// WithReadOnly marks ctx as carrying only reads: stores refuse writes
+// issued with it with ErrReadOnlyContext, and the SQL stores open its
+// transactions read-only. It does not choose where reads go; that is
+// WithConsistency.
+func WithReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+func IsReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey{}).(bool)
+	return readOnly
+}
+
+// WithConsistency sets how fresh reads with ctx must be. Strong reads skip
+// read replicas and the local tier of a tiered cache.
+func WithConsistency(ctx context.Context, c Consistency) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, c)
+}
+
+// ConsistencyFrom returns the consistency requested on ctx, defaulting to
+// Eventual when none was set.
+func ConsistencyFrom(ctx context.Context) Consistency {
+	c, _ := ctx.Value(consistencyKey{}).(Consistency)
+	return c
+}
@@ -0,0 +1,210 @@
+// data/encryption.go
+package data
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+type EncryptionMode string
+
+const (
+	// Deterministic encryption gives equal ciphertexts for equal values
+	// under the same data key, so encrypted values can still be compared
+	// and indexed. It reveals which values are equal.
+	Deterministic EncryptionMode = "deterministic"
+	// Randomized encryption reveals nothing about the value and binds the
+	// ciphertext to its key, so it cannot be moved to another key.
+	Randomized EncryptionMode = "randomized"
+)
+
+// KeyProvider supplies 32-byte data encryption keys by name.
+type KeyProvider interface {
+	DataKey(name string) ([]byte, error)
+}
+
+var (
+	ErrNoDataKey     = errors.New("data encryption key not available")
+	ErrDecryptFailed = errors.New("decrypting value failed")
+	// ErrReservedValue is returned for plaintext that would be read back
+	// as a ciphertext.
+	ErrReservedValue = errors.New("value starts with the ciphertext marker enc:v1:")
+)
+
+// encryptedPrefix marks stored ciphertexts as
+// enc:v1:<mode>:<key name>:<base64 nonce and sealed value>.
+const encryptedPrefix = "enc:v1:"
+
+type encryptionRule struct {
+	prefix  string
+	mode    EncryptionMode
+	keyName string
+}
+
+var (
+	encryptionMu sync.RWMutex
+	encryption   []encryptionRule
+	keyProvider  KeyProvider
+)
+
+// RegisterEncryption encrypts values of keys starting with prefix using the
+// named data key. The longest matching prefix wins.
+func RegisterEncryption(prefix string, mode EncryptionMode, keyName string) error {
+	if mode != Deterministic && mode != Randomized {
+		return fmt.Errorf("invalid encryption mode %q", mode)
+	}
+
+	encryptionMu.Lock()
+	defer encryptionMu.Unlock()
+
+	encryption = append(encryption, encryptionRule{prefix: prefix, mode: mode, keyName: keyName})
+	sort.SliceStable(encryption, func(i, j int) bool {
+		return len(encryption[i].prefix) > len(encryption[j].prefix)
+	})
+	return nil
+}
+
+func SetKeyProvider(p KeyProvider) {
+	encryptionMu.Lock()
+	defer encryptionMu.Unlock()
+	keyProvider = p
+}
+
+// EncryptValue is called by stores before writing. Values under an
+// encrypted prefix are returned as ciphertext strings; others pass
+// through unchanged, except strings starting with the ciphertext marker,
+// which are refused. Writes fail rather than store plaintext when the key
+// is unavailable.
+//
+// Every value is treated as plaintext. Stores write values that are
+// sealed already, as when undeleting or restoring them, without calling
+// EncryptValue.
+func EncryptValue(key string, value interface{}) (interface{}, error) {
+	encryptionMu.RLock()
+	var rule *encryptionRule
+	for i := range encryption {
+		if strings.HasPrefix(key, encryption[i].prefix) {
+			rule = &encryption[i]
+			break
+		}
+	}
+	provider := keyProvider
+	encryptionMu.RUnlock()
+
+	if rule == nil {
+		if s, ok := value.(string); ok && strings.HasPrefix(s, encryptedPrefix) {
+			return nil, fmt.Errorf("%w: %s", ErrReservedValue, key)
+		}
+		return value, nil
+	}
+	if value == nil {
+		return value, nil
+	}
+
+	aead, nonceKey, err := dataCipher(provider, rule.keyName)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := valueBytes(value)
+	nonce := make([]byte, aead.NonceSize())
+	var aad []byte
+	if rule.mode == Deterministic {
+		// A synthetic nonce: unique per value, repeated only for equal values
+		mac := hmac.New(sha256.New, nonceKey)
+		mac.Write(plaintext)
+		copy(nonce, mac.Sum(nil))
+		aad = []byte(rule.keyName)
+	} else {
+		if _, err := rand.Read(nonce); err != nil {
+			return nil, err
+		}
+		aad = []byte(key)
+	}
+
+	sealed := aead.Seal(nonce, nonce, plaintext, aad)
+	core.IncrCounterWithLabels("data.encrypted", map[string]string{"prefix": rule.prefix})
+	return fmt.Sprintf("%s%s:%s:%s", encryptedPrefix, rule.mode, rule.keyName, base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// DecryptValue is called by stores after reading. Ciphertexts are
+// recognized by their marker, so values written before a prefix stopped
+// being encrypted still decrypt.
+func DecryptValue(key string, value interface{}) (interface{}, error) {
+	s, ok := value.(string)
+	if !ok || !strings.HasPrefix(s, encryptedPrefix) {
+		return value, nil
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(s, encryptedPrefix), ":", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w for %s: malformed ciphertext", ErrDecryptFailed, key)
+	}
+	mode, keyName := EncryptionMode(parts[0]), parts[1]
+
+	sealed, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s: %v", ErrDecryptFailed, key, err)
+	}
+
+	encryptionMu.RLock()
+	provider := keyProvider
+	encryptionMu.RUnlock()
+
+	aad := []byte(key)
+	if mode == Deterministic {
+		aad = []byte(keyName)
+	}
+
+	aead, _, err := dataCipher(provider, keyName)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, fmt.Errorf("%w for %s: ciphertext too short", ErrDecryptFailed, key)
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], aad)
+	if err != nil {
+		return nil, fmt.Errorf("%w for %s", ErrDecryptFailed, key)
+	}
+	return string(plaintext), nil
+}
+
+// dataCipher derives separate encryption and nonce keys from the named
+// data key.
+func dataCipher(provider KeyProvider, name string) (cipher.AEAD, []byte, error) {
+	if provider == nil {
+		return nil, nil, fmt.Errorf("%w: %s (no key provider)", ErrNoDataKey, name)
+	}
+	master, err := provider.DataKey(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %v", ErrNoDataKey, name, err)
+	}
+
+	derive := func(label string) []byte {
+		mac := hmac.New(sha256.New, master)
+		mac.Write([]byte(label))
+		return mac.Sum(nil)
+	}
+
+	block, err := aes.NewCipher(derive("helper data encryption"))
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, nil, err
+	}
+	return aead, derive("helper data nonce"), nil
+}
@@ -0,0 +1,137 @@
+// data/guard.go
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+type QueryInterceptor func(ctx context.Context, query string, args []interface{}) error
+
+type InterceptorChain []QueryInterceptor
+
+func (c InterceptorChain) Run(ctx context.Context, query string, args []interface{}) error {
+	for _, interceptor := range c {
+		if err := interceptor(ctx, query, args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type GuardMode string
+
+const (
+	GuardOff    GuardMode = "off"
+	GuardWarn   GuardMode = "warn"
+	GuardReject GuardMode = "reject"
+)
+
+func ValidateGuardMode(v interface{}) error {
+	mode, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("query_guard must be string")
+	}
+	switch GuardMode(mode) {
+	case GuardOff, GuardWarn, GuardReject:
+		return nil
+	}
+	return fmt.Errorf("invalid query_guard: %s", mode)
+}
+
+var ErrSuspiciousQuery = errors.New("suspicious query rejected")
+
+// ValidateGuardSkip checks a query_guard_skip list.
+func ValidateGuardSkip(v interface{}) error {
+	raw, ok := v.([]interface{})
+	if !ok {
+		return fmt.Errorf("query_guard_skip must be a list")
+	}
+	for _, item := range raw {
+		switch item {
+		case "literal", "stacked", "comment", "union", "tautology":
+		default:
+			return fmt.Errorf("unknown query guard check %v", item)
+		}
+	}
+	return nil
+}
+
+var (
+	suspiciousPatterns = []struct {
+		check   string
+		reason  string
+		pattern *regexp.Regexp
+	}{
+		{"literal", "string literal in query", regexp.MustCompile(`'[^']*'`)},
+		{"stacked", "stacked statement", regexp.MustCompile(`;\s*\S`)},
+		{"comment", "inline comment", regexp.MustCompile(`--|/\*`)},
+		{"union", "union select", regexp.MustCompile(`(?i)\bunion\s+(all\s+)?select\b`)},
+	}
+	tautologyPattern = regexp.MustCompile(`(?i)\bor\s+(\w+)\s*=\s*(\w+)`)
+)
+
+// QueryGuard returns an interceptor that flags queries which look like they
+// were assembled by string concatenation rather than bound parameters. The
+// check is heuristic: it is meant to surface injection-prone call sites in
+// logs and metrics, and in reject mode to fail them before they run.
+//
+// The literal and comment checks also flag legitimate SQL, such as
+// WHERE status = 'active' or a -- comment in a migration. Checks named in
+// skip (literal, stacked, comment, union, tautology) are not run.
+func QueryGuard(store string, mode GuardMode, skip ...string) QueryInterceptor {
+	logger := core.GetLogger(store)
+	skipped := make(map[string]bool, len(skip))
+	for _, check := range skip {
+		skipped[check] = true
+	}
+
+	return func(ctx context.Context, query string, args []interface{}) error {
+		if mode == GuardOff {
+			return nil
+		}
+
+		reason := suspiciousReason(query, skipped)
+		if reason == "" {
+			return nil
+		}
+
+		core.IncrCounterWithLabels("data.query_guard.flagged", map[string]string{"store": store, "reason": reason})
+		core.ContextLogger(ctx, logger).Warn("Suspicious query (%s): %s", reason, truncateQuery(query))
+
+		if mode == GuardReject {
+			return fmt.Errorf("%w: %s", ErrSuspiciousQuery, reason)
+		}
+		return nil
+	}
+}
+
+func suspiciousReason(query string, skipped map[string]bool) string {
+	for _, p := range suspiciousPatterns {
+		if !skipped[p.check] && p.pattern.MatchString(query) {
+			return p.reason
+		}
+	}
+	if skipped["tautology"] {
+		return ""
+	}
+	for _, m := range tautologyPattern.FindAllStringSubmatch(query, -1) {
+		if m[1] == m[2] {
+			return "tautology"
+		}
+	}
+	return ""
+}
+
+func truncateQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) > 200 {
+		return query[:200] + "..."
+	}
+	return query
+}
@@ -0,0 +1,61 @@
+// data/init.go
+package data
+
+import (
+	"context"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+)
+
+type dataComponent struct{}
+
+func (c *dataComponent) Name() string {
+	return "data"
+}
+
+func (c *dataComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *dataComponent) Init() error {
+	cfg := config.Get()
+	applyReadOnlyConfig(cfg.Get("data", "read_only"), cfg.Get("data", "read_only_stores"))
+	return nil
+}
+
+func (c *dataComponent) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func applyReadOnlyConfig(global, stores interface{}) {
+	state := ReadOnlyState{}
+	state.Global, _ = global.(bool)
+	list, _ := stores.([]interface{})
+	for _, v := range list {
+		if name, ok := v.(string); ok && name != "" {
+			state.Stores = append(state.Stores, name)
+		}
+	}
+	SetReadOnlyState(state)
+}
+
+func init() {
+	config.Register("data", config.Schema{
+		"read_only": config.Field{
+			Default:     false,
+			Required:    false,
+			Description: "Reject every store write with ErrReadOnly while reads continue, including migrations",
+		},
+		"read_only_stores": config.Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "Store names, e.g. [\"mysql\"], to switch to read-only individually",
+		},
+	})
+
+	core.Register(&dataComponent{})
+	config.OnReload("data", func(old, new map[string]interface{}) {
+		applyReadOnlyConfig(new["read_only"], new["read_only_stores"])
+	})
+}
@@ -4,6 +4,7 @@ package data
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"time"
 )
 
@@ -16,12 +17,25 @@ type Store interface {
 	Exists(ctx context.Context, key string) (bool, error)
 }
 
+// KVStore is implemented by stores that can list their keys, so stores
+// shared through Namespaced can be enumerated per namespace.
+type KVStore interface {
+	Store
+	// Keys returns the keys starting with prefix, in ascending order.
+	Keys(ctx context.Context, prefix string) ([]string, error)
+	// Scan calls fn for each key starting with prefix, in ascending order,
+	// without holding them all in memory. An error from fn stops the scan
+	// and is returned.
+	Scan(ctx context.Context, prefix string, fn func(key string) error) error
+}
+
 type SQLStore interface {
 	Store
 	Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 	QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row
 	Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 	Begin(ctx context.Context) (*sql.Tx, error)
+	WithTx(ctx context.Context, fn func(*sql.Tx) error) error
 }
 
 type CacheStore interface {
@@ -30,6 +44,63 @@ type CacheStore interface {
 	GetMulti(ctx context.Context, keys []string) (map[string]interface{}, error)
 	Increment(ctx context.Context, key string, delta int64) (int64, error)
 	Decrement(ctx context.Context, key string, delta int64) (int64, error)
+	DeleteByPrefix(ctx context.Context, prefix string) (int64, error)
+	DeleteByPattern(ctx context.Context, pattern string) (int64, error)
+	GetTTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// NoTTL is returned by GetTTL for keys that never expire.
+const NoTTL time.Duration = -1
+
+// DocumentStore is implemented by document databases. Filters, updates and
+// documents use the database's own query language, e.g.
+// Document{"block": Document{"$gte": 100}}.
+type DocumentStore interface {
+	Store
+	// Find decodes the documents matching filter into results, a pointer
+	// to a slice.
+	Find(ctx context.Context, collection string, filter Document, opts FindOptions, results interface{}) error
+	// FindOne decodes the first document matching filter into result, or
+	// returns ErrDocumentNotFound.
+	FindOne(ctx context.Context, collection string, filter Document, result interface{}) error
+	Insert(ctx context.Context, collection string, docs ...interface{}) error
+	// Update applies update to the documents matching filter and returns
+	// how many matched.
+	Update(ctx context.Context, collection string, filter, update Document) (int64, error)
+	DeleteDocuments(ctx context.Context, collection string, filter Document) (int64, error)
+	// EnsureIndexes creates indexes that do not exist yet.
+	EnsureIndexes(ctx context.Context, collection string, indexes ...Index) error
+}
+
+type Document map[string]interface{}
+
+type FindOptions struct {
+	// Sort lists fields in order of precedence; a leading "-" sorts
+	// descending
+	Sort  []string
+	Limit int64
+	Skip  int64
+}
+
+type Index struct {
+	Name string
+	// Keys lists the indexed fields; a leading "-" indexes descending
+	Keys   []string
+	Unique bool
+	// ExpireAfter removes documents this long after the time in the
+	// (single) indexed field; zero keeps them
+	ExpireAfter time.Duration
+}
+
+var (
+	ErrKeyNotFound      = errors.New("key not found")
+	ErrDocumentNotFound = errors.New("document not found")
+)
+
+// StoreProvider is implemented by components that own a Store, so other
+// components can locate it by component name through core.GetComponent.
+type StoreProvider interface {
+	Store() Store
 }
 
 type StoreConfig interface {
@@ -38,3 +109,20 @@ type StoreConfig interface {
 	GetBool(key string) bool
 	GetDuration(key string) time.Duration
 }
+
+// RecoverableStore is implemented by stores that can soft delete kv rows
+// and journal kv mutations, so lost values can be recovered.
+type RecoverableStore interface {
+	Store
+	// Undelete revives a soft-deleted key that has not been purged yet.
+	Undelete(ctx context.Context, key string) error
+	// RestoreKey returns key to the state it had at the given time,
+	// according to the journal.
+	RestoreKey(ctx context.Context, key string, at time.Time) error
+	// RestoreAt restores every key changed after at and returns how many
+	// keys were restored.
+	RestoreAt(ctx context.Context, at time.Time) (int, error)
+	// Purge removes soft-deleted rows and journal entries past their
+	// retention.
+	Purge(ctx context.Context) (int64, error)
+}
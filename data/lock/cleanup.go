@@ -0,0 +1,42 @@
+// data/lock/cleanup.go
+package lock
+
+import (
+	"context"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// Cleanup clears locks whose holder stopped renewing them without
+// releasing them, typically because its process crashed, and returns how
+// many it cleared. Expired locks can be acquired anyway; clearing them
+// drops the dead holder's token and reports the crash.
+//
+// A lock is only cleared once both its expiry and its last renewal are
+// more than grace in the past, and only if its token and fence are still
+// the ones found, so a lock renewed or taken over meanwhile is left alone.
+func Cleanup(ctx context.Context, backend Backend, grace time.Duration) (int, error) {
+	orphans, err := backend.Orphans(ctx, grace)
+	if err != nil {
+		return 0, err
+	}
+	core.SetGauge("lock.orphans", int64(len(orphans)))
+
+	logger := core.GetLogger("lock")
+	cleared := 0
+	for _, o := range orphans {
+		ok, err := backend.Clear(ctx, o)
+		if err != nil {
+			return cleared, err
+		}
+		if !ok {
+			continue
+		}
+		cleared++
+		core.IncrCounterWithLabels("lock.orphans_cleared", map[string]string{"lock": o.Name})
+		logger.Warn("Cleared orphaned lock %s held by %s (fence %d, last renewed %s ago)",
+			o.Name, o.Token, o.Fence, time.Since(o.Heartbeat).Round(time.Second))
+	}
+	return cleared, nil
+}
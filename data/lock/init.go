@@ -0,0 +1,167 @@
+// data/lock/init.go
+package lock
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data"
+	"github.com/polkadot-go/helper/managers/scheduler"
+)
+
+var instance *Locker
+
+// Get returns the Locker configured by the lock section, or nil when
+// lock.backend is empty.
+func Get() *Locker {
+	return instance
+}
+
+type lockComponent struct{}
+
+func (c *lockComponent) Name() string {
+	return "lock"
+}
+
+func (c *lockComponent) Dependencies() []string {
+	return []string{"config", "logger", "scheduler"}
+}
+
+func (c *lockComponent) Init() error {
+	cfg := config.Get()
+
+	var backend Backend
+	switch name := cfg.GetString("lock", "backend"); name {
+	case "":
+		return nil
+	case "memory":
+		backend = NewMemory()
+	default:
+		backend = &storeBackend{name: name}
+	}
+	instance = New(backend)
+
+	grace := cfg.GetDuration("lock", "cleanup_grace")
+	return scheduler.Get().Register("lock_cleanup", scheduler.JobOptions{
+		Interval: cfg.GetDuration("lock", "cleanup_interval"),
+		Timeout:  30 * time.Second,
+	}, func(ctx context.Context) error {
+		_, err := Cleanup(ctx, backend, grace)
+		return err
+	})
+}
+
+func (c *lockComponent) Shutdown(ctx context.Context) error {
+	scheduler.Get().Remove("lock_cleanup")
+	return nil
+}
+
+// storeBackend is a MySQL backend over the store of a named component.
+// That component may initialize after this one, so it is looked up on
+// every call, and helper_locks is created on first use.
+type storeBackend struct {
+	name string
+
+	mu     sync.Mutex
+	schema bool
+}
+
+func (b *storeBackend) resolve(ctx context.Context) (*MySQL, error) {
+	if !core.IsInitialized(b.name) {
+		return nil, fmt.Errorf("lock backend %s is not initialized", b.name)
+	}
+	provider, ok := core.GetComponent(b.name).(data.StoreProvider)
+	if !ok || provider.Store() == nil {
+		return nil, fmt.Errorf("lock backend %s does not provide a store", b.name)
+	}
+	store, ok := provider.Store().(data.SQLStore)
+	if !ok {
+		return nil, fmt.Errorf("lock backend %s is not a SQLStore", b.name)
+	}
+
+	m := NewMySQL(store)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.schema {
+		if err := m.EnsureSchema(ctx); err != nil {
+			return nil, err
+		}
+		b.schema = true
+	}
+	return m, nil
+}
+
+func (b *storeBackend) Acquire(ctx context.Context, name, token string, ttl time.Duration) (int64, bool, error) {
+	m, err := b.resolve(ctx)
+	if err != nil {
+		return 0, false, err
+	}
+	return m.Acquire(ctx, name, token, ttl)
+}
+
+func (b *storeBackend) Renew(ctx context.Context, name, token string, ttl time.Duration) error {
+	m, err := b.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return m.Renew(ctx, name, token, ttl)
+}
+
+func (b *storeBackend) Release(ctx context.Context, name, token string) error {
+	m, err := b.resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return m.Release(ctx, name, token)
+}
+
+func (b *storeBackend) Orphans(ctx context.Context, grace time.Duration) ([]Orphan, error) {
+	m, err := b.resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return m.Orphans(ctx, grace)
+}
+
+func (b *storeBackend) Clear(ctx context.Context, o Orphan) (bool, error) {
+	m, err := b.resolve(ctx)
+	if err != nil {
+		return false, err
+	}
+	return m.Clear(ctx, o)
+}
+
+func validateBackend(value interface{}) error {
+	switch fmt.Sprint(value) {
+	case "", "memory", "mysql":
+		return nil
+	}
+	return fmt.Errorf("backend must be mysql, memory or empty")
+}
+
+func init() {
+	config.Register("lock", config.Schema{
+		"backend": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Where locks are kept: mysql, or memory for a single instance (empty disables lock.Get)",
+			Validator:   validateBackend,
+		},
+		"cleanup_interval": config.Field{
+			Default:     "1m",
+			Required:    false,
+			Description: "How often the lock_cleanup job clears locks orphaned by crashed holders",
+		},
+		"cleanup_grace": config.Field{
+			Default:     "1m",
+			Required:    false,
+			Description: "How long a lock must be expired and unrenewed before lock_cleanup clears it",
+		},
+	})
+
+	core.Register(&lockComponent{})
+}
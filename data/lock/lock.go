@@ -0,0 +1,302 @@
+// data/lock/lock.go
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+var (
+	// ErrHeld is returned by TryAcquire when the lock is held.
+	ErrHeld = errors.New("lock held by another holder")
+	// ErrNotHeld is returned by Renew and Release once the lock has expired
+	// or been taken over.
+	ErrNotHeld = errors.New("lock not held")
+)
+
+// Backend stores named locks with an expiry. Each acquisition has its own
+// token, so a lock that expired and was taken over is not renewed or
+// released by its previous holder. It also gets a fence, which grows with
+// every acquisition of the name.
+type Backend interface {
+	// Acquire takes name for ttl with token if it is free or expired, and
+	// returns its new fence.
+	Acquire(ctx context.Context, name, token string, ttl time.Duration) (fence int64, ok bool, err error)
+	// Renew extends the hold of token on name by ttl, or returns
+	// ErrNotHeld.
+	Renew(ctx context.Context, name, token string, ttl time.Duration) error
+	// Release frees name if token holds it, or returns ErrNotHeld.
+	Release(ctx context.Context, name, token string) error
+	// Orphans returns locks that expired, and were last renewed, more than
+	// grace ago without being released.
+	Orphans(ctx context.Context, grace time.Duration) ([]Orphan, error)
+	// Clear releases o if it is still held with the same token and fence,
+	// and reports whether it was.
+	Clear(ctx context.Context, o Orphan) (bool, error)
+}
+
+// Orphan is a lock whose holder stopped renewing it without releasing it,
+// typically because its process crashed.
+type Orphan struct {
+	Name      string    `json:"name"`
+	Token     string    `json:"token"`
+	Fence     int64     `json:"fence"`
+	Heartbeat time.Time `json:"heartbeat"`
+	Expired   time.Time `json:"expired"`
+}
+
+// Locker acquires locks in a Backend on behalf of one process, so several
+// helper-based instances can agree on which of them runs a job.
+type Locker struct {
+	backend Backend
+	owner   string
+	// RetryInterval is how often Acquire polls a held lock
+	RetryInterval time.Duration
+	logger        core.Logger
+}
+
+// New returns a Locker whose owner identifies this process by hostname and
+// pid. Tokens of its locks start with the owner.
+func New(backend Backend) *Locker {
+	host, _ := os.Hostname()
+	return &Locker{
+		backend:       backend,
+		owner:         fmt.Sprintf("%s-%d", host, os.Getpid()),
+		RetryInterval: time.Second,
+		logger:        core.GetLogger("lock"),
+	}
+}
+
+func (l *Locker) Owner() string {
+	return l.owner
+}
+
+func (l *Locker) newToken() string {
+	suffix := make([]byte, 8)
+	rand.Read(suffix)
+	return l.owner + "/" + hex.EncodeToString(suffix)
+}
+
+// Lock is one acquisition of a named lock. It stays held for its TTL after
+// the last Acquire or Renew.
+type Lock struct {
+	backend Backend
+	name    string
+	token   string
+	fence   int64
+	ttl     time.Duration
+}
+
+func (k *Lock) Name() string {
+	return k.name
+}
+
+// Fence grows with every acquisition of the lock's name. Writes made under
+// the lock can carry it so the resource they go to rejects a holder whose
+// lock has since been taken over.
+func (k *Lock) Fence() int64 {
+	return k.fence
+}
+
+// TryAcquire takes name for ttl, or returns ErrHeld without waiting. Locks
+// are not reentrant: a name held by this Locker is held like any other.
+func (l *Locker) TryAcquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	token := l.newToken()
+	fence, ok, err := l.backend.Acquire(ctx, name, token, ttl)
+	if err != nil {
+		return nil, fmt.Errorf("acquiring lock %s: %w", name, err)
+	}
+	labels := map[string]string{"lock": name}
+	if !ok {
+		core.IncrCounterWithLabels("lock.contended", labels)
+		return nil, ErrHeld
+	}
+	core.IncrCounterWithLabels("lock.acquired", labels)
+	return &Lock{backend: l.backend, name: name, token: token, fence: fence, ttl: ttl}, nil
+}
+
+// Acquire waits until it can take name for ttl or ctx is done.
+func (l *Locker) Acquire(ctx context.Context, name string, ttl time.Duration) (*Lock, error) {
+	for {
+		lock, err := l.TryAcquire(ctx, name, ttl)
+		if !errors.Is(err, ErrHeld) {
+			return lock, err
+		}
+		select {
+		case <-time.After(l.RetryInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Renew extends the lock by its TTL.
+func (k *Lock) Renew(ctx context.Context) error {
+	return k.backend.Renew(ctx, k.name, k.token, k.ttl)
+}
+
+func (k *Lock) Release(ctx context.Context) error {
+	return k.backend.Release(ctx, k.name, k.token)
+}
+
+// Run calls fn while holding name, renewing the lock every third of ttl.
+// If the lock is held, Run returns ErrHeld without calling fn. If a renewal
+// fails, fn's context is cancelled, since another instance may take the
+// lock over once it expires.
+func (l *Locker) Run(ctx context.Context, name string, ttl time.Duration, fn func(ctx context.Context, lock *Lock) error) error {
+	lock, err := l.TryAcquire(ctx, name, ttl)
+	if err != nil {
+		return err
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := lock.Renew(runCtx); err != nil {
+					if runCtx.Err() == nil {
+						core.IncrCounterWithLabels("lock.lost", map[string]string{"lock": name})
+						l.logger.Warn("Lost lock %s: %v", name, err)
+						cancel()
+					}
+					return
+				}
+			case <-runCtx.Done():
+				return
+			}
+		}
+	}()
+
+	err = fn(runCtx, lock)
+	cancel()
+	wg.Wait()
+
+	// Released even when ctx is done, so others need not wait out the TTL
+	releaseCtx, releaseCancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer releaseCancel()
+	if releaseErr := lock.Release(releaseCtx); releaseErr != nil && !errors.Is(releaseErr, ErrNotHeld) {
+		l.logger.Warn("Releasing lock %s: %v", name, releaseErr)
+	}
+	return err
+}
+
+// Memory is a Backend for a single process, e.g. for development or when
+// only one instance runs.
+type Memory struct {
+	mu    sync.Mutex
+	locks map[string]*memoryLock
+}
+
+type memoryLock struct {
+	token     string
+	fence     int64
+	heartbeat time.Time
+	expires   time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{locks: make(map[string]*memoryLock)}
+}
+
+func (m *Memory) Acquire(ctx context.Context, name, token string, ttl time.Duration) (int64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	held, ok := m.locks[name]
+	if !ok {
+		held = &memoryLock{}
+		m.locks[name] = held
+	}
+	if now.Before(held.expires) {
+		return 0, false, nil
+	}
+	held.token = token
+	held.fence++
+	held.heartbeat = now
+	held.expires = now.Add(ttl)
+	return held.fence, true, nil
+}
+
+// holder returns name's lock if token holds it. Called with mu held.
+func (m *Memory) holder(name, token string) (*memoryLock, error) {
+	held, ok := m.locks[name]
+	if !ok || held.token != token || !time.Now().Before(held.expires) {
+		return nil, ErrNotHeld
+	}
+	return held, nil
+}
+
+func (m *Memory) Renew(ctx context.Context, name, token string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	held, err := m.holder(name, token)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	held.heartbeat = now
+	held.expires = now.Add(ttl)
+	return nil
+}
+
+// Release keeps the name's fence, so later holders still get larger ones.
+func (m *Memory) Release(ctx context.Context, name, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	held, err := m.holder(name, token)
+	if err != nil {
+		return err
+	}
+	held.token = ""
+	held.expires = time.Now()
+	return nil
+}
+
+func (m *Memory) Orphans(ctx context.Context, grace time.Duration) ([]Orphan, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cutoff := time.Now().Add(-grace)
+	var orphans []Orphan
+	for name, held := range m.locks {
+		if held.token != "" && held.expires.Before(cutoff) && held.heartbeat.Before(cutoff) {
+			orphans = append(orphans, Orphan{
+				Name:      name,
+				Token:     held.token,
+				Fence:     held.fence,
+				Heartbeat: held.heartbeat,
+				Expired:   held.expires,
+			})
+		}
+	}
+	return orphans, nil
+}
+
+func (m *Memory) Clear(ctx context.Context, o Orphan) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	held, ok := m.locks[o.Name]
+	if !ok || held.token != o.Token || held.fence != o.Fence || time.Now().Before(held.expires) {
+		return false, nil
+	}
+	held.token = ""
+	return true, nil
+}
@@ -0,0 +1,131 @@
+// data/lock/mysql.go
+package lock
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/polkadot-go/helper/data"
+)
+
+// MySQL is a Backend keeping locks as rows of helper_locks in a MySQL
+// store. Expiry is judged by the database clock, so instances with skewed
+// clocks still agree on when a lock has expired. Released locks keep their
+// row, and with it their fence.
+//
+// Rows are used rather than GET_LOCK, which ties a lock to one pooled
+// connection and has no expiry.
+type MySQL struct {
+	store data.SQLStore
+}
+
+func NewMySQL(store data.SQLStore) *MySQL {
+	return &MySQL{store: store}
+}
+
+// EnsureSchema creates helper_locks if it does not exist.
+func (m *MySQL) EnsureSchema(ctx context.Context) error {
+	_, err := m.store.Exec(ctx, `CREATE TABLE IF NOT EXISTS helper_locks (
+		name VARCHAR(191) NOT NULL PRIMARY KEY,
+		token VARCHAR(191) NOT NULL DEFAULT '',
+		fence BIGINT NOT NULL DEFAULT 0,
+		heartbeat_at DATETIME(6) NOT NULL,
+		expires_at DATETIME(6) NOT NULL
+	)`)
+	return err
+}
+
+func (m *MySQL) Acquire(ctx context.Context, name, token string, ttl time.Duration) (int64, bool, error) {
+	var (
+		holder string
+		fence  int64
+	)
+	err := m.store.WithTx(ctx, func(tx *sql.Tx) error {
+		// MySQL applies the assignments in order: fence and token still
+		// see the old expiry, heartbeat_at and expires_at the new token
+		_, err := tx.ExecContext(ctx, `INSERT INTO helper_locks (name, token, fence, heartbeat_at, expires_at)
+			VALUES (?, ?, 1, UTC_TIMESTAMP(6), DATE_ADD(UTC_TIMESTAMP(6), INTERVAL ? MICROSECOND))
+			ON DUPLICATE KEY UPDATE
+				fence = IF(expires_at <= UTC_TIMESTAMP(6), fence + 1, fence),
+				token = IF(expires_at <= UTC_TIMESTAMP(6), VALUES(token), token),
+				heartbeat_at = IF(token = VALUES(token), VALUES(heartbeat_at), heartbeat_at),
+				expires_at = IF(token = VALUES(token), VALUES(expires_at), expires_at)`,
+			name, token, ttl.Microseconds())
+		if err != nil {
+			return err
+		}
+		return tx.QueryRowContext(ctx, "SELECT token, fence FROM helper_locks WHERE name = ?", name).Scan(&holder, &fence)
+	})
+	if err != nil || holder != token {
+		return 0, false, err
+	}
+	return fence, true, nil
+}
+
+func (m *MySQL) Renew(ctx context.Context, name, token string, ttl time.Duration) error {
+	res, err := m.store.Exec(ctx, `UPDATE helper_locks
+		SET heartbeat_at = UTC_TIMESTAMP(6), expires_at = DATE_ADD(UTC_TIMESTAMP(6), INTERVAL ? MICROSECOND)
+		WHERE name = ? AND token = ? AND expires_at > UTC_TIMESTAMP(6)`,
+		ttl.Microseconds(), name, token)
+	return checkHeld(res, err)
+}
+
+func (m *MySQL) Release(ctx context.Context, name, token string) error {
+	res, err := m.store.Exec(ctx, `UPDATE helper_locks
+		SET token = '', expires_at = UTC_TIMESTAMP(6)
+		WHERE name = ? AND token = ? AND expires_at > UTC_TIMESTAMP(6)`,
+		name, token)
+	return checkHeld(res, err)
+}
+
+func (m *MySQL) Orphans(ctx context.Context, grace time.Duration) ([]Orphan, error) {
+	// Replicas may not have seen the latest renewals yet
+	rows, err := m.store.Query(data.WithPrimary(ctx), `SELECT name, token, fence, heartbeat_at, expires_at
+		FROM helper_locks
+		WHERE token <> ''
+			AND expires_at < DATE_SUB(UTC_TIMESTAMP(6), INTERVAL ? MICROSECOND)
+			AND heartbeat_at < DATE_SUB(UTC_TIMESTAMP(6), INTERVAL ? MICROSECOND)`,
+		grace.Microseconds(), grace.Microseconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orphans []Orphan
+	for rows.Next() {
+		var o Orphan
+		if err := rows.Scan(&o.Name, &o.Token, &o.Fence, &o.Heartbeat, &o.Expired); err != nil {
+			return nil, err
+		}
+		orphans = append(orphans, o)
+	}
+	return orphans, rows.Err()
+}
+
+func (m *MySQL) Clear(ctx context.Context, o Orphan) (bool, error) {
+	res, err := m.store.Exec(ctx, `UPDATE helper_locks SET token = ''
+		WHERE name = ? AND token = ? AND fence = ? AND expires_at <= UTC_TIMESTAMP(6)`,
+		o.Name, o.Token, o.Fence)
+	if err := checkHeld(res, err); err != nil {
+		if err == ErrNotHeld {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func checkHeld(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrNotHeld
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// data/memory/init.go
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data"
+)
+
+type memoryComponent struct{}
+
+func (c *memoryComponent) Name() string {
+	return "memory"
+}
+
+func (c *memoryComponent) Dependencies() []string {
+	return []string{"config", "logger", "data"}
+}
+
+func (c *memoryComponent) Init(ctx context.Context) error {
+	instance = New(&memoryConfig{cfg: config.Get()})
+	if err := instance.Connect(ctx); err != nil {
+		return err
+	}
+
+	core.RegisterHealthCheck("memory", instance)
+	return nil
+}
+
+func (c *memoryComponent) Shutdown(ctx context.Context) error {
+	if instance != nil {
+		return instance.Close()
+	}
+	return nil
+}
+
+func (c *memoryComponent) Store() data.Store {
+	if instance == nil {
+		return nil
+	}
+	return instance
+}
+
+type memoryConfig struct {
+	cfg *config.Config
+}
+
+func (m *memoryConfig) GetString(key string) string {
+	return m.cfg.GetString("memory", key)
+}
+
+func (m *memoryConfig) GetInt(key string) int {
+	return m.cfg.GetInt("memory", key)
+}
+
+func (m *memoryConfig) GetBool(key string) bool {
+	return m.cfg.GetBool("memory", key)
+}
+
+func (m *memoryConfig) GetDuration(key string) time.Duration {
+	return m.cfg.GetDuration("memory", key)
+}
+
+func init() {
+	config.Register("memory", config.Schema{
+		"shards": config.Field{
+			Default:     32,
+			Required:    false,
+			Description: "Number of independently locked map shards",
+		},
+		"sweep_interval": config.Field{
+			Default:     "1m",
+			Required:    false,
+			Description: "How often expired keys are removed (0 disables the sweep; expired keys stay invisible)",
+		},
+	})
+
+	core.Register(&memoryComponent{})
+}
@@ -0,0 +1,370 @@
+// data/memory/memory.go
+package memory
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+// Memory is a process-local Store and CacheStore backed by a sharded map.
+// Expired keys are invisible immediately and removed by a background sweep.
+type Memory struct {
+	shards   []*shard
+	config   data.StoreConfig
+	logger   core.Logger
+	sweepMu  sync.Mutex
+	stop     chan struct{}
+	sweepWG  sync.WaitGroup
+	interval time.Duration
+}
+
+type shard struct {
+	mu    sync.RWMutex
+	items map[string]entry
+}
+
+type entry struct {
+	value   interface{}
+	expires time.Time
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expires.IsZero() && !now.Before(e.expires)
+}
+
+const (
+	defaultShards        = 32
+	defaultSweepInterval = time.Minute
+)
+
+var instance *Memory
+
+func Get() *Memory {
+	return instance
+}
+
+// New creates an empty store. A nil cfg uses the defaults, which makes the
+// store usable as a fake in tests without the config component.
+func New(cfg data.StoreConfig) *Memory {
+	shards, interval := defaultShards, defaultSweepInterval
+	if cfg != nil {
+		if n := cfg.GetInt("shards"); n > 0 {
+			shards = n
+		}
+		interval = cfg.GetDuration("sweep_interval")
+	}
+
+	m := &Memory{
+		shards:   make([]*shard, shards),
+		config:   cfg,
+		logger:   core.GetLogger("memory"),
+		interval: interval,
+	}
+	for i := range m.shards {
+		m.shards[i] = &shard{items: make(map[string]entry)}
+	}
+	return m
+}
+
+func (m *Memory) shard(key string) *shard {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return m.shards[h.Sum32()%uint32(len(m.shards))]
+}
+
+// Connect starts the expiry sweep; the store is usable without it.
+func (m *Memory) Connect(ctx context.Context) error {
+	m.sweepMu.Lock()
+	defer m.sweepMu.Unlock()
+
+	if m.stop != nil || m.interval <= 0 {
+		return nil
+	}
+	m.stop = make(chan struct{})
+	stop := m.stop
+
+	m.sweepWG.Add(1)
+	go func() {
+		defer m.sweepWG.Done()
+		core.Supervise("memory_sweep", stop, func() {
+			ticker := time.NewTicker(m.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					if n := m.Sweep(); n > 0 {
+						m.logger.Debug("Swept %d expired keys", n)
+					}
+					core.SetGauge("memory.entries", int64(m.Len()))
+				case <-stop:
+					return
+				}
+			}
+		})
+	}()
+	return nil
+}
+
+func (m *Memory) Close() error {
+	m.sweepMu.Lock()
+	stop := m.stop
+	m.stop = nil
+	m.sweepMu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		m.sweepWG.Wait()
+	}
+	return nil
+}
+
+func (m *Memory) Get(ctx context.Context, key string) (interface{}, error) {
+	s := m.shard(key)
+	s.mu.RLock()
+	e, ok := s.items[key]
+	s.mu.RUnlock()
+
+	if !ok || e.expired(time.Now()) {
+		core.IncrCounterWithLabels("memory.gets", map[string]string{"result": "miss"})
+		return nil, nil
+	}
+	core.IncrCounterWithLabels("memory.gets", map[string]string{"result": "hit"})
+	return e.value, nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value interface{}) error {
+	return m.SetWithTTL(ctx, key, value, 0)
+}
+
+// SetWithTTL stores value until ttl passes; a ttl of zero or less never
+// expires.
+func (m *Memory) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if err := data.CheckWritable(ctx, "memory"); err != nil {
+		return err
+	}
+	if err := data.ValidateValue(key, value); err != nil {
+		return err
+	}
+
+	e := entry{value: value}
+	if ttl > 0 {
+		e.expires = time.Now().Add(ttl)
+	}
+
+	s := m.shard(key)
+	s.mu.Lock()
+	s.items[key] = e
+	s.mu.Unlock()
+	return nil
+}
+
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	if err := data.CheckWritable(ctx, "memory"); err != nil {
+		return err
+	}
+
+	s := m.shard(key)
+	s.mu.Lock()
+	delete(s.items, key)
+	s.mu.Unlock()
+	return nil
+}
+
+func (m *Memory) Exists(ctx context.Context, key string) (bool, error) {
+	s := m.shard(key)
+	s.mu.RLock()
+	e, ok := s.items[key]
+	s.mu.RUnlock()
+	return ok && !e.expired(time.Now()), nil
+}
+
+// GetMulti returns the live keys among keys; missing ones are left out.
+func (m *Memory) GetMulti(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	now := time.Now()
+	result := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		s := m.shard(key)
+		s.mu.RLock()
+		e, ok := s.items[key]
+		s.mu.RUnlock()
+		if ok && !e.expired(now) {
+			result[key] = e.value
+		}
+	}
+	return result, nil
+}
+
+// Increment adds delta to an integer value, treating a missing key as 0.
+// The key keeps its TTL.
+func (m *Memory) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	if err := data.CheckWritable(ctx, "memory"); err != nil {
+		return 0, err
+	}
+
+	s := m.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.items[key]
+	if !ok || e.expired(time.Now()) {
+		e = entry{}
+	}
+
+	var current int64
+	if e.value != nil {
+		n, err := toInt64(e.value)
+		if err != nil {
+			return 0, fmt.Errorf("increment %s: %w", key, err)
+		}
+		current = n
+	}
+
+	e.value = current + delta
+	s.items[key] = e
+	return current + delta, nil
+}
+
+func (m *Memory) Decrement(ctx context.Context, key string, delta int64) (int64, error) {
+	return m.Increment(ctx, key, -delta)
+}
+
+func (m *Memory) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
+	return m.deleteMatching(ctx, func(key string) bool { return strings.HasPrefix(key, prefix) })
+}
+
+func (m *Memory) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	re, err := data.CompilePattern(pattern)
+	if err != nil {
+		return 0, err
+	}
+	return m.deleteMatching(ctx, re.MatchString)
+}
+
+func (m *Memory) deleteMatching(ctx context.Context, match func(string) bool) (int64, error) {
+	if err := data.CheckWritable(ctx, "memory"); err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	now := time.Now()
+	for _, s := range m.shards {
+		s.mu.Lock()
+		for key, e := range s.items {
+			if match(key) {
+				if !e.expired(now) {
+					deleted++
+				}
+				delete(s.items, key)
+			}
+		}
+		s.mu.Unlock()
+	}
+	return deleted, nil
+}
+
+func (m *Memory) Keys(ctx context.Context, prefix string) ([]string, error) {
+	now := time.Now()
+	var keys []string
+	for _, s := range m.shards {
+		s.mu.RLock()
+		for key, e := range s.items {
+			if strings.HasPrefix(key, prefix) && !e.expired(now) {
+				keys = append(keys, key)
+			}
+		}
+		s.mu.RUnlock()
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// Scan visits a snapshot of the matching keys, so fn may modify the store.
+func (m *Memory) Scan(ctx context.Context, prefix string, fn func(key string) error) error {
+	keys, _ := m.Keys(ctx, prefix)
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetTTL returns the time left before key expires, NoTTL if it never
+// does, or ErrKeyNotFound.
+func (m *Memory) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	s := m.shard(key)
+	s.mu.RLock()
+	e, ok := s.items[key]
+	s.mu.RUnlock()
+
+	now := time.Now()
+	if !ok || e.expired(now) {
+		return 0, data.ErrKeyNotFound
+	}
+	if e.expires.IsZero() {
+		return data.NoTTL, nil
+	}
+	return e.expires.Sub(now), nil
+}
+
+// Sweep removes expired keys and returns how many it removed.
+func (m *Memory) Sweep() int {
+	removed := 0
+	now := time.Now()
+	for _, s := range m.shards {
+		s.mu.Lock()
+		for key, e := range s.items {
+			if e.expired(now) {
+				delete(s.items, key)
+				removed++
+			}
+		}
+		s.mu.Unlock()
+	}
+	return removed
+}
+
+// Len counts stored keys, including expired ones not yet swept.
+func (m *Memory) Len() int {
+	n := 0
+	for _, s := range m.shards {
+		s.mu.RLock()
+		n += len(s.items)
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+func (m *Memory) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
+	return core.HealthHealthy, nil
+}
+
+func toInt64(v interface{}) (int64, error) {
+	switch n := v.(type) {
+	case int64:
+		return n, nil
+	case int:
+		return int64(n), nil
+	case int32:
+		return int64(n), nil
+	case string:
+		i, err := strconv.ParseInt(n, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("value is not an integer")
+		}
+		return i, nil
+	}
+	return 0, fmt.Errorf("value of type %T is not an integer", v)
+}
@@ -0,0 +1,280 @@
+// data/migrate/migrate.go
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+type Dialect string
+
+const (
+	MySQL    Dialect = "mysql"
+	Postgres Dialect = "postgres"
+)
+
+// Migration is one schema version. Down may be empty, in which case the
+// version cannot be rolled back.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_([^.]+)\.(up|down)\.sql$`)
+
+// Load reads migrations named <version>_<name>.up.sql and
+// <version>_<name>.down.sql from dir in fsys, which may be an embed.FS or
+// os.DirFS.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, _ := strconv.ParseInt(match[1], 10, 64)
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+type Migrator struct {
+	store      data.SQLStore
+	dialect    Dialect
+	table      string
+	migrations []Migration
+	logger     core.Logger
+}
+
+// New creates a migrator that records applied versions in table. Each
+// migration runs in its own transaction; note that MySQL commits DDL
+// implicitly, so a failed MySQL migration may be partially applied.
+func New(store data.SQLStore, dialect Dialect, table string, migrations []Migration) *Migrator {
+	return &Migrator{
+		store:      store,
+		dialect:    dialect,
+		table:      table,
+		migrations: migrations,
+		logger:     core.GetLogger("migrate"),
+	}
+}
+
+func (m *Migrator) ensureTable(ctx context.Context) error {
+	var ddl string
+	switch m.dialect {
+	case MySQL:
+		ddl = "CREATE TABLE IF NOT EXISTS " + m.table + " (version BIGINT PRIMARY KEY, name VARCHAR(255) NOT NULL, applied_at DATETIME(6) NOT NULL)"
+	case Postgres:
+		ddl = "CREATE TABLE IF NOT EXISTS " + m.table + " (version BIGINT PRIMARY KEY, name TEXT NOT NULL, applied_at TIMESTAMPTZ NOT NULL)"
+	default:
+		return fmt.Errorf("unsupported dialect %q", m.dialect)
+	}
+
+	return m.store.WithTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, ddl)
+		return err
+	})
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int64]bool, error) {
+	if err := m.ensureTable(ctx); err != nil {
+		return nil, fmt.Errorf("creating %s: %w", m.table, err)
+	}
+
+	rows, err := m.store.Query(ctx, "SELECT version FROM "+m.table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]bool)
+	for rows.Next() {
+		var v int64
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		applied[v] = true
+	}
+	return applied, rows.Err()
+}
+
+// Version returns the highest applied version, or 0.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var version int64
+	for v := range applied {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// Up applies every pending migration in version order and returns how
+// many ran.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, mig := range m.migrations {
+		if applied[mig.Version] {
+			continue
+		}
+		err := m.run(ctx, mig.Up, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "INSERT INTO "+m.table+" (version, name, applied_at) VALUES ("+m.placeholders(3)+")",
+				mig.Version, mig.Name, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return count, fmt.Errorf("migration %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		m.logger.Info("Applied migration %d_%s to %s", mig.Version, mig.Name, m.table)
+		core.IncrCounterWithLabels("migrate.applied", map[string]string{"table": m.table})
+		count++
+	}
+	return count, nil
+}
+
+// Down rolls back the latest steps applied migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		mig := m.migrations[i]
+		if !applied[mig.Version] {
+			continue
+		}
+		if mig.Down == "" {
+			return count, fmt.Errorf("migration %d_%s has no down file", mig.Version, mig.Name)
+		}
+		err := m.run(ctx, mig.Down, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, "DELETE FROM "+m.table+" WHERE version = "+m.placeholders(1), mig.Version)
+			return err
+		})
+		if err != nil {
+			return count, fmt.Errorf("rolling back %d_%s: %w", mig.Version, mig.Name, err)
+		}
+		m.logger.Info("Rolled back migration %d_%s on %s", mig.Version, mig.Name, m.table)
+		count++
+	}
+	return count, nil
+}
+
+func (m *Migrator) run(ctx context.Context, script string, record func(*sql.Tx) error) error {
+	return m.store.WithTx(ctx, func(tx *sql.Tx) error {
+		for _, stmt := range SplitStatements(script) {
+			if _, err := tx.ExecContext(ctx, stmt); err != nil {
+				return err
+			}
+		}
+		return record(tx)
+	})
+}
+
+func (m *Migrator) placeholders(n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		if m.dialect == Postgres {
+			parts[i] = "$" + strconv.Itoa(i+1)
+		} else {
+			parts[i] = "?"
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SplitStatements splits a script on semicolons outside quotes and
+// comments, since drivers do not reliably accept several statements in
+// one Exec.
+func SplitStatements(script string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote byte
+	lineComment := false
+
+	flush := func() {
+		if stmt := strings.TrimSpace(current.String()); stmt != "" {
+			statements = append(statements, stmt)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(script); i++ {
+		ch := script[i]
+		switch {
+		case lineComment:
+			if ch == '\n' {
+				lineComment = false
+				current.WriteByte(ch)
+			}
+			continue
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '-' && i+1 < len(script) && script[i+1] == '-':
+			lineComment = true
+			continue
+		case ch == '\'' || ch == '"' || ch == '`':
+			quote = ch
+		case ch == ';':
+			flush()
+			continue
+		}
+		current.WriteByte(ch)
+	}
+	flush()
+	return statements
+}
@@ -0,0 +1,133 @@
+// data/mongodb/init.go
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data"
+)
+
+type mongodbComponent struct{}
+
+func (c *mongodbComponent) Name() string {
+	return "mongodb"
+}
+
+func (c *mongodbComponent) Dependencies() []string {
+	return []string{"config", "logger", "data"}
+}
+
+func (c *mongodbComponent) Init(ctx context.Context) error {
+	instance = New(&mongodbConfig{cfg: config.Get()})
+	if err := instance.Connect(ctx); err != nil {
+		return err
+	}
+
+	core.RegisterHealthCheck("mongodb", instance)
+	return nil
+}
+
+func (c *mongodbComponent) Shutdown(ctx context.Context) error {
+	if instance != nil {
+		return instance.Close()
+	}
+	return nil
+}
+
+func (c *mongodbComponent) Store() data.Store {
+	if instance == nil {
+		return nil
+	}
+	return instance
+}
+
+type mongodbConfig struct {
+	cfg *config.Config
+}
+
+func (m *mongodbConfig) GetString(key string) string {
+	return m.cfg.GetString("mongodb", key)
+}
+
+func (m *mongodbConfig) GetInt(key string) int {
+	return m.cfg.GetInt("mongodb", key)
+}
+
+func (m *mongodbConfig) GetBool(key string) bool {
+	return m.cfg.GetBool("mongodb", key)
+}
+
+func (m *mongodbConfig) GetDuration(key string) time.Duration {
+	return m.cfg.GetDuration("mongodb", key)
+}
+
+// reconnectOnChange restarts the component, and the components that depend
+// on it, when a reload changes how it connects.
+func reconnectOnChange(old, new map[string]interface{}) {
+	changed := false
+	for _, key := range []string{"uri", "database", "kv_collection"} {
+		if fmt.Sprint(old[key]) != fmt.Sprint(new[key]) {
+			changed = true
+		}
+	}
+	if !changed || !core.IsInitialized("mongodb") {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := core.Restart(ctx, "mongodb"); err != nil {
+			core.GetLogger("mongodb").Error("Reconnecting after config change: %v", err)
+		}
+	}()
+}
+
+func init() {
+	config.Register("mongodb", config.Schema{
+		"uri": config.Field{
+			Default:     "mongodb://localhost:27017",
+			Required:    true,
+			Description: "MongoDB connection string; env:NAME and file:/path references are resolved at load",
+			Secret:      true,
+		},
+		"database": config.Field{
+			Default:     "polkadot",
+			Required:    true,
+			Description: "MongoDB database",
+		},
+		"kv_collection": config.Field{
+			Default:     "kv",
+			Required:    false,
+			Description: "Collection holding the kv store's documents",
+		},
+		"max_pool_size": config.Field{
+			Default:     100,
+			Required:    false,
+			Description: "Maximum connections per server",
+		},
+		"connect_timeout": config.Field{
+			Default:     "10s",
+			Required:    false,
+			Description: "Timeout for establishing a connection",
+		},
+	})
+
+	config.OnReload("mongodb", reconnectOnChange)
+	core.Register(&mongodbComponent{})
+	core.RegisterPreflight("mongodb", "dns", func(ctx context.Context) error {
+		u, err := url.Parse(config.Get().GetString("mongodb", "uri"))
+		if err != nil || u.Scheme != "mongodb" || strings.Contains(u.Host, ",") {
+			// mongodb+srv hosts are SRV names and seed lists are checked by
+			// the driver
+			return nil
+		}
+		return core.CheckDNS(ctx, u.Hostname())
+	})
+}
@@ -0,0 +1,298 @@
+// data/mongodb/mongodb.go
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+	"go.mongodb.org/mongo-driver/v2/mongo/readpref"
+)
+
+// MongoDB is a Store keeping kv pairs as {_id: key, value: value}
+// documents in one collection, and a DocumentStore over the rest of the
+// database.
+type MongoDB struct {
+	client *mongo.Client
+	db     *mongo.Database
+	kv     *mongo.Collection
+	config data.StoreConfig
+	logger core.Logger
+}
+
+var instance *MongoDB
+
+func Get() *MongoDB {
+	return instance
+}
+
+func New(cfg data.StoreConfig) *MongoDB {
+	return &MongoDB{
+		config: cfg,
+		logger: core.GetLogger("mongodb"),
+	}
+}
+
+func (m *MongoDB) Connect(ctx context.Context) error {
+	opts := options.Client().
+		ApplyURI(m.config.GetString("uri")).
+		SetConnectTimeout(m.config.GetDuration("connect_timeout"))
+	if n := m.config.GetInt("max_pool_size"); n > 0 {
+		opts.SetMaxPoolSize(uint64(n))
+	}
+
+	client, err := mongo.Connect(opts)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		client.Disconnect(context.Background())
+		return err
+	}
+
+	m.client = client
+	m.db = client.Database(m.config.GetString("database"))
+	m.kv = m.db.Collection(m.config.GetString("kv_collection"))
+
+	core.IncrCounter("mongodb.connections")
+	m.logger.Info("Connected to MongoDB database %s", m.config.GetString("database"))
+	return nil
+}
+
+func (m *MongoDB) Close() error {
+	if m.client == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return m.client.Disconnect(ctx)
+}
+
+func (m *MongoDB) Get(ctx context.Context, key string) (interface{}, error) {
+	defer core.TrackSpan(ctx, "store", "mongodb")()
+
+	start := time.Now()
+	var doc struct {
+		Value interface{} `bson:"value"`
+	}
+	err := m.kv.FindOne(ctx, bson.M{"_id": key}).Decode(&doc)
+	m.record(m.kv.Name(), "find", start, err)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data.DecryptValue(key, doc.Value)
+}
+
+func (m *MongoDB) Set(ctx context.Context, key string, value interface{}) error {
+	defer core.TrackSpan(ctx, "store", "mongodb")()
+
+	if err := data.CheckWritable(ctx, "mongodb"); err != nil {
+		return err
+	}
+
+	value, err := data.EncodeValue(value)
+	if err != nil {
+		return err
+	}
+	if err := data.ValidateValue(key, value); err != nil {
+		return err
+	}
+	value, err = data.EncryptValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, err = m.kv.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$set": bson.M{"value": value}},
+		options.UpdateOne().SetUpsert(true))
+	m.record(m.kv.Name(), "update", start, err)
+	return err
+}
+
+func (m *MongoDB) Delete(ctx context.Context, key string) error {
+	defer core.TrackSpan(ctx, "store", "mongodb")()
+
+	if err := data.CheckWritable(ctx, "mongodb"); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, err := m.kv.DeleteOne(ctx, bson.M{"_id": key})
+	m.record(m.kv.Name(), "delete", start, err)
+	return err
+}
+
+func (m *MongoDB) Exists(ctx context.Context, key string) (bool, error) {
+	defer core.TrackSpan(ctx, "store", "mongodb")()
+
+	start := time.Now()
+	count, err := m.kv.CountDocuments(ctx, bson.M{"_id": key}, options.Count().SetLimit(1))
+	m.record(m.kv.Name(), "count", start, err)
+	return count > 0, err
+}
+
+func (m *MongoDB) Find(ctx context.Context, collection string, filter data.Document, opts data.FindOptions, results interface{}) error {
+	defer core.TrackSpan(ctx, "store", "mongodb")()
+
+	findOpts := options.Find()
+	if len(opts.Sort) > 0 {
+		findOpts.SetSort(keysDocument(opts.Sort))
+	}
+	if opts.Limit > 0 {
+		findOpts.SetLimit(opts.Limit)
+	}
+	if opts.Skip > 0 {
+		findOpts.SetSkip(opts.Skip)
+	}
+
+	start := time.Now()
+	cursor, err := m.db.Collection(collection).Find(ctx, bsonFilter(filter), findOpts)
+	if err == nil {
+		err = cursor.All(ctx, results)
+	}
+	m.record(collection, "find", start, err)
+	return err
+}
+
+func (m *MongoDB) FindOne(ctx context.Context, collection string, filter data.Document, result interface{}) error {
+	defer core.TrackSpan(ctx, "store", "mongodb")()
+
+	start := time.Now()
+	err := m.db.Collection(collection).FindOne(ctx, bsonFilter(filter)).Decode(result)
+	m.record(collection, "find", start, err)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return data.ErrDocumentNotFound
+	}
+	return err
+}
+
+func (m *MongoDB) Insert(ctx context.Context, collection string, docs ...interface{}) error {
+	defer core.TrackSpan(ctx, "store", "mongodb")()
+
+	if err := data.CheckWritable(ctx, "mongodb"); err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	_, err := m.db.Collection(collection).InsertMany(ctx, docs)
+	m.record(collection, "insert", start, err)
+	return err
+}
+
+func (m *MongoDB) Update(ctx context.Context, collection string, filter, update data.Document) (int64, error) {
+	defer core.TrackSpan(ctx, "store", "mongodb")()
+
+	if err := data.CheckWritable(ctx, "mongodb"); err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	result, err := m.db.Collection(collection).UpdateMany(ctx, bsonFilter(filter), bson.M(update))
+	m.record(collection, "update", start, err)
+	if err != nil {
+		return 0, err
+	}
+	return result.MatchedCount, nil
+}
+
+func (m *MongoDB) DeleteDocuments(ctx context.Context, collection string, filter data.Document) (int64, error) {
+	defer core.TrackSpan(ctx, "store", "mongodb")()
+
+	if err := data.CheckWritable(ctx, "mongodb"); err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	result, err := m.db.Collection(collection).DeleteMany(ctx, bsonFilter(filter))
+	m.record(collection, "delete", start, err)
+	if err != nil {
+		return 0, err
+	}
+	return result.DeletedCount, nil
+}
+
+func (m *MongoDB) EnsureIndexes(ctx context.Context, collection string, indexes ...data.Index) error {
+	if len(indexes) == 0 {
+		return nil
+	}
+
+	models := make([]mongo.IndexModel, 0, len(indexes))
+	for _, index := range indexes {
+		if len(index.Keys) == 0 {
+			return fmt.Errorf("index %q on %s has no keys", index.Name, collection)
+		}
+		opts := options.Index().SetUnique(index.Unique)
+		if index.Name != "" {
+			opts.SetName(index.Name)
+		}
+		if index.ExpireAfter > 0 {
+			opts.SetExpireAfterSeconds(int32(index.ExpireAfter / time.Second))
+		}
+		models = append(models, mongo.IndexModel{Keys: keysDocument(index.Keys), Options: opts})
+	}
+
+	start := time.Now()
+	_, err := m.db.Collection(collection).Indexes().CreateMany(ctx, models)
+	m.record(collection, "create_index", start, err)
+	return err
+}
+
+func (m *MongoDB) record(collection, op string, start time.Time, err error) {
+	labels := map[string]string{"collection": collection, "op": op}
+	core.RecordDurationWithLabels("mongodb.query", labels, start)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		core.IncrCounterWithLabels("mongodb.errors", labels)
+	}
+}
+
+func (m *MongoDB) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := m.client.Ping(ctx, readpref.Primary()); err != nil {
+		if pingErr := m.client.Ping(ctx, readpref.Nearest()); pingErr == nil {
+			// Reads still work, writes do not
+			return core.HealthDegraded, err
+		}
+		return core.HealthUnhealthy, err
+	}
+	return core.HealthHealthy, nil
+}
+
+// keysDocument turns field names, "-" prefixed for descending, into an
+// ordered sort or index specification.
+func keysDocument(fields []string) bson.D {
+	keys := make(bson.D, 0, len(fields))
+	for _, field := range fields {
+		if name, desc := strings.CutPrefix(field, "-"); desc {
+			keys = append(keys, bson.E{Key: name, Value: -1})
+		} else {
+			keys = append(keys, bson.E{Key: field, Value: 1})
+		}
+	}
+	return keys
+}
+
+func bsonFilter(filter data.Document) bson.M {
+	if filter == nil {
+		return bson.M{}
+	}
+	return bson.M(filter)
+}
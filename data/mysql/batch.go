@@ -0,0 +1,207 @@
+// data/mysql/batch.go
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+// multiBatch caps the rows per statement, keeping statements well under
+// max_allowed_packet and the placeholder limit.
+const multiBatch = 500
+
+// SetMulti stores all values in one transaction, writing multiBatch rows
+// per statement. Keys are written in sorted order so concurrent batches
+// lock rows in the same order and do not deadlock each other.
+func (m *MySQL) SetMulti(ctx context.Context, values map[string]interface{}) error {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	stored := make([]interface{}, len(keys))
+	for i, key := range keys {
+		value, err := data.EncodeValue(values[key])
+		if err != nil {
+			return err
+		}
+		if err := data.ValidateValue(key, value); err != nil {
+			return err
+		}
+		value, err = data.EncryptValue(key, value)
+		if err != nil {
+			return err
+		}
+		stored[i] = value
+	}
+
+	update := " ON DUPLICATE KEY UPDATE value = VALUES(value)"
+	if m.softDelete() {
+		update += ", deleted_at = NULL"
+	}
+	if m.expiry() {
+		update += ", expires_at = NULL"
+	}
+
+	start := time.Now()
+	err := m.WithTx(ctx, func(tx *sql.Tx) error {
+		now := time.Now().UTC()
+		for lo := 0; lo < len(keys); lo += multiBatch {
+			hi := min(lo+multiBatch, len(keys))
+
+			args := make([]interface{}, 0, 2*(hi-lo))
+			for i := lo; i < hi; i++ {
+				args = append(args, keys[i], stored[i])
+			}
+			var live map[string]bool
+			if m.journaling() {
+				var err error
+				if live, err = m.liveKeysTx(ctx, tx, keys[lo:hi]); err != nil {
+					return err
+				}
+			}
+
+			query := "INSERT INTO kv (`key`, value) VALUES " + placeholders("(?, ?)", hi-lo) + update
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				return err
+			}
+
+			if !m.journaling() {
+				continue
+			}
+			args = args[:0]
+			for i := lo; i < hi; i++ {
+				op := "set"
+				if !live[keys[i]] {
+					op = "create"
+				}
+				args = append(args, keys[i], op, stored[i], now)
+			}
+			query = "INSERT INTO kv_journal (`key`, op, value, changed_at) VALUES " + placeholders("(?, ?, ?, ?)", hi-lo)
+			if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	m.recordKV("insert_multi", start, err)
+	return err
+}
+
+// liveKeysTx returns which of keys have a visible row.
+func (m *MySQL) liveKeysTx(ctx context.Context, tx *sql.Tx, keys []string) (map[string]bool, error) {
+	cond, condArgs := m.visible()
+	args := make([]interface{}, 0, len(keys)+len(condArgs))
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	rows, err := tx.QueryContext(ctx,
+		"SELECT `key` FROM kv WHERE `key` IN ("+placeholders("?", len(keys))+")"+cond, append(args, condArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	live := make(map[string]bool, len(keys))
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		live[key] = true
+	}
+	return live, rows.Err()
+}
+
+// GetMulti returns the stored values among keys, fetching multiBatch keys
+// per query; missing keys are left out.
+func (m *MySQL) GetMulti(ctx context.Context, keys []string) (map[string]interface{}, error) {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	result := make(map[string]interface{}, len(keys))
+	for lo := 0; lo < len(keys); lo += multiBatch {
+		hi := min(lo+multiBatch, len(keys))
+		if err := m.getBatch(ctx, keys[lo:hi], wanted, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (m *MySQL) getBatch(ctx context.Context, keys []string, wanted map[string]bool, result map[string]interface{}) error {
+	cond, visibleArgs := m.visible()
+	query := "SELECT `key`, value FROM kv WHERE `key` IN (" + placeholders("?", len(keys)) + ")" + cond
+	args := make([]interface{}, 0, len(keys)+len(visibleArgs))
+	for _, key := range keys {
+		args = append(args, key)
+	}
+	args = append(args, visibleArgs...)
+
+	start := time.Now()
+	done, err := m.breaker.Allow()
+	if err != nil {
+		return err
+	}
+	rows, err := m.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		done(err)
+		m.recordKV("select_multi", start, err)
+		return err
+	}
+	defer rows.Close()
+
+	raw := make(map[string]string, len(keys))
+	for rows.Next() {
+		var key, value string
+		if err = rows.Scan(&key, &value); err != nil {
+			break
+		}
+		// IN follows the column's collation, which may ignore case
+		if wanted[key] {
+			raw[key] = value
+		}
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	done(err)
+	m.recordKV("select_multi", start, err)
+	if err != nil {
+		return err
+	}
+
+	for key, value := range raw {
+		decrypted, err := data.DecryptValue(key, value)
+		if err != nil {
+			return err
+		}
+		result[key] = decrypted
+	}
+	return nil
+}
+
+// placeholders repeats group n times, comma separated.
+func placeholders(group string, n int) string {
+	return strings.TrimSuffix(strings.Repeat(group+", ", n), ", ")
+}
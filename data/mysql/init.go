@@ -3,10 +3,17 @@ package mysql
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
 	"time"
 
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/polkadot-go/helper"
 	"github.com/polkadot-go/helper/core"
 	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/core/resilience"
+	"github.com/polkadot-go/helper/data"
 )
 
 type mysqlComponent struct{}
@@ -16,22 +23,80 @@ func (c *mysqlComponent) Name() string {
 }
 
 func (c *mysqlComponent) Dependencies() []string {
-	return []string{"config", "logger"}
+	return []string{"config", "logger", "data"}
 }
 
-func (c *mysqlComponent) Init() error {
+func (c *mysqlComponent) Init(ctx context.Context) error {
 	cfg := config.Get()
 
 	configAdapter := &mysqlConfig{cfg: cfg}
 	instance = New(configAdapter)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	if mode := data.GuardMode(cfg.GetString("mysql", "query_guard")); mode != data.GuardOff {
+		var skip []string
+		raw, _ := cfg.Get("mysql", "query_guard_skip").([]interface{})
+		for _, check := range raw {
+			skip = append(skip, fmt.Sprint(check))
+		}
+		instance.Use(data.QueryGuard("mysql", mode, skip...))
+	}
+	instance.SetReplicas(cfg.GetStringSlice("mysql", "replicas"))
 
-	if err := instance.Connect(ctx); err != nil {
+	// Retry only while MySQL is unreachable, e.g. still starting next to
+	// the helper; errors the server reports, such as bad credentials, fail
+	// at once
+	err := resilience.Retry(ctx, resilience.RetryPolicy{
+		MaxAttempts: cfg.GetInt("mysql", "connect_attempts"),
+		Backoff:     cfg.GetDuration("mysql", "connect_backoff"),
+		Retryable:   isConnectionFailure,
+		OnRetry: func(attempt int, err error, wait time.Duration) {
+			core.GetLogger("mysql").Warn("Connecting to MySQL failed (attempt %d): %v; retrying in %s", attempt, err, wait.Round(time.Millisecond))
+		},
+	}, instance.Connect)
+	if err != nil {
 		return err
 	}
 
+	if failures := cfg.GetInt("mysql", "breaker_failures"); failures > 0 {
+		breaker := resilience.New("mysql", resilience.Options{
+			FailureThreshold: failures,
+			OpenTimeout:      cfg.GetDuration("mysql", "breaker_open_timeout"),
+			IsFailure:        isConnectionFailure,
+		})
+		instance.SetBreaker(breaker)
+		core.RegisterHealthCheck("mysql_breaker", breaker)
+	}
+
+	if cfg.GetBool("mysql", "auto_migrate") {
+		if err := instance.Migrate(ctx, cfg.GetString("mysql", "migrations_dir")); err != nil {
+			return err
+		}
+	}
+
+	if instance.softDelete() || instance.journaling() {
+		if err := instance.EnsureRecoverySchema(ctx); err != nil {
+			return err
+		}
+		if interval := cfg.GetDuration("mysql", "purge_interval"); interval > 0 {
+			instance.startPurge(interval)
+		}
+	}
+
+	if instance.expiry() {
+		if err := instance.EnsureExpirySchema(ctx); err != nil {
+			return err
+		}
+		if interval := cfg.GetDuration("mysql", "reap_interval"); interval > 0 {
+			instance.startReaper(interval)
+		}
+	}
+
+	if replicas := instance.Replicas(); replicas != nil {
+		core.RegisterHealthCheck("mysql_replicas", replicas)
+	} else {
+		// Replicas may have been removed by a reload
+		core.UnregisterHealthCheck("mysql_replicas")
+	}
 	core.RegisterHealthCheck("mysql", instance)
 	return nil
 }
@@ -43,6 +108,13 @@ func (c *mysqlComponent) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+func (c *mysqlComponent) Store() data.Store {
+	if instance == nil {
+		return nil
+	}
+	return instance
+}
+
 type mysqlConfig struct {
 	cfg *config.Config
 }
@@ -63,6 +135,29 @@ func (m *mysqlConfig) GetDuration(key string) time.Duration {
 	return m.cfg.GetDuration("mysql", key)
 }
 
+// reconnectOnChange restarts the component, and the components that depend
+// on it, when a reload changes how it connects, e.g. after credentials are
+// rotated in Vault.
+func reconnectOnChange(old, new map[string]interface{}) {
+	changed := false
+	for _, key := range []string{"host", "port", "user", "password", "database", "replicas"} {
+		if fmt.Sprint(old[key]) != fmt.Sprint(new[key]) {
+			changed = true
+		}
+	}
+	if !changed || !core.IsInitialized("mysql") {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := core.Restart(ctx, "mysql"); err != nil {
+			core.GetLogger("mysql").Error("Reconnecting after config change: %v", err)
+		}
+	}()
+}
+
 func init() {
 	config.Register("mysql", config.Schema{
 		"host": config.Field{
@@ -83,13 +178,34 @@ func init() {
 		"password": config.Field{
 			Default:     "",
 			Required:    true,
-			Description: "MySQL password",
+			Description: "MySQL password; env:NAME and file:/path references are resolved at load",
+			Secret:      true,
 		},
 		"database": config.Field{
 			Default:     "polkadot",
 			Required:    true,
 			Description: "MySQL database",
 		},
+		"replicas": config.Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "Read replicas as host or host:port, using the primary's credentials; Query and QueryRow are spread over the healthy ones",
+		},
+		"replica_check_interval": config.Field{
+			Default:     "5s",
+			Required:    false,
+			Description: "How often replicas are pinged; a replica that fails is skipped until it answers",
+		},
+		"connect_attempts": config.Field{
+			Default:     5,
+			Required:    false,
+			Description: "Attempts to reach MySQL at startup before giving up",
+		},
+		"connect_backoff": config.Field{
+			Default:     "1s",
+			Required:    false,
+			Description: "Initial wait between startup connection attempts, doubled per attempt",
+		},
 		"max_connections": config.Field{
 			Default:     25,
 			Required:    false,
@@ -105,7 +221,145 @@ func init() {
 			Required:    false,
 			Description: "Connection max lifetime",
 		},
+		"slow_query_threshold": config.Field{
+			Default:     "1s",
+			Required:    false,
+			Description: "Log statements slower than this at WARN, redacted, with their caller, and count them in mysql.slow_queries (0 disables)",
+		},
+		"query_guard": config.Field{
+			Default:     "off",
+			Required:    false,
+			Description: "Suspicious query guard mode (off, warn, reject)",
+			Validator:   data.ValidateGuardMode,
+		},
+		"query_guard_skip": config.Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "Query guard checks not to run: literal, stacked, comment, union, tautology (literal and comment also flag legitimate SQL)",
+			Validator:   data.ValidateGuardSkip,
+		},
+		"tx_max_retries": config.Field{
+			Default:     3,
+			Required:    false,
+			Description: "Times WithTx retries a transaction that hit a deadlock or serialization failure",
+		},
+		"tx_retry_backoff": config.Field{
+			Default:     "50ms",
+			Required:    false,
+			Description: "Initial backoff between WithTx retries, doubled per attempt",
+		},
+		"auto_migrate": config.Field{
+			Default:     false,
+			Required:    false,
+			Description: "Apply pending schema migrations, including the kv table, at startup",
+		},
+		"migrations_dir": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Directory of application migrations (<version>_<name>.up.sql / .down.sql) applied with auto_migrate",
+		},
+		"soft_delete": config.Field{
+			Default:     false,
+			Required:    false,
+			Description: "Mark deleted kv rows with deleted_at instead of removing them",
+		},
+		"journal": config.Field{
+			Default:     false,
+			Required:    false,
+			Description: "Record kv mutations in kv_journal for point-in-time restore",
+		},
+		"purge_after": config.Field{
+			Default:     "720h",
+			Required:    false,
+			Description: "Remove soft-deleted kv rows after this long",
+		},
+		"journal_retention": config.Field{
+			Default:     "720h",
+			Required:    false,
+			Description: "Remove kv_journal entries after this long (0 keeps all)",
+		},
+		"breaker_failures": config.Field{
+			Default:     5,
+			Required:    false,
+			Description: "Consecutive connection failures after which queries fail fast until MySQL recovers (0 disables the circuit breaker)",
+		},
+		"breaker_open_timeout": config.Field{
+			Default:     "30s",
+			Required:    false,
+			Description: "How long queries fail fast before a probe query is let through",
+		},
+		"purge_interval": config.Field{
+			Default:     "1h",
+			Required:    false,
+			Description: "How often to purge expired kv data (0 disables)",
+		},
+		"pool_stats_interval": config.Field{
+			Default:     "15s",
+			Required:    false,
+			Description: "How often connection pool statistics are exported as metrics (0 disables)",
+		},
+		"stmt_cache_size": config.Field{
+			Default:     100,
+			Required:    false,
+			Description: "Prepared statements kept for reuse, by query text (0 disables); each is prepared on every connection that runs it",
+		},
+		"ttl": config.Field{
+			Default:     false,
+			Required:    false,
+			Description: "Add the expires_at column so keys can expire, making the store usable as a CacheStore",
+		},
+		"reap_interval": config.Field{
+			Default:     "1m",
+			Required:    false,
+			Description: "How often to delete expired keys (0 disables; expired keys stay invisible)",
+		},
 	})
 
-	core.Register(&mysqlComponent{})
+	core.RegisterErrorClassifier(classifyError)
+}
+
+// Register adds the mysql store component to app.
+func Register(app *helper.App) {
+	config.OnReload("mysql", reconnectOnChange)
+	app.RegisterComponent(&mysqlComponent{})
+	core.RegisterPreflight("mysql", "dns", func(ctx context.Context) error {
+		return core.CheckDNS(ctx, config.Get().GetString("mysql", "host"))
+	})
+}
+
+func classifyError(err error) (string, string, bool) {
+	var myErr *driver.MySQLError
+	if !errors.As(err, &myErr) {
+		return "", "", false
+	}
+	switch myErr.Number {
+	case 1045:
+		return "auth", "Check mysql.user and mysql.password", true
+	case 1049:
+		return "missing_database", "Create the database named in mysql.database", true
+	case 1044:
+		return "permission", "Grant mysql.user access to mysql.database", true
+	}
+	return "", "", false
+}
+
+// isRetryable reports deadlocks and lock wait timeouts, after which MySQL
+// has rolled the transaction back and it is safe to run again.
+func isRetryable(err error) bool {
+	var myErr *driver.MySQLError
+	if !errors.As(err, &myErr) {
+		return false
+	}
+	return myErr.Number == 1213 || myErr.Number == 1205
+}
+
+// isConnectionFailure counts errors that say MySQL is unreachable towards
+// the circuit breaker. Errors reported by the server, such as a duplicate
+// key, mean it is up.
+func isConnectionFailure(err error) bool {
+	if err == nil || errors.Is(err, sql.ErrNoRows) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var myErr *driver.MySQLError
+	return !errors.As(err, &myErr)
 }
@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS kv (
+    `key` VARCHAR(255) NOT NULL PRIMARY KEY,
+    value LONGTEXT NOT NULL
+);
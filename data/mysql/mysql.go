@@ -5,17 +5,31 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/resilience"
 	"github.com/polkadot-go/helper/data"
 )
 
 type MySQL struct {
-	db     *sql.DB
-	config data.StoreConfig
-	logger *core.Logger
+	db           *sql.DB
+	config       data.StoreConfig
+	logger       core.Logger
+	interceptors data.InterceptorChain
+	slow         *data.SlowQueryLog
+	breaker      *resilience.Breaker
+	stmts        *stmtCache
+	purgeStop    chan struct{}
+	purgeWG      sync.WaitGroup
+	reapStop     chan struct{}
+	reapWG       sync.WaitGroup
+	replicaAddrs []string
+	replicas     *data.Replicas
+	replicaStmts map[*sql.DB]*stmtCache
+	poolStats    *data.PoolStats
 }
 
 var instance *MySQL
@@ -28,42 +42,74 @@ func New(cfg data.StoreConfig) *MySQL {
 	return &MySQL{
 		config: cfg,
 		logger: core.GetLogger("mysql"),
+		slow:   data.NewSlowQueryLog("mysql", cfg.GetDuration("slow_query_threshold")),
 	}
 }
 
-func (m *MySQL) Connect(ctx context.Context) error {
+// open returns a pool for the server at host:port, using the configured
+// credentials, database and pool limits.
+func (m *MySQL) open(host string, port int) (*sql.DB, error) {
 	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true&charset=utf8mb4",
 		m.config.GetString("user"),
 		m.config.GetString("password"),
-		m.config.GetString("host"),
-		m.config.GetInt("port"),
+		host,
+		port,
 		m.config.GetString("database"))
 
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(m.config.GetInt("max_connections"))
+	db.SetMaxIdleConns(m.config.GetInt("max_idle_connections"))
+	db.SetConnMaxLifetime(m.config.GetDuration("conn_max_lifetime"))
+	return db, nil
+}
+
+func (m *MySQL) Connect(ctx context.Context) error {
 	var err error
-	m.db, err = sql.Open("mysql", dsn)
+	m.db, err = m.open(m.config.GetString("host"), m.config.GetInt("port"))
 	if err != nil {
 		return err
 	}
 
-	m.db.SetMaxOpenConns(m.config.GetInt("max_connections"))
-	m.db.SetMaxIdleConns(m.config.GetInt("max_idle_connections"))
-	m.db.SetConnMaxLifetime(m.config.GetDuration("conn_max_lifetime"))
-
-	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	err = m.db.PingContext(ctx)
+	err = m.db.PingContext(pingCtx)
 	if err != nil {
 		m.db.Close()
 		return err
 	}
 
+	m.stmts = newStmtCache(m.db, m.config.GetInt("stmt_cache_size"))
+	if err := m.connectReplicas(ctx); err != nil {
+		m.db.Close()
+		return err
+	}
+	m.poolStats = data.StartPoolStats("mysql", m.config.GetDuration("pool_stats_interval"), m.pools)
+
 	core.IncrCounter("mysql.connections")
 	m.logger.Info("Connected to MySQL at %s:%d", m.config.GetString("host"), m.config.GetInt("port"))
 	return nil
 }
 
+// SetBreaker guards queries with b; nil turns the guard off.
+func (m *MySQL) SetBreaker(b *resilience.Breaker) {
+	m.breaker = b
+}
+
+func (m *MySQL) Use(interceptors ...data.QueryInterceptor) {
+	m.interceptors = append(m.interceptors, interceptors...)
+}
+
 func (m *MySQL) Close() error {
+	m.poolStats.Stop()
+	m.stopPurge()
+	m.stopReaper()
+	m.stmts.close()
+	m.closeReplicas()
 	if m.db != nil {
 		return m.db.Close()
 	}
@@ -71,36 +117,143 @@ func (m *MySQL) Close() error {
 }
 
 func (m *MySQL) Get(ctx context.Context, key string) (interface{}, error) {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	start := time.Now()
 	var value string
-	err := m.db.QueryRowContext(ctx, "SELECT value FROM kv WHERE key = ?", key).Scan(&value)
+	cond, args := m.visible()
+	done, err := m.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	err = m.queryRowContext(ctx, "SELECT value FROM kv WHERE `key` = ?"+cond,
+		append([]interface{}{key}, args...)...).Scan(&value)
+	done(err)
+	m.recordKV("select", start, err)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return value, err
+	if err != nil {
+		return nil, err
+	}
+	return data.DecryptValue(key, value)
 }
 
 func (m *MySQL) Set(ctx context.Context, key string, value interface{}) error {
-	_, err := m.db.ExecContext(ctx,
-		"INSERT INTO kv (key, value) VALUES (?, ?) ON DUPLICATE KEY UPDATE value = ?",
-		key, value, value)
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return err
+	}
+
+	value, err := data.EncodeValue(value)
+	if err != nil {
+		return err
+	}
+	if err := data.ValidateValue(key, value); err != nil {
+		return err
+	}
+	value, err = data.EncryptValue(key, value)
+	if err != nil {
+		return err
+	}
+	return m.setStored(ctx, key, value)
+}
+
+// setStored writes value as it is stored: encoded, validated and
+// encrypted already, as when undeleting or restoring it.
+func (m *MySQL) setStored(ctx context.Context, key string, value interface{}) error {
+	start := time.Now()
+	query := "INSERT INTO kv (`key`, value) VALUES (?, ?) ON DUPLICATE KEY UPDATE value = ?"
+	if m.softDelete() {
+		query += ", deleted_at = NULL"
+	}
+	if m.expiry() {
+		query += ", expires_at = NULL"
+	}
+	done, err := m.breaker.Allow()
+	if err != nil {
+		return err
+	}
+	err = m.mutate(ctx, key, "set", value, query, key, value, value)
+	done(err)
+	m.recordKV("insert", start, err)
 	return err
 }
 
 func (m *MySQL) Delete(ctx context.Context, key string) error {
-	_, err := m.db.ExecContext(ctx, "DELETE FROM kv WHERE key = ?", key)
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	done, err := m.breaker.Allow()
+	if err != nil {
+		return err
+	}
+	if m.softDelete() {
+		err = m.mutate(ctx, key, "delete", nil, "UPDATE kv SET deleted_at = ? WHERE `key` = ? AND deleted_at IS NULL", time.Now().UTC(), key)
+	} else {
+		err = m.mutate(ctx, key, "delete", nil, "DELETE FROM kv WHERE `key` = ?", key)
+	}
+	done(err)
+	m.recordKV("delete", start, err)
 	return err
 }
 
 func (m *MySQL) Exists(ctx context.Context, key string) (bool, error) {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	start := time.Now()
 	var count int
-	err := m.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM kv WHERE key = ?", key).Scan(&count)
+	cond, args := m.visible()
+	done, err := m.breaker.Allow()
+	if err != nil {
+		return false, err
+	}
+	err = m.queryRowContext(ctx, "SELECT COUNT(*) FROM kv WHERE `key` = ?"+cond,
+		append([]interface{}{key}, args...)...).Scan(&count)
+	done(err)
+	m.recordKV("select", start, err)
 	return count > 0, err
 }
 
+func (m *MySQL) recordKV(op string, start time.Time, err error) {
+	labels := map[string]string{"table": "kv", "op": op}
+	core.RecordDurationWithLabels("mysql.query", labels, start)
+	m.slow.Observe(context.Background(), "kv "+op, start)
+	if err != nil && err != sql.ErrNoRows {
+		core.IncrCounterWithLabels("mysql.errors", labels)
+	}
+}
+
 func (m *MySQL) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	if err := m.interceptors.Run(ctx, query, args); err != nil {
+		core.IncrCounter("mysql.errors")
+		return nil, err
+	}
+
+	if replica, stmts := m.replica(ctx); replica != nil {
+		start := time.Now()
+		rows, err := queryOn(ctx, replica.DB, stmts, query, args...)
+		m.recordReplica(replica, start, err)
+		m.slow.Observe(ctx, query, start)
+		return rows, err
+	}
+
+	done, err := m.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
 	start := time.Now()
-	rows, err := m.db.QueryContext(ctx, query, args...)
+	rows, err := m.queryContext(ctx, query, args...)
+	done(err)
 	core.RecordDuration("mysql.query", start)
+	m.slow.Observe(ctx, query, start)
 	if err != nil {
 		core.IncrCounter("mysql.errors")
 	}
@@ -108,16 +261,49 @@ func (m *MySQL) Query(ctx context.Context, query string, args ...interface{}) (*
 }
 
 func (m *MySQL) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	if err := m.interceptors.Run(ctx, query, args); err != nil {
+		core.IncrCounter("mysql.errors")
+		return data.ErrRow(err)
+	}
+
+	if replica, stmts := m.replica(ctx); replica != nil {
+		start := time.Now()
+		row := queryRowOn(ctx, replica.DB, stmts, query, args...)
+		m.recordReplica(replica, start, row.Err())
+		m.slow.Observe(ctx, query, start)
+		return row
+	}
+
 	start := time.Now()
-	row := m.db.QueryRowContext(ctx, query, args...)
+	row := m.queryRowContext(ctx, query, args...)
 	core.RecordDuration("mysql.query", start)
+	m.slow.Observe(ctx, query, start)
 	return row
 }
 
 func (m *MySQL) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return nil, err
+	}
+
+	if err := m.interceptors.Run(ctx, query, args); err != nil {
+		core.IncrCounter("mysql.errors")
+		return nil, err
+	}
+
+	done, err := m.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
 	start := time.Now()
-	result, err := m.db.ExecContext(ctx, query, args...)
+	result, err := m.execContext(ctx, query, args...)
+	done(err)
 	core.RecordDuration("mysql.exec", start)
+	m.slow.Observe(ctx, query, start)
 	if err != nil {
 		core.IncrCounter("mysql.errors")
 	}
@@ -125,7 +311,38 @@ func (m *MySQL) Exec(ctx context.Context, query string, args ...interface{}) (sq
 }
 
 func (m *MySQL) Begin(ctx context.Context) (*sql.Tx, error) {
-	return m.db.BeginTx(ctx, nil)
+	// A switched-off store still serves reads, so only the transaction is
+	// made read-only
+	readOnly := data.IsReadOnly(ctx) || data.StoreReadOnly("mysql")
+	return m.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: readOnly})
+}
+
+func (m *MySQL) guardedBegin(ctx context.Context) (*sql.Tx, error) {
+	done, err := m.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	tx, err := m.Begin(ctx)
+	done(err)
+	return tx, err
+}
+
+// WithTx runs fn in a transaction, committing on success and rolling back
+// on error or panic. Deadlocks and lock timeouts are retried up to
+// tx_max_retries times. Only starting the transaction counts towards the
+// circuit breaker, so errors returned by fn do not open it.
+func (m *MySQL) WithTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return err
+	}
+	return data.RunTx(ctx, m.guardedBegin, data.TxPolicy{
+		Store:      "mysql",
+		MaxRetries: m.config.GetInt("tx_max_retries"),
+		Backoff:    m.config.GetDuration("tx_retry_backoff"),
+		Retryable:  isRetryable,
+	}, fn)
 }
 
 func (m *MySQL) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
@@ -0,0 +1,263 @@
+// data/mysql/recovery.go
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+func (m *MySQL) softDelete() bool {
+	return m.config.GetBool("soft_delete")
+}
+
+func (m *MySQL) journaling() bool {
+	return m.config.GetBool("journal")
+}
+
+// EnsureRecoverySchema adds the deleted_at column and the kv_journal
+// table when soft delete or journaling is enabled.
+func (m *MySQL) EnsureRecoverySchema(ctx context.Context) error {
+	if m.softDelete() {
+		var count int
+		err := m.db.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'kv' AND COLUMN_NAME = 'deleted_at'").Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			if _, err := m.db.ExecContext(ctx, "ALTER TABLE kv ADD COLUMN deleted_at DATETIME(6) NULL, ADD INDEX idx_kv_deleted_at (deleted_at)"); err != nil {
+				return err
+			}
+		}
+	}
+
+	if m.journaling() {
+		_, err := m.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS kv_journal (
+			id BIGINT AUTO_INCREMENT PRIMARY KEY,
+			`+"`key`"+` VARCHAR(255) NOT NULL,
+			op VARCHAR(16) NOT NULL,
+			value LONGTEXT NULL,
+			changed_at DATETIME(6) NOT NULL,
+			INDEX idx_kv_journal_key (`+"`key`"+`, changed_at),
+			INDEX idx_kv_journal_time (changed_at)
+		)`)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mutate runs a kv write and, when journaling, records it in kv_journal
+// in the same transaction. A set of a key with no visible row is recorded
+// as a create, so RestoreKey can tell keys that did not exist yet.
+func (m *MySQL) mutate(ctx context.Context, key, op string, value interface{}, query string, args ...interface{}) error {
+	if !m.journaling() {
+		_, err := m.execContext(ctx, query, args...)
+		return err
+	}
+
+	return m.WithTx(ctx, func(tx *sql.Tx) error {
+		journalOp := op
+		if op == "set" {
+			live, err := m.liveTx(ctx, tx, key)
+			if err != nil {
+				return err
+			}
+			if !live {
+				journalOp = "create"
+			}
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		return m.journalTx(ctx, tx, key, journalOp, value)
+	})
+}
+
+// liveTx reports whether key has a visible row.
+func (m *MySQL) liveTx(ctx context.Context, tx *sql.Tx, key string) (bool, error) {
+	cond, args := m.visible()
+	var count int
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM kv WHERE `key` = ?"+cond,
+		append([]interface{}{key}, args...)...).Scan(&count)
+	return count > 0, err
+}
+
+// journalTx records a kv write made in tx, when journaling.
+func (m *MySQL) journalTx(ctx context.Context, tx *sql.Tx, key, op string, value interface{}) error {
+	if !m.journaling() {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO kv_journal (`key`, op, value, changed_at) VALUES (?, ?, ?, ?)",
+		key, op, value, time.Now().UTC())
+	return err
+}
+
+func (m *MySQL) Undelete(ctx context.Context, key string) error {
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return err
+	}
+
+	var value string
+	err := m.db.QueryRowContext(ctx, "SELECT value FROM kv WHERE `key` = ? AND deleted_at IS NOT NULL", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return m.setStored(ctx, key, value)
+}
+
+func (m *MySQL) RestoreKey(ctx context.Context, key string, at time.Time) error {
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return err
+	}
+
+	var op string
+	var value sql.NullString
+	err := m.db.QueryRowContext(ctx,
+		"SELECT op, value FROM kv_journal WHERE `key` = ? AND changed_at <= ? ORDER BY changed_at DESC, id DESC LIMIT 1",
+		key, at.UTC()).Scan(&op, &value)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err == sql.ErrNoRows {
+		return m.restoreUnrecorded(ctx, key, at)
+	}
+
+	core.IncrCounter("mysql.restores")
+	if op == "delete" {
+		return m.Delete(ctx, key)
+	}
+	return m.setStored(ctx, key, value.String)
+}
+
+// restoreUnrecorded handles a key with no journal entry at or before at.
+// It is deleted only if its first entry shows it was created after at;
+// otherwise its earlier history may have been purged or predate the
+// journal, and it is left alone.
+func (m *MySQL) restoreUnrecorded(ctx context.Context, key string, at time.Time) error {
+	var first string
+	err := m.db.QueryRowContext(ctx,
+		"SELECT op FROM kv_journal WHERE `key` = ? ORDER BY changed_at, id LIMIT 1", key).Scan(&first)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if first != "create" {
+		core.IncrCounter("mysql.restores_skipped")
+		m.logger.Warn("Not restoring %s: the journal has no record of it at %s", key, at.Format(time.RFC3339))
+		return nil
+	}
+	core.IncrCounter("mysql.restores")
+	return m.Delete(ctx, key)
+}
+
+func (m *MySQL) RestoreAt(ctx context.Context, at time.Time) (int, error) {
+	rows, err := m.db.QueryContext(ctx, "SELECT DISTINCT `key` FROM kv_journal WHERE changed_at > ?", at.UTC())
+	if err != nil {
+		return 0, err
+	}
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for i, key := range keys {
+		if err := m.RestoreKey(ctx, key, at); err != nil {
+			return i, err
+		}
+	}
+	m.logger.Info("Restored %d keys to %s", len(keys), at.Format(time.RFC3339))
+	return len(keys), nil
+}
+
+func (m *MySQL) Purge(ctx context.Context) (int64, error) {
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	now := time.Now().UTC()
+
+	if m.softDelete() {
+		result, err := m.db.ExecContext(ctx, "DELETE FROM kv WHERE deleted_at IS NOT NULL AND deleted_at < ?",
+			now.Add(-m.config.GetDuration("purge_after")))
+		if err != nil {
+			return purged, err
+		}
+		n, _ := result.RowsAffected()
+		purged += n
+	}
+
+	if retention := m.config.GetDuration("journal_retention"); m.journaling() && retention > 0 {
+		result, err := m.db.ExecContext(ctx, "DELETE FROM kv_journal WHERE changed_at < ?", now.Add(-retention))
+		if err != nil {
+			return purged, err
+		}
+		n, _ := result.RowsAffected()
+		purged += n
+	}
+
+	return purged, nil
+}
+
+// startPurge runs Purge every interval until the store is closed.
+func (m *MySQL) startPurge(interval time.Duration) {
+	m.purgeStop = make(chan struct{})
+	m.purgeWG.Add(1)
+	go func() {
+		defer m.purgeWG.Done()
+		core.Supervise("mysql_purge", m.purgeStop, func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					// Skipped until writes are switched back on
+					if data.StoreReadOnly("mysql") {
+						continue
+					}
+					ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+					n, err := m.Purge(ctx)
+					cancel()
+					if err != nil {
+						m.logger.Error("Purging kv data: %v", err)
+					} else if n > 0 {
+						m.logger.Info("Purged %d expired kv rows", n)
+					}
+				case <-m.purgeStop:
+					return
+				}
+			}
+		})
+	}()
+}
+
+func (m *MySQL) stopPurge() {
+	if m.purgeStop != nil {
+		close(m.purgeStop)
+		m.purgeWG.Wait()
+		m.purgeStop = nil
+	}
+}
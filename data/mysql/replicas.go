@@ -0,0 +1,101 @@
+// data/mysql/replicas.go
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+// SetReplicas makes Connect open read replicas at addrs, each host or
+// host:port, with the primary's credentials and database. Query and
+// QueryRow then read from a healthy replica unless the context asks for
+// Strong consistency; kv reads, Exec and transactions stay on the primary.
+func (m *MySQL) SetReplicas(addrs []string) {
+	m.replicaAddrs = addrs
+}
+
+// Replicas returns the read replicas, or nil when none are configured.
+func (m *MySQL) Replicas() *data.Replicas {
+	return m.replicas
+}
+
+func (m *MySQL) connectReplicas(ctx context.Context) error {
+	if len(m.replicaAddrs) == 0 {
+		return nil
+	}
+
+	m.replicaStmts = make(map[*sql.DB]*stmtCache, len(m.replicaAddrs))
+	members := make([]*data.Replica, 0, len(m.replicaAddrs))
+	for _, addr := range m.replicaAddrs {
+		host, port := splitAddr(addr, m.config.GetInt("port"))
+		db, err := m.open(host, port)
+		if err != nil {
+			for _, opened := range members {
+				opened.DB.Close()
+			}
+			return err
+		}
+		members = append(members, &data.Replica{Addr: addr, DB: db})
+		m.replicaStmts[db] = newStmtCache(db, m.config.GetInt("stmt_cache_size"))
+	}
+
+	m.replicas = data.NewReplicas("mysql", members)
+	// A replica that is down at startup is skipped until it answers
+	m.replicas.Check(ctx)
+	m.replicas.Start(m.config.GetDuration("replica_check_interval"))
+	m.logger.Info("Reading from %d MySQL replicas", len(members))
+	return nil
+}
+
+func (m *MySQL) closeReplicas() {
+	for _, stmts := range m.replicaStmts {
+		stmts.close()
+	}
+	m.replicas.Close()
+}
+
+// pools returns the primary's and replicas' pools for PoolStats.
+func (m *MySQL) pools() map[string]*sql.DB {
+	pools := map[string]*sql.DB{"primary": m.db}
+	for _, r := range m.replicas.Members() {
+		pools[r.Addr] = r.DB
+	}
+	return pools
+}
+
+// replica returns the replica a read with ctx should go to and its
+// statement cache, or nil to read from the primary.
+func (m *MySQL) replica(ctx context.Context) (*data.Replica, *stmtCache) {
+	r := m.replicas.Pick(ctx)
+	if r == nil {
+		return nil, nil
+	}
+	return r, m.replicaStmts[r.DB]
+}
+
+func (m *MySQL) recordReplica(r *data.Replica, start time.Time, err error) {
+	labels := map[string]string{"replica": r.Addr}
+	core.RecordDurationWithLabels("mysql.replica_query", labels, start)
+	if err != nil && err != sql.ErrNoRows {
+		core.IncrCounterWithLabels("mysql.replica_errors", labels)
+	}
+}
+
+// splitAddr splits host:port, using defaultPort when addr has no port.
+func splitAddr(addr string, defaultPort int) (string, int) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, defaultPort
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return host, defaultPort
+	}
+	return host, port
+}
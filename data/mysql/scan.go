@@ -0,0 +1,89 @@
+// data/mysql/scan.go
+package mysql
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// scanBatch is how many keys Scan reads per query.
+const scanBatch = 500
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (m *MySQL) Keys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := m.Scan(ctx, prefix, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+	return keys, err
+}
+
+// Scan pages through the matching keys scanBatch at a time, resuming after
+// the last key seen, so keys written during the scan may or may not be
+// visited.
+func (m *MySQL) Scan(ctx context.Context, prefix string, fn func(key string) error) error {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	pattern := likeEscaper.Replace(prefix) + "%"
+
+	after := ""
+	for {
+		keys, err := m.scanPage(ctx, pattern, after)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			// LIKE follows the column's collation, which may ignore case
+			if !strings.HasPrefix(key, prefix) {
+				continue
+			}
+			if err := fn(key); err != nil {
+				return err
+			}
+		}
+		if len(keys) < scanBatch {
+			return nil
+		}
+		after = keys[len(keys)-1]
+	}
+}
+
+func (m *MySQL) scanPage(ctx context.Context, pattern, after string) ([]string, error) {
+	cond, args := m.visible()
+	query := "SELECT `key` FROM kv WHERE `key` LIKE ? AND `key` > ?" + cond + " ORDER BY `key` LIMIT ?"
+	args = append([]interface{}{pattern, after}, args...)
+	args = append(args, scanBatch)
+
+	start := time.Now()
+	done, err := m.breaker.Allow()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := m.queryContext(ctx, query, args...)
+	if err != nil {
+		done(err)
+		m.recordKV("scan", start, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := make([]string, 0, scanBatch)
+	for rows.Next() {
+		var key string
+		if err = rows.Scan(&key); err != nil {
+			break
+		}
+		keys = append(keys, key)
+	}
+	if err == nil {
+		err = rows.Err()
+	}
+	done(err)
+	m.recordKV("scan", start, err)
+	return keys, err
+}
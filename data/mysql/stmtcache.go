@@ -0,0 +1,162 @@
+// data/mysql/stmtcache.go
+package mysql
+
+import (
+	"container/list"
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// stmtCache keeps up to capacity prepared statements, keyed by query text,
+// and closes the least recently used one when full. Statements are
+// reference counted, so one evicted while a query runs on it is closed
+// once that query is done.
+type stmtCache struct {
+	db       *sql.DB
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type cachedStmt struct {
+	query   string
+	stmt    *sql.Stmt
+	refs    int
+	evicted bool
+}
+
+func newStmtCache(db *sql.DB, capacity int) *stmtCache {
+	if capacity <= 0 {
+		return nil
+	}
+	return &stmtCache{
+		db:       db,
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// acquire returns the prepared statement for query and a func to call when
+// done with it, or false when the cache is off or query cannot be
+// prepared; callers then run query unprepared.
+func (c *stmtCache) acquire(ctx context.Context, query string) (*sql.Stmt, func(), bool) {
+	if c == nil {
+		return nil, nil, false
+	}
+
+	c.mu.Lock()
+	if el, ok := c.items[query]; ok {
+		c.order.MoveToFront(el)
+		e := el.Value.(*cachedStmt)
+		e.refs++
+		c.mu.Unlock()
+		core.IncrCounterWithLabels("mysql.stmt_cache", map[string]string{"result": "hit"})
+		return e.stmt, c.releaser(e), true
+	}
+	c.mu.Unlock()
+
+	core.IncrCounterWithLabels("mysql.stmt_cache", map[string]string{"result": "miss"})
+	stmt, err := c.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[query]; ok {
+		// Prepared concurrently by another caller
+		stmt.Close()
+		c.order.MoveToFront(el)
+		e := el.Value.(*cachedStmt)
+		e.refs++
+		return e.stmt, c.releaser(e), true
+	}
+
+	e := &cachedStmt{query: query, stmt: stmt, refs: 1}
+	c.items[query] = c.order.PushFront(e)
+	for c.order.Len() > c.capacity {
+		c.evict(c.order.Back())
+	}
+	core.SetGauge("mysql.stmt_cache_size", int64(c.order.Len()))
+	return stmt, c.releaser(e), true
+}
+
+func (c *stmtCache) releaser(e *cachedStmt) func() {
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		e.refs--
+		if e.evicted && e.refs == 0 {
+			e.stmt.Close()
+		}
+	}
+}
+
+// evict must be called with c.mu held.
+func (c *stmtCache) evict(el *list.Element) {
+	e := el.Value.(*cachedStmt)
+	c.order.Remove(el)
+	delete(c.items, e.query)
+	e.evicted = true
+	if e.refs == 0 {
+		e.stmt.Close()
+	}
+	core.IncrCounter("mysql.stmt_cache_evictions")
+}
+
+// close closes every statement; ones still in use close when released.
+func (c *stmtCache) close() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.order.Len() > 0 {
+		c.evict(c.order.Back())
+	}
+	core.SetGauge("mysql.stmt_cache_size", 0)
+}
+
+// The helpers below run a query on db through its statement cache, if it
+// has one. Rows and rows behind a *sql.Row keep their statement open, so
+// the statement is released as soon as the call returns.
+
+func queryOn(ctx context.Context, db *sql.DB, stmts *stmtCache, query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, release, ok := stmts.acquire(ctx, query)
+	if !ok {
+		return db.QueryContext(ctx, query, args...)
+	}
+	defer release()
+	return stmt.QueryContext(ctx, args...)
+}
+
+func queryRowOn(ctx context.Context, db *sql.DB, stmts *stmtCache, query string, args ...interface{}) *sql.Row {
+	stmt, release, ok := stmts.acquire(ctx, query)
+	if !ok {
+		return db.QueryRowContext(ctx, query, args...)
+	}
+	defer release()
+	return stmt.QueryRowContext(ctx, args...)
+}
+
+func (m *MySQL) queryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return queryOn(ctx, m.db, m.stmts, query, args...)
+}
+
+func (m *MySQL) queryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return queryRowOn(ctx, m.db, m.stmts, query, args...)
+}
+
+func (m *MySQL) execContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, release, ok := m.stmts.acquire(ctx, query)
+	if !ok {
+		return m.db.ExecContext(ctx, query, args...)
+	}
+	defer release()
+	return stmt.ExecContext(ctx, args...)
+}
@@ -0,0 +1,346 @@
+// data/mysql/ttl.go
+package mysql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+// reapBatch caps the rows one reaper statement deletes, so reaping a
+// backlog does not hold locks on the whole table.
+const reapBatch = 1000
+
+var ErrTTLDisabled = errors.New("mysql: expiring keys need mysql.ttl enabled")
+
+func (m *MySQL) expiry() bool {
+	return m.config.GetBool("ttl")
+}
+
+// visible returns the condition, and its arguments, that hides
+// soft-deleted and expired rows from reads.
+func (m *MySQL) visible() (string, []interface{}) {
+	var cond string
+	var args []interface{}
+	if m.softDelete() {
+		cond += " AND deleted_at IS NULL"
+	}
+	if m.expiry() {
+		cond += " AND (expires_at IS NULL OR expires_at > ?)"
+		args = append(args, time.Now().UTC())
+	}
+	return cond, args
+}
+
+// EnsureExpirySchema adds the expires_at column when ttl is enabled.
+func (m *MySQL) EnsureExpirySchema(ctx context.Context) error {
+	var count int
+	err := m.db.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'kv' AND COLUMN_NAME = 'expires_at'").Scan(&count)
+	if err != nil || count > 0 {
+		return err
+	}
+	_, err = m.db.ExecContext(ctx, "ALTER TABLE kv ADD COLUMN expires_at DATETIME(6) NULL, ADD INDEX idx_kv_expires_at (expires_at)")
+	return err
+}
+
+// SetWithTTL stores value until ttl passes; a ttl of zero or less never
+// expires. Expired keys are invisible at once and removed by the reaper.
+func (m *MySQL) SetWithTTL(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if ttl <= 0 {
+		return m.Set(ctx, key, value)
+	}
+	if !m.expiry() {
+		return ErrTTLDisabled
+	}
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return err
+	}
+
+	value, err := data.EncodeValue(value)
+	if err != nil {
+		return err
+	}
+	if err := data.ValidateValue(key, value); err != nil {
+		return err
+	}
+	value, err = data.EncryptValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	expires := start.Add(ttl).UTC()
+	query := "INSERT INTO kv (`key`, value, expires_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE value = VALUES(value), expires_at = VALUES(expires_at)"
+	if m.softDelete() {
+		query += ", deleted_at = NULL"
+	}
+	done, err := m.breaker.Allow()
+	if err != nil {
+		return err
+	}
+	err = m.mutate(ctx, key, "set", value, query, key, value, expires)
+	done(err)
+	m.recordKV("insert", start, err)
+	return err
+}
+
+// GetTTL returns the time left before key expires, NoTTL if it never
+// does, or ErrKeyNotFound.
+func (m *MySQL) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	column := "NULL"
+	if m.expiry() {
+		column = "expires_at"
+	}
+	cond, args := m.visible()
+
+	start := time.Now()
+	var expires sql.NullTime
+	done, err := m.breaker.Allow()
+	if err != nil {
+		return 0, err
+	}
+	err = m.db.QueryRowContext(ctx, "SELECT "+column+" FROM kv WHERE `key` = ?"+cond,
+		append([]interface{}{key}, args...)...).Scan(&expires)
+	done(err)
+	m.recordKV("select", start, err)
+	if err == sql.ErrNoRows {
+		return 0, data.ErrKeyNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+	if !expires.Valid {
+		return data.NoTTL, nil
+	}
+	return max(time.Until(expires.Time), 0), nil
+}
+
+// Increment adds delta to an integer value, treating a missing key as 0.
+// The key keeps its TTL.
+func (m *MySQL) Increment(ctx context.Context, key string, delta int64) (int64, error) {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return 0, err
+	}
+
+	cond, args := m.visible()
+	update := " ON DUPLICATE KEY UPDATE value = VALUES(value)"
+	if m.softDelete() {
+		update += ", deleted_at = NULL"
+	}
+	if m.expiry() {
+		update += ", expires_at = NULL"
+	}
+
+	start := time.Now()
+	var next int64
+	err := m.WithTx(ctx, func(tx *sql.Tx) error {
+		var stored string
+		err := tx.QueryRowContext(ctx, "SELECT value FROM kv WHERE `key` = ?"+cond+" FOR UPDATE",
+			append([]interface{}{key}, args...)...).Scan(&stored)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		found := err == nil
+
+		var current int64
+		if found {
+			value, err := data.DecryptValue(key, stored)
+			if err != nil {
+				return err
+			}
+			current, err = strconv.ParseInt(fmt.Sprint(value), 10, 64)
+			if err != nil {
+				return fmt.Errorf("increment %s: value is not an integer", key)
+			}
+		}
+
+		next = current + delta
+		if err := data.ValidateValue(key, next); err != nil {
+			return err
+		}
+		value, err := data.EncryptValue(key, next)
+		if err != nil {
+			return err
+		}
+
+		if found {
+			_, err = tx.ExecContext(ctx, "UPDATE kv SET value = ? WHERE `key` = ?", value, key)
+		} else {
+			// A soft-deleted or expired row may still be there
+			_, err = tx.ExecContext(ctx, "INSERT INTO kv (`key`, value) VALUES (?, ?)"+update, key, value)
+		}
+		if err != nil {
+			return err
+		}
+		op := "set"
+		if !found {
+			op = "create"
+		}
+		return m.journalTx(ctx, tx, key, op, value)
+	})
+	m.recordKV("increment", start, err)
+	if err != nil {
+		return 0, err
+	}
+	return next, nil
+}
+
+func (m *MySQL) Decrement(ctx context.Context, key string, delta int64) (int64, error) {
+	return m.Increment(ctx, key, -delta)
+}
+
+func (m *MySQL) DeleteByPrefix(ctx context.Context, prefix string) (int64, error) {
+	return m.deleteMatching(ctx, prefix, nil)
+}
+
+// DeleteByPattern deletes keys matching a Redis-style glob. Only the
+// pattern's literal prefix narrows the query, so a pattern starting with
+// a wildcard scans the whole table.
+func (m *MySQL) DeleteByPattern(ctx context.Context, pattern string) (int64, error) {
+	re, err := data.CompilePattern(pattern)
+	if err != nil {
+		return 0, err
+	}
+	prefix := pattern
+	if i := strings.IndexAny(pattern, `*?[\`); i >= 0 {
+		prefix = pattern[:i]
+	}
+	return m.deleteMatching(ctx, prefix, re.MatchString)
+}
+
+// deleteMatching deletes, in one transaction, the visible keys that start
+// with prefix and that match, if given, accepts. It returns how many keys
+// it deleted.
+func (m *MySQL) deleteMatching(ctx context.Context, prefix string, match func(string) bool) (int64, error) {
+	defer core.TrackSpan(ctx, "store", "mysql")()
+
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return 0, err
+	}
+
+	var keys []interface{}
+	err := m.Scan(ctx, prefix, func(key string) error {
+		if match == nil || match(key) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil || len(keys) == 0 {
+		return 0, err
+	}
+
+	start := time.Now()
+	var deleted int64
+	err = m.WithTx(ctx, func(tx *sql.Tx) error {
+		deleted = 0
+		for lo := 0; lo < len(keys); lo += multiBatch {
+			batch := keys[lo:min(lo+multiBatch, len(keys))]
+
+			var result sql.Result
+			var err error
+			if m.softDelete() {
+				args := append([]interface{}{time.Now().UTC()}, batch...)
+				result, err = tx.ExecContext(ctx,
+					"UPDATE kv SET deleted_at = ? WHERE deleted_at IS NULL AND `key` IN ("+placeholders("?", len(batch))+")", args...)
+			} else {
+				result, err = tx.ExecContext(ctx,
+					"DELETE FROM kv WHERE `key` IN ("+placeholders("?", len(batch))+")", batch...)
+			}
+			if err != nil {
+				return err
+			}
+			n, _ := result.RowsAffected()
+			deleted += n
+
+			for _, key := range batch {
+				if err := m.journalTx(ctx, tx, key.(string), "delete", nil); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	m.recordKV("delete_multi", start, err)
+	return deleted, err
+}
+
+// ReapExpired removes expired rows, reapBatch at a time, and returns how
+// many it removed. Expiry is not journaled: an expired key cannot be
+// restored.
+func (m *MySQL) ReapExpired(ctx context.Context) (int64, error) {
+	if err := data.CheckWritable(ctx, "mysql"); err != nil {
+		return 0, err
+	}
+
+	var reaped int64
+	for {
+		start := time.Now()
+		result, err := m.db.ExecContext(ctx,
+			"DELETE FROM kv WHERE expires_at IS NOT NULL AND expires_at <= ? LIMIT ?", time.Now().UTC(), reapBatch)
+		m.recordKV("reap", start, err)
+		if err != nil {
+			return reaped, err
+		}
+		n, _ := result.RowsAffected()
+		reaped += n
+		if n < reapBatch {
+			core.AddCounter("mysql.expired_keys", reaped)
+			return reaped, nil
+		}
+	}
+}
+
+// startReaper runs ReapExpired every interval until the store is closed.
+func (m *MySQL) startReaper(interval time.Duration) {
+	m.reapStop = make(chan struct{})
+	m.reapWG.Add(1)
+	go func() {
+		defer m.reapWG.Done()
+		core.Supervise("mysql_reaper", m.reapStop, func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					// Skipped until writes are switched back on
+					if data.StoreReadOnly("mysql") {
+						continue
+					}
+					ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+					n, err := m.ReapExpired(ctx)
+					cancel()
+					if err != nil {
+						m.logger.Error("Reaping expired keys: %v", err)
+					} else if n > 0 {
+						m.logger.Debug("Reaped %d expired keys", n)
+					}
+				case <-m.reapStop:
+					return
+				}
+			}
+		})
+	}()
+}
+
+func (m *MySQL) stopReaper() {
+	if m.reapStop != nil {
+		close(m.reapStop)
+		m.reapWG.Wait()
+		m.reapStop = nil
+	}
+}
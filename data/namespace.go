@@ -0,0 +1,75 @@
+// data/namespace.go
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrScanUnsupported is returned by Namespace.Keys and Namespace.Scan when
+// the underlying store does not implement KVStore.
+var ErrScanUnsupported = errors.New("store cannot list keys")
+
+// Namespace is a view of a shared Store in which every key is prefixed,
+// so components sharing one kv table or bucket cannot collide. Keys and
+// Scan see only the namespace's keys, with the prefix removed.
+type Namespace struct {
+	store  Store
+	prefix string
+}
+
+// Namespaced returns a view of store whose keys are prefixed with prefix,
+// separator included, e.g. Namespaced(store, "payouts:"). Namespaces nest.
+// The view does not own store: Connect and Close do nothing.
+func Namespaced(store Store, prefix string) *Namespace {
+	return &Namespace{store: store, prefix: prefix}
+}
+
+func (n *Namespace) Prefix() string {
+	return n.prefix
+}
+
+func (n *Namespace) Connect(ctx context.Context) error {
+	return nil
+}
+
+func (n *Namespace) Close() error {
+	return nil
+}
+
+func (n *Namespace) Get(ctx context.Context, key string) (interface{}, error) {
+	return n.store.Get(ctx, n.prefix+key)
+}
+
+func (n *Namespace) Set(ctx context.Context, key string, value interface{}) error {
+	return n.store.Set(ctx, n.prefix+key, value)
+}
+
+func (n *Namespace) Delete(ctx context.Context, key string) error {
+	return n.store.Delete(ctx, n.prefix+key)
+}
+
+func (n *Namespace) Exists(ctx context.Context, key string) (bool, error) {
+	return n.store.Exists(ctx, n.prefix+key)
+}
+
+func (n *Namespace) Keys(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := n.Scan(ctx, prefix, func(key string) error {
+		keys = append(keys, key)
+		return nil
+	})
+	return keys, err
+}
+
+func (n *Namespace) Scan(ctx context.Context, prefix string, fn func(key string) error) error {
+	kv, ok := n.store.(KVStore)
+	if !ok {
+		return fmt.Errorf("namespace %s: %w", n.prefix, ErrScanUnsupported)
+	}
+	return kv.Scan(ctx, n.prefix+prefix, func(key string) error {
+		return fn(strings.TrimPrefix(key, n.prefix))
+	})
+}
@@ -0,0 +1,97 @@
+// data/poolstats.go
+package data
+
+import (
+	"database/sql"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// PoolStats samples the connection pools of a SQL store and exports their
+// sql.DBStats as <store>.pool_* metrics, labelled by pool: "primary" or a
+// replica's address.
+type PoolStats struct {
+	store string
+	pools func() map[string]*sql.DB
+
+	mu   sync.Mutex
+	last map[string]sql.DBStats
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// StartPoolStats samples the pools returned by pools every interval until
+// Stop. An interval of zero or less returns nil, which is safe to Stop.
+func StartPoolStats(store string, interval time.Duration, pools func() map[string]*sql.DB) *PoolStats {
+	if interval <= 0 {
+		return nil
+	}
+	p := &PoolStats{
+		store: store,
+		pools: pools,
+		last:  make(map[string]sql.DBStats),
+		stop:  make(chan struct{}),
+	}
+	p.Collect()
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		core.Supervise(store+"_pool_stats", p.stop, func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					p.Collect()
+				case <-p.stop:
+					return
+				}
+			}
+		})
+	}()
+	return p
+}
+
+// Collect takes one sample of every pool.
+func (p *PoolStats) Collect() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for name, db := range p.pools() {
+		s := db.Stats()
+		labels := map[string]string{"pool": name}
+
+		core.SetGaugeWithLabels(p.store+".pool_open", labels, int64(s.OpenConnections))
+		core.SetGaugeWithLabels(p.store+".pool_in_use", labels, int64(s.InUse))
+		core.SetGaugeWithLabels(p.store+".pool_idle", labels, int64(s.Idle))
+		core.SetGaugeWithLabels(p.store+".pool_max_open", labels, int64(s.MaxOpenConnections))
+
+		core.SetCounterWithLabels(p.store+".pool_waits", labels, s.WaitCount)
+		core.SetCounterWithLabels(p.store+".pool_wait_us", labels, s.WaitDuration.Microseconds())
+		core.SetCounterWithLabels(p.store+".pool_closed", map[string]string{"pool": name, "reason": "max_idle"}, s.MaxIdleClosed)
+		core.SetCounterWithLabels(p.store+".pool_closed", map[string]string{"pool": name, "reason": "max_idle_time"}, s.MaxIdleTimeClosed)
+		core.SetCounterWithLabels(p.store+".pool_closed", map[string]string{"pool": name, "reason": "max_lifetime"}, s.MaxLifetimeClosed)
+
+		// The average wait, in microseconds like other durations, of the
+		// connections that had to wait since the last sample
+		if prev, ok := p.last[name]; ok {
+			if waits := s.WaitCount - prev.WaitCount; waits > 0 {
+				avg := (s.WaitDuration - prev.WaitDuration) / time.Duration(waits)
+				core.RecordValueWithLabels(p.store+".pool_wait", labels, float64(avg.Microseconds()))
+			}
+		}
+		p.last[name] = s
+	}
+}
+
+func (p *PoolStats) Stop() {
+	if p == nil {
+		return
+	}
+	close(p.stop)
+	p.wg.Wait()
+}
@@ -0,0 +1,286 @@
+// data/postgres/init.go
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data"
+)
+
+type postgresComponent struct{}
+
+func (c *postgresComponent) Name() string {
+	return "postgres"
+}
+
+func (c *postgresComponent) Dependencies() []string {
+	return []string{"config", "logger", "data"}
+}
+
+func (c *postgresComponent) Init(ctx context.Context) error {
+	cfg := config.Get()
+
+	configAdapter := &postgresConfig{cfg: cfg}
+	instance = New(configAdapter)
+
+	if mode := data.GuardMode(cfg.GetString("postgres", "query_guard")); mode != data.GuardOff {
+		var skip []string
+		raw, _ := cfg.Get("postgres", "query_guard_skip").([]interface{})
+		for _, check := range raw {
+			skip = append(skip, fmt.Sprint(check))
+		}
+		instance.Use(data.QueryGuard("postgres", mode, skip...))
+	}
+	instance.SetReplicas(cfg.GetStringSlice("postgres", "replicas"))
+
+	if err := instance.Connect(ctx); err != nil {
+		return err
+	}
+
+	if cfg.GetBool("postgres", "auto_migrate") {
+		if err := instance.Migrate(ctx, cfg.GetString("postgres", "migrations_dir")); err != nil {
+			return err
+		}
+	}
+
+	if instance.softDelete() || instance.journaling() {
+		if err := instance.EnsureRecoverySchema(ctx); err != nil {
+			return err
+		}
+		if interval := cfg.GetDuration("postgres", "purge_interval"); interval > 0 {
+			instance.startPurge(interval)
+		}
+	}
+
+	if replicas := instance.Replicas(); replicas != nil {
+		core.RegisterHealthCheck("postgres_replicas", replicas)
+	} else {
+		// Replicas may have been removed by a reload
+		core.UnregisterHealthCheck("postgres_replicas")
+	}
+	core.RegisterHealthCheck("postgres", instance)
+	return nil
+}
+
+func (c *postgresComponent) Shutdown(ctx context.Context) error {
+	if instance != nil {
+		return instance.Close()
+	}
+	return nil
+}
+
+func (c *postgresComponent) Store() data.Store {
+	if instance == nil {
+		return nil
+	}
+	return instance
+}
+
+type postgresConfig struct {
+	cfg *config.Config
+}
+
+func (p *postgresConfig) GetString(key string) string {
+	return p.cfg.GetString("postgres", key)
+}
+
+func (p *postgresConfig) GetInt(key string) int {
+	return p.cfg.GetInt("postgres", key)
+}
+
+func (p *postgresConfig) GetBool(key string) bool {
+	return p.cfg.GetBool("postgres", key)
+}
+
+func (p *postgresConfig) GetDuration(key string) time.Duration {
+	return p.cfg.GetDuration("postgres", key)
+}
+
+// reconnectOnChange restarts the component, and the components that depend
+// on it, when a reload changes how it connects, e.g. after credentials are
+// rotated in Vault.
+func reconnectOnChange(old, new map[string]interface{}) {
+	changed := false
+	for _, key := range []string{"host", "port", "user", "password", "database", "sslmode", "replicas"} {
+		if fmt.Sprint(old[key]) != fmt.Sprint(new[key]) {
+			changed = true
+		}
+	}
+	if !changed || !core.IsInitialized("postgres") {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := core.Restart(ctx, "postgres"); err != nil {
+			core.GetLogger("postgres").Error("Reconnecting after config change: %v", err)
+		}
+	}()
+}
+
+func init() {
+	config.Register("postgres", config.Schema{
+		"host": config.Field{
+			Default:     "localhost",
+			Required:    true,
+			Description: "PostgreSQL host",
+		},
+		"port": config.Field{
+			Default:     5432,
+			Required:    false,
+			Description: "PostgreSQL port",
+		},
+		"user": config.Field{
+			Default:     "postgres",
+			Required:    true,
+			Description: "PostgreSQL user",
+		},
+		"password": config.Field{
+			Default:     "",
+			Required:    true,
+			Description: "PostgreSQL password; env:NAME and file:/path references are resolved at load",
+			Secret:      true,
+		},
+		"database": config.Field{
+			Default:     "polkadot",
+			Required:    true,
+			Description: "PostgreSQL database",
+		},
+		"sslmode": config.Field{
+			Default:     "disable",
+			Required:    false,
+			Description: "SSL mode (disable, require, verify-ca, verify-full)",
+		},
+		"replicas": config.Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "Read replicas as host or host:port, using the primary's credentials; Query and QueryRow are spread over the healthy ones",
+		},
+		"replica_check_interval": config.Field{
+			Default:     "5s",
+			Required:    false,
+			Description: "How often replicas are pinged; a replica that fails is skipped until it answers",
+		},
+		"max_connections": config.Field{
+			Default:     25,
+			Required:    false,
+			Description: "Maximum connections",
+		},
+		"max_idle_connections": config.Field{
+			Default:     5,
+			Required:    false,
+			Description: "Maximum idle connections",
+		},
+		"conn_max_lifetime": config.Field{
+			Default:     "5m",
+			Required:    false,
+			Description: "Connection max lifetime",
+		},
+		"pool_stats_interval": config.Field{
+			Default:     "15s",
+			Required:    false,
+			Description: "How often connection pool statistics are exported as metrics (0 disables)",
+		},
+		"slow_query_threshold": config.Field{
+			Default:     "1s",
+			Required:    false,
+			Description: "Log statements slower than this at WARN, redacted, with their caller, and count them in postgres.slow_queries (0 disables)",
+		},
+		"query_guard": config.Field{
+			Default:     "off",
+			Required:    false,
+			Description: "Suspicious query guard mode (off, warn, reject)",
+			Validator:   data.ValidateGuardMode,
+		},
+		"query_guard_skip": config.Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "Query guard checks not to run: literal, stacked, comment, union, tautology (literal and comment also flag legitimate SQL)",
+			Validator:   data.ValidateGuardSkip,
+		},
+		"tx_max_retries": config.Field{
+			Default:     3,
+			Required:    false,
+			Description: "Times WithTx retries a transaction that hit a deadlock or serialization failure",
+		},
+		"tx_retry_backoff": config.Field{
+			Default:     "50ms",
+			Required:    false,
+			Description: "Initial backoff between WithTx retries, doubled per attempt",
+		},
+		"auto_migrate": config.Field{
+			Default:     false,
+			Required:    false,
+			Description: "Apply pending schema migrations, including the kv table, at startup",
+		},
+		"migrations_dir": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Directory of application migrations (<version>_<name>.up.sql / .down.sql) applied with auto_migrate",
+		},
+		"soft_delete": config.Field{
+			Default:     false,
+			Required:    false,
+			Description: "Mark deleted kv rows with deleted_at instead of removing them",
+		},
+		"journal": config.Field{
+			Default:     false,
+			Required:    false,
+			Description: "Record kv mutations in kv_journal for point-in-time restore",
+		},
+		"purge_after": config.Field{
+			Default:     "720h",
+			Required:    false,
+			Description: "Remove soft-deleted kv rows after this long",
+		},
+		"journal_retention": config.Field{
+			Default:     "720h",
+			Required:    false,
+			Description: "Remove kv_journal entries after this long (0 keeps all)",
+		},
+		"purge_interval": config.Field{
+			Default:     "1h",
+			Required:    false,
+			Description: "How often to purge expired kv data (0 disables)",
+		},
+	})
+
+	config.OnReload("postgres", reconnectOnChange)
+	core.Register(&postgresComponent{})
+	core.RegisterPreflight("postgres", "dns", func(ctx context.Context) error {
+		return core.CheckDNS(ctx, config.Get().GetString("postgres", "host"))
+	})
+	core.RegisterErrorClassifier(classifyError)
+}
+
+func classifyError(err error) (string, string, bool) {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return "", "", false
+	}
+	switch pqErr.Code {
+	case "28P01", "28000":
+		return "auth", "Check postgres.user and postgres.password, and pg_hba.conf", true
+	case "3D000":
+		return "missing_database", "Create the database named in postgres.database", true
+	case "42501":
+		return "permission", "Grant postgres.user access to postgres.database", true
+	}
+	return "", "", false
+}
+
+// isRetryable reports serialization failures and detected deadlocks.
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
@@ -0,0 +1,36 @@
+// data/postgres/migrations.go
+package postgres
+
+import (
+	"context"
+	"embed"
+	"os"
+
+	"github.com/polkadot-go/helper/data/migrate"
+)
+
+//go:embed migrations/*.sql
+var builtinMigrations embed.FS
+
+// Migrate applies the helper's own schema (the kv table), tracked in
+// helper_migrations, then any application migrations from dir, tracked in
+// schema_migrations. An empty dir skips application migrations.
+func (p *Postgres) Migrate(ctx context.Context, dir string) error {
+	builtin, err := migrate.Load(builtinMigrations, "migrations")
+	if err != nil {
+		return err
+	}
+	if _, err := migrate.New(p, migrate.Postgres, "helper_migrations", builtin).Up(ctx); err != nil {
+		return err
+	}
+
+	if dir == "" {
+		return nil
+	}
+	app, err := migrate.Load(os.DirFS(dir), ".")
+	if err != nil {
+		return err
+	}
+	_, err = migrate.New(p, migrate.Postgres, "schema_migrations", app).Up(ctx)
+	return err
+}
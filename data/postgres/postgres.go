@@ -0,0 +1,312 @@
+// data/postgres/postgres.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+type Postgres struct {
+	db           *sql.DB
+	config       data.StoreConfig
+	logger       core.Logger
+	interceptors data.InterceptorChain
+	slow         *data.SlowQueryLog
+	purgeStop    chan struct{}
+	purgeWG      sync.WaitGroup
+	replicaAddrs []string
+	replicas     *data.Replicas
+	poolStats    *data.PoolStats
+}
+
+var instance *Postgres
+
+func Get() *Postgres {
+	return instance
+}
+
+func New(cfg data.StoreConfig) *Postgres {
+	return &Postgres{
+		config: cfg,
+		logger: core.GetLogger("postgres"),
+		slow:   data.NewSlowQueryLog("postgres", cfg.GetDuration("slow_query_threshold")),
+	}
+}
+
+// open returns a pool for the server at host:port, using the configured
+// credentials, database and pool limits.
+func (p *Postgres) open(host string, port int) (*sql.DB, error) {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(p.config.GetString("user"), p.config.GetString("password")),
+		Host:     net.JoinHostPort(host, strconv.Itoa(port)),
+		Path:     "/" + p.config.GetString("database"),
+		RawQuery: url.Values{"sslmode": {p.config.GetString("sslmode")}}.Encode(),
+	}
+
+	db, err := sql.Open("postgres", dsn.String())
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(p.config.GetInt("max_connections"))
+	db.SetMaxIdleConns(p.config.GetInt("max_idle_connections"))
+	db.SetConnMaxLifetime(p.config.GetDuration("conn_max_lifetime"))
+	return db, nil
+}
+
+func (p *Postgres) Connect(ctx context.Context) error {
+	var err error
+	p.db, err = p.open(p.config.GetString("host"), p.config.GetInt("port"))
+	if err != nil {
+		return err
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err = p.db.PingContext(pingCtx)
+	if err != nil {
+		p.db.Close()
+		return err
+	}
+
+	if err := p.connectReplicas(ctx); err != nil {
+		p.db.Close()
+		return err
+	}
+	p.poolStats = data.StartPoolStats("postgres", p.config.GetDuration("pool_stats_interval"), p.pools)
+
+	core.IncrCounter("postgres.connections")
+	p.logger.Info("Connected to PostgreSQL at %s:%d", p.config.GetString("host"), p.config.GetInt("port"))
+	return nil
+}
+
+func (p *Postgres) Use(interceptors ...data.QueryInterceptor) {
+	p.interceptors = append(p.interceptors, interceptors...)
+}
+
+func (p *Postgres) Close() error {
+	p.poolStats.Stop()
+	p.stopPurge()
+	p.replicas.Close()
+	if p.db != nil {
+		return p.db.Close()
+	}
+	return nil
+}
+
+func (p *Postgres) Get(ctx context.Context, key string) (interface{}, error) {
+	defer core.TrackSpan(ctx, "store", "postgres")()
+
+	start := time.Now()
+	var value string
+	query := "SELECT value FROM kv WHERE key = $1"
+	if p.softDelete() {
+		query += " AND deleted_at IS NULL"
+	}
+	err := p.db.QueryRowContext(ctx, query, key).Scan(&value)
+	p.recordKV("select", start, err)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data.DecryptValue(key, value)
+}
+
+func (p *Postgres) Set(ctx context.Context, key string, value interface{}) error {
+	defer core.TrackSpan(ctx, "store", "postgres")()
+
+	if err := data.CheckWritable(ctx, "postgres"); err != nil {
+		return err
+	}
+
+	value, err := data.EncodeValue(value)
+	if err != nil {
+		return err
+	}
+	if err := data.ValidateValue(key, value); err != nil {
+		return err
+	}
+	value, err = data.EncryptValue(key, value)
+	if err != nil {
+		return err
+	}
+	return p.setStored(ctx, key, value)
+}
+
+// setStored writes value as it is stored: encoded, validated and
+// encrypted already, as when undeleting or restoring it.
+func (p *Postgres) setStored(ctx context.Context, key string, value interface{}) error {
+	start := time.Now()
+	query := "INSERT INTO kv (key, value) VALUES ($1, $2) ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value"
+	if p.softDelete() {
+		query += ", deleted_at = NULL"
+	}
+	err := p.mutate(ctx, key, "set", value, query, key, value)
+	p.recordKV("insert", start, err)
+	return err
+}
+
+func (p *Postgres) Delete(ctx context.Context, key string) error {
+	defer core.TrackSpan(ctx, "store", "postgres")()
+
+	if err := data.CheckWritable(ctx, "postgres"); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	var err error
+	if p.softDelete() {
+		err = p.mutate(ctx, key, "delete", nil, "UPDATE kv SET deleted_at = $1 WHERE key = $2 AND deleted_at IS NULL", time.Now().UTC(), key)
+	} else {
+		err = p.mutate(ctx, key, "delete", nil, "DELETE FROM kv WHERE key = $1", key)
+	}
+	p.recordKV("delete", start, err)
+	return err
+}
+
+func (p *Postgres) Exists(ctx context.Context, key string) (bool, error) {
+	defer core.TrackSpan(ctx, "store", "postgres")()
+
+	start := time.Now()
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM kv WHERE key = $1)"
+	if p.softDelete() {
+		query = "SELECT EXISTS(SELECT 1 FROM kv WHERE key = $1 AND deleted_at IS NULL)"
+	}
+	err := p.db.QueryRowContext(ctx, query, key).Scan(&exists)
+	p.recordKV("select", start, err)
+	return exists, err
+}
+
+func (p *Postgres) recordKV(op string, start time.Time, err error) {
+	labels := map[string]string{"table": "kv", "op": op}
+	core.RecordDurationWithLabels("postgres.query", labels, start)
+	p.slow.Observe(context.Background(), "kv "+op, start)
+	if err != nil && err != sql.ErrNoRows {
+		core.IncrCounterWithLabels("postgres.errors", labels)
+	}
+}
+
+func (p *Postgres) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer core.TrackSpan(ctx, "store", "postgres")()
+
+	if err := p.interceptors.Run(ctx, query, args); err != nil {
+		core.IncrCounter("postgres.errors")
+		return nil, err
+	}
+
+	if replica := p.replicas.Pick(ctx); replica != nil {
+		start := time.Now()
+		rows, err := replica.DB.QueryContext(ctx, query, args...)
+		p.recordReplica(replica, start, err)
+		p.slow.Observe(ctx, query, start)
+		return rows, err
+	}
+
+	start := time.Now()
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	core.RecordDuration("postgres.query", start)
+	p.slow.Observe(ctx, query, start)
+	if err != nil {
+		core.IncrCounter("postgres.errors")
+	}
+	return rows, err
+}
+
+func (p *Postgres) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer core.TrackSpan(ctx, "store", "postgres")()
+
+	if err := p.interceptors.Run(ctx, query, args); err != nil {
+		core.IncrCounter("postgres.errors")
+		return data.ErrRow(err)
+	}
+
+	if replica := p.replicas.Pick(ctx); replica != nil {
+		start := time.Now()
+		row := replica.DB.QueryRowContext(ctx, query, args...)
+		p.recordReplica(replica, start, row.Err())
+		p.slow.Observe(ctx, query, start)
+		return row
+	}
+
+	start := time.Now()
+	row := p.db.QueryRowContext(ctx, query, args...)
+	core.RecordDuration("postgres.query", start)
+	p.slow.Observe(ctx, query, start)
+	return row
+}
+
+func (p *Postgres) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer core.TrackSpan(ctx, "store", "postgres")()
+
+	if err := data.CheckWritable(ctx, "postgres"); err != nil {
+		return nil, err
+	}
+
+	if err := p.interceptors.Run(ctx, query, args); err != nil {
+		core.IncrCounter("postgres.errors")
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := p.db.ExecContext(ctx, query, args...)
+	core.RecordDuration("postgres.exec", start)
+	p.slow.Observe(ctx, query, start)
+	if err != nil {
+		core.IncrCounter("postgres.errors")
+	}
+	return result, err
+}
+
+func (p *Postgres) Begin(ctx context.Context) (*sql.Tx, error) {
+	// A switched-off store still serves reads, so only the transaction is
+	// made read-only
+	readOnly := data.IsReadOnly(ctx) || data.StoreReadOnly("postgres")
+	return p.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: readOnly})
+}
+
+// WithTx runs fn in a transaction, committing on success and rolling back
+// on error or panic. Deadlocks and lock timeouts are retried up to
+// tx_max_retries times.
+func (p *Postgres) WithTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	defer core.TrackSpan(ctx, "store", "postgres")()
+
+	if err := data.CheckWritable(ctx, "postgres"); err != nil {
+		return err
+	}
+	return data.RunTx(ctx, p.Begin, data.TxPolicy{
+		Store:      "postgres",
+		MaxRetries: p.config.GetInt("tx_max_retries"),
+		Backoff:    p.config.GetDuration("tx_retry_backoff"),
+		Retryable:  isRetryable,
+	}, fn)
+}
+
+func (p *Postgres) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := p.db.PingContext(ctx); err != nil {
+		return core.HealthUnhealthy, err
+	}
+
+	var one int
+	if err := p.db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return core.HealthDegraded, err
+	}
+
+	return core.HealthHealthy, nil
+}
@@ -0,0 +1,249 @@
+// data/postgres/recovery.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+func (p *Postgres) softDelete() bool {
+	return p.config.GetBool("soft_delete")
+}
+
+func (p *Postgres) journaling() bool {
+	return p.config.GetBool("journal")
+}
+
+// EnsureRecoverySchema adds the deleted_at column and the kv_journal
+// table when soft delete or journaling is enabled.
+func (p *Postgres) EnsureRecoverySchema(ctx context.Context) error {
+	if p.softDelete() {
+		if _, err := p.db.ExecContext(ctx, "ALTER TABLE kv ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ NULL"); err != nil {
+			return err
+		}
+		if _, err := p.db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_kv_deleted_at ON kv (deleted_at)"); err != nil {
+			return err
+		}
+	}
+
+	if p.journaling() {
+		_, err := p.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS kv_journal (
+			id BIGSERIAL PRIMARY KEY,
+			key TEXT NOT NULL,
+			op TEXT NOT NULL,
+			value TEXT NULL,
+			changed_at TIMESTAMPTZ NOT NULL
+		)`)
+		if err != nil {
+			return err
+		}
+		if _, err := p.db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_kv_journal_key ON kv_journal (key, changed_at)"); err != nil {
+			return err
+		}
+		if _, err := p.db.ExecContext(ctx, "CREATE INDEX IF NOT EXISTS idx_kv_journal_time ON kv_journal (changed_at)"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mutate runs a kv write and, when journaling, records it in kv_journal
+// in the same transaction. A set of a key with no live row is recorded as
+// a create, so RestoreKey can tell keys that did not exist yet.
+func (p *Postgres) mutate(ctx context.Context, key, op string, value interface{}, query string, args ...interface{}) error {
+	if !p.journaling() {
+		_, err := p.db.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	return p.WithTx(ctx, func(tx *sql.Tx) error {
+		journalOp := op
+		if op == "set" {
+			live := "SELECT EXISTS(SELECT 1 FROM kv WHERE key = $1)"
+			if p.softDelete() {
+				live = "SELECT EXISTS(SELECT 1 FROM kv WHERE key = $1 AND deleted_at IS NULL)"
+			}
+			var exists bool
+			if err := tx.QueryRowContext(ctx, live, key).Scan(&exists); err != nil {
+				return err
+			}
+			if !exists {
+				journalOp = "create"
+			}
+		}
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+		_, err := tx.ExecContext(ctx,
+			"INSERT INTO kv_journal (key, op, value, changed_at) VALUES ($1, $2, $3, $4)",
+			key, journalOp, value, time.Now().UTC())
+		return err
+	})
+}
+
+func (p *Postgres) Undelete(ctx context.Context, key string) error {
+	if err := data.CheckWritable(ctx, "postgres"); err != nil {
+		return err
+	}
+
+	var value string
+	err := p.db.QueryRowContext(ctx, "SELECT value FROM kv WHERE key = $1 AND deleted_at IS NOT NULL", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return p.setStored(ctx, key, value)
+}
+
+func (p *Postgres) RestoreKey(ctx context.Context, key string, at time.Time) error {
+	if err := data.CheckWritable(ctx, "postgres"); err != nil {
+		return err
+	}
+
+	var op string
+	var value sql.NullString
+	err := p.db.QueryRowContext(ctx,
+		"SELECT op, value FROM kv_journal WHERE key = $1 AND changed_at <= $2 ORDER BY changed_at DESC, id DESC LIMIT 1",
+		key, at.UTC()).Scan(&op, &value)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if err == sql.ErrNoRows {
+		return p.restoreUnrecorded(ctx, key, at)
+	}
+
+	core.IncrCounter("postgres.restores")
+	if op == "delete" {
+		return p.Delete(ctx, key)
+	}
+	return p.setStored(ctx, key, value.String)
+}
+
+// restoreUnrecorded handles a key with no journal entry at or before at.
+// It is deleted only if its first entry shows it was created after at;
+// otherwise its earlier history may have been purged or predate the
+// journal, and it is left alone.
+func (p *Postgres) restoreUnrecorded(ctx context.Context, key string, at time.Time) error {
+	var first string
+	err := p.db.QueryRowContext(ctx,
+		"SELECT op FROM kv_journal WHERE key = $1 ORDER BY changed_at, id LIMIT 1", key).Scan(&first)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if first != "create" {
+		core.IncrCounter("postgres.restores_skipped")
+		p.logger.Warn("Not restoring %s: the journal has no record of it at %s", key, at.Format(time.RFC3339))
+		return nil
+	}
+	core.IncrCounter("postgres.restores")
+	return p.Delete(ctx, key)
+}
+
+func (p *Postgres) RestoreAt(ctx context.Context, at time.Time) (int, error) {
+	rows, err := p.db.QueryContext(ctx, "SELECT DISTINCT key FROM kv_journal WHERE changed_at > $1", at.UTC())
+	if err != nil {
+		return 0, err
+	}
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for i, key := range keys {
+		if err := p.RestoreKey(ctx, key, at); err != nil {
+			return i, err
+		}
+	}
+	p.logger.Info("Restored %d keys to %s", len(keys), at.Format(time.RFC3339))
+	return len(keys), nil
+}
+
+func (p *Postgres) Purge(ctx context.Context) (int64, error) {
+	if err := data.CheckWritable(ctx, "postgres"); err != nil {
+		return 0, err
+	}
+
+	var purged int64
+	now := time.Now().UTC()
+
+	if p.softDelete() {
+		result, err := p.db.ExecContext(ctx, "DELETE FROM kv WHERE deleted_at IS NOT NULL AND deleted_at < $1",
+			now.Add(-p.config.GetDuration("purge_after")))
+		if err != nil {
+			return purged, err
+		}
+		n, _ := result.RowsAffected()
+		purged += n
+	}
+
+	if retention := p.config.GetDuration("journal_retention"); p.journaling() && retention > 0 {
+		result, err := p.db.ExecContext(ctx, "DELETE FROM kv_journal WHERE changed_at < $1", now.Add(-retention))
+		if err != nil {
+			return purged, err
+		}
+		n, _ := result.RowsAffected()
+		purged += n
+	}
+
+	return purged, nil
+}
+
+// startPurge runs Purge every interval until the store is closed.
+func (p *Postgres) startPurge(interval time.Duration) {
+	p.purgeStop = make(chan struct{})
+	p.purgeWG.Add(1)
+	go func() {
+		defer p.purgeWG.Done()
+		core.Supervise("postgres_purge", p.purgeStop, func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					// Skipped until writes are switched back on
+					if data.StoreReadOnly("postgres") {
+						continue
+					}
+					ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+					n, err := p.Purge(ctx)
+					cancel()
+					if err != nil {
+						p.logger.Error("Purging kv data: %v", err)
+					} else if n > 0 {
+						p.logger.Info("Purged %d expired kv rows", n)
+					}
+				case <-p.purgeStop:
+					return
+				}
+			}
+		})
+	}()
+}
+
+func (p *Postgres) stopPurge() {
+	if p.purgeStop != nil {
+		close(p.purgeStop)
+		p.purgeWG.Wait()
+		p.purgeStop = nil
+	}
+}
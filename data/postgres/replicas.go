@@ -0,0 +1,82 @@
+// data/postgres/replicas.go
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+// SetReplicas makes Connect open read replicas at addrs, each host or
+// host:port, with the primary's credentials and database. Query and
+// QueryRow then read from a healthy replica unless the context asks for
+// Strong consistency; kv reads, Exec and transactions stay on the primary.
+func (p *Postgres) SetReplicas(addrs []string) {
+	p.replicaAddrs = addrs
+}
+
+// Replicas returns the read replicas, or nil when none are configured.
+func (p *Postgres) Replicas() *data.Replicas {
+	return p.replicas
+}
+
+func (p *Postgres) connectReplicas(ctx context.Context) error {
+	if len(p.replicaAddrs) == 0 {
+		return nil
+	}
+
+	members := make([]*data.Replica, 0, len(p.replicaAddrs))
+	for _, addr := range p.replicaAddrs {
+		host, port := splitAddr(addr, p.config.GetInt("port"))
+		db, err := p.open(host, port)
+		if err != nil {
+			for _, opened := range members {
+				opened.DB.Close()
+			}
+			return err
+		}
+		members = append(members, &data.Replica{Addr: addr, DB: db})
+	}
+
+	p.replicas = data.NewReplicas("postgres", members)
+	// A replica that is down at startup is skipped until it answers
+	p.replicas.Check(ctx)
+	p.replicas.Start(p.config.GetDuration("replica_check_interval"))
+	p.logger.Info("Reading from %d PostgreSQL replicas", len(members))
+	return nil
+}
+
+// pools returns the primary's and replicas' pools for PoolStats.
+func (p *Postgres) pools() map[string]*sql.DB {
+	pools := map[string]*sql.DB{"primary": p.db}
+	for _, r := range p.replicas.Members() {
+		pools[r.Addr] = r.DB
+	}
+	return pools
+}
+
+func (p *Postgres) recordReplica(r *data.Replica, start time.Time, err error) {
+	labels := map[string]string{"replica": r.Addr}
+	core.RecordDurationWithLabels("postgres.replica_query", labels, start)
+	if err != nil && err != sql.ErrNoRows {
+		core.IncrCounterWithLabels("postgres.replica_errors", labels)
+	}
+}
+
+// splitAddr splits host:port, using defaultPort when addr has no port.
+func splitAddr(addr string, defaultPort int) (string, int) {
+	host, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr, defaultPort
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return host, defaultPort
+	}
+	return host, port
+}
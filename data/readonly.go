@@ -0,0 +1,131 @@
+// data/readonly.go
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// ErrReadOnly is returned by writes while the data layer, or the store
+// written to, is switched to read-only.
+var ErrReadOnly = errors.New("data layer is read-only")
+
+var readOnly = struct {
+	mu     sync.RWMutex
+	global bool
+	stores map[string]bool
+}{stores: make(map[string]bool)}
+
+// ReadOnlyState is the current switch state.
+type ReadOnlyState struct {
+	Global bool     `json:"global"`
+	Stores []string `json:"stores"`
+}
+
+// SetReadOnly switches writes off or on for one store by name, or for every
+// store when name is "*".
+func SetReadOnly(name string, on bool) {
+	readOnly.mu.Lock()
+	var changed bool
+	if name == "*" {
+		changed = readOnly.global != on
+		readOnly.global = on
+	} else {
+		changed = readOnly.stores[name] != on
+		if on {
+			readOnly.stores[name] = true
+		} else {
+			delete(readOnly.stores, name)
+		}
+	}
+	readOnly.mu.Unlock()
+
+	if changed {
+		announceReadOnly(name, on)
+	}
+}
+
+// SetReadOnlyState replaces the whole switch state, e.g. from config. The
+// swap is atomic, so a store that stays read-only never accepts a write in
+// between, and only stores whose switch actually moves are announced.
+func SetReadOnlyState(state ReadOnlyState) {
+	next := make(map[string]bool, len(state.Stores))
+	for _, name := range state.Stores {
+		next[name] = true
+	}
+
+	readOnly.mu.Lock()
+	changes := make(map[string]bool)
+	for name := range readOnly.stores {
+		if !next[name] {
+			changes[name] = false
+		}
+	}
+	for name := range next {
+		if !readOnly.stores[name] {
+			changes[name] = true
+		}
+	}
+	if readOnly.global != state.Global {
+		changes["*"] = state.Global
+	}
+	readOnly.stores = next
+	readOnly.global = state.Global
+	readOnly.mu.Unlock()
+
+	names := make([]string, 0, len(changes))
+	for name := range changes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		announceReadOnly(name, changes[name])
+	}
+}
+
+func announceReadOnly(name string, on bool) {
+	state := "off"
+	if on {
+		state = "on"
+	}
+	core.GetLogger("data").Warn("Read-only mode %s for %s", state, name)
+	core.PublishEvent("data.read_only", map[string]interface{}{"store": name, "read_only": on})
+}
+
+func GetReadOnlyState() ReadOnlyState {
+	readOnly.mu.RLock()
+	defer readOnly.mu.RUnlock()
+
+	state := ReadOnlyState{Global: readOnly.global, Stores: []string{}}
+	for name := range readOnly.stores {
+		state.Stores = append(state.Stores, name)
+	}
+	sort.Strings(state.Stores)
+	return state
+}
+
+// StoreReadOnly reports whether writes to the named store are switched off.
+func StoreReadOnly(name string) bool {
+	readOnly.mu.RLock()
+	defer readOnly.mu.RUnlock()
+	return readOnly.global || readOnly.stores[name]
+}
+
+// CheckWritable is called by stores before any write. It fails with
+// ErrReadOnlyContext for a read-only context and ErrReadOnly while the
+// store is switched to read-only.
+func CheckWritable(ctx context.Context, store string) error {
+	if IsReadOnly(ctx) {
+		return ErrReadOnlyContext
+	}
+	if StoreReadOnly(store) {
+		core.IncrCounterWithLabels("data.read_only_rejected", map[string]string{"store": store})
+		return fmt.Errorf("%s: %w", store, ErrReadOnly)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+// data/replicas.go
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// Replica is one read replica of a SQL store.
+type Replica struct {
+	Addr    string
+	DB      *sql.DB
+	healthy atomic.Bool
+}
+
+func (r *Replica) Healthy() bool {
+	return r.healthy.Load()
+}
+
+// Replicas spreads reads round-robin over the healthy members of a set of
+// read replicas. Members are pinged periodically; one that fails is
+// skipped until a ping succeeds again.
+type Replicas struct {
+	store   string
+	members []*Replica
+	next    atomic.Uint64
+	logger  core.Logger
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewReplicas returns a set over members, which start out healthy. A set
+// without members picks none, so reads stay on the primary.
+func NewReplicas(store string, members []*Replica) *Replicas {
+	for _, m := range members {
+		m.healthy.Store(true)
+	}
+	return &Replicas{
+		store:   store,
+		members: members,
+		logger:  core.GetLogger(store),
+	}
+}
+
+// WithPrimary makes reads with ctx go to the primary, for reading back
+// what was just written. It is shorthand for WithConsistency(ctx, Strong).
+func WithPrimary(ctx context.Context) context.Context {
+	return WithConsistency(ctx, Strong)
+}
+
+// Pick returns the replica to read from, or nil when ctx asks for Strong
+// consistency or no replica is healthy and the primary should be used.
+func (r *Replicas) Pick(ctx context.Context) *Replica {
+	if r == nil || len(r.members) == 0 || ConsistencyFrom(ctx) == Strong {
+		return nil
+	}
+	start := r.next.Add(1)
+	for i := range r.members {
+		m := r.members[(start+uint64(i))%uint64(len(r.members))]
+		if m.Healthy() {
+			return m
+		}
+	}
+	core.IncrCounter(r.store + ".replica_fallbacks")
+	return nil
+}
+
+// Members returns the replicas in configuration order.
+func (r *Replicas) Members() []*Replica {
+	if r == nil {
+		return nil
+	}
+	return r.members
+}
+
+// Check pings every replica and updates its health.
+func (r *Replicas) Check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, m := range r.members {
+		wg.Add(1)
+		go func(m *Replica) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+			defer cancel()
+
+			err := m.DB.PingContext(ctx)
+			if was := m.healthy.Swap(err == nil); was != (err == nil) {
+				if err != nil {
+					r.logger.Warn("Replica %s is down, reading from the others: %v", m.Addr, err)
+				} else {
+					r.logger.Info("Replica %s is back", m.Addr)
+				}
+			}
+		}(m)
+	}
+	wg.Wait()
+
+	healthy := 0
+	for _, m := range r.members {
+		if m.Healthy() {
+			healthy++
+		}
+	}
+	core.SetGauge(r.store+".replicas_healthy", int64(healthy))
+}
+
+// Start checks the replicas every interval until Close.
+func (r *Replicas) Start(interval time.Duration) {
+	if r == nil || len(r.members) == 0 || interval <= 0 {
+		return
+	}
+	r.stop = make(chan struct{})
+	stop := r.stop
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		core.Supervise(r.store+"_replica_check", stop, func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					r.Check(context.Background())
+				case <-stop:
+					return
+				}
+			}
+		})
+	}()
+}
+
+// Close stops the checks and closes the replicas' connection pools.
+func (r *Replicas) Close() error {
+	if r == nil {
+		return nil
+	}
+	if r.stop != nil {
+		close(r.stop)
+		r.wg.Wait()
+		r.stop = nil
+	}
+	var firstErr error
+	for _, m := range r.members {
+		if err := m.DB.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// HealthCheck reports degraded while any replica is down; the primary
+// still serves reads then, so the store keeps working.
+func (r *Replicas) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
+	var down []string
+	for _, m := range r.members {
+		if !m.Healthy() {
+			down = append(down, m.Addr)
+		}
+	}
+	if len(down) > 0 {
+		return core.HealthDegraded, fmt.Errorf("replicas down: %v", down)
+	}
+	return core.HealthHealthy, nil
+}
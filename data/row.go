@@ -0,0 +1,47 @@
+// data/row.go
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"sync"
+)
+
+var (
+	errRowsOnce sync.Once
+	errRows     *sql.DB
+)
+
+type rowErrKey struct{}
+
+// ErrRow returns a *sql.Row whose Err and Scan return err, for QueryRow
+// implementations that refuse a query before it reaches the database.
+// database/sql offers no way to build one, so it comes from a DB whose
+// connector fails with err.
+func ErrRow(err error) *sql.Row {
+	errRowsOnce.Do(func() {
+		errRows = sql.OpenDB(errConnector{})
+	})
+	return errRows.QueryRowContext(context.WithValue(context.Background(), rowErrKey{}, err), "")
+}
+
+type errConnector struct{}
+
+func (errConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if err, ok := ctx.Value(rowErrKey{}).(error); ok {
+		return nil, err
+	}
+	return nil, errors.New("query refused")
+}
+
+func (errConnector) Driver() driver.Driver {
+	return errDriver{}
+}
+
+type errDriver struct{}
+
+func (errDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("query refused")
+}
@@ -0,0 +1,83 @@
+// data/slowquery.go
+package data
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+var (
+	stringLiteral = regexp.MustCompile(`'(?:[^'\\]|\\.|'')*'`)
+	// Digits not part of an identifier or a $1 placeholder
+	numberLiteral = regexp.MustCompile(`(^|[^\w$.])-?\d+(\.\d+)?\b`)
+)
+
+// RedactQuery replaces string and number literals in query with ?, so a
+// logged statement does not carry values that were inlined rather than
+// bound.
+func RedactQuery(query string) string {
+	query = stringLiteral.ReplaceAllString(query, "?")
+	return numberLiteral.ReplaceAllString(query, "${1}?")
+}
+
+// SlowQueryLog warns about statements that take longer than a threshold
+// and counts them in <store>.slow_queries. Statements are logged redacted,
+// without their arguments, along with the code that issued them.
+type SlowQueryLog struct {
+	store     string
+	threshold time.Duration
+	logger    core.Logger
+}
+
+// NewSlowQueryLog returns nil, which logs nothing, when threshold is zero
+// or less.
+func NewSlowQueryLog(store string, threshold time.Duration) *SlowQueryLog {
+	if threshold <= 0 {
+		return nil
+	}
+	return &SlowQueryLog{
+		store:     store,
+		threshold: threshold,
+		logger:    core.GetLogger(store),
+	}
+}
+
+// Observe logs query if it has been running since start for longer than
+// the threshold.
+func (l *SlowQueryLog) Observe(ctx context.Context, query string, start time.Time) {
+	if l == nil {
+		return
+	}
+	elapsed := time.Since(start)
+	if elapsed < l.threshold {
+		return
+	}
+
+	core.IncrCounter(l.store + ".slow_queries")
+	core.ContextLogger(ctx, l.logger).Warn("Slow query (%s) from %s: %s",
+		elapsed.Round(time.Millisecond), queryCaller(), truncateQuery(RedactQuery(query)))
+}
+
+// queryCaller returns file:line of the first caller outside the data
+// packages and database/sql.
+func queryCaller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "github.com/polkadot-go/helper/data") &&
+			!strings.HasPrefix(frame.Function, "database/sql") {
+			return fmt.Sprintf("%s:%d", filepath.Join(filepath.Base(filepath.Dir(frame.File)), filepath.Base(frame.File)), frame.Line)
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
@@ -0,0 +1,216 @@
+// data/sqlite/init.go
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+type sqliteComponent struct{}
+
+func (c *sqliteComponent) Name() string {
+	return "sqlite"
+}
+
+func (c *sqliteComponent) Dependencies() []string {
+	return []string{"config", "logger", "data"}
+}
+
+func (c *sqliteComponent) Init(ctx context.Context) error {
+	cfg := config.Get()
+
+	instance = New(&sqliteConfig{cfg: cfg})
+
+	if mode := data.GuardMode(cfg.GetString("sqlite", "query_guard")); mode != data.GuardOff {
+		var skip []string
+		raw, _ := cfg.Get("sqlite", "query_guard_skip").([]interface{})
+		for _, check := range raw {
+			skip = append(skip, fmt.Sprint(check))
+		}
+		instance.Use(data.QueryGuard("sqlite", mode, skip...))
+	}
+
+	if err := instance.Connect(ctx); err != nil {
+		return err
+	}
+
+	core.RegisterHealthCheck("sqlite", instance)
+	return nil
+}
+
+func (c *sqliteComponent) Shutdown(ctx context.Context) error {
+	if instance != nil {
+		return instance.Close()
+	}
+	return nil
+}
+
+func (c *sqliteComponent) Store() data.Store {
+	if instance == nil {
+		return nil
+	}
+	return instance
+}
+
+type sqliteConfig struct {
+	cfg *config.Config
+}
+
+func (s *sqliteConfig) GetString(key string) string {
+	return s.cfg.GetString("sqlite", key)
+}
+
+func (s *sqliteConfig) GetInt(key string) int {
+	return s.cfg.GetInt("sqlite", key)
+}
+
+func (s *sqliteConfig) GetBool(key string) bool {
+	return s.cfg.GetBool("sqlite", key)
+}
+
+func (s *sqliteConfig) GetDuration(key string) time.Duration {
+	return s.cfg.GetDuration("sqlite", key)
+}
+
+// reconnectOnChange reopens the database when a reload changes the file
+// or the pragmas applied to its connections. An in-memory database starts
+// over empty.
+func reconnectOnChange(old, new map[string]interface{}) {
+	changed := false
+	for _, key := range []string{"path", "journal_mode", "synchronous", "busy_timeout", "max_connections"} {
+		if fmt.Sprint(old[key]) != fmt.Sprint(new[key]) {
+			changed = true
+		}
+	}
+	if !changed || !core.IsInitialized("sqlite") {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := core.Restart(ctx, "sqlite"); err != nil {
+			core.GetLogger("sqlite").Error("Reopening after config change: %v", err)
+		}
+	}()
+}
+
+func validateJournalMode(value interface{}) error {
+	switch strings.ToLower(fmt.Sprint(value)) {
+	case "", "wal", "delete", "truncate", "persist", "memory", "off":
+		return nil
+	}
+	return fmt.Errorf("journal_mode must be wal, delete, truncate, persist, memory or off")
+}
+
+func validateSynchronous(value interface{}) error {
+	switch strings.ToLower(fmt.Sprint(value)) {
+	case "", "off", "normal", "full", "extra":
+		return nil
+	}
+	return fmt.Errorf("synchronous must be off, normal, full or extra")
+}
+
+func init() {
+	config.Register("sqlite", config.Schema{
+		"path": config.Field{
+			Default:     "helper.db",
+			Required:    true,
+			Description: "Database file, created if missing, or :memory: for a database that lives as long as the process",
+		},
+		"journal_mode": config.Field{
+			Default:     "wal",
+			Required:    false,
+			Description: "Journal mode for file databases (wal, delete, truncate, persist, memory, off)",
+			Validator:   validateJournalMode,
+		},
+		"synchronous": config.Field{
+			Default:     "normal",
+			Required:    false,
+			Description: "How often SQLite syncs to disk (off, normal, full, extra)",
+			Validator:   validateSynchronous,
+		},
+		"busy_timeout": config.Field{
+			Default:     "5s",
+			Required:    false,
+			Description: "How long a statement waits for a lock held by another connection or process",
+		},
+		"max_connections": config.Field{
+			Default:     4,
+			Required:    false,
+			Description: "Maximum connections to a file database; in-memory databases always use one",
+		},
+		"pool_stats_interval": config.Field{
+			Default:     "15s",
+			Required:    false,
+			Description: "How often connection pool statistics are exported as metrics (0 disables)",
+		},
+		"slow_query_threshold": config.Field{
+			Default:     "1s",
+			Required:    false,
+			Description: "Log statements slower than this at WARN, redacted, with their caller, and count them in sqlite.slow_queries (0 disables)",
+		},
+		"query_guard": config.Field{
+			Default:     "off",
+			Required:    false,
+			Description: "Suspicious query guard mode (off, warn, reject)",
+			Validator:   data.ValidateGuardMode,
+		},
+		"query_guard_skip": config.Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "Query guard checks not to run: literal, stacked, comment, union, tautology (literal and comment also flag legitimate SQL)",
+			Validator:   data.ValidateGuardSkip,
+		},
+		"tx_max_retries": config.Field{
+			Default:     3,
+			Required:    false,
+			Description: "Times WithTx retries a transaction that found the database busy or locked",
+		},
+		"tx_retry_backoff": config.Field{
+			Default:     "50ms",
+			Required:    false,
+			Description: "Initial backoff between WithTx retries, doubled per attempt",
+		},
+	})
+
+	config.OnReload("sqlite", reconnectOnChange)
+	core.Register(&sqliteComponent{})
+	core.RegisterPreflight("sqlite", "path", func(ctx context.Context) error {
+		path := config.Get().GetString("sqlite", "path")
+		if path == Memory {
+			return nil
+		}
+		dir := filepath.Dir(path)
+		info, err := os.Stat(dir)
+		if err != nil {
+			return fmt.Errorf("database directory: %w", err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", dir)
+		}
+		return nil
+	})
+}
+
+// isRetryable reports errors from a database another connection or
+// process held locked past busy_timeout.
+func isRetryable(err error) bool {
+	var sqliteErr *sqlite.Error
+	if !errors.As(err, &sqliteErr) {
+		return false
+	}
+	code := sqliteErr.Code() & 0xff
+	return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+}
@@ -0,0 +1,300 @@
+// data/sqlite/sqlite.go
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+	_ "modernc.org/sqlite"
+)
+
+// Memory is the path that keeps the database in memory for the life of
+// the process.
+const Memory = ":memory:"
+
+// SQLite is a SQLStore over a single database file, or an in-memory
+// database, for tools that have no database server to talk to.
+type SQLite struct {
+	db           *sql.DB
+	config       data.StoreConfig
+	logger       core.Logger
+	interceptors data.InterceptorChain
+	slow         *data.SlowQueryLog
+	poolStats    *data.PoolStats
+}
+
+var instance *SQLite
+
+func Get() *SQLite {
+	return instance
+}
+
+func New(cfg data.StoreConfig) *SQLite {
+	return &SQLite{
+		config: cfg,
+		logger: core.GetLogger("sqlite"),
+		slow:   data.NewSlowQueryLog("sqlite", cfg.GetDuration("slow_query_threshold")),
+	}
+}
+
+func (s *SQLite) inMemory() bool {
+	return s.config.GetString("path") == Memory
+}
+
+// dsn builds the driver DSN, applying the pragmas to every connection the
+// pool opens.
+func (s *SQLite) dsn() string {
+	pragmas := url.Values{}
+	pragmas.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", s.config.GetDuration("busy_timeout").Milliseconds()))
+	pragmas.Add("_pragma", "foreign_keys(1)")
+	if s.inMemory() {
+		return "file::memory:?" + pragmas.Encode()
+	}
+	if mode := s.config.GetString("journal_mode"); mode != "" {
+		pragmas.Add("_pragma", "journal_mode("+mode+")")
+	}
+	if sync := s.config.GetString("synchronous"); sync != "" {
+		pragmas.Add("_pragma", "synchronous("+sync+")")
+	}
+	return "file:" + s.config.GetString("path") + "?" + pragmas.Encode()
+}
+
+func (s *SQLite) Connect(ctx context.Context) error {
+	var err error
+	s.db, err = sql.Open("sqlite", s.dsn())
+	if err != nil {
+		return err
+	}
+
+	if s.inMemory() {
+		// Each connection to :memory: opens a database of its own, so the
+		// pool holds exactly one and never lets it go
+		s.db.SetMaxOpenConns(1)
+		s.db.SetMaxIdleConns(1)
+		s.db.SetConnMaxLifetime(0)
+		s.db.SetConnMaxIdleTime(0)
+	} else {
+		s.db.SetMaxOpenConns(s.config.GetInt("max_connections"))
+		s.db.SetMaxIdleConns(s.config.GetInt("max_connections"))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := s.db.PingContext(ctx); err != nil {
+		s.db.Close()
+		return err
+	}
+	if err := s.ensureSchema(ctx); err != nil {
+		s.db.Close()
+		return err
+	}
+
+	s.poolStats = data.StartPoolStats("sqlite", s.config.GetDuration("pool_stats_interval"), func() map[string]*sql.DB {
+		return map[string]*sql.DB{"primary": s.db}
+	})
+
+	core.IncrCounter("sqlite.connections")
+	if s.inMemory() {
+		s.logger.Info("Opened in-memory SQLite database")
+	} else {
+		s.logger.Info("Opened SQLite database %s", s.config.GetString("path"))
+	}
+	return nil
+}
+
+// ensureSchema creates the kv table if the database does not have it yet.
+func (s *SQLite) ensureSchema(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS kv (
+		key TEXT NOT NULL PRIMARY KEY,
+		value TEXT NOT NULL
+	)`)
+	return err
+}
+
+func (s *SQLite) Use(interceptors ...data.QueryInterceptor) {
+	s.interceptors = append(s.interceptors, interceptors...)
+}
+
+func (s *SQLite) Close() error {
+	s.poolStats.Stop()
+	if s.db != nil {
+		return s.db.Close()
+	}
+	return nil
+}
+
+func (s *SQLite) Get(ctx context.Context, key string) (interface{}, error) {
+	defer core.TrackSpan(ctx, "store", "sqlite")()
+
+	start := time.Now()
+	var value string
+	err := s.db.QueryRowContext(ctx, "SELECT value FROM kv WHERE key = ?", key).Scan(&value)
+	s.recordKV("select", start, err)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return data.DecryptValue(key, value)
+}
+
+func (s *SQLite) Set(ctx context.Context, key string, value interface{}) error {
+	defer core.TrackSpan(ctx, "store", "sqlite")()
+
+	if err := data.CheckWritable(ctx, "sqlite"); err != nil {
+		return err
+	}
+
+	value, err := data.EncodeValue(value)
+	if err != nil {
+		return err
+	}
+	if err := data.ValidateValue(key, value); err != nil {
+		return err
+	}
+	value, err = data.EncryptValue(key, value)
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, err = s.db.ExecContext(ctx,
+		"INSERT INTO kv (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value",
+		key, value)
+	s.recordKV("insert", start, err)
+	return err
+}
+
+func (s *SQLite) Delete(ctx context.Context, key string) error {
+	defer core.TrackSpan(ctx, "store", "sqlite")()
+
+	if err := data.CheckWritable(ctx, "sqlite"); err != nil {
+		return err
+	}
+
+	start := time.Now()
+	_, err := s.db.ExecContext(ctx, "DELETE FROM kv WHERE key = ?", key)
+	s.recordKV("delete", start, err)
+	return err
+}
+
+func (s *SQLite) Exists(ctx context.Context, key string) (bool, error) {
+	defer core.TrackSpan(ctx, "store", "sqlite")()
+
+	start := time.Now()
+	var exists bool
+	err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM kv WHERE key = ?)", key).Scan(&exists)
+	s.recordKV("select", start, err)
+	return exists, err
+}
+
+func (s *SQLite) recordKV(op string, start time.Time, err error) {
+	labels := map[string]string{"table": "kv", "op": op}
+	core.RecordDurationWithLabels("sqlite.query", labels, start)
+	s.slow.Observe(context.Background(), "kv "+op, start)
+	if err != nil && err != sql.ErrNoRows {
+		core.IncrCounterWithLabels("sqlite.errors", labels)
+	}
+}
+
+func (s *SQLite) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer core.TrackSpan(ctx, "store", "sqlite")()
+
+	if err := s.interceptors.Run(ctx, query, args); err != nil {
+		core.IncrCounter("sqlite.errors")
+		return nil, err
+	}
+
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	core.RecordDuration("sqlite.query", start)
+	s.slow.Observe(ctx, query, start)
+	if err != nil {
+		core.IncrCounter("sqlite.errors")
+	}
+	return rows, err
+}
+
+func (s *SQLite) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer core.TrackSpan(ctx, "store", "sqlite")()
+
+	if err := s.interceptors.Run(ctx, query, args); err != nil {
+		core.IncrCounter("sqlite.errors")
+		return data.ErrRow(err)
+	}
+
+	start := time.Now()
+	row := s.db.QueryRowContext(ctx, query, args...)
+	core.RecordDuration("sqlite.query", start)
+	s.slow.Observe(ctx, query, start)
+	return row
+}
+
+func (s *SQLite) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer core.TrackSpan(ctx, "store", "sqlite")()
+
+	if err := data.CheckWritable(ctx, "sqlite"); err != nil {
+		return nil, err
+	}
+
+	if err := s.interceptors.Run(ctx, query, args); err != nil {
+		core.IncrCounter("sqlite.errors")
+		return nil, err
+	}
+
+	start := time.Now()
+	result, err := s.db.ExecContext(ctx, query, args...)
+	core.RecordDuration("sqlite.exec", start)
+	s.slow.Observe(ctx, query, start)
+	if err != nil {
+		core.IncrCounter("sqlite.errors")
+	}
+	return result, err
+}
+
+func (s *SQLite) Begin(ctx context.Context) (*sql.Tx, error) {
+	return s.db.BeginTx(ctx, nil)
+}
+
+// WithTx runs fn in a transaction, committing on success and rolling back
+// on error or panic. Transactions that found the database busy or locked
+// are retried up to tx_max_retries times.
+func (s *SQLite) WithTx(ctx context.Context, fn func(*sql.Tx) error) error {
+	defer core.TrackSpan(ctx, "store", "sqlite")()
+
+	if err := data.CheckWritable(ctx, "sqlite"); err != nil {
+		return err
+	}
+	return data.RunTx(ctx, s.Begin, data.TxPolicy{
+		Store:      "sqlite",
+		MaxRetries: s.config.GetInt("tx_max_retries"),
+		Backoff:    s.config.GetDuration("tx_retry_backoff"),
+		Retryable:  isRetryable,
+	}, fn)
+}
+
+func (s *SQLite) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return core.HealthUnhealthy, err
+	}
+
+	var result string
+	if err := s.db.QueryRowContext(ctx, "PRAGMA quick_check").Scan(&result); err != nil {
+		return core.HealthDegraded, err
+	}
+	if !strings.EqualFold(result, "ok") {
+		return core.HealthUnhealthy, fmt.Errorf("integrity check: %s", result)
+	}
+	return core.HealthHealthy, nil
+}
@@ -0,0 +1,66 @@
+// data/tx.go
+package data
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/resilience"
+)
+
+// TxPolicy controls how RunTx retries transactions that fail with a
+// transient conflict such as a deadlock or serialization failure.
+type TxPolicy struct {
+	Store      string
+	MaxRetries int
+	Backoff    time.Duration
+	Retryable  func(error) bool
+}
+
+// RunTx begins a transaction, runs fn, and commits if fn returns nil. The
+// transaction is rolled back if fn returns an error or panics; panics are
+// re-raised after the rollback. When the policy marks an error retryable,
+// the whole transaction is run again after an exponential backoff, so fn
+// must not have side effects outside tx.
+func RunTx(ctx context.Context, begin func(context.Context) (*sql.Tx, error), policy TxPolicy, fn func(*sql.Tx) error) error {
+	retryable := policy.Retryable
+	if retryable == nil {
+		retryable = func(error) bool { return false }
+	}
+	return resilience.Retry(ctx, resilience.RetryPolicy{
+		MaxAttempts: policy.MaxRetries + 1,
+		Backoff:     policy.Backoff,
+		// Full jitter keeps conflicting writers from retrying in lockstep
+		Jitter:    resilience.FullJitter,
+		Retryable: retryable,
+		OnRetry: func(int, error, time.Duration) {
+			core.IncrCounterWithLabels("data.tx_retries", map[string]string{"store": policy.Store})
+		},
+	}, func(ctx context.Context) error {
+		return runTxOnce(ctx, begin, fn)
+	})
+}
+
+func runTxOnce(ctx context.Context, begin func(context.Context) (*sql.Tx, error), fn func(*sql.Tx) error) (err error) {
+	tx, err := begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,93 @@
+// data/validate.go
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// ValueValidator checks a value before a store writes it under key.
+type ValueValidator func(key string, value interface{}) error
+
+var ErrInvalidValue = errors.New("invalid value")
+
+type prefixValidator struct {
+	prefix    string
+	validator ValueValidator
+}
+
+var (
+	validatorsMu sync.RWMutex
+	validators   []prefixValidator
+)
+
+// RegisterValidator runs v on every Set of a key starting with prefix. All
+// matching validators run, longest prefix first.
+func RegisterValidator(prefix string, v ValueValidator) {
+	validatorsMu.Lock()
+	defer validatorsMu.Unlock()
+
+	validators = append(validators, prefixValidator{prefix: prefix, validator: v})
+	sort.SliceStable(validators, func(i, j int) bool {
+		return len(validators[i].prefix) > len(validators[j].prefix)
+	})
+}
+
+// ValidateValue is called by stores before writing. Failures wrap
+// ErrInvalidValue and name the key.
+func ValidateValue(key string, value interface{}) error {
+	validatorsMu.RLock()
+	defer validatorsMu.RUnlock()
+
+	for _, v := range validators {
+		if !strings.HasPrefix(key, v.prefix) {
+			continue
+		}
+		if err := v.validator(key, value); err != nil {
+			core.IncrCounterWithLabels("data.validation_rejected", map[string]string{"prefix": v.prefix})
+			return fmt.Errorf("%w for %s: %v", ErrInvalidValue, key, err)
+		}
+	}
+	return nil
+}
+
+// JSONValidator requires the value to be a well-formed JSON document.
+func JSONValidator() ValueValidator {
+	return func(key string, value interface{}) error {
+		if !json.Valid(valueBytes(value)) {
+			return fmt.Errorf("not valid JSON")
+		}
+		return nil
+	}
+}
+
+// MaxSizeValidator rejects values larger than max bytes.
+func MaxSizeValidator(max int) ValueValidator {
+	return func(key string, value interface{}) error {
+		if n := len(valueBytes(value)); n > max {
+			return fmt.Errorf("%d bytes exceeds limit of %d", n, max)
+		}
+		return nil
+	}
+}
+
+func valueBytes(value interface{}) []byte {
+	switch v := value.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	case nil:
+		return nil
+	}
+	if b, err := json.Marshal(value); err == nil {
+		return b
+	}
+	return []byte(fmt.Sprintf("%v", value))
+}
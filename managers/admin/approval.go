@@ -0,0 +1,279 @@
+// managers/admin/approval.go
+package admin
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// ActionFunc performs a sensitive admin action once it has collected
+// enough approvals.
+type ActionFunc func(ctx context.Context, params map[string]interface{}) error
+
+// PendingAction is an action request waiting for operator approvals.
+// Operators approve by signing ApprovalMessage(id, action) with their
+// ed25519 key.
+type PendingAction struct {
+	ID        string                 `json:"id"`
+	Action    string                 `json:"action"`
+	Params    map[string]interface{} `json:"params,omitempty"`
+	Requested time.Time              `json:"requested"`
+	Expires   time.Time              `json:"expires"`
+	Approvals []string               `json:"approvals"`
+	Required  int                    `json:"required"`
+	// input is what the action runs with; Params, shown to operators, may
+	// have secrets masked
+	input map[string]interface{}
+}
+
+// snapshot copies p so it can be encoded after the lock is released.
+func (p *PendingAction) snapshot() *PendingAction {
+	c := *p
+	c.Approvals = append([]string{}, p.Approvals...)
+	return &c
+}
+
+var (
+	ErrUnknownAction   = errors.New("unknown admin action")
+	ErrUnknownRequest  = errors.New("unknown or expired action request")
+	ErrUnknownOperator = errors.New("unknown operator")
+	ErrBadApproval     = errors.New("approval signature is invalid")
+	ErrNoOperators     = errors.New("no operators configured for approvals")
+)
+
+type approvals struct {
+	mu        sync.Mutex
+	actions   map[string]ActionFunc
+	pending   map[string]*PendingAction
+	operators map[string]ed25519.PublicKey
+	required  int
+	window    time.Duration
+	webhooks  []string
+}
+
+var workflow = &approvals{
+	actions: make(map[string]ActionFunc),
+	pending: make(map[string]*PendingAction),
+}
+
+// RegisterAction makes fn available at POST /actions/{name}. It only runs
+// after the configured number of distinct operators have approved.
+func RegisterAction(name string, fn ActionFunc) {
+	workflow.mu.Lock()
+	defer workflow.mu.Unlock()
+	workflow.actions[name] = fn
+}
+
+func ApprovalMessage(id, action string) []byte {
+	return []byte("approve:" + id + ":" + action)
+}
+
+func (a *approvals) configure(operators map[string]ed25519.PublicKey, required int, window time.Duration, webhooks []string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.operators = operators
+	a.required = required
+	a.window = window
+	a.webhooks = webhooks
+}
+
+// request opens an action request that runs with input once approved.
+// Operators are shown params instead.
+func (a *approvals) request(ctx context.Context, action string, input, params map[string]interface{}) (*PendingAction, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, ok := a.actions[action]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAction, action)
+	}
+	if len(a.operators) == 0 || a.required < 1 {
+		return nil, ErrNoOperators
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("generating request ID: %w", err)
+	}
+	now := time.Now()
+	p := &PendingAction{
+		ID:        hex.EncodeToString(id),
+		Action:    action,
+		Params:    params,
+		Requested: now,
+		Expires:   now.Add(a.window),
+		Approvals: []string{},
+		Required:  a.required,
+		input:     input,
+	}
+	a.pending[p.ID] = p
+
+	audit(ctx).With(map[string]interface{}{"id": p.ID, "action": action}).Info("Admin action requested")
+	notify(a.webhooks, ActionNotice{Event: TopicActionRequested, Request: p.snapshot()})
+	return p, nil
+}
+
+// approve records a signed approval and runs the action when the quorum
+// is reached. It reports whether the action ran.
+func (a *approvals) approve(ctx context.Context, id, operator string, signature []byte) (*PendingAction, bool, error) {
+	a.mu.Lock()
+	a.expire()
+
+	p, ok := a.pending[id]
+	if !ok {
+		a.mu.Unlock()
+		return nil, false, ErrUnknownRequest
+	}
+	key, ok := a.operators[operator]
+	if !ok {
+		a.mu.Unlock()
+		return nil, false, fmt.Errorf("%w: %s", ErrUnknownOperator, operator)
+	}
+	if !ed25519.Verify(key, ApprovalMessage(p.ID, p.Action), signature) {
+		a.mu.Unlock()
+		return nil, false, ErrBadApproval
+	}
+
+	for _, existing := range p.Approvals {
+		if existing == operator {
+			a.mu.Unlock()
+			return p.snapshot(), false, nil
+		}
+	}
+	p.Approvals = append(p.Approvals, operator)
+	audit(ctx).With(map[string]interface{}{"id": p.ID, "action": p.Action, "operator": operator}).Info("Admin action approved")
+	notify(a.webhooks, ActionNotice{Event: TopicActionApproved, Request: p.snapshot(), Operator: operator})
+
+	if len(p.Approvals) < p.Required {
+		a.mu.Unlock()
+		return p.snapshot(), false, nil
+	}
+
+	delete(a.pending, id)
+	fn := a.actions[p.Action]
+	webhooks := a.webhooks
+	a.mu.Unlock()
+
+	// The action outlives the approving request
+	err := fn(context.WithoutCancel(ctx), p.input)
+	fields := map[string]interface{}{"id": p.ID, "action": p.Action, "approvals": p.Approvals}
+	if err != nil {
+		fields["error"] = err.Error()
+		audit(ctx).With(fields).Error("Admin action failed")
+		notify(webhooks, ActionNotice{Event: TopicActionFailed, Request: p, Error: err.Error()})
+		return p, true, err
+	}
+	audit(ctx).With(fields).Info("Admin action executed")
+	notify(webhooks, ActionNotice{Event: TopicActionExecuted, Request: p})
+	return p, true, nil
+}
+
+// expire drops requests whose window has closed. Called with mu held.
+func (a *approvals) expire() {
+	now := time.Now()
+	for id, p := range a.pending {
+		if now.After(p.Expires) {
+			delete(a.pending, id)
+			audit(context.Background()).With(map[string]interface{}{"id": id, "action": p.Action, "approvals": p.Approvals}).Warn("Admin action request expired")
+			notify(a.webhooks, ActionNotice{Event: TopicActionExpired, Request: p})
+		}
+	}
+}
+
+func (a *approvals) list() []*PendingAction {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.expire()
+
+	result := make([]*PendingAction, 0, len(a.pending))
+	for _, p := range a.pending {
+		result = append(result, p.snapshot())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Requested.Before(result[j].Requested) })
+	return result
+}
+
+// audit returns the audit logger, tagged with the correlation ID of the
+// request on ctx.
+func audit(ctx context.Context) core.Logger {
+	return core.ContextLogger(ctx, core.GetLogger("audit"))
+}
+
+func parseOperators(raw map[string]interface{}) (map[string]ed25519.PublicKey, error) {
+	operators := make(map[string]ed25519.PublicKey, len(raw))
+	for name, v := range raw {
+		encoded, _ := v.(string)
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("admin.operators: invalid key for %s", name)
+		}
+		operators[name] = ed25519.PublicKey(key)
+	}
+	return operators, nil
+}
+
+func (s *Server) handleActionRequest(w http.ResponseWriter, r *http.Request) {
+	var params map[string]interface{}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+	}
+
+	p, err := workflow.request(r.Context(), r.PathValue("name"), params, params)
+	if err != nil {
+		WriteJSON(w, requestStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	WriteJSON(w, http.StatusAccepted, p)
+}
+
+// requestStatus is the HTTP status for an error opening an action request.
+func requestStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrUnknownAction):
+		return http.StatusNotFound
+	case errors.Is(err, ErrNoOperators):
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
+
+func (s *Server) handleApprove(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Operator  string `json:"operator"`
+		Signature []byte `json:"signature"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	p, executed, err := workflow.approve(r.Context(), r.PathValue("id"), body.Operator, body.Signature)
+	switch {
+	case errors.Is(err, ErrUnknownRequest):
+		WriteJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+	case errors.Is(err, ErrUnknownOperator), errors.Is(err, ErrBadApproval):
+		WriteJSON(w, http.StatusForbidden, map[string]string{"error": err.Error()})
+	case err != nil:
+		WriteJSON(w, http.StatusInternalServerError, map[string]interface{}{"request": p, "executed": executed, "error": err.Error()})
+	default:
+		WriteJSON(w, http.StatusOK, map[string]interface{}{"request": p, "executed": executed})
+	}
+}
+
+func (s *Server) handleApprovals(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, workflow.list())
+}
@@ -0,0 +1,150 @@
+// managers/admin/events.go
+package admin
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+const (
+	sseHeartbeat       = 15 * time.Second
+	maxLongPollTimeout = 60 * time.Second
+)
+
+// handleEventStream streams events as Server-Sent Events. Clients resume
+// with the Last-Event-ID header or ?since=<seq>; ?topics= takes a comma
+// separated list of topic filters.
+func (s *Server) handleEventStream(w http.ResponseWriter, r *http.Request) {
+	if !authorizeEvents(r) {
+		WriteJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	filters := topicFilters(r)
+	since, resume := resumeSeq(r)
+
+	// Subscribe before reading the backlog so nothing falls in between
+	sub := core.SubscribeEvents(filters, 256)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	last := since
+	if resume {
+		backlog, complete := core.EventsSince(since, filters)
+		if !complete {
+			fmt.Fprint(w, "event: gap\ndata: {}\n\n")
+		}
+		for _, e := range backlog {
+			writeSSE(w, e)
+			last = e.Seq
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case e, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if e.Seq <= last {
+				continue
+			}
+			writeSSE(w, e)
+			last = e.Seq
+			flusher.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": keepalive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// handleEventPoll long-polls for events after ?since=<seq>, waiting up to
+// ?timeout= (default 30s, at most 60s).
+func (s *Server) handleEventPoll(w http.ResponseWriter, r *http.Request) {
+	if !authorizeEvents(r) {
+		WriteJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+
+	timeout := 30 * time.Second
+	if t := r.URL.Query().Get("timeout"); t != "" {
+		d, err := time.ParseDuration(t)
+		if err != nil {
+			WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid timeout"})
+			return
+		}
+		timeout = min(d, maxLongPollTimeout)
+	}
+
+	since, resume := resumeSeq(r)
+	if !resume {
+		// Without a cursor, only wait for new events
+		since = core.LastEventSeq()
+	}
+
+	result, complete := core.WaitEvents(since, topicFilters(r), timeout)
+	if result == nil {
+		result = []core.Event{}
+	}
+	next := since
+	if len(result) > 0 {
+		next = result[len(result)-1].Seq
+	}
+
+	WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"events": result,
+		"next":   next,
+		"gap":    !complete,
+	})
+}
+
+func writeSSE(w http.ResponseWriter, e core.Event) {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.Seq, e.Topic, payload)
+}
+
+func topicFilters(r *http.Request) []string {
+	var filters []string
+	for _, t := range strings.Split(r.URL.Query().Get("topics"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			filters = append(filters, t)
+		}
+	}
+	return filters
+}
+
+// resumeSeq returns the client's cursor and whether it sent one.
+func resumeSeq(r *http.Request) (uint64, bool) {
+	value := r.Header.Get("Last-Event-ID")
+	if value == "" {
+		value = r.URL.Query().Get("since")
+	}
+	seq, err := strconv.ParseUint(value, 10, 64)
+	return seq, err == nil
+}
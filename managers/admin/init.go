@@ -0,0 +1,141 @@
+// managers/admin/init.go
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/polkadot-go/helper"
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+)
+
+type adminComponent struct{}
+
+func (c *adminComponent) Name() string {
+	return "admin"
+}
+
+func (c *adminComponent) Dependencies() []string {
+	return []string{"config", "logger", "ratelimit"}
+}
+
+func (c *adminComponent) Init() error {
+	cfg := config.Get()
+
+	address := cfg.GetString("admin", "address")
+	if address == "" {
+		return nil
+	}
+
+	operators, _ := cfg.Get("admin", "operators").(map[string]interface{})
+	keys, err := parseOperators(operators)
+	if err != nil {
+		return err
+	}
+	workflow.configure(keys, cfg.GetInt("admin", "approvals_required"), cfg.GetDuration("admin", "approval_window"),
+		stringList(cfg.Get("admin", "approval_webhooks")))
+
+	configureEventAccess(stringList(cfg.Get("admin", "event_tokens")), stringList(cfg.Get("admin", "event_origins")),
+		float64(cfg.GetInt("admin", "ws_rate_limit")), cfg.GetInt("admin", "ws_burst"))
+
+	return NewServer(address, cfg.GetBool("admin", "enable_pprof")).Start()
+}
+
+// stringList returns the non-empty strings of a config list.
+func stringList(value interface{}) []string {
+	var result []string
+	raw, _ := value.([]interface{})
+	for _, v := range raw {
+		if s, ok := v.(string); ok && s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func (c *adminComponent) Shutdown(ctx context.Context) error {
+	if instance != nil {
+		return instance.Stop(ctx)
+	}
+	return nil
+}
+
+func init() {
+	config.Register("admin", config.Schema{
+		"address": config.Field{
+			Default:     "127.0.0.1:8080",
+			Required:    false,
+			Description: "Bind address for the admin server (empty disables it)",
+		},
+		"enable_pprof": config.Field{
+			Default:     false,
+			Required:    false,
+			Description: "Expose /debug/pprof endpoints, which reveal the command line and memory contents, on the admin server",
+		},
+		"operators": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Operators allowed to approve sensitive actions, mapping names to base64 ed25519 public keys",
+		},
+		"approvals_required": config.Field{
+			Default:     2,
+			Required:    false,
+			Description: "Distinct operator approvals needed before a sensitive action runs",
+		},
+		"approval_window": config.Field{
+			Default:     "15m",
+			Required:    false,
+			Description: "Time an action request stays open for approvals",
+		},
+		"approval_webhooks": config.Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "URLs that each admin.action_* event is POSTed to as JSON, e.g. a chat webhook that alerts operators",
+			Secret:      true,
+		},
+		"event_tokens": config.Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "Bearer tokens accepted by /events, /events/poll and /events/ws (empty leaves them open)",
+			Secret:      true,
+		},
+		"event_origins": config.Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "Browser origins, besides the admin server's own, allowed to open /events/ws (e.g. https://ops.example.com)",
+		},
+		"ws_rate_limit": config.Field{
+			Default:     10,
+			Required:    false,
+			Description: "Client messages per second allowed on each /events/ws connection (0 disables the limit)",
+		},
+		"ws_burst": config.Field{
+			Default:     20,
+			Required:    false,
+			Description: "Burst of client messages allowed on each /events/ws connection",
+		},
+	})
+
+	RegisterAction("component.restart", func(ctx context.Context, params map[string]interface{}) error {
+		name, _ := params["name"].(string)
+		if name == "" {
+			return fmt.Errorf("component.restart needs a name parameter")
+		}
+		return core.Restart(ctx, name)
+	})
+	RegisterAction("config.patch", applyConfigPatch)
+	RegisterAction("config.clear_overrides", clearOverrides)
+	RegisterAction("data.restore", restoreData)
+	RegisterAction("data.set_read_only", setReadOnly)
+	RegisterAction("data.undelete", undeleteData)
+}
+
+// Register adds the admin server to app. Routes and actions other packages
+// add are served once it runs.
+func Register(app *helper.App) {
+	app.RegisterComponent(&adminComponent{})
+	core.RegisterPreflight("admin", "port_free", func(ctx context.Context) error {
+		return core.CheckPortFree(config.Get().GetString("admin", "address"))
+	})
+}
@@ -0,0 +1,84 @@
+// managers/admin/notify.go
+package admin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// Topics published as action requests move through the approval workflow.
+// GET /events and /events/ws stream them to operators.
+const (
+	TopicActionRequested = "admin.action_requested"
+	TopicActionApproved  = "admin.action_approved"
+	TopicActionExecuted  = "admin.action_executed"
+	TopicActionFailed    = "admin.action_failed"
+	TopicActionExpired   = "admin.action_expired"
+)
+
+// ActionNotice is the payload of the admin.action_* events and of the
+// requests sent to admin.approval_webhooks.
+type ActionNotice struct {
+	Event    string         `json:"event"`
+	Request  *PendingAction `json:"request"`
+	Operator string         `json:"operator,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+var webhookClient = &http.Client{Timeout: 10 * time.Second}
+
+// notify publishes n and posts it to each webhook in the background, so a
+// slow or unreachable webhook never holds up an approval.
+func notify(webhooks []string, n ActionNotice) {
+	core.PublishEvent(n.Event, n)
+
+	if len(webhooks) == 0 {
+		return
+	}
+	body, err := json.Marshal(n)
+	if err != nil {
+		core.GetLogger("admin").Error("Encoding %s notice: %v", n.Event, err)
+		return
+	}
+	for _, target := range webhooks {
+		go func(target string) {
+			if err := postWebhook(target, body); err != nil {
+				// Webhook URLs often carry a token, so they are left out of logs
+				var uerr *url.Error
+				if errors.As(err, &uerr) {
+					err = uerr.Err
+				}
+				core.IncrCounterWithLabels("admin.webhook_errors", map[string]string{"event": n.Event})
+				core.GetLogger("admin").Warn("Sending %s notice for %s: %v", n.Event, n.Request.ID, err)
+			}
+		}(target)
+	}
+}
+
+func postWebhook(target string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookClient.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
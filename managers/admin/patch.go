@@ -0,0 +1,72 @@
+// managers/admin/patch.go
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/polkadot-go/helper/core/config"
+)
+
+// handleConfigPatch requests a JSON merge patch of the running config. A
+// patch can change anything, including admin.operators, so it goes through
+// the same operator approvals as other sensitive actions and is applied by
+// the config.patch action. It is ephemeral unless ?persist=true, in which
+// case the config file is rewritten with it.
+func (s *Server) handleConfigPatch(w http.ResponseWriter, r *http.Request) {
+	persist, _ := strconv.ParseBool(r.URL.Query().Get("persist"))
+
+	var patch map[string]interface{}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&patch); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "body must be a JSON merge patch object"})
+		return
+	}
+	if err := config.Get().CheckPatch(patch); err != nil {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	p, err := workflow.request(r.Context(), "config.patch",
+		map[string]interface{}{"patch": patch, "persist": persist},
+		map[string]interface{}{"patch": config.Get().RedactPatch(patch), "persist": persist})
+	if err != nil {
+		WriteJSON(w, requestStatus(err), map[string]string{"error": err.Error()})
+		return
+	}
+	WriteJSON(w, http.StatusAccepted, p)
+}
+
+// applyConfigPatch is the config.patch action.
+func applyConfigPatch(ctx context.Context, params map[string]interface{}) error {
+	patch, _ := params["patch"].(map[string]interface{})
+	persist, _ := params["persist"].(bool)
+
+	result, err := config.Get().ApplyPatch(patch, persist)
+	if err != nil {
+		return err
+	}
+	audit(ctx).With(map[string]interface{}{
+		"sections":  result.Changed,
+		"persisted": persist,
+	}).Info("Config patched")
+	return nil
+}
+
+func (s *Server) handleConfigOverrides(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, config.Get().Overrides())
+}
+
+// clearOverrides is the config.clear_overrides action, reverting every
+// ephemeral patch. Those patches were approved, so reverting them needs
+// approval too.
+func clearOverrides(ctx context.Context, params map[string]interface{}) error {
+	// Overrides masks secrets, so the audit entry holds none
+	cleared := config.Get().Overrides()
+	if err := config.Get().ClearOverrides(); err != nil {
+		return err
+	}
+	audit(ctx).With(map[string]interface{}{"overrides": cleared}).Info("Config overrides cleared")
+	return nil
+}
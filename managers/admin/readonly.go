@@ -0,0 +1,29 @@
+// managers/admin/readonly.go
+package admin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/polkadot-go/helper/data"
+)
+
+func (s *Server) handleReadOnly(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, data.GetReadOnlyState())
+}
+
+// setReadOnly is the data.set_read_only action, switching one store, or
+// every store with "*", in or out of read-only mode until the next restart
+// or config reload of the data section.
+func setReadOnly(ctx context.Context, params map[string]interface{}) error {
+	store, _ := params["store"].(string)
+	on, ok := params["read_only"].(bool)
+	if store == "" || !ok {
+		return fmt.Errorf("store and read_only parameters are required")
+	}
+
+	data.SetReadOnly(store, on)
+	audit(ctx).With(map[string]interface{}{"store": store, "read_only": on}).Warn("Read-only switched")
+	return nil
+}
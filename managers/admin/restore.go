@@ -0,0 +1,75 @@
+// managers/admin/restore.go
+package admin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+// recoverableStore looks up the store owned by the component named in
+// params["store"].
+func recoverableStore(params map[string]interface{}) (string, data.RecoverableStore, error) {
+	name, _ := params["store"].(string)
+	if name == "" {
+		return "", nil, fmt.Errorf("a store parameter is required")
+	}
+	if !core.IsInitialized(name) {
+		return name, nil, fmt.Errorf("store %s is not initialized", name)
+	}
+	provider, ok := core.GetComponent(name).(data.StoreProvider)
+	if !ok || provider.Store() == nil {
+		return name, nil, fmt.Errorf("%s does not provide a store", name)
+	}
+	store, ok := provider.Store().(data.RecoverableStore)
+	if !ok {
+		return name, nil, fmt.Errorf("store %s does not support recovery", name)
+	}
+	return name, store, nil
+}
+
+// restoreData is the data.restore action. It returns one key, or with no
+// key every key changed since, to its state at an RFC 3339 time.
+func restoreData(ctx context.Context, params map[string]interface{}) error {
+	name, store, err := recoverableStore(params)
+	if err != nil {
+		return err
+	}
+	raw, _ := params["at"].(string)
+	at, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return fmt.Errorf("at must be an RFC 3339 time")
+	}
+
+	if key, _ := params["key"].(string); key != "" {
+		if err := store.RestoreKey(ctx, key, at); err != nil {
+			return err
+		}
+		audit(ctx).With(map[string]interface{}{"store": name, "key": key, "at": raw}).Info("Key restored")
+		return nil
+	}
+
+	n, err := store.RestoreAt(ctx, at)
+	audit(ctx).With(map[string]interface{}{"store": name, "at": raw, "keys": n}).Info("Store restored")
+	return err
+}
+
+// undeleteData is the data.undelete action, reviving a soft-deleted key.
+func undeleteData(ctx context.Context, params map[string]interface{}) error {
+	name, store, err := recoverableStore(params)
+	if err != nil {
+		return err
+	}
+	key, _ := params["key"].(string)
+	if key == "" {
+		return fmt.Errorf("a key parameter is required")
+	}
+	if err := store.Undelete(ctx, key); err != nil {
+		return err
+	}
+	audit(ctx).With(map[string]interface{}{"store": name, "key": key}).Info("Key undeleted")
+	return nil
+}
@@ -0,0 +1,273 @@
+// managers/admin/server.go
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/core/resilience/ratelimit"
+)
+
+type Server struct {
+	address string
+	mux     *http.ServeMux
+	server  *http.Server
+	logger  core.Logger
+}
+
+type route struct {
+	pattern string
+	handler http.Handler
+}
+
+var (
+	instance *Server
+	routesMu sync.Mutex
+	routes   []route
+)
+
+func Get() *Server {
+	return instance
+}
+
+// Handle adds an endpoint to the admin server. Routes registered before the
+// server starts are mounted when it does, so components can call Handle
+// from their own Init regardless of initialization order.
+func Handle(pattern string, handler http.Handler) {
+	routesMu.Lock()
+	defer routesMu.Unlock()
+
+	routes = append(routes, route{pattern: pattern, handler: handler})
+	if instance != nil {
+		instance.mux.Handle(pattern, handler)
+	}
+}
+
+func HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	Handle(pattern, http.HandlerFunc(handler))
+}
+
+func NewServer(address string, enablePprof bool) *Server {
+	s := &Server{
+		address: address,
+		mux:     http.NewServeMux(),
+		logger:  core.GetLogger("admin"),
+	}
+	// Streaming handlers run until their request context ends, so cancel
+	// it when shutdown begins instead of waiting out the deadline.
+	baseCtx, cancel := context.WithCancel(context.Background())
+	s.server = &http.Server{
+		Handler:           s.correlate(s.limit(s.mux)),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return baseCtx },
+	}
+	s.server.RegisterOnShutdown(cancel)
+
+	s.mux.HandleFunc("/healthz", s.handleHealthz)
+	s.mux.HandleFunc("/readyz", s.handleReadyz)
+	s.mux.Handle("/metrics", core.MetricsHandler())
+	s.mux.HandleFunc("/config", s.handleConfig)
+	s.mux.HandleFunc("/config/usage", s.handleConfigUsage)
+	s.mux.HandleFunc("PATCH /config", s.handleConfigPatch)
+	s.mux.HandleFunc("GET /config/overrides", s.handleConfigOverrides)
+	s.mux.HandleFunc("/components", s.handleComponents)
+	s.mux.HandleFunc("GET /preflight", s.handlePreflight)
+	s.mux.HandleFunc("GET /data/read-only", s.handleReadOnly)
+	s.mux.HandleFunc("GET /events", s.handleEventStream)
+	s.mux.HandleFunc("GET /events/poll", s.handleEventPoll)
+	s.mux.HandleFunc("GET /events/ws", s.handleEventSocket)
+	s.mux.HandleFunc("POST /actions/{name}", s.handleActionRequest)
+	s.mux.HandleFunc("GET /approvals", s.handleApprovals)
+	s.mux.HandleFunc("POST /approvals/{id}", s.handleApprove)
+
+	if enablePprof {
+		s.mux.HandleFunc("/debug/pprof/", pprof.Index)
+		s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		s.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		s.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		s.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+
+	return s
+}
+
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+
+	routesMu.Lock()
+	for _, r := range routes {
+		s.mux.Handle(r.pattern, r.handler)
+	}
+	instance = s
+	routesMu.Unlock()
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Admin server failed: %v", err)
+		}
+	}()
+
+	s.logger.Info("Admin server listening on %s", ln.Addr())
+	return nil
+}
+
+// correlate tags each request with a correlation ID, reusing a well-formed
+// one from the caller, and puts a logger carrying it on the request context.
+func (s *Server) correlate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(core.CorrelationHeader)
+		if !validCorrelationID(id) {
+			id = core.NewCorrelationID()
+		}
+		w.Header().Set(core.CorrelationHeader, id)
+
+		ctx := core.WithCorrelationID(r.Context(), id)
+		ctx = core.ContextWithLogger(ctx, s.logger)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// limit applies the "admin" rate limiter, keyed by client address, when
+// ratelimit.limiters configures one.
+func (s *Server) limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limiter := ratelimit.Get("admin")
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if !limiter.Allow(host) {
+			retry := max(limiter.RetryAfter(host), time.Second)
+			w.Header().Set("Retry-After", strconv.Itoa(int(retry.Round(time.Second)/time.Second)))
+			WriteJSON(w, http.StatusTooManyRequests, map[string]string{"error": "rate limit exceeded"})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.' || c == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// Handler serves the admin endpoints, for listeners other than the admin
+// server's own.
+func (s *Server) Handler() http.Handler {
+	return s.server.Handler
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
+
+type healthEntry struct {
+	Status      string            `json:"status"`
+	Error       string            `json:"error,omitempty"`
+	Time        time.Time         `json:"time"`
+	Annotations []core.Annotation `json:"annotations,omitempty"`
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	writeHealth(w, "alive", healthResults(ctx, core.LivenessCheck))
+}
+
+func (s *Server) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	writeHealth(w, "ready", healthResults(ctx, core.ReadinessCheck))
+}
+
+// healthResults serves cached results when the background monitor runs and
+// falls back to checking synchronously otherwise.
+func healthResults(ctx context.Context, kind core.HealthCheckKind) map[string]core.HealthResult {
+	if monitor := core.GetHealthMonitor(); monitor != nil {
+		return core.FilterHealthResults(monitor.LastResults(), kind)
+	}
+	if kind == core.LivenessCheck {
+		return core.CheckLiveness(ctx)
+	}
+	return core.CheckReadiness(ctx)
+}
+
+func writeHealth(w http.ResponseWriter, field string, results map[string]core.HealthResult) {
+	status := http.StatusOK
+	checks := make(map[string]healthEntry, len(results))
+	for name, result := range results {
+		if result.Status == core.HealthUnhealthy || result.Status == core.HealthUnknown {
+			status = http.StatusServiceUnavailable
+		}
+		entry := toHealthEntry(result)
+		entry.Annotations = core.AnnotationsFor("component", name)
+		checks[name] = entry
+	}
+
+	WriteJSON(w, status, map[string]interface{}{
+		field:    status == http.StatusOK,
+		"checks": checks,
+	})
+}
+
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, config.Get().Redacted())
+}
+
+func (s *Server) handleConfigUsage(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, config.Get().Usage())
+}
+
+func (s *Server) handleComponents(w http.ResponseWriter, r *http.Request) {
+	WriteJSON(w, http.StatusOK, core.GetComponentStatus())
+}
+
+func toHealthEntry(result core.HealthResult) healthEntry {
+	entry := healthEntry{
+		Status: result.Status.String(),
+		Time:   result.Time,
+	}
+	if result.Error != nil {
+		entry.Error = result.Error.Error()
+	}
+	return entry
+}
+
+func (s *Server) handlePreflight(w http.ResponseWriter, r *http.Request) {
+	report := core.LastPreflight()
+	if report == nil {
+		report = &core.PreflightReport{Passed: true}
+	}
+	WriteJSON(w, http.StatusOK, report)
+}
+
+// WriteJSON writes v as a JSON response with status.
+func WriteJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,268 @@
+// managers/admin/socket.go
+package admin
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/resilience/ratelimit"
+)
+
+// eventAccess holds the bearer tokens the event endpoints accept, the
+// browser origins allowed to open WebSockets and the per-connection limit
+// on WebSocket client messages.
+var eventAccess struct {
+	mu      sync.RWMutex
+	tokens  []string
+	origins []string
+	rate    float64
+	burst   int
+}
+
+func configureEventAccess(tokens, origins []string, rate float64, burst int) {
+	eventAccess.mu.Lock()
+	defer eventAccess.mu.Unlock()
+	eventAccess.tokens = tokens
+	eventAccess.origins = origins
+	eventAccess.rate = rate
+	eventAccess.burst = burst
+}
+
+// allowOrigin guards against cross-site WebSocket hijacking: browsers send
+// cookies and reach local addresses on behalf of any page, and do not
+// apply CORS to WebSockets. Clients that send no Origin are not browsers
+// and are allowed; browsers must come from the admin server's own origin
+// or one listed in admin.event_origins.
+func allowOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+
+	eventAccess.mu.RLock()
+	defer eventAccess.mu.RUnlock()
+	for _, allowed := range eventAccess.origins {
+		if strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeEvents accepts "Authorization: Bearer <token>" or, for browser
+// WebSocket clients that cannot set headers, ?access_token=. With no
+// tokens configured the endpoints are open.
+func authorizeEvents(r *http.Request) bool {
+	eventAccess.mu.RLock()
+	tokens := eventAccess.tokens
+	eventAccess.mu.RUnlock()
+	if len(tokens) == 0 {
+		return true
+	}
+
+	presented, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		presented = r.URL.Query().Get("access_token")
+	}
+	if presented == "" {
+		return false
+	}
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(presented)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+var activeSockets atomic.Int64
+
+// socketCommand is a client message. "subscribe" replaces the topic
+// filters; with since set, retained events after that sequence are
+// replayed first.
+type socketCommand struct {
+	Type   string   `json:"type"`
+	Topics []string `json:"topics"`
+	Since  *uint64  `json:"since"`
+}
+
+type socketMessage struct {
+	Type  string      `json:"type"`
+	Seq   uint64      `json:"seq,omitempty"`
+	Event *core.Event `json:"event,omitempty"`
+	Error string      `json:"error,omitempty"`
+}
+
+// handleEventSocket serves events over WebSocket. Events are sent in
+// sequence order and never skipped silently: if the history no longer
+// holds events a client missed, it gets a "gap" message. Clients that
+// reconnect with since set to the last sequence they processed receive
+// every event at least once.
+func (s *Server) handleEventSocket(w http.ResponseWriter, r *http.Request) {
+	if !authorizeEvents(r) {
+		WriteJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return
+	}
+	if !allowOrigin(r) {
+		core.IncrCounter("admin.ws_origin_rejected")
+		WriteJSON(w, http.StatusForbidden, map[string]string{"error": "origin not allowed"})
+		return
+	}
+
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer ws.close(closeNormal, "")
+
+	core.IncrCounter("admin.ws_connections")
+	core.SetGauge("admin.ws_active", activeSockets.Add(1))
+	defer func() { core.SetGauge("admin.ws_active", activeSockets.Add(-1)) }()
+
+	eventAccess.mu.RLock()
+	limiter := ratelimit.NewBucket(eventAccess.rate, eventAccess.burst)
+	eventAccess.mu.RUnlock()
+
+	done := make(chan struct{})
+	defer close(done)
+	commands := make(chan socketCommand)
+	readerDone := make(chan struct{})
+
+	go func() {
+		defer close(readerDone)
+		for {
+			msg, err := ws.readMessage()
+			if err != nil {
+				return
+			}
+			if !limiter.Allow() {
+				core.IncrCounter("admin.ws_rate_limited")
+				ws.close(closePolicyViolation, "rate limit exceeded")
+				return
+			}
+
+			var cmd socketCommand
+			if err := json.Unmarshal(msg, &cmd); err != nil {
+				ws.WriteJSON(socketMessage{Type: "error", Error: "invalid command: " + err.Error()})
+				continue
+			}
+			select {
+			case commands <- cmd:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var (
+		sub     *core.EventSubscription
+		stream  <-chan core.Event
+		filters []string
+		last    uint64
+		dropped uint64
+	)
+	defer func() {
+		if sub != nil {
+			sub.Close()
+		}
+	}()
+
+	// catchUp sends retained events after last, for replays and after the
+	// subscription dropped events.
+	catchUp := func() error {
+		backlog, complete := core.EventsSince(last, filters)
+		if !complete {
+			if err := ws.WriteJSON(socketMessage{Type: "gap", Seq: last}); err != nil {
+				return err
+			}
+		}
+		for i := range backlog {
+			if err := ws.WriteJSON(socketMessage{Type: "event", Seq: backlog[i].Seq, Event: &backlog[i]}); err != nil {
+				return err
+			}
+			last = backlog[i].Seq
+		}
+		return nil
+	}
+
+	ping := time.NewTicker(sseHeartbeat)
+	defer ping.Stop()
+
+	for {
+		select {
+		case cmd := <-commands:
+			switch cmd.Type {
+			case "subscribe":
+				if sub != nil {
+					sub.Close()
+				}
+				filters = cmd.Topics
+				sub = core.SubscribeEvents(filters, 256)
+				stream = sub.C
+				dropped = 0
+
+				if cmd.Since != nil {
+					last = *cmd.Since
+				} else {
+					last = core.LastEventSeq()
+				}
+				if err := ws.WriteJSON(socketMessage{Type: "subscribed", Seq: last}); err != nil {
+					return
+				}
+				if cmd.Since != nil {
+					if err := catchUp(); err != nil {
+						return
+					}
+				}
+			case "unsubscribe":
+				if sub != nil {
+					sub.Close()
+					sub, stream = nil, nil
+				}
+			default:
+				ws.WriteJSON(socketMessage{Type: "error", Error: "unknown command " + cmd.Type})
+			}
+
+		case e, ok := <-stream:
+			if !ok {
+				stream = nil
+				continue
+			}
+			if e.Seq <= last {
+				continue
+			}
+			if n := sub.Dropped(); n != dropped {
+				dropped = n
+				if err := catchUp(); err != nil {
+					return
+				}
+				continue
+			}
+			if err := ws.WriteJSON(socketMessage{Type: "event", Seq: e.Seq, Event: &e}); err != nil {
+				return
+			}
+			last = e.Seq
+
+		case <-ping.C:
+			if err := ws.writeFrame(opPing, nil); err != nil {
+				return
+			}
+
+		case <-readerDone:
+			return
+		case <-r.Context().Done():
+			ws.close(closeGoingAway, "server shutting down")
+			return
+		}
+	}
+}
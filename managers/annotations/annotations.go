@@ -0,0 +1,199 @@
+// managers/annotations/annotations.go
+package annotations
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+const maxNoteLength = 1024
+
+var ErrUnknownAnnotation = errors.New("unknown annotation")
+
+// Book holds operator annotations. With a store they are kept as one JSON
+// document under a single key, shared by every instance using that store;
+// without one they last until the process exits.
+type Book struct {
+	mu      sync.Mutex
+	all     []core.Annotation
+	resolve func() (data.Store, error)
+	key     string
+	logger  core.Logger
+}
+
+var instance = New(nil, "")
+
+func Get() *Book {
+	return instance
+}
+
+// New returns a book persisted under key in the store resolve returns, or
+// kept in memory only when resolve is nil.
+func New(resolve func() (data.Store, error), key string) *Book {
+	return &Book{
+		resolve: resolve,
+		key:     key,
+		logger:  core.GetLogger("annotations"),
+	}
+}
+
+// Load replaces the annotations with those in the store, picking up
+// changes made by other instances.
+func (b *Book) Load(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.load(ctx)
+}
+
+// Add stores a new annotation and returns it with its ID and creation time
+// filled in.
+func (b *Book) Add(ctx context.Context, a core.Annotation) (core.Annotation, error) {
+	if a.Kind == "" || a.Name == "" || a.Note == "" {
+		return a, fmt.Errorf("annotations need a kind, a name and a note")
+	}
+	if len(a.Note) > maxNoteLength {
+		return a, fmt.Errorf("note is longer than %d bytes", maxNoteLength)
+	}
+	id := make([]byte, 8)
+	rand.Read(id)
+	a.ID = hex.EncodeToString(id)
+	a.Created = time.Now().UTC()
+	if !a.Expires.IsZero() && a.Expired(a.Created) {
+		return a, fmt.Errorf("expiry is in the past")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Re-read first so annotations other instances added are kept
+	if err := b.load(ctx); err != nil {
+		return a, err
+	}
+	if err := b.save(ctx, append(b.all, a)); err != nil {
+		return a, err
+	}
+	core.PublishEvent("annotations.added", a)
+	return a, nil
+}
+
+func (b *Book) Remove(ctx context.Context, id string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.load(ctx); err != nil {
+		return err
+	}
+	kept := make([]core.Annotation, 0, len(b.all))
+	var removed *core.Annotation
+	for i, a := range b.all {
+		if a.ID == id {
+			removed = &b.all[i]
+			continue
+		}
+		kept = append(kept, a)
+	}
+	if removed == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownAnnotation, id)
+	}
+	if err := b.save(ctx, kept); err != nil {
+		return err
+	}
+	core.PublishEvent("annotations.removed", *removed)
+	return nil
+}
+
+// List returns the unexpired annotations on kind/name, oldest first. An
+// empty kind or name matches any.
+func (b *Book) List(kind, name string) []core.Annotation {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	result := []core.Annotation{}
+	for _, a := range b.all {
+		if (kind == "" || a.Kind == kind) && (name == "" || a.Name == name) && !a.Expired(now) {
+			result = append(result, a)
+		}
+	}
+	return result
+}
+
+// load must be called with mu held.
+func (b *Book) load(ctx context.Context) error {
+	if b.resolve == nil {
+		return nil
+	}
+	store, err := b.resolve()
+	if err != nil {
+		return err
+	}
+	v, err := store.Get(ctx, b.key)
+	if err != nil {
+		return fmt.Errorf("reading annotations: %w", err)
+	}
+
+	var raw []byte
+	switch val := v.(type) {
+	case nil:
+	case string:
+		raw = []byte(val)
+	case []byte:
+		raw = val
+	default:
+		return fmt.Errorf("annotations key %s holds %T, not JSON", b.key, v)
+	}
+
+	var all []core.Annotation
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &all); err != nil {
+			return fmt.Errorf("decoding annotations: %w", err)
+		}
+	}
+	b.set(all)
+	return nil
+}
+
+// save writes all, dropping expired annotations, and must be called with
+// mu held.
+func (b *Book) save(ctx context.Context, all []core.Annotation) error {
+	now := time.Now()
+	kept := make([]core.Annotation, 0, len(all))
+	for _, a := range all {
+		if !a.Expired(now) {
+			kept = append(kept, a)
+		}
+	}
+
+	if b.resolve != nil {
+		store, err := b.resolve()
+		if err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(kept)
+		if err != nil {
+			return err
+		}
+		if err := store.Set(ctx, b.key, string(encoded)); err != nil {
+			return fmt.Errorf("writing annotations: %w", err)
+		}
+	}
+	b.set(kept)
+	return nil
+}
+
+func (b *Book) set(all []core.Annotation) {
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Created.Before(all[j].Created) })
+	b.all = all
+	core.SetAnnotations(all)
+	core.SetGauge("annotations.count", int64(len(all)))
+}
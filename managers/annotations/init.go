@@ -0,0 +1,140 @@
+// managers/annotations/init.go
+package annotations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/polkadot-go/helper"
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data"
+	"github.com/polkadot-go/helper/managers/admin"
+	"github.com/polkadot-go/helper/managers/scheduler"
+)
+
+type annotationsComponent struct{}
+
+func (c *annotationsComponent) Name() string {
+	return "annotations"
+}
+
+func (c *annotationsComponent) Dependencies() []string {
+	return []string{"config", "logger", "scheduler"}
+}
+
+func (c *annotationsComponent) Init() error {
+	cfg := config.Get()
+
+	storeName := cfg.GetString("annotations", "store")
+	if storeName == "" {
+		return nil
+	}
+
+	// The store's component may initialize after this one, so it is looked
+	// up on every read and write.
+	resolve := func() (data.Store, error) {
+		if !core.IsInitialized(storeName) {
+			return nil, fmt.Errorf("annotations store %s is not initialized", storeName)
+		}
+		provider, ok := core.GetComponent(storeName).(data.StoreProvider)
+		if !ok || provider.Store() == nil {
+			return nil, fmt.Errorf("annotations store %s does not provide a store", storeName)
+		}
+		return provider.Store(), nil
+	}
+	instance = New(resolve, cfg.GetString("annotations", "key"))
+
+	return scheduler.Get().Register("annotations_refresh", scheduler.JobOptions{
+		Interval:   cfg.GetDuration("annotations", "refresh_interval"),
+		Timeout:    30 * time.Second,
+		RunOnStart: true,
+	}, instance.Load)
+}
+
+func (c *annotationsComponent) Shutdown(ctx context.Context) error {
+	scheduler.Get().Remove("annotations_refresh")
+	return nil
+}
+
+func handleList(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	admin.WriteJSON(w, http.StatusOK, instance.List(q.Get("kind"), q.Get("name")))
+}
+
+// handleAdd takes an annotation with either an absolute expires time or a
+// ttl duration, or neither to keep it until it is removed.
+func handleAdd(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		core.Annotation
+		TTL string `json:"ttl"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		admin.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	a := body.Annotation
+	if body.TTL != "" {
+		ttl, err := time.ParseDuration(body.TTL)
+		if err != nil || ttl <= 0 || !a.Expires.IsZero() {
+			admin.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "ttl must be a positive duration and excludes expires"})
+			return
+		}
+		a.Expires = time.Now().Add(ttl).UTC()
+	}
+
+	a, err := instance.Add(r.Context(), a)
+	if err != nil {
+		admin.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	core.LoggerFromContext(r.Context()).Info("Annotation %s added on %s/%s by %q: %s", a.ID, a.Kind, a.Name, a.Author, a.Note)
+	admin.WriteJSON(w, http.StatusCreated, a)
+}
+
+func handleRemove(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := instance.Remove(r.Context(), id); err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrUnknownAnnotation) {
+			status = http.StatusNotFound
+		}
+		admin.WriteJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	core.LoggerFromContext(r.Context()).Info("Annotation %s removed", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func init() {
+	config.Register("annotations", config.Schema{
+		"store": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Component name of the store annotations are kept in (empty keeps them in memory only)",
+		},
+		"key": config.Field{
+			Default:     "helper:annotations",
+			Required:    false,
+			Description: "Store key holding the annotations",
+		},
+		"refresh_interval": config.Field{
+			Default:     "1m",
+			Required:    false,
+			Description: "How often annotations written by other instances are picked up",
+		},
+	})
+}
+
+// Register adds the annotations component and its admin routes to app.
+func Register(app *helper.App) {
+	app.RegisterComponent(&annotationsComponent{})
+
+	admin.HandleFunc("GET /annotations", handleList)
+	admin.HandleFunc("POST /annotations", handleAdd)
+	admin.HandleFunc("DELETE /annotations/{id}", handleRemove)
+}
@@ -0,0 +1,230 @@
+// managers/fleet/fleet.go
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// Instance is the last known health of one peer helper, as reported by its
+// admin /readyz endpoint.
+type Instance struct {
+	Name       string               `json:"name"`
+	URL        string               `json:"url"`
+	Reachable  bool                 `json:"reachable"`
+	Status     string               `json:"status"`
+	Error      string               `json:"error,omitempty"`
+	CheckedAt  time.Time            `json:"checked_at"`
+	Components map[string]Component `json:"components"`
+}
+
+type Component struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// View is the aggregated fleet health. Components counts instances per
+// status for each component name across the fleet.
+type View struct {
+	Status     string                    `json:"status"`
+	Instances  []Instance                `json:"instances"`
+	Components map[string]map[string]int `json:"components"`
+}
+
+type Aggregator struct {
+	peers    map[string]string
+	interval time.Duration
+	client   *http.Client
+	logger   core.Logger
+
+	mu        sync.RWMutex
+	instances map[string]Instance
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+var instance *Aggregator
+
+func Get() *Aggregator {
+	return instance
+}
+
+// NewAggregator polls peers, a map of instance name to admin base URL such
+// as "http://10.0.0.5:8080".
+func NewAggregator(peers map[string]string, interval, timeout time.Duration) *Aggregator {
+	instances := make(map[string]Instance, len(peers))
+	for name, url := range peers {
+		instances[name] = Instance{Name: name, URL: url, Status: core.HealthUnknown.String()}
+	}
+	return &Aggregator{
+		peers:     peers,
+		interval:  interval,
+		client:    &http.Client{Timeout: timeout},
+		logger:    core.GetLogger("fleet"),
+		instances: instances,
+	}
+}
+
+func (a *Aggregator) Start() {
+	a.stop = make(chan struct{})
+	a.wg.Add(1)
+	go func() {
+		defer a.wg.Done()
+		core.Supervise("fleet", a.stop, func() {
+			a.pollAll()
+
+			ticker := time.NewTicker(a.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+					a.pollAll()
+				case <-a.stop:
+					return
+				}
+			}
+		})
+	}()
+}
+
+func (a *Aggregator) Stop() {
+	if a.stop != nil {
+		close(a.stop)
+		a.wg.Wait()
+	}
+}
+
+func (a *Aggregator) pollAll() {
+	var wg sync.WaitGroup
+	for name, url := range a.peers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a.record(a.poll(name, url))
+		}()
+	}
+	wg.Wait()
+
+	unhealthy := 0
+	for _, inst := range a.View().Instances {
+		if inst.Status != core.HealthHealthy.String() {
+			unhealthy++
+		}
+	}
+	core.SetGauge("fleet.instances_unhealthy", int64(unhealthy))
+}
+
+func (a *Aggregator) poll(name, url string) Instance {
+	inst := Instance{Name: name, URL: url, CheckedAt: time.Now(), Components: map[string]Component{}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/readyz", nil)
+	if err != nil {
+		inst.Status = core.HealthUnknown.String()
+		inst.Error = err.Error()
+		return inst
+	}
+
+	start := time.Now()
+	resp, err := a.client.Do(req)
+	core.RecordDurationWithLabels("fleet.poll", map[string]string{"instance": name}, start)
+	if err != nil {
+		inst.Status = core.HealthUnhealthy.String()
+		inst.Error = err.Error()
+		return inst
+	}
+	defer resp.Body.Close()
+
+	// /readyz answers 503 with a normal body when a check fails
+	var body struct {
+		Checks map[string]Component `json:"checks"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		inst.Status = core.HealthUnhealthy.String()
+		inst.Error = fmt.Sprintf("invalid /readyz response (HTTP %d): %v", resp.StatusCode, err)
+		return inst
+	}
+
+	inst.Reachable = true
+	inst.Components = body.Checks
+	inst.Status = worstStatus(body.Checks)
+	return inst
+}
+
+func (a *Aggregator) record(inst Instance) {
+	a.mu.Lock()
+	previous := a.instances[inst.Name]
+	a.instances[inst.Name] = inst
+	a.mu.Unlock()
+
+	if previous.Status != inst.Status {
+		a.logger.With(map[string]interface{}{
+			"instance": inst.Name,
+			"previous": previous.Status,
+			"status":   inst.Status,
+		}).Info("Fleet instance health changed")
+		core.PublishEvent("fleet.changed", map[string]interface{}{
+			"instance": inst.Name,
+			"previous": previous.Status,
+			"status":   inst.Status,
+			"error":    inst.Error,
+		})
+	}
+}
+
+// View returns the current aggregate. The fleet status is the worst
+// instance status.
+func (a *Aggregator) View() View {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	view := View{Instances: make([]Instance, 0, len(a.instances)), Components: map[string]map[string]int{}}
+	statuses := make(map[string]Component, len(a.instances))
+	for name, inst := range a.instances {
+		view.Instances = append(view.Instances, inst)
+		statuses[name] = Component{Status: inst.Status}
+		for component, c := range inst.Components {
+			if view.Components[component] == nil {
+				view.Components[component] = map[string]int{}
+			}
+			view.Components[component][c.Status]++
+		}
+	}
+	sort.Slice(view.Instances, func(i, j int) bool { return view.Instances[i].Name < view.Instances[j].Name })
+	view.Status = worstStatus(statuses)
+	return view
+}
+
+var severity = map[string]int{
+	core.HealthHealthy.String():   0,
+	core.HealthDegraded.String():  1,
+	core.HealthUnknown.String():   2,
+	core.HealthUnhealthy.String(): 3,
+}
+
+func worstStatus(components map[string]Component) string {
+	worst := core.HealthHealthy.String()
+	for _, c := range components {
+		rank, ok := severity[c.Status]
+		if !ok {
+			rank = severity[core.HealthUnknown.String()]
+		}
+		if rank > severity[worst] {
+			worst = c.Status
+			if !ok {
+				worst = core.HealthUnknown.String()
+			}
+		}
+	}
+	return worst
+}
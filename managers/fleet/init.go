@@ -0,0 +1,122 @@
+// managers/fleet/init.go
+package fleet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/polkadot-go/helper"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/managers/admin"
+	"github.com/polkadot-go/helper/managers/peer"
+)
+
+type fleetComponent struct{}
+
+func (c *fleetComponent) Name() string {
+	return "fleet"
+}
+
+func (c *fleetComponent) Dependencies() []string {
+	return []string{"config", "logger", "peer"}
+}
+
+func (c *fleetComponent) Init() error {
+	cfg := config.Get()
+
+	raw, _ := cfg.Get("fleet", "peers").(map[string]interface{})
+	if len(raw) == 0 {
+		return nil
+	}
+
+	peers := make(map[string]string, len(raw))
+	for name, v := range raw {
+		url, ok := v.(string)
+		if !ok || !(strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://")) {
+			return fmt.Errorf("fleet.peers.%s must be an http(s) URL", name)
+		}
+		peers[name] = strings.TrimSuffix(url, "/")
+	}
+
+	timeout := cfg.GetDuration("fleet", "timeout")
+	instance = NewAggregator(peers, cfg.GetDuration("fleet", "interval"), timeout)
+	// https peers are polled over the authenticated peer channel
+	if channel := peer.Get(); channel != nil {
+		instance.client = channel.HTTPClient(timeout)
+	}
+	instance.Start()
+	return nil
+}
+
+func (c *fleetComponent) Shutdown(ctx context.Context) error {
+	if instance != nil {
+		instance.Stop()
+	}
+	return nil
+}
+
+var statusPage = template.Must(template.New("fleet").Parse(`<!DOCTYPE html>
+<html><head><title>Fleet status: {{.Status}}</title><meta http-equiv="refresh" content="30"></head>
+<body>
+<h1>Fleet status: {{.Status}}</h1>
+<table border="1" cellpadding="4">
+<tr><th>Instance</th><th>Status</th><th>Checked</th><th>Components</th></tr>
+{{range .Instances}}<tr>
+<td><a href="{{.URL}}/readyz">{{.Name}}</a></td>
+<td>{{.Status}}{{if .Error}}: {{.Error}}{{end}}</td>
+<td>{{.CheckedAt.Format "2006-01-02 15:04:05Z07:00"}}</td>
+<td>{{range $name, $c := .Components}}{{$name}}={{$c.Status}} {{end}}</td>
+</tr>{{end}}
+</table>
+</body></html>
+`))
+
+// handleFleet serves the aggregate as JSON, or as an HTML status page for
+// ?format=html.
+func handleFleet(w http.ResponseWriter, r *http.Request) {
+	if instance == nil {
+		http.Error(w, "no fleet peers configured", http.StatusNotFound)
+		return
+	}
+
+	view := instance.View()
+	if r.URL.Query().Get("format") == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		statusPage.Execute(w, view)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(view)
+}
+
+func init() {
+	config.Register("fleet", config.Schema{
+		"peers": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Peer helpers to aggregate, mapping instance names to admin base URLs, or https peer listener URLs when peer.key is set (empty disables fleet polling)",
+		},
+		"interval": config.Field{
+			Default:     "30s",
+			Required:    false,
+			Description: "How often to poll peer /readyz endpoints",
+		},
+		"timeout": config.Field{
+			Default:     "5s",
+			Required:    false,
+			Description: "Timeout for each peer poll",
+		},
+	})
+}
+
+// Register adds the fleet component and its admin route to app.
+func Register(app *helper.App) {
+	app.RegisterComponent(&fleetComponent{})
+
+	admin.HandleFunc("GET /fleet", handleFleet)
+}
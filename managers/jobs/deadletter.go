@@ -0,0 +1,111 @@
+// managers/jobs/deadletter.go
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/polkadot-go/helper/data"
+)
+
+// DeadLetters keeps tasks that exhausted their retries in a store, as one
+// JSON list per queue under prefix plus the queue name. Only the newest
+// limit tasks of a queue are kept. Updates read and write the whole list,
+// so instances dead-lettering to the same queue at once can lose entries.
+type DeadLetters struct {
+	mu      sync.Mutex
+	resolve func() (data.Store, error)
+	prefix  string
+	limit   int
+}
+
+func NewDeadLetters(resolve func() (data.Store, error), prefix string, limit int) *DeadLetters {
+	return &DeadLetters{resolve: resolve, prefix: prefix, limit: limit}
+}
+
+func (d *DeadLetters) Add(ctx context.Context, t *Task) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tasks, err := d.read(ctx, t.Queue)
+	if err != nil {
+		return err
+	}
+	tasks = append(tasks, *t)
+	if d.limit > 0 && len(tasks) > d.limit {
+		tasks = tasks[len(tasks)-d.limit:]
+	}
+	return d.write(ctx, t.Queue, tasks)
+}
+
+// List returns a queue's dead-lettered tasks, oldest first.
+func (d *DeadLetters) List(ctx context.Context, queue string) ([]Task, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.read(ctx, queue)
+}
+
+// Take removes and returns a queue's dead-lettered tasks.
+func (d *DeadLetters) Take(ctx context.Context, queue string) ([]Task, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tasks, err := d.read(ctx, queue)
+	if err != nil || len(tasks) == 0 {
+		return tasks, err
+	}
+	store, err := d.resolve()
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Delete(ctx, d.prefix+queue); err != nil {
+		return nil, fmt.Errorf("clearing dead letters of %s: %w", queue, err)
+	}
+	return tasks, nil
+}
+
+func (d *DeadLetters) read(ctx context.Context, queue string) ([]Task, error) {
+	store, err := d.resolve()
+	if err != nil {
+		return nil, err
+	}
+	v, err := store.Get(ctx, d.prefix+queue)
+	if err != nil {
+		return nil, fmt.Errorf("reading dead letters of %s: %w", queue, err)
+	}
+
+	var raw []byte
+	switch val := v.(type) {
+	case nil:
+		return []Task{}, nil
+	case string:
+		raw = []byte(val)
+	case []byte:
+		raw = val
+	default:
+		return nil, fmt.Errorf("dead letters of %s hold %T, not JSON", queue, v)
+	}
+
+	var tasks []Task
+	if err := json.Unmarshal(raw, &tasks); err != nil {
+		return nil, fmt.Errorf("decoding dead letters of %s: %w", queue, err)
+	}
+	return tasks, nil
+}
+
+func (d *DeadLetters) write(ctx context.Context, queue string, tasks []Task) error {
+	store, err := d.resolve()
+	if err != nil {
+		return err
+	}
+	encoded, err := json.Marshal(tasks)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(ctx, d.prefix+queue, string(encoded)); err != nil {
+		return fmt.Errorf("writing dead letters of %s: %w", queue, err)
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+// managers/jobs/init.go
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data"
+	"github.com/polkadot-go/helper/managers/admin"
+	"github.com/polkadot-go/helper/managers/maintenance"
+)
+
+type jobsComponent struct{}
+
+func (c *jobsComponent) Name() string {
+	return "jobs"
+}
+
+func (c *jobsComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *jobsComponent) Init() error {
+	cfg := config.Get()
+
+	raw, _ := cfg.Get("jobs", "queues").(map[string]interface{})
+	overrides := make(map[string]func(*QueueOptions), len(raw))
+	for name, v := range raw {
+		spec, ok := v.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("jobs.queues.%s must be an object", name)
+		}
+		override, err := parseOverride(spec)
+		if err != nil {
+			return fmt.Errorf("jobs.queues.%s: %w", name, err)
+		}
+		overrides[name] = override
+	}
+	instance.SetOverrides(overrides)
+
+	if storeName := cfg.GetString("jobs", "dead_letter_store"); storeName != "" {
+		// The store's component may initialize after this one, so it is
+		// looked up when a task is dead-lettered.
+		resolve := func() (data.Store, error) {
+			if !core.IsInitialized(storeName) {
+				return nil, fmt.Errorf("dead-letter store %s is not initialized", storeName)
+			}
+			provider, ok := core.GetComponent(storeName).(data.StoreProvider)
+			if !ok || provider.Store() == nil {
+				return nil, fmt.Errorf("dead-letter store %s does not provide a store", storeName)
+			}
+			return provider.Store(), nil
+		}
+		instance.SetDeadLetters(NewDeadLetters(resolve, cfg.GetString("jobs", "dead_letter_prefix"), cfg.GetInt("jobs", "dead_letter_limit")))
+	}
+
+	core.RegisterHealthCheck("jobs", instance)
+	return nil
+}
+
+func (c *jobsComponent) Shutdown(ctx context.Context) error {
+	return instance.Stop(ctx)
+}
+
+// parseOverride reads one entry of jobs.queues.
+func parseOverride(spec map[string]interface{}) (func(*QueueOptions), error) {
+	var steps []func(*QueueOptions)
+	for key, v := range spec {
+		switch key {
+		case "workers", "capacity", "max_attempts":
+			n, ok := v.(float64)
+			if !ok || n < 1 || n != float64(int(n)) {
+				return nil, fmt.Errorf("%s must be a positive integer", key)
+			}
+			steps = append(steps, func(o *QueueOptions) {
+				switch key {
+				case "workers":
+					o.Workers = int(n)
+				case "capacity":
+					o.Capacity = int(n)
+				case "max_attempts":
+					o.Retry.MaxAttempts = int(n)
+				}
+			})
+		case "timeout", "backoff", "max_backoff":
+			s, _ := v.(string)
+			d, err := time.ParseDuration(s)
+			if err != nil || d < 0 {
+				return nil, fmt.Errorf("%s must be a duration", key)
+			}
+			steps = append(steps, func(o *QueueOptions) {
+				switch key {
+				case "timeout":
+					o.Timeout = d
+				case "backoff":
+					o.Retry.Backoff = d
+				case "max_backoff":
+					o.Retry.MaxBackoff = d
+				}
+			})
+		default:
+			return nil, fmt.Errorf("unknown option %q", key)
+		}
+	}
+	return func(o *QueueOptions) {
+		for _, step := range steps {
+			step(o)
+		}
+	}, nil
+}
+
+func handleQueues(w http.ResponseWriter, r *http.Request) {
+	admin.WriteJSON(w, http.StatusOK, instance.Stats())
+}
+
+func handleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	tasks, err := instance.DeadLetters(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, tasks)
+}
+
+func handleRequeue(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := maintenance.Get().Check("jobs.requeue"); err != nil {
+		writeError(w, err)
+		return
+	}
+	n, err := instance.Requeue(r.Context(), name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	core.LoggerFromContext(r.Context()).Info("Requeued %d dead-lettered tasks on %s", n, name)
+	admin.WriteJSON(w, http.StatusOK, map[string]int{"requeued": n})
+}
+
+func handleClearDeadLetters(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := maintenance.Get().Check("jobs.clear_dead_letters"); err != nil {
+		writeError(w, err)
+		return
+	}
+	n, err := instance.ClearDeadLetters(r.Context(), name)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	core.LoggerFromContext(r.Context()).Info("Discarded %d dead-lettered tasks on %s", n, name)
+	admin.WriteJSON(w, http.StatusOK, map[string]int{"discarded": n})
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrUnknownQueue):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrNoDeadLetters), errors.Is(err, maintenance.ErrOutsideWindow):
+		status = http.StatusConflict
+	}
+	admin.WriteJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+func init() {
+	config.Register("jobs", config.Schema{
+		"queues": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Option overrides by queue name: workers, capacity, timeout, max_attempts, backoff, max_backoff",
+		},
+		"dead_letter_store": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Component name of the store tasks that exhausted their retries are kept in (empty drops them)",
+		},
+		"dead_letter_prefix": config.Field{
+			Default:     "jobs:dead:",
+			Required:    false,
+			Description: "Key prefix of each queue's dead-letter list",
+		},
+		"dead_letter_limit": config.Field{
+			Default:     1000,
+			Required:    false,
+			Description: "Dead-lettered tasks kept per queue; the oldest are discarded first",
+		},
+	})
+
+	core.Register(&jobsComponent{})
+
+	admin.HandleFunc("GET /jobs/queues", handleQueues)
+	admin.HandleFunc("GET /jobs/queues/{name}/dead", handleDeadLetters)
+	admin.HandleFunc("POST /jobs/queues/{name}/dead/requeue", handleRequeue)
+	admin.HandleFunc("DELETE /jobs/queues/{name}/dead", handleClearDeadLetters)
+}
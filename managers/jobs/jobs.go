@@ -0,0 +1,196 @@
+// managers/jobs/jobs.go
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+var (
+	ErrDuplicateQueue = errors.New("queue already exists")
+	ErrUnknownQueue   = errors.New("unknown queue")
+	ErrNoDeadLetters  = errors.New("no dead-letter store configured")
+)
+
+// Manager owns the named queues. Components create their queues on it from
+// their own Init, with "jobs" among their dependencies.
+type Manager struct {
+	mu          sync.Mutex
+	queues      map[string]*Queue
+	overrides   map[string]func(*QueueOptions)
+	deadLetters *DeadLetters
+}
+
+var instance = New()
+
+func Get() *Manager {
+	return instance
+}
+
+func New() *Manager {
+	return &Manager{queues: make(map[string]*Queue)}
+}
+
+// NewQueue creates a queue and starts its workers. Overrides set for the
+// name apply on top of opts.
+func (m *Manager) NewQueue(name string, opts QueueOptions, handler Handler) (*Queue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.queues[name]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateQueue, name)
+	}
+	if override, ok := m.overrides[name]; ok {
+		override(&opts)
+	}
+
+	var deadLetter func(context.Context, *Task) error
+	if m.deadLetters != nil {
+		deadLetter = m.deadLetters.Add
+	}
+	q := newQueue(name, opts.withDefaults(), handler, deadLetter)
+	m.queues[name] = q
+	return q, nil
+}
+
+func (m *Manager) Queue(name string) *Queue {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.queues[name]
+}
+
+// SetOverrides replaces the per-queue option overrides. They apply to
+// queues created afterwards.
+func (m *Manager) SetOverrides(overrides map[string]func(*QueueOptions)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.overrides = overrides
+}
+
+// SetDeadLetters sets where queues created afterwards put tasks that
+// exhausted their retries; nil drops them.
+func (m *Manager) SetDeadLetters(d *DeadLetters) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deadLetters = d
+}
+
+// Stats lists every queue by name.
+func (m *Manager) Stats() []QueueStats {
+	m.mu.Lock()
+	queues := make([]*Queue, 0, len(m.queues))
+	for _, q := range m.queues {
+		queues = append(queues, q)
+	}
+	m.mu.Unlock()
+
+	result := make([]QueueStats, 0, len(queues))
+	for _, q := range queues {
+		result = append(result, q.Stats())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// DeadLetters lists the tasks a queue dead-lettered, oldest first.
+func (m *Manager) DeadLetters(ctx context.Context, name string) ([]Task, error) {
+	m.mu.Lock()
+	d := m.deadLetters
+	m.mu.Unlock()
+	if d == nil {
+		return nil, ErrNoDeadLetters
+	}
+	return d.List(ctx, name)
+}
+
+// Requeue moves a queue's dead-lettered tasks back onto it, each with a
+// fresh set of attempts, and returns how many were requeued. Tasks that
+// cannot be requeued are dead-lettered again.
+func (m *Manager) Requeue(ctx context.Context, name string) (int, error) {
+	m.mu.Lock()
+	q, d := m.queues[name], m.deadLetters
+	m.mu.Unlock()
+	if q == nil {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownQueue, name)
+	}
+	if d == nil {
+		return 0, ErrNoDeadLetters
+	}
+
+	tasks, err := d.Take(ctx, name)
+	if err != nil {
+		return 0, err
+	}
+	for i, t := range tasks {
+		if err := q.requeue(ctx, t); err != nil {
+			for _, rest := range tasks[i:] {
+				if addErr := d.Add(context.Background(), &rest); addErr != nil {
+					err = errors.Join(err, addErr)
+				}
+			}
+			return i, err
+		}
+	}
+	return len(tasks), nil
+}
+
+// ClearDeadLetters discards a queue's dead-lettered tasks and returns how
+// many there were.
+func (m *Manager) ClearDeadLetters(ctx context.Context, name string) (int, error) {
+	m.mu.Lock()
+	d := m.deadLetters
+	m.mu.Unlock()
+	if d == nil {
+		return 0, ErrNoDeadLetters
+	}
+	tasks, err := d.Take(ctx, name)
+	return len(tasks), err
+}
+
+// Stop stops every queue, each draining until ctx is done, and forgets
+// them so that components can create them again after a restart.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	queues := m.queues
+	m.queues = make(map[string]*Queue)
+	m.mu.Unlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, q := range queues {
+		wg.Add(1)
+		go func(q *Queue) {
+			defer wg.Done()
+			if err := q.Stop(ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(q)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// HealthCheck reports degraded while any queue is full.
+func (m *Manager) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
+	var full []string
+	for _, s := range m.Stats() {
+		if s.Depth >= s.Capacity {
+			full = append(full, s.Name)
+		}
+	}
+	if len(full) > 0 {
+		return core.HealthDegraded, fmt.Errorf("queues full: %s", strings.Join(full, ", "))
+	}
+	return core.HealthHealthy, nil
+}
@@ -0,0 +1,456 @@
+// managers/jobs/queue.go
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/resilience"
+)
+
+var (
+	ErrQueueFull   = errors.New("queue is full")
+	ErrQueueClosed = errors.New("queue is closed")
+)
+
+// Task is one unit of work on a queue. Its payload is kept as JSON so that
+// a task that keeps failing can be dead-lettered to a store and requeued
+// later, possibly by another instance.
+type Task struct {
+	ID        string          `json:"id"`
+	Queue     string          `json:"queue"`
+	Payload   json.RawMessage `json:"payload"`
+	Attempts  int             `json:"attempts"`
+	Enqueued  time.Time       `json:"enqueued"`
+	LastError string          `json:"last_error,omitempty"`
+	Failed    time.Time       `json:"failed,omitzero"`
+
+	// ready is when the task last became runnable, for the wait metric
+	ready time.Time
+}
+
+// Decode unmarshals the task's payload into v.
+func (t *Task) Decode(v interface{}) error {
+	return json.Unmarshal(t.Payload, v)
+}
+
+// Handler processes a task. ctx ends at the queue's timeout or when
+// shutdown gives up waiting. Returning an error retries the task according
+// to the queue's policy unless the error is wrapped with Permanent.
+type Handler func(ctx context.Context, t *Task) error
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying; the task is dead-lettered
+// straight away.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// RetryPolicy controls how failed tasks are retried. The wait before each
+// retry doubles from Backoff up to MaxBackoff, with jitter so that tasks
+// failing together do not retry in lockstep.
+type RetryPolicy struct {
+	// MaxAttempts counts the first attempt; 1 disables retries
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	return resilience.RetryPolicy{
+		Backoff:    p.Backoff,
+		MaxBackoff: p.MaxBackoff,
+		Jitter:     resilience.EqualJitter,
+	}.Delay(attempt)
+}
+
+// QueueOptions configures a queue. Zero fields take the defaults noted;
+// the jobs.queues config section overrides them by queue name.
+type QueueOptions struct {
+	// Workers process tasks concurrently; default 1
+	Workers int
+	// Capacity bounds tasks waiting for a worker; default 100
+	Capacity int
+	// Timeout bounds each attempt; zero leaves attempts unbounded
+	Timeout time.Duration
+	// Retry defaults to 5 attempts backing off from 1s to 5m
+	Retry RetryPolicy
+}
+
+func (o QueueOptions) withDefaults() QueueOptions {
+	if o.Workers <= 0 {
+		o.Workers = 1
+	}
+	if o.Capacity <= 0 {
+		o.Capacity = 100
+	}
+	if o.Retry.MaxAttempts <= 0 {
+		o.Retry.MaxAttempts = 5
+	}
+	if o.Retry.Backoff <= 0 {
+		o.Retry.Backoff = time.Second
+	}
+	if o.Retry.MaxBackoff < o.Retry.Backoff {
+		o.Retry.MaxBackoff = max(5*time.Minute, o.Retry.Backoff)
+	}
+	return o
+}
+
+// QueueStats describes one queue. Failures counts failed attempts,
+// including those that were retried.
+type QueueStats struct {
+	Name         string `json:"name"`
+	Workers      int    `json:"workers"`
+	Capacity     int    `json:"capacity"`
+	Depth        int    `json:"depth"`
+	InFlight     int64  `json:"in_flight"`
+	Retrying     int    `json:"retrying"`
+	Processed    int64  `json:"processed"`
+	Failures     int64  `json:"failures"`
+	DeadLettered int64  `json:"dead_lettered"`
+}
+
+// Queue is a named, bounded queue served by a fixed pool of workers.
+type Queue struct {
+	name       string
+	opts       QueueOptions
+	handler    Handler
+	deadLetter func(ctx context.Context, t *Task) error
+	labels     map[string]string
+	logger     core.Logger
+
+	tasks  chan *Task
+	drain  chan struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu       sync.Mutex
+	closed   bool
+	pending  map[*Task]*time.Timer
+	workers  sync.WaitGroup
+	retrying sync.WaitGroup
+
+	inFlight     atomic.Int64
+	processed    atomic.Int64
+	failures     atomic.Int64
+	deadLettered atomic.Int64
+}
+
+func newQueue(name string, opts QueueOptions, handler Handler, deadLetter func(context.Context, *Task) error) *Queue {
+	ctx, cancel := context.WithCancel(context.Background())
+	q := &Queue{
+		name:       name,
+		opts:       opts,
+		handler:    handler,
+		deadLetter: deadLetter,
+		labels:     map[string]string{"queue": name},
+		logger:     core.GetLogger("jobs"),
+		tasks:      make(chan *Task, opts.Capacity),
+		drain:      make(chan struct{}),
+		ctx:        ctx,
+		cancel:     cancel,
+		pending:    make(map[*Task]*time.Timer),
+	}
+	for i := 0; i < opts.Workers; i++ {
+		q.workers.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+func (q *Queue) Name() string {
+	return q.name
+}
+
+// Enqueue adds a task with payload marshalled to JSON, waiting for room
+// while the queue is full until ctx is done. It returns the task's ID.
+func (q *Queue) Enqueue(ctx context.Context, payload interface{}) (string, error) {
+	t, err := q.newTask(payload)
+	if err != nil {
+		return "", err
+	}
+	if q.isClosed() {
+		return "", fmt.Errorf("%w: %s", ErrQueueClosed, q.name)
+	}
+	select {
+	case q.tasks <- t:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	case <-q.drain:
+		return "", fmt.Errorf("%w: %s", ErrQueueClosed, q.name)
+	}
+	q.updateDepth()
+	return t.ID, nil
+}
+
+// TryEnqueue is Enqueue without waiting: it fails with ErrQueueFull when
+// the queue has no room.
+func (q *Queue) TryEnqueue(payload interface{}) (string, error) {
+	t, err := q.newTask(payload)
+	if err != nil {
+		return "", err
+	}
+	if q.isClosed() {
+		return "", fmt.Errorf("%w: %s", ErrQueueClosed, q.name)
+	}
+	select {
+	case q.tasks <- t:
+	default:
+		core.IncrCounterWithLabels("jobs.rejected", q.labels)
+		return "", fmt.Errorf("%w: %s", ErrQueueFull, q.name)
+	}
+	q.updateDepth()
+	return t.ID, nil
+}
+
+// requeue puts a dead-lettered task back with a fresh set of attempts.
+func (q *Queue) requeue(ctx context.Context, t Task) error {
+	t.Attempts = 0
+	t.Failed = time.Time{}
+	t.ready = time.Now()
+	select {
+	case q.tasks <- &t:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-q.drain:
+		return fmt.Errorf("%w: %s", ErrQueueClosed, q.name)
+	}
+	q.updateDepth()
+	return nil
+}
+
+func (q *Queue) newTask(payload interface{}) (*Task, error) {
+	var raw json.RawMessage
+	switch p := payload.(type) {
+	case json.RawMessage:
+		raw = p
+	default:
+		encoded, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("encoding %s task: %w", q.name, err)
+		}
+		raw = encoded
+	}
+	id := make([]byte, 8)
+	rand.Read(id)
+	now := time.Now()
+	return &Task{ID: hex.EncodeToString(id), Queue: q.name, Payload: raw, Enqueued: now, ready: now}, nil
+}
+
+func (q *Queue) Stats() QueueStats {
+	q.mu.Lock()
+	retrying := len(q.pending)
+	q.mu.Unlock()
+	return QueueStats{
+		Name:         q.name,
+		Workers:      q.opts.Workers,
+		Capacity:     q.opts.Capacity,
+		Depth:        len(q.tasks),
+		InFlight:     q.inFlight.Load(),
+		Retrying:     retrying,
+		Processed:    q.processed.Load(),
+		Failures:     q.failures.Load(),
+		DeadLettered: q.deadLettered.Load(),
+	}
+}
+
+// Stop stops taking tasks and lets the workers finish those already
+// queued. Tasks waiting for a retry are dead-lettered. Once ctx is done
+// the running tasks' contexts are cancelled and whatever is still queued
+// is dead-lettered too.
+func (q *Queue) Stop(ctx context.Context) error {
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		return nil
+	}
+	q.closed = true
+	var waiting []*Task
+	for t, timer := range q.pending {
+		if timer.Stop() {
+			waiting = append(waiting, t)
+			delete(q.pending, t)
+			q.retrying.Done()
+		}
+	}
+	q.mu.Unlock()
+	close(q.drain)
+
+	for _, t := range waiting {
+		q.bury(t)
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		q.retrying.Wait()
+		close(stopped)
+	}()
+
+	var err error
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		q.cancel()
+		err = fmt.Errorf("queue %s still busy at shutdown: %w", q.name, ctx.Err())
+	}
+
+	for {
+		select {
+		case t := <-q.tasks:
+			t.LastError = "shut down before completion"
+			q.bury(t)
+		default:
+			q.updateDepth()
+			return err
+		}
+	}
+}
+
+func (q *Queue) isClosed() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.closed
+}
+
+func (q *Queue) work() {
+	defer q.workers.Done()
+	for {
+		select {
+		case t := <-q.tasks:
+			q.process(t)
+		case <-q.drain:
+			for {
+				select {
+				case t := <-q.tasks:
+					q.process(t)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (q *Queue) process(t *Task) {
+	q.updateDepth()
+	core.RecordDurationWithLabels("jobs.wait", q.labels, t.ready)
+	core.SetGaugeWithLabels("jobs.in_flight", q.labels, q.inFlight.Add(1))
+
+	ctx := q.ctx
+	if q.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, q.opts.Timeout)
+		defer cancel()
+	}
+
+	t.Attempts++
+	start := time.Now()
+	err := q.run(ctx, t)
+	core.RecordDurationWithLabels("jobs.latency", q.labels, start)
+	core.SetGaugeWithLabels("jobs.in_flight", q.labels, q.inFlight.Add(-1))
+
+	if err == nil {
+		q.processed.Add(1)
+		core.IncrCounterWithLabels("jobs.processed", q.labels)
+		return
+	}
+
+	q.failures.Add(1)
+	core.IncrCounterWithLabels("jobs.failures", q.labels)
+	t.LastError = err.Error()
+
+	var permanent *permanentError
+	if errors.As(err, &permanent) || t.Attempts >= q.opts.Retry.MaxAttempts {
+		q.bury(t)
+		return
+	}
+	q.retry(t)
+}
+
+// run calls the handler, turning a panic into an error so that one bad
+// task cannot take the process down.
+func (q *Queue) run(ctx context.Context, t *Task) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			q.logger.Error("Task %s on %s panicked: %v\n%s", t.ID, q.name, r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return q.handler(ctx, t)
+}
+
+func (q *Queue) retry(t *Task) {
+	delay := q.opts.Retry.delay(t.Attempts)
+
+	q.mu.Lock()
+	if q.closed {
+		q.mu.Unlock()
+		q.bury(t)
+		return
+	}
+	q.logger.Debug("Task %s on %s failed (attempt %d/%d), retrying in %s: %s",
+		t.ID, q.name, t.Attempts, q.opts.Retry.MaxAttempts, delay.Round(time.Millisecond), t.LastError)
+	q.retrying.Add(1)
+	q.pending[t] = time.AfterFunc(delay, func() {
+		defer q.retrying.Done()
+		q.mu.Lock()
+		delete(q.pending, t)
+		retrying := len(q.pending)
+		q.mu.Unlock()
+		core.SetGaugeWithLabels("jobs.retrying", q.labels, int64(retrying))
+
+		t.ready = time.Now()
+		select {
+		case q.tasks <- t:
+			q.updateDepth()
+		case <-q.drain:
+			q.bury(t)
+		}
+	})
+	retrying := len(q.pending)
+	q.mu.Unlock()
+	core.SetGaugeWithLabels("jobs.retrying", q.labels, int64(retrying))
+}
+
+// bury hands a task that will not be retried to the dead-letter store, or
+// drops it with a log entry when there is none.
+func (q *Queue) bury(t *Task) {
+	t.Failed = time.Now().UTC()
+	q.deadLettered.Add(1)
+	core.IncrCounterWithLabels("jobs.dead_lettered", q.labels)
+
+	if q.deadLetter == nil {
+		q.logger.Error("Dropping task %s on %s after %d attempts: %s", t.ID, q.name, t.Attempts, t.LastError)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := q.deadLetter(ctx, t); err != nil {
+		q.logger.Error("Dead-lettering task %s on %s failed, task lost: %v (payload %s)", t.ID, q.name, err, t.Payload)
+		return
+	}
+	q.logger.Warn("Dead-lettered task %s on %s after %d attempts: %s", t.ID, q.name, t.Attempts, t.LastError)
+}
+
+func (q *Queue) updateDepth() {
+	core.SetGaugeWithLabels("jobs.queue_depth", q.labels, int64(len(q.tasks)))
+}
@@ -0,0 +1,186 @@
+// managers/keys/attestation.go
+package keys
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/managers/admin"
+)
+
+var ErrInvalidAttestation = errors.New("invalid attestation")
+
+// Attestation is a signed health and metrics report. Report holds the
+// report's JSON as a string, so that re-encoding the attestation cannot
+// alter the signed bytes; Signature is over AttestationMessage(Report).
+type Attestation struct {
+	Report    string `json:"report"`
+	Scheme    Scheme `json:"scheme"`
+	PublicKey string `json:"public_key"`
+	Signature string `json:"signature"`
+}
+
+// AttestationReport is what an Attestation vouches for. Sequence increases
+// with every report an instance signs, and Nonce echoes the caller's, so
+// verifiers can reject replayed reports.
+type AttestationReport struct {
+	Instance  string                 `json:"instance"`
+	Timestamp time.Time              `json:"timestamp"`
+	Sequence  uint64                 `json:"sequence"`
+	Nonce     string                 `json:"nonce,omitempty"`
+	Status    string                 `json:"status"`
+	Checks    map[string]string      `json:"checks"`
+	Metrics   map[string]interface{} `json:"metrics"`
+}
+
+func AttestationMessage(report string) []byte {
+	return []byte("attest:" + report)
+}
+
+// Attester signs reports of this instance's health and metrics.
+type Attester struct {
+	signer   Signer
+	instance string
+	prefixes []string
+	seq      atomic.Uint64
+}
+
+var attester atomic.Pointer[Attester]
+
+// NewAttester signs with signer, naming the instance in each report.
+// Only metrics whose names start with one of prefixes are included; with
+// none, every metric is.
+func NewAttester(signer Signer, instance string, prefixes []string) *Attester {
+	if instance == "" {
+		instance, _ = os.Hostname()
+	}
+	return &Attester{signer: signer, instance: instance, prefixes: prefixes}
+}
+
+func (a *Attester) Attest(ctx context.Context, nonce string) (*Attestation, error) {
+	report := AttestationReport{
+		Instance:  a.instance,
+		Timestamp: time.Now().UTC(),
+		Sequence:  a.seq.Add(1),
+		Nonce:     nonce,
+		Status:    core.HealthHealthy.String(),
+		Checks:    make(map[string]string),
+		Metrics:   make(map[string]interface{}),
+	}
+
+	var results map[string]core.HealthResult
+	if monitor := core.GetHealthMonitor(); monitor != nil {
+		results = monitor.LastResults()
+	} else {
+		results = core.CheckHealth(ctx)
+	}
+	// An unknown check counts as worse than a degraded one
+	severity := map[core.HealthStatus]int{core.HealthHealthy: 0, core.HealthDegraded: 1, core.HealthUnknown: 2, core.HealthUnhealthy: 3}
+	worst := core.HealthHealthy
+	for name, result := range results {
+		report.Checks[name] = result.Status.String()
+		if severity[result.Status] > severity[worst] {
+			worst = result.Status
+		}
+	}
+	report.Status = worst.String()
+
+	for name, v := range core.GetMetrics() {
+		if a.includes(name) {
+			report.Metrics[name] = v
+		}
+	}
+
+	payload, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := a.signer.Sign(AttestationMessage(string(payload)))
+	if err != nil {
+		return nil, err
+	}
+	core.IncrCounter("keys.attestations")
+
+	return &Attestation{
+		Report:    string(payload),
+		Scheme:    a.signer.Scheme(),
+		PublicKey: hex.EncodeToString(a.signer.PublicKey()),
+		Signature: hex.EncodeToString(sig),
+	}, nil
+}
+
+func (a *Attester) includes(metric string) bool {
+	if len(a.prefixes) == 0 {
+		return true
+	}
+	for _, p := range a.prefixes {
+		if strings.HasPrefix(metric, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// VerifyAttestation checks att's signature against the expected public key
+// and that the report is no older than maxAge (0 skips the age check).
+// Tracking Sequence or Nonce across calls is left to the verifier.
+func VerifyAttestation(att *Attestation, publicKey []byte, maxAge time.Duration) (*AttestationReport, error) {
+	if att.PublicKey != hex.EncodeToString(publicKey) {
+		return nil, fmt.Errorf("%w: signed by an unexpected key", ErrInvalidAttestation)
+	}
+	sig, err := hex.DecodeString(att.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature is not hex", ErrInvalidAttestation)
+	}
+	ok, err := Verify(att.Scheme, publicKey, AttestationMessage(att.Report), sig)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: bad signature", ErrInvalidAttestation)
+	}
+
+	var report AttestationReport
+	if err := json.Unmarshal([]byte(att.Report), &report); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAttestation, err)
+	}
+	if maxAge > 0 {
+		if age := time.Since(report.Timestamp); age > maxAge || age < -maxAge {
+			return nil, fmt.Errorf("%w: report is %s old", ErrInvalidAttestation, age.Round(time.Second))
+		}
+	}
+	return &report, nil
+}
+
+// handleAttestation serves a freshly signed report. Callers should pass a
+// random nonce so an old response cannot be replayed to them.
+func handleAttestation(w http.ResponseWriter, r *http.Request) {
+	a := attester.Load()
+	if a == nil {
+		admin.WriteJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "attestation is disabled"})
+		return
+	}
+
+	nonce := r.URL.Query().Get("nonce")
+	if len(nonce) > 128 {
+		admin.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "nonce is longer than 128 characters"})
+		return
+	}
+
+	att, err := a.Attest(r.Context(), nonce)
+	if err != nil {
+		core.LoggerFromContext(r.Context()).Error("Signing attestation: %v", err)
+		admin.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "signing failed"})
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, att)
+}
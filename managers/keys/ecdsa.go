@@ -0,0 +1,247 @@
+// managers/keys/ecdsa.go
+package keys
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// ecdsa is ECDSA over secp256k1 the way Substrate uses it: the seed is the
+// secret key, public keys are 33-byte compressed points, and signatures are
+// 65 bytes, r || s || recovery id, over the BLAKE2b-256 hash of the message
+// with RFC 6979 nonces and low s.
+//
+// The curve arithmetic uses math/big and is not constant time.
+
+type secp256k1Point struct {
+	x, y *big.Int // nil for the point at infinity
+}
+
+var (
+	secpP, _  = new(big.Int).SetString("fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc2f", 16)
+	secpN, _  = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+	secpGx, _ = new(big.Int).SetString("79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798", 16)
+	secpGy, _ = new(big.Int).SetString("483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8", 16)
+	secpG     = secp256k1Point{secpGx, secpGy}
+	secpHalfN = new(big.Int).Rsh(secpN, 1)
+)
+
+func (p secp256k1Point) infinity() bool {
+	return p.x == nil
+}
+
+func secpAdd(p, q secp256k1Point) secp256k1Point {
+	if p.infinity() {
+		return q
+	}
+	if q.infinity() {
+		return p
+	}
+
+	var lambda *big.Int
+	if p.x.Cmp(q.x) == 0 {
+		sum := new(big.Int).Add(p.y, q.y)
+		if sum.Mod(sum, secpP).Sign() == 0 {
+			return secp256k1Point{}
+		}
+		// Doubling: 3x² / 2y, since a = 0
+		num := new(big.Int).Mul(p.x, p.x)
+		num.Mul(num, big.NewInt(3))
+		den := new(big.Int).Lsh(p.y, 1)
+		lambda = num.Mul(num, den.ModInverse(den, secpP))
+	} else {
+		num := new(big.Int).Sub(q.y, p.y)
+		den := new(big.Int).Sub(q.x, p.x)
+		den.Mod(den, secpP)
+		lambda = num.Mul(num, den.ModInverse(den, secpP))
+	}
+	lambda.Mod(lambda, secpP)
+
+	x := new(big.Int).Mul(lambda, lambda)
+	x.Sub(x, p.x).Sub(x, q.x).Mod(x, secpP)
+	y := new(big.Int).Sub(p.x, x)
+	y.Mul(y, lambda).Sub(y, p.y).Mod(y, secpP)
+	return secp256k1Point{x, y}
+}
+
+func secpMul(p secp256k1Point, k *big.Int) secp256k1Point {
+	result := secp256k1Point{}
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		result = secpAdd(result, result)
+		if k.Bit(i) == 1 {
+			result = secpAdd(result, p)
+		}
+	}
+	return result
+}
+
+func secpCompress(p secp256k1Point) []byte {
+	out := make([]byte, 33)
+	out[0] = 2 + byte(p.y.Bit(0))
+	p.x.FillBytes(out[1:])
+	return out
+}
+
+func secpDecompress(b []byte) (secp256k1Point, error) {
+	if len(b) != 33 || (b[0] != 2 && b[0] != 3) {
+		return secp256k1Point{}, fmt.Errorf("ecdsa public key must be 33 compressed bytes")
+	}
+	x := new(big.Int).SetBytes(b[1:])
+	if x.Cmp(secpP) >= 0 {
+		return secp256k1Point{}, fmt.Errorf("ecdsa public key is not on the curve")
+	}
+	// y² = x³ + 7, and p ≡ 3 (mod 4) so y = (y²)^((p+1)/4)
+	y2 := new(big.Int).Exp(x, big.NewInt(3), secpP)
+	y2.Add(y2, big.NewInt(7)).Mod(y2, secpP)
+	y := new(big.Int).Exp(y2, new(big.Int).Rsh(new(big.Int).Add(secpP, big.NewInt(1)), 2), secpP)
+	if new(big.Int).Exp(y, big.NewInt(2), secpP).Cmp(y2) != 0 {
+		return secp256k1Point{}, fmt.Errorf("ecdsa public key is not on the curve")
+	}
+	if y.Bit(0) != uint(b[0]-2) {
+		y.Sub(secpP, y)
+	}
+	return secp256k1Point{x, y}, nil
+}
+
+type ecdsaSigner struct {
+	key    *big.Int
+	public []byte
+}
+
+func newEcdsaSigner(seed []byte) (Signer, error) {
+	if len(seed) != 32 {
+		return nil, fmt.Errorf("ecdsa seed must be 32 bytes, got %d", len(seed))
+	}
+	key := new(big.Int).SetBytes(seed)
+	if key.Sign() == 0 || key.Cmp(secpN) >= 0 {
+		return nil, fmt.Errorf("ecdsa seed is not a valid secp256k1 secret key")
+	}
+	return &ecdsaSigner{key: key, public: secpCompress(secpMul(secpG, key))}, nil
+}
+
+func (s *ecdsaSigner) Scheme() Scheme {
+	return Ecdsa
+}
+
+func (s *ecdsaSigner) PublicKey() []byte {
+	return append([]byte{}, s.public...)
+}
+
+func (s *ecdsaSigner) Sign(message []byte) ([]byte, error) {
+	digest := blake2b.Sum256(message)
+	return ecdsaSignDigest(s.key, digest[:]), nil
+}
+
+// ecdsaSignDigest signs a 32-byte digest and returns r || s || recovery id.
+func ecdsaSignDigest(key *big.Int, digest []byte) []byte {
+	z := new(big.Int).SetBytes(digest)
+	nonces := newRFC6979(key, digest)
+	for {
+		k := nonces.next()
+		R := secpMul(secpG, k)
+		r := new(big.Int).Mod(R.x, secpN)
+		if r.Sign() == 0 {
+			continue
+		}
+		s := new(big.Int).Mul(r, key)
+		s.Add(s, z).Mul(s, new(big.Int).ModInverse(k, secpN)).Mod(s, secpN)
+		if s.Sign() == 0 {
+			continue
+		}
+
+		recovery := byte(R.y.Bit(0))
+		if R.x.Cmp(secpN) >= 0 {
+			recovery |= 2
+		}
+		if s.Cmp(secpHalfN) > 0 {
+			s.Sub(secpN, s)
+			recovery ^= 1
+		}
+
+		out := make([]byte, 65)
+		r.FillBytes(out[:32])
+		s.FillBytes(out[32:64])
+		out[64] = recovery
+		return out
+	}
+}
+
+// verifyEcdsa checks a 64-byte r || s signature, or a 65-byte one with a
+// recovery id, against a compressed public key.
+func verifyEcdsa(publicKey, message, signature []byte) (bool, error) {
+	Q, err := secpDecompress(publicKey)
+	if err != nil {
+		return false, err
+	}
+	if len(signature) != 64 && len(signature) != 65 {
+		return false, nil
+	}
+
+	r := new(big.Int).SetBytes(signature[:32])
+	s := new(big.Int).SetBytes(signature[32:64])
+	if r.Sign() == 0 || r.Cmp(secpN) >= 0 || s.Sign() == 0 || s.Cmp(secpN) >= 0 {
+		return false, nil
+	}
+
+	digest := blake2b.Sum256(message)
+	z := new(big.Int).SetBytes(digest[:])
+	w := new(big.Int).ModInverse(s, secpN)
+	u1 := new(big.Int).Mul(z, w)
+	u1.Mod(u1, secpN)
+	u2 := new(big.Int).Mul(r, w)
+	u2.Mod(u2, secpN)
+
+	X := secpAdd(secpMul(secpG, u1), secpMul(Q, u2))
+	if X.infinity() {
+		return false, nil
+	}
+	return new(big.Int).Mod(X.x, secpN).Cmp(r) == 0, nil
+}
+
+// rfc6979 generates deterministic nonces with HMAC-SHA256 (RFC 6979 3.2).
+type rfc6979 struct {
+	k, v []byte
+}
+
+func newRFC6979(key *big.Int, digest []byte) *rfc6979 {
+	x := key.FillBytes(make([]byte, 32))
+	h := new(big.Int).SetBytes(digest)
+	h.Mod(h, secpN)
+	h1 := h.FillBytes(make([]byte, 32))
+
+	g := &rfc6979{k: make([]byte, 32), v: make([]byte, 32)}
+	for i := range g.v {
+		g.v[i] = 1
+	}
+	for _, sep := range []byte{0, 1} {
+		g.k = g.mac(g.v, []byte{sep}, x, h1)
+		g.v = g.mac(g.v)
+	}
+	return g
+}
+
+func (g *rfc6979) mac(parts ...[]byte) []byte {
+	m := hmac.New(sha256.New, g.k)
+	for _, p := range parts {
+		m.Write(p)
+	}
+	return m.Sum(nil)
+}
+
+// next returns the next candidate nonce in [1, n).
+func (g *rfc6979) next() *big.Int {
+	for {
+		g.v = g.mac(g.v)
+		k := new(big.Int).SetBytes(g.v)
+		// Updated now, so a candidate the caller rejects is followed by the next one
+		g.k = g.mac(g.v, []byte{0})
+		g.v = g.mac(g.v)
+		if k.Sign() > 0 && k.Cmp(secpN) < 0 {
+			return k
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+func TestEcdsaPublicKey(t *testing.T) {
+	seed := make([]byte, 32)
+	seed[31] = 1
+	signer, err := NewSigner(Ecdsa, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := hex.EncodeToString(signer.PublicKey())
+	if want := "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"; got != want {
+		t.Fatalf("public key = %s, want %s", got, want)
+	}
+}
+
+func TestEcdsaRFC6979(t *testing.T) {
+	// Widely used secp256k1 RFC 6979 vector
+	digest := sha256.Sum256([]byte("Satoshi Nakamoto"))
+	sig := ecdsaSignDigest(big.NewInt(1), digest[:])
+	if got, want := hex.EncodeToString(sig[:32]), "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8"; got != want {
+		t.Fatalf("r = %s, want %s", got, want)
+	}
+	if got, want := hex.EncodeToString(sig[32:64]), "2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5"; got != want {
+		t.Fatalf("s = %s, want %s", got, want)
+	}
+}
+
+func TestEcdsaSignVerify(t *testing.T) {
+	signer, err := NewSigner(Ecdsa, bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("attest")
+	sig, err := signer.Sign(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != 65 {
+		t.Fatalf("signature is %d bytes", len(sig))
+	}
+
+	if ok, err := Verify(Ecdsa, signer.PublicKey(), message, sig); err != nil || !ok {
+		t.Fatalf("Verify = %v, %v", ok, err)
+	}
+	if ok, _ := Verify(Ecdsa, signer.PublicKey(), []byte("other"), sig); ok {
+		t.Fatal("signature verified for another message")
+	}
+	sig[10] ^= 1
+	if ok, _ := Verify(Ecdsa, signer.PublicKey(), message, sig); ok {
+		t.Fatal("tampered signature verified")
+	}
+}
+
+func TestEcdsaRejectsInvalidSeed(t *testing.T) {
+	if _, err := NewSigner(Ecdsa, make([]byte, 32)); err == nil {
+		t.Fatal("zero seed accepted")
+	}
+	if _, err := NewSigner(Ecdsa, bytes.Repeat([]byte{0xff}, 32)); err == nil {
+		t.Fatal("seed above the group order accepted")
+	}
+}
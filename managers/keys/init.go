@@ -0,0 +1,306 @@
+// managers/keys/init.go
+package keys
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data"
+	"github.com/polkadot-go/helper/managers/admin"
+	"github.com/polkadot-go/helper/managers/maintenance"
+)
+
+type keysComponent struct{}
+
+func (c *keysComponent) Name() string {
+	return "keys"
+}
+
+func (c *keysComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *keysComponent) Init() error {
+	specs, _ := config.Get().Get("keys", "private_keys").(map[string]interface{})
+
+	keyring := NewKeyring()
+	names := make([]string, 0, len(specs))
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		spec, ok := specs[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("keys.private_keys.%s must be an object", name)
+		}
+		signer, err := loadKey(spec)
+		if err != nil {
+			return fmt.Errorf("loading key %s: %w", name, err)
+		}
+		keyring.Add(name, signer)
+	}
+
+	dataSpecs, _ := config.Get().Get("keys", "data_keys").(map[string]interface{})
+	for name, raw := range dataSpecs {
+		spec, ok := raw.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("keys.data_keys.%s must be an object", name)
+		}
+		key, err := loadDataKey(spec)
+		if err != nil {
+			return fmt.Errorf("loading data key %s: %w", name, err)
+		}
+		if err := keyring.AddDataKey(name, key); err != nil {
+			return err
+		}
+	}
+
+	instance = keyring
+	data.SetKeyProvider(keyring)
+
+	prefixes, _ := config.Get().Get("keys", "encrypted_prefixes").(map[string]interface{})
+	for prefix, raw := range prefixes {
+		spec, _ := raw.(map[string]interface{})
+		mode, _ := spec["mode"].(string)
+		keyName, _ := spec["key"].(string)
+		if _, err := keyring.DataKey(keyName); err != nil {
+			return fmt.Errorf("keys.encrypted_prefixes.%s: %w", prefix, err)
+		}
+		if err := data.RegisterEncryption(prefix, data.EncryptionMode(mode), keyName); err != nil {
+			return fmt.Errorf("keys.encrypted_prefixes.%s: %w", prefix, err)
+		}
+	}
+
+	attester.Store(nil)
+	if name := config.Get().GetString("keys", "attestation_key"); name != "" {
+		signer, err := keyring.Signer(name)
+		if err != nil {
+			return fmt.Errorf("keys.attestation_key: %w", err)
+		}
+		var prefixes []string
+		raw, _ := config.Get().Get("keys", "attestation_metrics").([]interface{})
+		for _, p := range raw {
+			if s, ok := p.(string); ok && s != "" {
+				prefixes = append(prefixes, s)
+			}
+		}
+		attester.Store(NewAttester(signer, config.Get().GetString("keys", "attestation_instance"), prefixes))
+	}
+
+	core.GetLogger("keys").Info("Loaded %d keys and %d data keys", len(names), len(dataSpecs))
+	return nil
+}
+
+func (c *keysComponent) Shutdown(ctx context.Context) error {
+	instance = nil
+	attester.Store(nil)
+	return nil
+}
+
+// reloadOnChange reloads the keys when the private keys change, restarting
+// the components that use them so they pick up rotated keys. Data keys are
+// left alone: rotating one in place would leave existing values unreadable.
+func reloadOnChange(old, new map[string]interface{}) {
+	if fmt.Sprint(old["private_keys"]) == fmt.Sprint(new["private_keys"]) || !core.IsInitialized("keys") {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := core.Restart(ctx, "keys"); err != nil {
+			core.GetLogger("keys").Error("Reloading keys after config change: %v", err)
+		}
+	}()
+}
+
+// rotateKey is the keys.rotate action. It reloads the private key named in
+// params from its configured file, once the new key has been written
+// there, and restarts the components using the keyring. To move a key to
+// another source, patch keys.private_keys through config.patch instead.
+func rotateKey(ctx context.Context, params map[string]interface{}) error {
+	if err := maintenance.Get().Check("keys.rotate"); err != nil {
+		return err
+	}
+	name, _ := params["name"].(string)
+	keyring := Get()
+	if keyring == nil {
+		return fmt.Errorf("keys are not loaded")
+	}
+	old, err := keyring.Signer(name)
+	if err != nil {
+		return err
+	}
+
+	// Check the new key before restarting anything
+	specs, _ := config.Get().Get("keys", "private_keys").(map[string]interface{})
+	spec, _ := specs[name].(map[string]interface{})
+	next, err := loadKey(spec)
+	if err != nil {
+		return fmt.Errorf("loading key %s: %w", name, err)
+	}
+	if bytes.Equal(next.PublicKey(), old.PublicKey()) {
+		return fmt.Errorf("key %s is unchanged; write the new key to its source first", name)
+	}
+
+	if err := core.Restart(ctx, "keys"); err != nil {
+		return err
+	}
+	core.ContextLogger(ctx, core.GetLogger("audit")).With(map[string]interface{}{
+		"key": name,
+		"old": hex.EncodeToString(old.PublicKey()),
+		"new": hex.EncodeToString(next.PublicKey()),
+	}).Info("Key rotated")
+	return nil
+}
+
+// loadKey reads one key from exactly one of seed, env or file. Files may
+// hold a hex seed or an encrypted keystore, whose password is read from
+// the variable named by password_env.
+func loadKey(spec map[string]interface{}) (Signer, error) {
+	str := func(k string) string {
+		s, _ := spec[k].(string)
+		return s
+	}
+	scheme := Scheme(str("scheme"))
+
+	switch {
+	case str("seed") != "":
+		seed, err := ParseSeed(str("seed"))
+		if err != nil {
+			return nil, err
+		}
+		return NewSigner(scheme, seed)
+
+	case str("env") != "":
+		value, ok := os.LookupEnv(str("env"))
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", str("env"))
+		}
+		seed, err := ParseSeed(value)
+		if err != nil {
+			return nil, err
+		}
+		return NewSigner(scheme, seed)
+
+	case str("file") != "":
+		content, err := os.ReadFile(str("file"))
+		if err != nil {
+			return nil, err
+		}
+		if bytes.HasPrefix(bytes.TrimSpace(content), []byte("{")) {
+			password := os.Getenv(str("password_env"))
+			return DecryptKeystore(content, password)
+		}
+		seed, err := ParseSeed(string(content))
+		if err != nil {
+			return nil, err
+		}
+		return NewSigner(scheme, seed)
+	}
+
+	return nil, fmt.Errorf("key needs one of seed, env or file")
+}
+
+// loadDataKey reads a base64 32-byte key from exactly one of key, env or
+// file.
+func loadDataKey(spec map[string]interface{}) ([]byte, error) {
+	str := func(k string) string {
+		s, _ := spec[k].(string)
+		return s
+	}
+
+	var encoded string
+	switch {
+	case str("key") != "":
+		encoded = str("key")
+	case str("env") != "":
+		value, ok := os.LookupEnv(str("env"))
+		if !ok {
+			return nil, fmt.Errorf("environment variable %s is not set", str("env"))
+		}
+		encoded = value
+	case str("file") != "":
+		content, err := os.ReadFile(str("file"))
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(content)
+	default:
+		return nil, fmt.Errorf("data key needs one of key, env or file")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 key: %w", err)
+	}
+	return key, nil
+}
+
+// validatePrivateKeys refuses unknown schemes, so a typo fails the config
+// rather than the keys component.
+func validatePrivateKeys(value interface{}) error {
+	specs, _ := value.(map[string]interface{})
+	for name, raw := range specs {
+		spec, _ := raw.(map[string]interface{})
+		switch scheme, _ := spec["scheme"].(string); Scheme(scheme) {
+		case Ed25519, Sr25519, Ecdsa, "":
+		default:
+			return fmt.Errorf("key %s: %w: %s", name, ErrUnsupportedScheme, scheme)
+		}
+	}
+	return nil
+}
+
+func init() {
+	config.Register("keys", config.Schema{
+		"private_keys": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Keypairs by name: {\"scheme\": \"ed25519\", \"sr25519\" or \"ecdsa\" (secp256k1), and one of \"seed\" (hex), \"env\" (variable holding a hex seed) or \"file\" (hex seed or encrypted keystore, with \"password_env\")}",
+			Validator:   validatePrivateKeys,
+		},
+		"data_keys": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Symmetric data encryption keys by name, each a base64 32-byte key given as one of \"key\", \"env\" or \"file\"",
+			Secret:      true,
+		},
+		"encrypted_prefixes": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Store key prefixes whose values are encrypted: {\"mode\": \"deterministic\"|\"randomized\", \"key\": <data key name>}",
+		},
+		"attestation_key": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Name of the private key that signs GET /attestation reports (empty disables the endpoint)",
+		},
+		"attestation_instance": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Instance name put in attestation reports (defaults to the hostname)",
+		},
+		"attestation_metrics": config.Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "Metric name prefixes included in attestation reports, e.g. \"gauge.health\" (empty includes all)",
+		},
+	})
+
+	config.OnReload("keys", reloadOnChange)
+	core.Register(&keysComponent{})
+
+	admin.HandleFunc("GET /attestation", handleAttestation)
+	admin.RegisterAction("keys.rotate", rotateKey)
+}
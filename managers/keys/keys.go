@@ -0,0 +1,168 @@
+// managers/keys/keys.go
+package keys
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Scheme names one of the signature schemes Substrate uses. Sr25519 is the
+// default for accounts and session keys.
+type Scheme string
+
+const (
+	Ed25519 Scheme = "ed25519"
+	Sr25519 Scheme = "sr25519"
+	Ecdsa   Scheme = "ecdsa"
+)
+
+var (
+	ErrUnknownKey        = errors.New("unknown key")
+	ErrUnsupportedScheme = errors.New("unsupported key scheme")
+)
+
+// Signer signs payloads with a single keypair. Implementations must not
+// expose the private key.
+type Signer interface {
+	Scheme() Scheme
+	PublicKey() []byte
+	Sign(message []byte) ([]byte, error)
+}
+
+type ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+func (s *ed25519Signer) Scheme() Scheme {
+	return Ed25519
+}
+
+func (s *ed25519Signer) PublicKey() []byte {
+	return append([]byte{}, s.key.Public().(ed25519.PublicKey)...)
+}
+
+func (s *ed25519Signer) Sign(message []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, message), nil
+}
+
+// NewSigner builds a signer from a 32-byte seed, which for sr25519 is a
+// schnorrkel mini secret key and for ecdsa the secp256k1 secret key, as in
+// Substrate's Pair::from_seed.
+func NewSigner(scheme Scheme, seed []byte) (Signer, error) {
+	switch scheme {
+	case Ed25519, "":
+		if len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("ed25519 seed must be %d bytes, got %d", ed25519.SeedSize, len(seed))
+		}
+		return &ed25519Signer{key: ed25519.NewKeyFromSeed(seed)}, nil
+	case Sr25519:
+		return newSr25519Signer(seed)
+	case Ecdsa:
+		return newEcdsaSigner(seed)
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedScheme, scheme)
+}
+
+func Verify(scheme Scheme, publicKey, message, signature []byte) (bool, error) {
+	switch scheme {
+	case Ed25519, "":
+		if len(publicKey) != ed25519.PublicKeySize {
+			return false, fmt.Errorf("ed25519 public key must be %d bytes", ed25519.PublicKeySize)
+		}
+		return ed25519.Verify(publicKey, message, signature), nil
+	case Sr25519:
+		return verifySr25519(publicKey, message, signature)
+	case Ecdsa:
+		return verifyEcdsa(publicKey, message, signature)
+	}
+	return false, fmt.Errorf("%w: %s", ErrUnsupportedScheme, scheme)
+}
+
+// ParseSeed decodes a hex seed, with or without a 0x prefix.
+func ParseSeed(s string) ([]byte, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "0x")
+	seed, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex seed: %w", err)
+	}
+	return seed, nil
+}
+
+type Keyring struct {
+	mu       sync.RWMutex
+	signers  map[string]Signer
+	dataKeys map[string][]byte
+}
+
+var instance *Keyring
+
+func Get() *Keyring {
+	return instance
+}
+
+func NewKeyring() *Keyring {
+	return &Keyring{signers: make(map[string]Signer), dataKeys: make(map[string][]byte)}
+}
+
+func (k *Keyring) Add(name string, signer Signer) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.signers[name] = signer
+}
+
+func (k *Keyring) Signer(name string) (Signer, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	signer, ok := k.signers[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, name)
+	}
+	return signer, nil
+}
+
+func (k *Keyring) Remove(name string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	delete(k.signers, name)
+}
+
+func (k *Keyring) Names() []string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	names := make([]string, 0, len(k.signers))
+	for name := range k.signers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddDataKey stores a 32-byte symmetric key for data encryption.
+func (k *Keyring) AddDataKey(name string, key []byte) error {
+	if len(key) != 32 {
+		return fmt.Errorf("data key %s must be 32 bytes, got %d", name, len(key))
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.dataKeys[name] = append([]byte{}, key...)
+	return nil
+}
+
+// DataKey implements data.KeyProvider.
+func (k *Keyring) DataKey(name string) ([]byte, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	key, ok := k.dataKeys[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownKey, name)
+	}
+	return key, nil
+}
@@ -0,0 +1,95 @@
+// managers/keys/keystore.go
+package keys
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Keystore is the encrypted on-disk form of a seed: AES-256-GCM under a
+// PBKDF2-SHA256 derived key. It is this module's own format, not the
+// polkadot.js keystore.
+type Keystore struct {
+	Version    int    `json:"version"`
+	Scheme     Scheme `json:"scheme"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+	PublicKey  []byte `json:"public_key"`
+}
+
+const keystoreIterations = 600000
+
+var ErrWrongPassword = errors.New("keystore password is incorrect")
+
+func EncryptKeystore(scheme Scheme, seed []byte, password string) ([]byte, error) {
+	signer, err := NewSigner(scheme, seed)
+	if err != nil {
+		return nil, err
+	}
+
+	ks := Keystore{
+		Version:    1,
+		Scheme:     signer.Scheme(),
+		KDF:        "pbkdf2-sha256",
+		Iterations: keystoreIterations,
+		Salt:       make([]byte, 16),
+		PublicKey:  signer.PublicKey(),
+	}
+	if _, err := rand.Read(ks.Salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := keystoreCipher(password, ks.Salt, ks.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	ks.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(ks.Nonce); err != nil {
+		return nil, err
+	}
+	ks.Ciphertext = aead.Seal(nil, ks.Nonce, seed, ks.PublicKey)
+
+	return json.MarshalIndent(ks, "", "  ")
+}
+
+func DecryptKeystore(data []byte, password string) (Signer, error) {
+	var ks Keystore
+	if err := json.Unmarshal(data, &ks); err != nil {
+		return nil, fmt.Errorf("parsing keystore: %w", err)
+	}
+	if ks.Version != 1 || ks.KDF != "pbkdf2-sha256" {
+		return nil, fmt.Errorf("unsupported keystore version %d (%s)", ks.Version, ks.KDF)
+	}
+
+	aead, err := keystoreCipher(password, ks.Salt, ks.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	seed, err := aead.Open(nil, ks.Nonce, ks.Ciphertext, ks.PublicKey)
+	if err != nil {
+		return nil, ErrWrongPassword
+	}
+
+	return NewSigner(ks.Scheme, seed)
+}
+
+func keystoreCipher(password string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, password, salt, iterations, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,179 @@
+// managers/keys/merlin.go
+package keys
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+// transcript is a Merlin transcript, which schnorrkel hashes sr25519
+// signatures with. It is built on STROBE-128 over Keccak-f[1600], following
+// the reference implementation (merlin.cool); only the operations Merlin
+// uses are implemented.
+type transcript struct {
+	s strobe
+}
+
+func newTranscript(label string) *transcript {
+	t := &transcript{s: newStrobe128("Merlin v1.0")}
+	t.appendMessage("dom-sep", []byte(label))
+	return t
+}
+
+func (t *transcript) appendMessage(label string, message []byte) {
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(message)))
+	t.s.metaAD([]byte(label), false)
+	t.s.metaAD(size[:], true)
+	t.s.ad(message, false)
+}
+
+func (t *transcript) challengeBytes(label string, n int) []byte {
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(n))
+	t.s.metaAD([]byte(label), false)
+	t.s.metaAD(size[:], true)
+	out := make([]byte, n)
+	t.s.prf(out, false)
+	return out
+}
+
+const strobeR = 166
+
+const (
+	strobeFlagI = 1 << iota
+	strobeFlagA
+	strobeFlagC
+	strobeFlagT
+	strobeFlagM
+	strobeFlagK
+)
+
+type strobe struct {
+	state    [200]byte
+	pos      int
+	posBegin int
+	curFlags byte
+}
+
+func newStrobe128(protocol string) strobe {
+	var s strobe
+	copy(s.state[:], []byte{1, strobeR + 2, 1, 0, 1, 96})
+	copy(s.state[6:], "STROBEv1.0.2")
+	keccakF1600(&s.state)
+	s.metaAD([]byte(protocol), false)
+	return s
+}
+
+func (s *strobe) metaAD(data []byte, more bool) {
+	s.beginOp(strobeFlagM|strobeFlagA, more)
+	s.absorb(data)
+}
+
+func (s *strobe) ad(data []byte, more bool) {
+	s.beginOp(strobeFlagA, more)
+	s.absorb(data)
+}
+
+func (s *strobe) prf(out []byte, more bool) {
+	s.beginOp(strobeFlagI|strobeFlagA|strobeFlagC, more)
+	s.squeeze(out)
+}
+
+func (s *strobe) runF() {
+	s.state[s.pos] ^= byte(s.posBegin)
+	s.state[s.pos+1] ^= 0x04
+	s.state[strobeR+1] ^= 0x80
+	keccakF1600(&s.state)
+	s.pos = 0
+	s.posBegin = 0
+}
+
+func (s *strobe) absorb(data []byte) {
+	for _, b := range data {
+		s.state[s.pos] ^= b
+		s.pos++
+		if s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+func (s *strobe) squeeze(out []byte) {
+	for i := range out {
+		out[i] = s.state[s.pos]
+		s.state[s.pos] = 0
+		s.pos++
+		if s.pos == strobeR {
+			s.runF()
+		}
+	}
+}
+
+func (s *strobe) beginOp(flags byte, more bool) {
+	if more {
+		// Continuing an operation of the same kind
+		return
+	}
+	oldBegin := s.posBegin
+	s.posBegin = s.pos + 1
+	s.curFlags = flags
+	s.absorb([]byte{byte(oldBegin), flags})
+
+	if flags&(strobeFlagC|strobeFlagK) != 0 && s.pos != 0 {
+		s.runF()
+	}
+}
+
+var keccakRC = [24]uint64{
+	0x0000000000000001, 0x0000000000008082, 0x800000000000808a, 0x8000000080008000,
+	0x000000000000808b, 0x0000000080000001, 0x8000000080008081, 0x8000000000008009,
+	0x000000000000008a, 0x0000000000000088, 0x0000000080008009, 0x000000008000000a,
+	0x000000008000808b, 0x800000000000008b, 0x8000000000008089, 0x8000000000008003,
+	0x8000000000008002, 0x8000000000000080, 0x000000000000800a, 0x800000008000000a,
+	0x8000000080008081, 0x8000000000008080, 0x0000000080000001, 0x8000000080008008,
+}
+
+// keccakRot is the rotation of lane x+5y.
+var keccakRot = [25]int{
+	0, 1, 62, 28, 27,
+	36, 44, 6, 55, 20,
+	3, 10, 43, 25, 39,
+	41, 45, 15, 21, 8,
+	18, 2, 61, 56, 14,
+}
+
+// keccakF1600 permutes a 200-byte state of little-endian lanes.
+func keccakF1600(state *[200]byte) {
+	var a [25]uint64
+	for i := range a {
+		a[i] = binary.LittleEndian.Uint64(state[i*8:])
+	}
+
+	var b [25]uint64
+	var c, d [5]uint64
+	for round := 0; round < 24; round++ {
+		for x := 0; x < 5; x++ {
+			c[x] = a[x] ^ a[x+5] ^ a[x+10] ^ a[x+15] ^ a[x+20]
+		}
+		for x := 0; x < 5; x++ {
+			d[x] = c[(x+4)%5] ^ bits.RotateLeft64(c[(x+1)%5], 1)
+		}
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				lane := a[x+5*y] ^ d[x]
+				b[y+5*((2*x+3*y)%5)] = bits.RotateLeft64(lane, keccakRot[x+5*y])
+			}
+		}
+		for x := 0; x < 5; x++ {
+			for y := 0; y < 5; y++ {
+				a[x+5*y] = b[x+5*y] ^ (^b[(x+1)%5+5*y] & b[(x+2)%5+5*y])
+			}
+		}
+		a[0] ^= keccakRC[round]
+	}
+
+	for i := range a {
+		binary.LittleEndian.PutUint64(state[i*8:], a[i])
+	}
+}
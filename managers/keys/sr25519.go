@@ -0,0 +1,238 @@
+// managers/keys/sr25519.go
+package keys
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"filippo.io/edwards25519"
+	"filippo.io/edwards25519/field"
+)
+
+// sr25519 is Schnorr signatures over Ristretto255 as implemented by
+// schnorrkel, the default scheme of Substrate accounts and session keys.
+// Seeds are schnorrkel mini secret keys, expanded the way Substrate does
+// (ExpansionMode::Ed25519), and messages are signed in the "substrate"
+// signing context, so keys and signatures interoperate with a node.
+
+const sr25519Context = "substrate"
+
+type sr25519Signer struct {
+	key    *edwards25519.Scalar
+	nonce  [32]byte
+	public []byte
+}
+
+func newSr25519Signer(seed []byte) (Signer, error) {
+	if len(seed) != 32 {
+		return nil, fmt.Errorf("sr25519 seed must be 32 bytes, got %d", len(seed))
+	}
+
+	h := sha512.Sum512(seed)
+	var key [32]byte
+	copy(key[:], h[:32])
+	key[0] &= 248
+	key[31] &= 63
+	key[31] |= 64
+	// schnorrkel keeps the clamped key divided by the cofactor
+	var low byte
+	for i := 31; i >= 0; i-- {
+		r := key[i] & 7
+		key[i] = key[i]>>3 + low
+		low = r << 5
+	}
+
+	wide := make([]byte, 64)
+	copy(wide, key[:])
+	scalar, err := edwards25519.NewScalar().SetUniformBytes(wide)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &sr25519Signer{key: scalar}
+	copy(s.nonce[:], h[32:])
+	s.public = ristrettoEncode(new(edwards25519.Point).ScalarBaseMult(scalar))
+	return s, nil
+}
+
+func (s *sr25519Signer) Scheme() Scheme {
+	return Sr25519
+}
+
+func (s *sr25519Signer) PublicKey() []byte {
+	return append([]byte{}, s.public...)
+}
+
+func (s *sr25519Signer) Sign(message []byte) ([]byte, error) {
+	t := sr25519Transcript(message, s.public)
+
+	// Hedged like schnorrkel's witness: a weak RNG alone cannot leak the key
+	entropy := make([]byte, 32)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+	h := sha512.New()
+	h.Write(s.nonce[:])
+	h.Write(entropy)
+	h.Write(message)
+	r, err := edwards25519.NewScalar().SetUniformBytes(h.Sum(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	R := ristrettoEncode(new(edwards25519.Point).ScalarBaseMult(r))
+	t.appendMessage("sign:R", R)
+	k, err := edwards25519.NewScalar().SetUniformBytes(t.challengeBytes("sign:c", 64))
+	if err != nil {
+		return nil, err
+	}
+
+	signature := append(R, edwards25519.NewScalar().MultiplyAdd(k, s.key, r).Bytes()...)
+	// Marks the signature as schnorrkel 0.8 or later
+	signature[63] |= 0x80
+	return signature, nil
+}
+
+func verifySr25519(publicKey, message, signature []byte) (bool, error) {
+	if len(publicKey) != 32 {
+		return false, fmt.Errorf("sr25519 public key must be 32 bytes")
+	}
+	A, err := ristrettoDecode(publicKey)
+	if err != nil {
+		return false, fmt.Errorf("sr25519 public key: %w", err)
+	}
+	if len(signature) != 64 || signature[63]&0x80 == 0 {
+		return false, nil
+	}
+
+	R, err := ristrettoDecode(signature[:32])
+	if err != nil {
+		return false, nil
+	}
+	sBytes := append([]byte{}, signature[32:]...)
+	sBytes[31] &= 0x7f
+	sig, err := edwards25519.NewScalar().SetCanonicalBytes(sBytes)
+	if err != nil {
+		return false, nil
+	}
+
+	t := sr25519Transcript(message, publicKey)
+	t.appendMessage("sign:R", signature[:32])
+	k, err := edwards25519.NewScalar().SetUniformBytes(t.challengeBytes("sign:c", 64))
+	if err != nil {
+		return false, err
+	}
+
+	// R = s*B - k*A
+	check := new(edwards25519.Point).VarTimeDoubleScalarBaseMult(edwards25519.NewScalar().Negate(k), A, sig)
+	return ristrettoEqual(check, R), nil
+}
+
+// sr25519Transcript is schnorrkel's signing transcript up to the commitment
+// to R.
+func sr25519Transcript(message, publicKey []byte) *transcript {
+	t := newTranscript("SigningContext")
+	t.appendMessage("", []byte(sr25519Context))
+	t.appendMessage("sign-bytes", message)
+	t.appendMessage("proto-name", []byte("Schnorr-sig"))
+	t.appendMessage("sign:pk", publicKey)
+	return t
+}
+
+// Ristretto255 encoding of edwards25519 points (RFC 9496).
+
+var (
+	fieldD              = fieldConstant("37095705934669439343138083508754565189542113879843219016388785533085940283555")
+	fieldSqrtM1         = fieldConstant("19681161376707505956807079304988542015446066515923890162744021073123829784752")
+	fieldInvSqrtAMinusD = fieldConstant("54469307008909316920995813868745141605393597292927456921205312896311721017578")
+	errInvalidRistretto = errors.New("invalid ristretto255 encoding")
+	fieldOne            = new(field.Element).One()
+	fieldZero           = new(field.Element).Zero()
+)
+
+func fieldConstant(decimal string) *field.Element {
+	n, _ := new(big.Int).SetString(decimal, 10)
+	b := n.FillBytes(make([]byte, 32))
+	for i, j := 0, 31; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	e, err := new(field.Element).SetBytes(b)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+func ristrettoEncode(p *edwards25519.Point) []byte {
+	X, Y, Z, T := p.ExtendedCoordinates()
+
+	u1 := new(field.Element).Multiply(new(field.Element).Add(Z, Y), new(field.Element).Subtract(Z, Y))
+	u2 := new(field.Element).Multiply(X, Y)
+	invSqrt, _ := new(field.Element).SqrtRatio(fieldOne, new(field.Element).Multiply(u1, new(field.Element).Square(u2)))
+	den1 := new(field.Element).Multiply(invSqrt, u1)
+	den2 := new(field.Element).Multiply(invSqrt, u2)
+	zInv := new(field.Element).Multiply(new(field.Element).Multiply(den1, den2), T)
+
+	ix := new(field.Element).Multiply(X, fieldSqrtM1)
+	iy := new(field.Element).Multiply(Y, fieldSqrtM1)
+	enchanted := new(field.Element).Multiply(den1, fieldInvSqrtAMinusD)
+	rotate := new(field.Element).Multiply(T, zInv).IsNegative()
+
+	x := new(field.Element).Select(iy, X, rotate)
+	y := new(field.Element).Select(ix, Y, rotate)
+	denInv := new(field.Element).Select(enchanted, den2, rotate)
+
+	negY := new(field.Element).Negate(y)
+	y.Select(negY, y, new(field.Element).Multiply(x, zInv).IsNegative())
+
+	s := new(field.Element).Multiply(denInv, new(field.Element).Subtract(Z, y))
+	return s.Absolute(s).Bytes()
+}
+
+func ristrettoDecode(b []byte) (*edwards25519.Point, error) {
+	s, err := new(field.Element).SetBytes(b)
+	if err != nil {
+		return nil, errInvalidRistretto
+	}
+	// Only the canonical, non-negative encoding is accepted
+	if string(s.Bytes()) != string(b) || s.IsNegative() == 1 {
+		return nil, errInvalidRistretto
+	}
+
+	ss := new(field.Element).Square(s)
+	u1 := new(field.Element).Subtract(fieldOne, ss)
+	u2 := new(field.Element).Add(fieldOne, ss)
+	u2Sqr := new(field.Element).Square(u2)
+	v := new(field.Element).Multiply(fieldD, new(field.Element).Square(u1))
+	v.Negate(v)
+	v.Subtract(v, u2Sqr)
+
+	invSqrt, wasSquare := new(field.Element).SqrtRatio(fieldOne, new(field.Element).Multiply(v, u2Sqr))
+	denX := new(field.Element).Multiply(invSqrt, u2)
+	denY := new(field.Element).Multiply(new(field.Element).Multiply(invSqrt, denX), v)
+
+	x := new(field.Element).Multiply(new(field.Element).Add(s, s), denX)
+	x.Absolute(x)
+	y := new(field.Element).Multiply(u1, denY)
+	t := new(field.Element).Multiply(x, y)
+	if wasSquare == 0 || t.IsNegative() == 1 || y.Equal(fieldZero) == 1 {
+		return nil, errInvalidRistretto
+	}
+
+	p, err := new(edwards25519.Point).SetExtendedCoordinates(x, y, new(field.Element).One(), t)
+	if err != nil {
+		return nil, errInvalidRistretto
+	}
+	return p, nil
+}
+
+func ristrettoEqual(p, q *edwards25519.Point) bool {
+	X1, Y1, _, _ := p.ExtendedCoordinates()
+	X2, Y2, _, _ := q.ExtendedCoordinates()
+	a := new(field.Element).Multiply(X1, Y2).Equal(new(field.Element).Multiply(Y1, X2))
+	b := new(field.Element).Multiply(Y1, Y2).Equal(new(field.Element).Multiply(X1, X2))
+	return a|b == 1
+}
@@ -0,0 +1,68 @@
+package keys
+
+import (
+	"bytes"
+	"crypto/sha3"
+	"encoding/hex"
+	"testing"
+)
+
+func TestMerlinTranscript(t *testing.T) {
+	// Test vector from the merlin crate
+	tr := newTranscript("test protocol")
+	tr.appendMessage("some label", []byte("some data"))
+	got := hex.EncodeToString(tr.challengeBytes("challenge", 32))
+	if want := "d5a21972d0d5fe320c0d263fac7fffb8145aa640af6e9bca177c03c7efcf0615"; got != want {
+		t.Fatalf("challenge = %s, want %s", got, want)
+	}
+}
+
+func TestKeccakF1600(t *testing.T) {
+	// SHA3-256 of a single-block message, padded by hand
+	var state [200]byte
+	copy(state[:], "abc")
+	state[3] ^= 0x06
+	state[135] ^= 0x80
+	keccakF1600(&state)
+
+	want := sha3.Sum256([]byte("abc"))
+	if !bytes.Equal(state[:32], want[:]) {
+		t.Fatalf("sha3-256(abc) = %x, want %x", state[:32], want)
+	}
+}
+
+func TestSr25519PublicKey(t *testing.T) {
+	// Alice's dev seed and account key
+	seed, _ := hex.DecodeString("e5be9a5092b81bca64be81d212e7f2f9eba183bb7a90954f7b76361f6edb5c0a")
+	signer, err := NewSigner(Sr25519, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := hex.EncodeToString(signer.PublicKey())
+	if want := "d43593c715fdd31c61141abd04a99fd6822c8558854ccde39a5684e7a56da27d"; got != want {
+		t.Fatalf("public key = %s, want %s", got, want)
+	}
+}
+
+func TestSr25519SignVerify(t *testing.T) {
+	signer, err := NewSigner(Sr25519, bytes.Repeat([]byte{7}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("attest")
+	sig, err := signer.Sign(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := Verify(Sr25519, signer.PublicKey(), message, sig); err != nil || !ok {
+		t.Fatalf("Verify = %v, %v", ok, err)
+	}
+	if ok, _ := Verify(Sr25519, signer.PublicKey(), []byte("other"), sig); ok {
+		t.Fatal("signature verified for another message")
+	}
+	sig[0] ^= 1
+	if ok, _ := Verify(Sr25519, signer.PublicKey(), message, sig); ok {
+		t.Fatal("tampered signature verified")
+	}
+}
@@ -0,0 +1,68 @@
+// managers/keys/tls.go
+package keys
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+)
+
+// cryptoSigner lets crypto/x509 and crypto/tls sign with a Signer without
+// the private key leaving it.
+type cryptoSigner struct {
+	signer Signer
+}
+
+func (c cryptoSigner) Public() crypto.PublicKey {
+	return ed25519.PublicKey(c.signer.PublicKey())
+}
+
+func (c cryptoSigner) Sign(_ io.Reader, message []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, fmt.Errorf("ed25519 signs unhashed messages")
+	}
+	return c.signer.Sign(message)
+}
+
+// Certificate returns a self-signed TLS certificate for signer's key, valid
+// from an hour ago for validity. Such certificates carry no trust of their
+// own: peers are expected to pin the public key. Only ed25519 keys are
+// supported.
+func Certificate(signer Signer, commonName string, validity time.Duration) (tls.Certificate, error) {
+	if signer.Scheme() != Ed25519 {
+		return tls.Certificate{}, fmt.Errorf("%w for TLS: %s", ErrUnsupportedScheme, signer.Scheme())
+	}
+
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(validity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	key := cryptoSigner{signer: signer}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("creating certificate: %w", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, nil
+}
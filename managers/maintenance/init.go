@@ -0,0 +1,117 @@
+// managers/maintenance/init.go
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/managers/admin"
+)
+
+type maintenanceComponent struct{}
+
+func (c *maintenanceComponent) Name() string {
+	return "maintenance"
+}
+
+func (c *maintenanceComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *maintenanceComponent) Init() error {
+	raw, _ := config.Get().Get("maintenance", "windows").(map[string]interface{})
+	windows, err := parseWindows(raw)
+	if err != nil {
+		return err
+	}
+	instance.SetWindows(windows)
+	return nil
+}
+
+func parseWindows(raw map[string]interface{}) ([]Window, error) {
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	windows := make([]Window, 0, len(names))
+	for _, name := range names {
+		spec, ok := raw[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("maintenance.windows.%s must be an object", name)
+		}
+		w, err := ParseWindow(name, spec)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, nil
+}
+
+func (c *maintenanceComponent) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func handleStatus(w http.ResponseWriter, r *http.Request) {
+	status := map[string]interface{}{"overrides": instance.Overrides()}
+	if op := r.URL.Query().Get("operation"); op != "" {
+		allowed, reason := instance.Allowed(op)
+		status["operation"] = op
+		status["allowed"] = allowed
+		status["reason"] = reason
+	}
+	admin.WriteJSON(w, http.StatusOK, status)
+}
+
+// setOverride is the maintenance.override action. An override lets an
+// operation run outside its windows, so it needs the same approvals as the
+// operations it unblocks.
+func setOverride(ctx context.Context, params map[string]interface{}) error {
+	operation, _ := params["operation"].(string)
+	reason, _ := params["reason"].(string)
+	raw, _ := params["duration"].(string)
+	d, err := time.ParseDuration(raw)
+	if operation == "" || reason == "" || err != nil || d <= 0 {
+		return fmt.Errorf("operation, reason and a positive duration are required")
+	}
+
+	instance.SetOverride(Override{Operation: operation, Until: time.Now().Add(d), Reason: reason})
+	return nil
+}
+
+func handleClearOverride(w http.ResponseWriter, r *http.Request) {
+	instance.ClearOverride(r.PathValue("operation"))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func init() {
+	config.Register("maintenance", config.Schema{
+		"windows": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Maintenance windows by name: {\"kind\": \"allow\"|\"blackout\", \"operations\": [...], \"days\": [\"mon\", ...], \"start\": \"HH:MM\", \"end\": \"HH:MM\", \"timezone\": \"UTC\"}. Operations are scheduler job names, keys.rotate, jobs.requeue and jobs.clear_dead_letters",
+		},
+	})
+
+	config.OnReload("maintenance", func(old, new map[string]interface{}) {
+		raw, _ := new["windows"].(map[string]interface{})
+		windows, err := parseWindows(raw)
+		if err != nil {
+			core.GetLogger("maintenance").Error("Keeping previous windows: %v", err)
+			return
+		}
+		instance.SetWindows(windows)
+	})
+	core.Register(&maintenanceComponent{})
+
+	admin.HandleFunc("GET /maintenance", handleStatus)
+	admin.RegisterAction("maintenance.override", setOverride)
+	admin.HandleFunc("DELETE /maintenance/overrides/{operation}", handleClearOverride)
+}
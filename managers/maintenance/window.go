@@ -0,0 +1,252 @@
+// managers/maintenance/window.go
+package maintenance
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+type WindowKind string
+
+const (
+	// Allow windows are the only times their operations may run.
+	Allow WindowKind = "allow"
+	// Blackout windows forbid their operations, even inside an allow window.
+	Blackout WindowKind = "blackout"
+)
+
+var ErrOutsideWindow = errors.New("operation not permitted at this time")
+
+// Window is a recurring daily time range. End before Start wraps past
+// midnight; Days restricts which weekdays the window opens on.
+type Window struct {
+	Name       string
+	Kind       WindowKind
+	Operations []string
+	Days       []time.Weekday
+	Start      time.Duration
+	End        time.Duration
+	Location   *time.Location
+}
+
+func (w *Window) appliesTo(operation string) bool {
+	for _, op := range w.Operations {
+		if op == "*" || op == operation {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Window) contains(t time.Time) bool {
+	t = t.In(w.Location)
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+
+	day := t.Weekday()
+	if w.End <= w.Start && offset < w.End {
+		// Still in the window that opened yesterday
+		day = (day + 6) % 7
+	} else if !(offset >= w.Start && (w.End <= w.Start || offset < w.End)) {
+		return false
+	}
+
+	if len(w.Days) == 0 {
+		return true
+	}
+	for _, d := range w.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+type Override struct {
+	Operation string    `json:"operation"`
+	Until     time.Time `json:"until"`
+	Reason    string    `json:"reason"`
+}
+
+type Gate struct {
+	mu        sync.RWMutex
+	windows   []Window
+	overrides map[string]Override
+	now       func() time.Time
+}
+
+var instance = NewGate(nil)
+
+func Get() *Gate {
+	return instance
+}
+
+func NewGate(windows []Window) *Gate {
+	return &Gate{
+		windows:   windows,
+		overrides: make(map[string]Override),
+		now:       time.Now,
+	}
+}
+
+func (g *Gate) SetWindows(windows []Window) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.windows = windows
+}
+
+// Allowed reports whether operation may run now and, if not, why.
+func (g *Gate) Allowed(operation string) (bool, string) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	now := g.now()
+	if o, ok := g.overrides[operation]; ok && now.Before(o.Until) {
+		return true, "override: " + o.Reason
+	}
+
+	restricted := false
+	inAllow := false
+	for i := range g.windows {
+		w := &g.windows[i]
+		if !w.appliesTo(operation) {
+			continue
+		}
+		switch w.Kind {
+		case Blackout:
+			if w.contains(now) {
+				return false, "inside blackout window " + w.Name
+			}
+		case Allow:
+			restricted = true
+			if w.contains(now) {
+				inAllow = true
+			}
+		}
+	}
+
+	if restricted && !inAllow {
+		return false, "outside maintenance windows"
+	}
+	return true, ""
+}
+
+// Check is Allowed as an error, for automation that should skip or retry
+// later. Runs permitted only by an override are audit logged.
+func (g *Gate) Check(operation string) error {
+	ok, reason := g.Allowed(operation)
+	if !ok {
+		core.IncrCounterWithLabels("maintenance.blocked", map[string]string{"operation": operation})
+		return fmt.Errorf("%w: %s %s", ErrOutsideWindow, operation, reason)
+	}
+	if strings.HasPrefix(reason, "override: ") {
+		core.GetLogger("audit").With(map[string]interface{}{
+			"operation": operation,
+			"reason":    strings.TrimPrefix(reason, "override: "),
+		}).Warn("Operation permitted by maintenance override")
+	}
+	return nil
+}
+
+// SetOverride permits operation regardless of windows until the given time.
+func (g *Gate) SetOverride(o Override) {
+	g.mu.Lock()
+	g.overrides[o.Operation] = o
+	g.mu.Unlock()
+
+	core.GetLogger("audit").With(map[string]interface{}{
+		"operation": o.Operation,
+		"until":     o.Until.Format(time.RFC3339),
+		"reason":    o.Reason,
+	}).Warn("Maintenance window override set")
+}
+
+func (g *Gate) ClearOverride(operation string) {
+	g.mu.Lock()
+	delete(g.overrides, operation)
+	g.mu.Unlock()
+
+	core.GetLogger("audit").With(map[string]interface{}{"operation": operation}).Info("Maintenance window override cleared")
+}
+
+func (g *Gate) Overrides() []Override {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	now := g.now()
+	result := make([]Override, 0, len(g.overrides))
+	for _, o := range g.overrides {
+		if now.Before(o.Until) {
+			result = append(result, o)
+		}
+	}
+	return result
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseWindow builds a Window from its config form:
+// {"kind": "allow", "operations": ["payouts"], "days": ["mon"],
+// "start": "02:00", "end": "04:00", "timezone": "UTC"}.
+func ParseWindow(name string, raw map[string]interface{}) (Window, error) {
+	str := func(k string) string {
+		s, _ := raw[k].(string)
+		return s
+	}
+
+	w := Window{Name: name, Kind: WindowKind(str("kind"))}
+	if w.Kind == "" {
+		w.Kind = Allow
+	}
+	if w.Kind != Allow && w.Kind != Blackout {
+		return w, fmt.Errorf("window %s: invalid kind %q", name, w.Kind)
+	}
+
+	var err error
+	if w.Start, err = parseClock(str("start")); err != nil {
+		return w, fmt.Errorf("window %s: start: %w", name, err)
+	}
+	if w.End, err = parseClock(str("end")); err != nil {
+		return w, fmt.Errorf("window %s: end: %w", name, err)
+	}
+
+	w.Location = time.UTC
+	if tz := str("timezone"); tz != "" {
+		if w.Location, err = time.LoadLocation(tz); err != nil {
+			return w, fmt.Errorf("window %s: %w", name, err)
+		}
+	}
+
+	ops, _ := raw["operations"].([]interface{})
+	for _, op := range ops {
+		w.Operations = append(w.Operations, fmt.Sprintf("%v", op))
+	}
+	if len(w.Operations) == 0 {
+		w.Operations = []string{"*"}
+	}
+
+	days, _ := raw["days"].([]interface{})
+	for _, d := range days {
+		day, ok := weekdays[strings.ToLower(fmt.Sprintf("%.3s", d))]
+		if !ok {
+			return w, fmt.Errorf("window %s: invalid day %v", name, d)
+		}
+		w.Days = append(w.Days, day)
+	}
+	return w, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("expected HH:MM, got %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
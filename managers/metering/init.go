@@ -0,0 +1,202 @@
+// managers/metering/init.go
+package metering
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data"
+	"github.com/polkadot-go/helper/managers/admin"
+)
+
+type meteringComponent struct{}
+
+func (c *meteringComponent) Name() string {
+	return "metering"
+}
+
+func (c *meteringComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *meteringComponent) Init() error {
+	cfg := config.Get()
+
+	storeName := cfg.GetString("metering", "store")
+	if storeName == "" {
+		return nil
+	}
+
+	raw, _ := cfg.Get("metering", "api_keys").(map[string]interface{})
+	apiKeys := make(map[string]string, len(raw))
+	for key, v := range raw {
+		tenant, ok := v.(string)
+		if !ok || tenant == "" {
+			return fmt.Errorf("metering.api_keys entries must map a key to a tenant name")
+		}
+		apiKeys[key] = tenant
+	}
+
+	// The store's component may initialize after this one, so it is looked
+	// up when usage is flushed.
+	resolve := func() (data.Store, error) {
+		if !core.IsInitialized(storeName) {
+			return nil, fmt.Errorf("metering store %s is not initialized", storeName)
+		}
+		provider, ok := core.GetComponent(storeName).(data.StoreProvider)
+		if !ok || provider.Store() == nil {
+			return nil, fmt.Errorf("metering store %s does not provide a store", storeName)
+		}
+		return provider.Store(), nil
+	}
+
+	rawLimits, _ := cfg.Get("metering", "limits").(map[string]interface{})
+	limits, err := ParseLimits(rawLimits)
+	if err != nil {
+		return fmt.Errorf("metering.limits: %w", err)
+	}
+
+	instance = New(resolve, apiKeys, cfg.GetDuration("metering", "flush_interval"))
+	instance.SetLimits(limits)
+	instance.Start()
+	return nil
+}
+
+func (c *meteringComponent) Shutdown(ctx context.Context) error {
+	if instance != nil {
+		return instance.Stop(ctx)
+	}
+	return nil
+}
+
+// handleUsage reports persisted rollups for one tenant, or this instance's
+// in-memory totals for every tenant when none is given.
+func handleUsage(w http.ResponseWriter, r *http.Request) {
+	if instance == nil {
+		admin.WriteJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "metering is disabled"})
+		return
+	}
+
+	q := r.URL.Query()
+	tenant := q.Get("tenant")
+	if tenant == "" {
+		admin.WriteJSON(w, http.StatusOK, instance.Totals())
+		return
+	}
+
+	granularity := q.Get("granularity")
+	if granularity == "" {
+		granularity = Daily
+	}
+	to := time.Now()
+	from := to.Add(-7 * 24 * time.Hour)
+	for name, t := range map[string]*time.Time{"from": &from, "to": &to} {
+		if v := q.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				admin.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": name + " must be RFC 3339"})
+				return
+			}
+			*t = parsed
+		}
+	}
+
+	buckets, err := instance.Report(r.Context(), tenant, granularity, from, to)
+	if err != nil {
+		admin.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	total := make(map[string]int64)
+	for _, b := range buckets {
+		for kind, n := range b.Usage {
+			total[kind] += n
+		}
+	}
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"tenant":      tenant,
+		"granularity": granularity,
+		"total":       total,
+		"buckets":     buckets,
+	})
+}
+
+// setLimit is the metering.set_limit action. It sets a tenant's daily limit
+// for one kind, or removes it with a limit of 0, by patching
+// metering.limits, and with persist also writes it to the config file.
+func setLimit(ctx context.Context, params map[string]interface{}) error {
+	tenant, _ := params["tenant"].(string)
+	kind, _ := params["kind"].(string)
+	limit, ok := toInt64(params["limit"])
+	if tenant == "" || kind == "" || !ok || limit < 0 {
+		return fmt.Errorf("metering.set_limit needs a tenant, a kind and a limit of 0 or more")
+	}
+	persist, _ := params["persist"].(bool)
+
+	var value interface{} = limit
+	if limit == 0 {
+		value = nil
+	}
+	patch := map[string]interface{}{"metering": map[string]interface{}{
+		"limits": map[string]interface{}{tenant: map[string]interface{}{kind: value}},
+	}}
+	if _, err := config.Get().ApplyPatch(patch, persist); err != nil {
+		return err
+	}
+	core.ContextLogger(ctx, core.GetLogger("audit")).With(map[string]interface{}{
+		"tenant":    tenant,
+		"kind":      kind,
+		"limit":     limit,
+		"persisted": persist,
+	}).Info("Usage limit changed")
+	return nil
+}
+
+func init() {
+	config.Register("metering", config.Schema{
+		"store": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Component name of the store usage rollups are persisted to (empty disables metering)",
+		},
+		"api_keys": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Secret:      true,
+			Description: "Tenant name by API key; unknown keys are metered under a hash of the key",
+		},
+		"flush_interval": config.Field{
+			Default:     "1m",
+			Required:    false,
+			Description: "How often in-memory usage is added to the persisted rollups",
+		},
+		"limits": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Daily usage limits by tenant and kind, e.g. {\"acme\": {\"requests\": 100000}}; change them with the metering.set_limit action",
+			Validator:   validateLimits,
+		},
+	})
+
+	config.OnReload("metering", func(old, new map[string]interface{}) {
+		meter := Get()
+		if meter == nil {
+			return
+		}
+		raw, _ := new["limits"].(map[string]interface{})
+		limits, err := ParseLimits(raw)
+		if err != nil {
+			core.GetLogger("metering").Error("Keeping previous limits: %v", err)
+			return
+		}
+		meter.SetLimits(limits)
+	})
+	core.Register(&meteringComponent{})
+
+	admin.HandleFunc("GET /metering/usage", handleUsage)
+	admin.RegisterAction("metering.set_limit", setLimit)
+}
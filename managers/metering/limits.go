@@ -0,0 +1,115 @@
+// managers/metering/limits.go
+package metering
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/polkadot-go/helper/data"
+)
+
+// Limits caps each tenant's usage of a kind per UTC day, by tenant and
+// kind. A tenant's usage counts what every instance has added to the daily
+// rollup, as of this instance's last flush, plus what this instance has
+// recorded since.
+type Limits map[string]map[string]int64
+
+// ParseLimits reads limits from config, e.g.
+// {"acme": {"requests": 100000, "rpc_calls": 5000}}.
+func ParseLimits(raw map[string]interface{}) (Limits, error) {
+	limits := make(Limits, len(raw))
+	for tenant, v := range raw {
+		kinds, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("limits for %s must map kinds to numbers", tenant)
+		}
+		limits[tenant] = make(map[string]int64, len(kinds))
+		for kind, n := range kinds {
+			max, ok := toInt64(n)
+			if !ok || max < 1 {
+				return nil, fmt.Errorf("limit of %s for %s must be a positive number", kind, tenant)
+			}
+			limits[tenant][kind] = max
+		}
+	}
+	return limits, nil
+}
+
+func validateLimits(v interface{}) error {
+	raw, ok := v.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("limits must map tenants to kinds and limits")
+	}
+	_, err := ParseLimits(raw)
+	return err
+}
+
+// SetLimits replaces the daily limits. Usage of newly limited tenants is
+// only known from the next flush on.
+func (m *Meter) SetLimits(limits Limits) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.limits = limits
+}
+
+// Exceeded returns a kind whose daily limit tenant has reached.
+func (m *Meter) Exceeded(tenant string) (string, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kinds := make([]string, 0, len(m.limits[tenant]))
+	for kind := range m.limits[tenant] {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+
+	day := dayOf(time.Now())
+	for _, kind := range kinds {
+		if m.spent[usageKey{tenant: tenant, kind: kind, hour: day}] >= m.limits[tenant][kind] {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// refreshSpent reloads today's usage of limited tenants from the daily
+// rollups.
+func (m *Meter) refreshSpent(ctx context.Context, store data.Store) error {
+	m.mu.Lock()
+	limits := m.limits
+	m.mu.Unlock()
+
+	now := time.Now()
+	day := dayOf(now)
+	spent := make(map[usageKey]int64)
+	for tenant, kinds := range limits {
+		for kind := range kinds {
+			v, err := store.Get(ctx, rollupKey(tenant, kind, Daily, now))
+			if err != nil {
+				return err
+			}
+			n, _ := toInt64(v)
+			spent[usageKey{tenant: tenant, kind: kind, hour: day}] = n
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	// Usage recorded since the flush took it is not in the rollups yet
+	for k, n := range m.pending {
+		key := usageKey{tenant: k.tenant, kind: k.kind, hour: day}
+		if _, ok := spent[key]; ok && k.hour >= day {
+			spent[key] += n
+		}
+	}
+	m.spent = spent
+	return nil
+}
+
+// dayOf returns the hour, counted like usageKey.hour, that t's UTC day
+// starts at.
+func dayOf(t time.Time) int64 {
+	return truncate(t.UTC(), Daily).Unix() / 3600
+}
@@ -0,0 +1,390 @@
+// managers/metering/metering.go
+package metering
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+	"github.com/polkadot-go/helper/managers/admin"
+)
+
+// Usage kinds recorded by the helpers in this package. Record accepts any
+// other kind as well.
+const (
+	KindRequests     = "requests"
+	KindRPCCalls     = "rpc_calls"
+	KindStorageBytes = "storage_bytes"
+)
+
+// Granularities usage is rolled up to when persisted.
+const (
+	Hourly = "hour"
+	Daily  = "day"
+)
+
+const maxReportBuckets = 1000
+
+// Meter counts usage per tenant and kind in memory and periodically adds it
+// to hourly and daily rollups in a Store.
+type Meter struct {
+	mu      sync.Mutex
+	pending map[usageKey]int64
+	totals  map[usageKey]int64
+	kinds   map[string]bool
+	apiKeys map[string]string
+	limits  Limits
+	// spent is today's usage of limited tenants, keyed by dayOf
+	spent map[usageKey]int64
+
+	resolve  func() (data.Store, error)
+	interval time.Duration
+	logger   core.Logger
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type usageKey struct {
+	tenant string
+	kind   string
+	hour   int64
+}
+
+// Bucket is one rollup period of a usage report.
+type Bucket struct {
+	Start time.Time        `json:"start"`
+	Usage map[string]int64 `json:"usage"`
+}
+
+var instance *Meter
+
+func Get() *Meter {
+	return instance
+}
+
+// New returns a meter that persists to the store resolve returns. apiKeys
+// maps API keys to tenant names.
+func New(resolve func() (data.Store, error), apiKeys map[string]string, interval time.Duration) *Meter {
+	return &Meter{
+		pending:  make(map[usageKey]int64),
+		totals:   make(map[usageKey]int64),
+		spent:    make(map[usageKey]int64),
+		kinds:    map[string]bool{KindRequests: true, KindRPCCalls: true, KindStorageBytes: true},
+		apiKeys:  apiKeys,
+		resolve:  resolve,
+		interval: interval,
+		logger:   core.GetLogger("metering"),
+	}
+}
+
+// Record adds n to tenant's usage of kind in the current hour.
+func (m *Meter) Record(tenant, kind string, n int64) {
+	if tenant == "" || n == 0 {
+		return
+	}
+	now := time.Now()
+	k := usageKey{tenant: tenant, kind: kind, hour: now.Unix() / 3600}
+
+	m.mu.Lock()
+	m.pending[k] += n
+	m.totals[usageKey{tenant: tenant, kind: kind}] += n
+	m.kinds[kind] = true
+	if _, ok := m.limits[tenant][kind]; ok {
+		m.spent[usageKey{tenant: tenant, kind: kind, hour: dayOf(now)}] += n
+	}
+	m.mu.Unlock()
+}
+
+// TenantForKey returns the tenant an API key is configured for. Unknown
+// keys are metered under a short hash, so the key itself is never stored.
+func (m *Meter) TenantForKey(apiKey string) string {
+	if tenant, ok := m.apiKeys[apiKey]; ok {
+		return tenant
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return "key-" + hex.EncodeToString(sum[:6])
+}
+
+// Middleware meters each request under the tenant of the API key in
+// header, and puts the tenant on the request context for WrapStore and
+// RecordRPC. Requests without a key pass through unmetered. Requests of a
+// tenant that reached one of its daily limits are refused with 429 until
+// the next UTC day.
+func (m *Meter) Middleware(header string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey := r.Header.Get(header)
+			if apiKey == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			tenant := m.TenantForKey(apiKey)
+			if kind, over := m.Exceeded(tenant); over {
+				core.IncrCounterWithLabels("metering.limited", map[string]string{"tenant": tenant, "kind": kind})
+				tomorrow := truncate(time.Now().UTC(), Daily).Add(24 * time.Hour)
+				w.Header().Set("Retry-After", strconv.Itoa(int(time.Until(tomorrow).Seconds())+1))
+				admin.WriteJSON(w, http.StatusTooManyRequests, map[string]string{"error": "daily " + kind + " limit reached"})
+				return
+			}
+			m.Record(tenant, KindRequests, 1)
+			next.ServeHTTP(w, r.WithContext(WithTenant(r.Context(), tenant)))
+		})
+	}
+}
+
+// RecordRPC meters one RPC call for the tenant on ctx.
+func (m *Meter) RecordRPC(ctx context.Context) {
+	m.Record(TenantFrom(ctx), KindRPCCalls, 1)
+}
+
+// Totals returns usage recorded by this instance since it started, by
+// tenant and kind.
+func (m *Meter) Totals() map[string]map[string]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make(map[string]map[string]int64)
+	for k, n := range m.totals {
+		if result[k.tenant] == nil {
+			result[k.tenant] = make(map[string]int64)
+		}
+		result[k.tenant][k.kind] = n
+	}
+	return result
+}
+
+func (m *Meter) Start() {
+	m.stop = make(chan struct{})
+	m.done = make(chan struct{})
+	go func() {
+		defer close(m.done)
+		core.Supervise("metering", m.stop, func() {
+			ticker := time.NewTicker(m.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-m.stop:
+					return
+				case <-ticker.C:
+					ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+					if err := m.Flush(ctx); err != nil {
+						m.logger.Warn("Flushing usage: %v", err)
+					}
+					cancel()
+				}
+			}
+		})
+	}()
+}
+
+// Stop ends the flush loop and flushes what is still pending.
+func (m *Meter) Stop(ctx context.Context) error {
+	if m.stop != nil {
+		close(m.stop)
+		<-m.done
+		m.stop = nil
+	}
+	return m.Flush(ctx)
+}
+
+// Flush adds pending usage to the hourly and daily rollups. Usage that
+// cannot be written stays pending for the next flush.
+func (m *Meter) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	pending := m.pending
+	m.pending = make(map[usageKey]int64)
+	m.mu.Unlock()
+
+	m.mu.Lock()
+	limited := len(m.limits) > 0
+	m.mu.Unlock()
+	if len(pending) == 0 && !limited {
+		return nil
+	}
+
+	store, err := m.resolve()
+	if err != nil {
+		m.requeue(pending)
+		return err
+	}
+
+	var firstErr error
+	for k, n := range pending {
+		hour := time.Unix(k.hour*3600, 0).UTC()
+		err := add(ctx, store, rollupKey(k.tenant, k.kind, Hourly, hour), n)
+		if err == nil {
+			err = add(ctx, store, rollupKey(k.tenant, k.kind, Daily, hour), n)
+			if err != nil {
+				// The hourly rollup already has it; only the daily one is retried
+				m.logger.Error("Daily rollup for %s/%s lost %d after the hourly one was written: %v", k.tenant, k.kind, n, err)
+				core.IncrCounterWithLabels("metering.flush_errors", map[string]string{"rollup": Daily})
+				continue
+			}
+		}
+		if err != nil {
+			core.IncrCounterWithLabels("metering.flush_errors", map[string]string{"rollup": Hourly})
+			m.requeue(map[usageKey]int64{k: n})
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	core.IncrCounter("metering.flushes")
+
+	if limited {
+		if err := m.refreshSpent(ctx, store); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *Meter) requeue(pending map[usageKey]int64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for k, n := range pending {
+		m.pending[k] += n
+	}
+}
+
+// Report returns tenant's persisted usage per bucket of granularity from
+// from up to to.
+func (m *Meter) Report(ctx context.Context, tenant, granularity string, from, to time.Time) ([]Bucket, error) {
+	step, ok := map[string]time.Duration{Hourly: time.Hour, Daily: 24 * time.Hour}[granularity]
+	if !ok {
+		return nil, fmt.Errorf("granularity must be %s or %s", Hourly, Daily)
+	}
+	start := truncate(from.UTC(), granularity)
+	if n := to.Sub(start) / step; n > maxReportBuckets {
+		return nil, fmt.Errorf("report spans %d buckets, more than %d", n, maxReportBuckets)
+	}
+
+	store, err := m.resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	kinds := make([]string, 0, len(m.kinds))
+	for kind := range m.kinds {
+		kinds = append(kinds, kind)
+	}
+	m.mu.Unlock()
+	sort.Strings(kinds)
+
+	var buckets []Bucket
+	for t := start; t.Before(to); t = t.Add(step) {
+		b := Bucket{Start: t, Usage: make(map[string]int64)}
+		for _, kind := range kinds {
+			v, err := store.Get(ctx, rollupKey(tenant, kind, granularity, t))
+			if err != nil {
+				return nil, err
+			}
+			if n, ok := toInt64(v); ok && n != 0 {
+				b.Usage[kind] = n
+			}
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, nil
+}
+
+func truncate(t time.Time, granularity string) time.Time {
+	if granularity == Daily {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(time.Hour)
+}
+
+func rollupKey(tenant, kind, granularity string, t time.Time) string {
+	layout := "2006010215"
+	if granularity == Daily {
+		layout = "20060102"
+	}
+	return fmt.Sprintf("metering:%s:%s:%s:%s", granularity, t.UTC().Format(layout), tenant, kind)
+}
+
+// add increments key atomically when the store supports it, and otherwise
+// reads and writes it back, which can lose updates between instances.
+func add(ctx context.Context, store data.Store, key string, n int64) error {
+	if cs, ok := store.(data.CacheStore); ok {
+		_, err := cs.Increment(ctx, key, n)
+		return err
+	}
+	v, err := store.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	current, _ := toInt64(v)
+	return store.Set(ctx, key, current+n)
+}
+
+func toInt64(v interface{}) (int64, bool) {
+	switch val := v.(type) {
+	case int64:
+		return val, true
+	case int:
+		return int64(val), true
+	case float64:
+		return int64(val), true
+	case string:
+		n, err := strconv.ParseInt(val, 10, 64)
+		return n, err == nil
+	case []byte:
+		n, err := strconv.ParseInt(string(val), 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+type tenantKey struct{}
+
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+func TenantFrom(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
+
+// WrapStore meters the size of values written through store under the
+// tenant on the write's context.
+func (m *Meter) WrapStore(store data.Store) data.Store {
+	return &meteredStore{Store: store, meter: m}
+}
+
+type meteredStore struct {
+	data.Store
+	meter *Meter
+}
+
+func (s *meteredStore) Set(ctx context.Context, key string, value interface{}) error {
+	if err := s.Store.Set(ctx, key, value); err != nil {
+		return err
+	}
+	if tenant := TenantFrom(ctx); tenant != "" {
+		s.meter.Record(tenant, KindStorageBytes, int64(len(key)+valueSize(value)))
+	}
+	return nil
+}
+
+func valueSize(v interface{}) int {
+	switch val := v.(type) {
+	case string:
+		return len(val)
+	case []byte:
+		return len(val)
+	case nil:
+		return 0
+	}
+	return len(fmt.Sprint(v))
+}
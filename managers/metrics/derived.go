@@ -0,0 +1,606 @@
+// managers/metrics/derived.go
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// Derived evaluates operator-defined expressions over the metrics already
+// recorded and publishes each result as a gauge named after its rule.
+//
+// Expressions combine numbers and metric references with + - * / and
+// parentheses. A reference is a metric name, optionally with a label
+// selector, and sums every matching series:
+//
+//	mysql.errors
+//	cache.requests{result="miss"}
+//	histogram.db.query.p99
+//
+// increase(ref, 5m) and rate(ref, 5m) turn a counter into its growth, or
+// growth per second, over a window:
+//
+//	increase(mysql.errors, 5m) / increase(mysql.query.count, 5m)
+type Derived struct {
+	mu       sync.Mutex
+	rules    []rule
+	history  []snapshot
+	interval time.Duration
+	logger   core.Logger
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+type rule struct {
+	name string
+	expr node
+}
+
+type snapshot struct {
+	at     time.Time
+	values map[string]float64
+}
+
+var derived *Derived
+
+// GetDerived returns the derived metrics evaluator, or nil when no rules
+// are configured.
+func GetDerived() *Derived {
+	return derived
+}
+
+func NewDerived(interval time.Duration) *Derived {
+	return &Derived{interval: interval, logger: core.GetLogger("metrics")}
+}
+
+// SetRules parses exprs, keyed by the gauge each one publishes, and
+// replaces the current rules only if all of them parse.
+func (d *Derived) SetRules(exprs map[string]string) error {
+	names := make([]string, 0, len(exprs))
+	for name := range exprs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rules := make([]rule, 0, len(names))
+	for _, name := range names {
+		expr, err := parseExpr(exprs[name])
+		if err != nil {
+			return fmt.Errorf("derived metric %s: %w", name, err)
+		}
+		rules = append(rules, rule{name: name, expr: expr})
+	}
+
+	d.mu.Lock()
+	d.rules = rules
+	d.mu.Unlock()
+	return nil
+}
+
+func (d *Derived) Start() {
+	d.stop = make(chan struct{})
+	d.done = make(chan struct{})
+	go func() {
+		defer close(d.done)
+		core.Supervise("derived_metrics", d.stop, func() {
+			ticker := time.NewTicker(d.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-d.stop:
+					return
+				case <-ticker.C:
+					d.Evaluate(time.Now())
+				}
+			}
+		})
+	}()
+}
+
+func (d *Derived) Stop() {
+	if d.stop != nil {
+		close(d.stop)
+		<-d.done
+		d.stop = nil
+	}
+}
+
+// Evaluate records a snapshot of current metrics and publishes every rule.
+// A rule whose value is undefined, e.g. a ratio over a window with no
+// traffic, keeps its previous value.
+func (d *Derived) Evaluate(now time.Time) {
+	current := snapshot{at: now, values: numericMetrics()}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.history = append(d.history, current)
+	d.trim(now)
+
+	ctx := &evalContext{current: current, history: d.history}
+	for _, r := range d.rules {
+		value, err := r.expr.eval(ctx)
+		if err != nil {
+			core.IncrCounterWithLabels("metrics.derived_errors", map[string]string{"rule": r.name})
+			d.logger.Debug("Evaluating derived metric %s: %v", r.name, err)
+			continue
+		}
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			continue
+		}
+		core.SetFloatGauge(r.name, value)
+	}
+}
+
+// trim keeps one snapshot at or before the longest window any rule uses,
+// plus everything after it.
+func (d *Derived) trim(now time.Time) {
+	var longest time.Duration
+	for _, r := range d.rules {
+		if w := r.expr.window(); w > longest {
+			longest = w
+		}
+	}
+
+	cutoff := now.Add(-longest)
+	keep := 0
+	for i, s := range d.history {
+		if !s.at.After(cutoff) {
+			keep = i
+		}
+	}
+	d.history = d.history[keep:]
+}
+
+// numericMetrics flattens GetMetrics to float values keyed like it is.
+func numericMetrics() map[string]float64 {
+	values := make(map[string]float64)
+	for key, v := range core.GetMetrics() {
+		switch val := v.(type) {
+		case int64:
+			values[key] = float64(val)
+		case int:
+			values[key] = float64(val)
+		case float64:
+			values[key] = val
+		}
+	}
+	return values
+}
+
+type evalContext struct {
+	current snapshot
+	history []snapshot
+}
+
+// at returns the newest snapshot at least window old, or the oldest one
+// when the history does not reach back that far yet.
+func (c *evalContext) at(window time.Duration) snapshot {
+	cutoff := c.current.at.Add(-window)
+	found := c.history[0]
+	for _, s := range c.history {
+		if s.at.After(cutoff) {
+			break
+		}
+		found = s
+	}
+	return found
+}
+
+type node interface {
+	eval(ctx *evalContext) (float64, error)
+	window() time.Duration
+}
+
+type number float64
+
+func (n number) eval(*evalContext) (float64, error) { return float64(n), nil }
+func (n number) window() time.Duration              { return 0 }
+
+type binary struct {
+	op          byte
+	left, right node
+}
+
+func (b *binary) eval(ctx *evalContext) (float64, error) {
+	l, err := b.left.eval(ctx)
+	if err != nil {
+		return 0, err
+	}
+	r, err := b.right.eval(ctx)
+	if err != nil {
+		return 0, err
+	}
+	switch b.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	}
+	if r == 0 {
+		return math.NaN(), nil
+	}
+	return l / r, nil
+}
+
+func (b *binary) window() time.Duration {
+	return max(b.left.window(), b.right.window())
+}
+
+type negate struct {
+	operand node
+}
+
+func (n *negate) eval(ctx *evalContext) (float64, error) {
+	v, err := n.operand.eval(ctx)
+	return -v, err
+}
+
+func (n *negate) window() time.Duration { return n.operand.window() }
+
+// reference sums the series of one metric whose labels include selector.
+type reference struct {
+	name     string
+	selector map[string]string
+}
+
+func (r *reference) eval(ctx *evalContext) (float64, error) {
+	sum, found := r.sum(ctx.current)
+	if !found {
+		return 0, fmt.Errorf("no series for %s", r.name)
+	}
+	return sum, nil
+}
+
+func (r *reference) window() time.Duration { return 0 }
+
+func (r *reference) sum(s snapshot) (float64, bool) {
+	var sum float64
+	found := false
+	for key, v := range s.values {
+		if r.matches(key) {
+			sum += v
+			found = true
+		}
+	}
+	return sum, found
+}
+
+// matches accepts keys with or without their kind prefix, such as
+// "counter.", and with the label set anywhere in the name, as histogram
+// keys carry it before the quantile suffix.
+func (r *reference) matches(key string) bool {
+	base, labels := splitSeries(key)
+	if base != r.name {
+		found := false
+		for _, kind := range []string{"counter.", "gauge.", "histogram."} {
+			if base == kind+r.name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	for k, v := range r.selector {
+		if labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// windowed is increase() or rate() over a counter.
+type windowed struct {
+	fn  string
+	ref *reference
+	dur time.Duration
+}
+
+func (w *windowed) eval(ctx *evalContext) (float64, error) {
+	now, found := w.ref.sum(ctx.current)
+	if !found {
+		return 0, fmt.Errorf("no series for %s", w.ref.name)
+	}
+	past := ctx.at(w.dur)
+	then, _ := w.ref.sum(past)
+
+	increase := now - then
+	if increase < 0 {
+		// The counter was reset, e.g. by a restart of whatever feeds it
+		increase = now
+	}
+	if w.fn == "increase" {
+		return increase, nil
+	}
+	elapsed := ctx.current.at.Sub(past.at).Seconds()
+	if elapsed <= 0 {
+		return math.NaN(), nil
+	}
+	return increase / elapsed, nil
+}
+
+func (w *windowed) window() time.Duration { return w.dur }
+
+func splitSeries(key string) (string, map[string]string) {
+	open := strings.IndexByte(key, '{')
+	if open < 0 {
+		return key, nil
+	}
+	end := strings.LastIndexByte(key, '}')
+	if end < open {
+		return key, nil
+	}
+	return key[:open] + key[end+1:], parseLabels(key[open+1 : end])
+}
+
+// parseLabels reads k="v",... as written by the core metrics package.
+func parseLabels(s string) map[string]string {
+	labels := make(map[string]string)
+	for s != "" {
+		eq := strings.IndexByte(s, '=')
+		if eq < 0 || eq+1 >= len(s) || s[eq+1] != '"' {
+			break
+		}
+		key := strings.TrimSpace(s[:eq])
+		value, rest, ok := readQuoted(s[eq+1:])
+		if !ok {
+			break
+		}
+		labels[key] = value
+		s = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+	}
+	return labels
+}
+
+func readQuoted(s string) (string, string, bool) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 < len(s) {
+				i++
+				if s[i] == 'n' {
+					b.WriteByte('\n')
+				} else {
+					b.WriteByte(s[i])
+				}
+			}
+		case '"':
+			return b.String(), s[i+1:], true
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", "", false
+}
+
+// parseExpr parses a derived metric expression.
+func parseExpr(s string) (node, error) {
+	p := &parser{input: s}
+	p.next()
+	n, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at %d", p.tok.text, p.tok.pos)
+	}
+	return n, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokDuration
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+type parser struct {
+	input string
+	pos   int
+	tok   token
+	err   error
+}
+
+func (p *parser) next() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+	start := p.pos
+	if p.pos >= len(p.input) {
+		p.tok = token{kind: tokEOF, pos: start}
+		return
+	}
+
+	c := p.input[p.pos]
+	switch {
+	case c >= '0' && c <= '9' || c == '.':
+		for p.pos < len(p.input) && (isDigit(p.input[p.pos]) || p.input[p.pos] == '.') {
+			p.pos++
+		}
+		kind := tokNumber
+		for p.pos < len(p.input) && (isLetter(p.input[p.pos]) || isDigit(p.input[p.pos])) {
+			kind = tokDuration
+			p.pos++
+		}
+		p.tok = token{kind: kind, text: p.input[start:p.pos], pos: start}
+	case isLetter(c) || c == '_':
+		for p.pos < len(p.input) && (isLetter(p.input[p.pos]) || isDigit(p.input[p.pos]) || strings.IndexByte("_.:", p.input[p.pos]) >= 0) {
+			p.pos++
+		}
+		if p.pos < len(p.input) && p.input[p.pos] == '{' {
+			end := strings.IndexByte(p.input[p.pos:], '}')
+			if end < 0 {
+				p.tok = token{kind: tokOp, text: "{", pos: p.pos}
+				p.pos = len(p.input)
+				return
+			}
+			p.pos += end + 1
+		}
+		p.tok = token{kind: tokIdent, text: p.input[start:p.pos], pos: start}
+	default:
+		p.pos++
+		p.tok = token{kind: tokOp, text: string(c), pos: start}
+	}
+}
+
+func isDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isLetter(c byte) bool { return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+
+// expr := term { ("+" | "-") term }
+func (p *parser) expr() (node, error) {
+	left, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp && (p.tok.text == "+" || p.tok.text == "-") {
+		op := p.tok.text[0]
+		p.next()
+		right, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// term := unary { ("*" | "/") unary }
+func (p *parser) term() (node, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.tok.kind == tokOp && (p.tok.text == "*" || p.tok.text == "/") {
+		op := p.tok.text[0]
+		p.next()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binary{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+// unary := "-" unary | primary
+func (p *parser) unary() (node, error) {
+	if p.tok.kind == tokOp && p.tok.text == "-" {
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &negate{operand: operand}, nil
+	}
+	return p.primary()
+}
+
+// primary := number | "(" expr ")" | fn "(" ref "," duration ")" | ref
+func (p *parser) primary() (node, error) {
+	tok := p.tok
+	switch tok.kind {
+	case tokNumber:
+		p.next()
+		v, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok.text)
+		}
+		return number(v), nil
+	case tokIdent:
+		p.next()
+		if p.tok.kind == tokOp && p.tok.text == "(" {
+			return p.call(tok)
+		}
+		return parseReference(tok.text)
+	case tokOp:
+		if tok.text == "(" {
+			p.next()
+			n, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return n, nil
+		}
+	case tokEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", tok.text, tok.pos)
+}
+
+func (p *parser) call(fn token) (node, error) {
+	if fn.text != "increase" && fn.text != "rate" {
+		return nil, fmt.Errorf("unknown function %s", fn.text)
+	}
+	p.next()
+
+	if p.tok.kind != tokIdent {
+		return nil, fmt.Errorf("%s expects a metric at %d", fn.text, p.tok.pos)
+	}
+	ref, err := parseReference(p.tok.text)
+	if err != nil {
+		return nil, err
+	}
+	p.next()
+
+	if err := p.expect(","); err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokDuration {
+		return nil, fmt.Errorf("%s expects a window such as 5m at %d", fn.text, p.tok.pos)
+	}
+	dur, err := time.ParseDuration(p.tok.text)
+	if err != nil || dur <= 0 {
+		return nil, fmt.Errorf("invalid window %q", p.tok.text)
+	}
+	p.next()
+
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	return &windowed{fn: fn.text, ref: ref, dur: dur}, nil
+}
+
+func (p *parser) expect(op string) error {
+	if p.tok.kind != tokOp || p.tok.text != op {
+		return fmt.Errorf("expected %q at %d", op, p.tok.pos)
+	}
+	p.next()
+	return nil
+}
+
+func parseReference(text string) (*reference, error) {
+	name, selector, hasSelector := strings.Cut(text, "{")
+	ref := &reference{name: name}
+	if hasSelector {
+		inner := strings.TrimSuffix(selector, "}")
+		ref.selector = parseLabels(inner)
+		if strings.TrimSpace(inner) != "" && len(ref.selector) == 0 {
+			return nil, fmt.Errorf("invalid label selector in %s", text)
+		}
+	}
+	return ref, nil
+}
@@ -0,0 +1,255 @@
+// managers/metrics/init.go
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/polkadot-go/helper"
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+)
+
+type metricsHTTPComponent struct{}
+
+func (c *metricsHTTPComponent) Name() string {
+	return "metrics_http"
+}
+
+func (c *metricsHTTPComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *metricsHTTPComponent) Init() error {
+	cfg := config.Get()
+
+	address := cfg.GetString("metrics_http", "address")
+	if address == "" {
+		return nil
+	}
+
+	instance = NewServer(address, cfg.GetString("metrics_http", "path"))
+	return instance.Start()
+}
+
+func (c *metricsHTTPComponent) Shutdown(ctx context.Context) error {
+	if instance != nil {
+		return instance.Stop(ctx)
+	}
+	return nil
+}
+
+type derivedComponent struct{}
+
+func (c *derivedComponent) Name() string {
+	return "derived_metrics"
+}
+
+func (c *derivedComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *derivedComponent) Init() error {
+	cfg := config.Get()
+
+	exprs, err := ruleExprs(cfg.Get("derived_metrics", "rules"))
+	if err != nil || len(exprs) == 0 {
+		return err
+	}
+
+	d := NewDerived(cfg.GetDuration("derived_metrics", "interval"))
+	if err := d.SetRules(exprs); err != nil {
+		return err
+	}
+	derived = d
+	derived.Start()
+	return nil
+}
+
+func (c *derivedComponent) Shutdown(ctx context.Context) error {
+	if derived != nil {
+		derived.Stop()
+		derived = nil
+	}
+	return nil
+}
+
+type statsdComponent struct{}
+
+func (c *statsdComponent) Name() string {
+	return "statsd"
+}
+
+func (c *statsdComponent) ConfigSections() []string {
+	return []string{"metrics"}
+}
+
+func (c *statsdComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *statsdComponent) Init() error {
+	cfg := config.Get()
+
+	address := cfg.GetString("metrics", "statsd_address")
+	if address == "" {
+		return nil
+	}
+
+	raw, _ := cfg.Get("metrics", "statsd_tags").(map[string]interface{})
+	tags := make(map[string]string, len(raw))
+	for k, v := range raw {
+		tags[k] = fmt.Sprint(v)
+	}
+
+	s, err := NewStatsD(address, cfg.GetString("metrics", "statsd_prefix"),
+		cfg.GetBool("metrics", "statsd_dogstatsd"), tags, cfg.GetDuration("metrics", "statsd_interval"))
+	if err != nil {
+		return err
+	}
+	statsd = s
+	statsd.Start()
+	return nil
+}
+
+func (c *statsdComponent) Shutdown(ctx context.Context) error {
+	if statsd != nil {
+		err := statsd.Stop()
+		statsd = nil
+		return err
+	}
+	return nil
+}
+
+type runtimeComponent struct{}
+
+func (c *runtimeComponent) Name() string {
+	return "runtime_metrics"
+}
+
+func (c *runtimeComponent) ConfigSections() []string {
+	return []string{"metrics"}
+}
+
+func (c *runtimeComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *runtimeComponent) Init() error {
+	cfg := config.Get()
+	if !cfg.GetBool("metrics", "runtime") {
+		return nil
+	}
+
+	runtimeCollector = NewRuntimeCollector(cfg.GetDuration("metrics", "runtime_interval"))
+	runtimeCollector.Start()
+	return nil
+}
+
+func (c *runtimeComponent) Shutdown(ctx context.Context) error {
+	if runtimeCollector != nil {
+		runtimeCollector.Stop()
+		runtimeCollector = nil
+	}
+	return nil
+}
+
+func ruleExprs(raw interface{}) (map[string]string, error) {
+	rules, _ := raw.(map[string]interface{})
+	exprs := make(map[string]string, len(rules))
+	for name, v := range rules {
+		expr, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("derived_metrics.rules.%s must be a string expression", name)
+		}
+		exprs[name] = expr
+	}
+	return exprs, nil
+}
+
+func init() {
+	config.Register("metrics_http", config.Schema{
+		"address": config.Field{
+			Default:     "127.0.0.1:9090",
+			Required:    false,
+			Description: "Bind address for the Prometheus metrics endpoint, e.g. :9090 for remote scrapers (empty disables it)",
+		},
+		"path": config.Field{
+			Default:     "/metrics",
+			Required:    false,
+			Description: "HTTP path serving metrics",
+		},
+	})
+
+	config.Register("derived_metrics", config.Schema{
+		"rules": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Gauges computed from other metrics, by name, e.g. {\"error_rate\": \"increase(mysql.errors, 5m) / increase(mysql.query.count, 5m)\"}",
+		},
+		"interval": config.Field{
+			Default:     "15s",
+			Required:    false,
+			Description: "How often derived metrics are evaluated",
+		},
+	})
+
+	config.Register("metrics", config.Schema{
+		"statsd_address": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "StatsD agent to push metrics to, host:port (UDP) or unix:///path (empty disables pushing)",
+		},
+		"statsd_prefix": config.Field{
+			Default:     "helper",
+			Required:    false,
+			Description: "Prefix prepended to every pushed metric name",
+		},
+		"statsd_interval": config.Field{
+			Default:     "10s",
+			Required:    false,
+			Description: "How often metrics are pushed",
+		},
+		"statsd_dogstatsd": config.Field{
+			Default:     true,
+			Required:    false,
+			Description: "Send labels as DogStatsD tags; plain StatsD gets label values appended to the name instead",
+		},
+		"statsd_tags": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Tags added to every pushed metric in DogStatsD mode, e.g. {\"env\": \"prod\"}",
+		},
+		"runtime": config.Field{
+			Default:     false,
+			Required:    false,
+			Description: "Record Go runtime metrics: goroutines, heap, GC pauses and open file descriptors",
+		},
+		"runtime_interval": config.Field{
+			Default:     "15s",
+			Required:    false,
+			Description: "How often runtime metrics are sampled",
+		},
+	})
+}
+
+// Register adds the metrics endpoint, derived metrics, statsd export and
+// runtime metrics components to app.
+func Register(app *helper.App) {
+	app.RegisterComponent(&metricsHTTPComponent{})
+	app.RegisterComponent(&derivedComponent{})
+	app.RegisterComponent(&statsdComponent{})
+	app.RegisterComponent(&runtimeComponent{})
+	config.OnReload("derived_metrics", func(old, new map[string]interface{}) {
+		exprs, err := ruleExprs(new["rules"])
+		if err == nil && derived != nil {
+			err = derived.SetRules(exprs)
+		}
+		if err != nil {
+			core.GetLogger("metrics").Error("Keeping previous derived metrics: %v", err)
+		}
+	})
+	core.RegisterPreflight("metrics_http", "port_free", func(ctx context.Context) error {
+		return core.CheckPortFree(config.Get().GetString("metrics_http", "address"))
+	})
+}
@@ -0,0 +1,105 @@
+// managers/metrics/runtime.go
+package metrics
+
+import (
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// RuntimeCollector records Go runtime statistics as core metrics: gauges
+// for goroutines, heap and open file descriptors, and a histogram of GC
+// pauses in microseconds.
+type RuntimeCollector struct {
+	interval time.Duration
+	lastGC   uint32
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+var runtimeCollector *RuntimeCollector
+
+func NewRuntimeCollector(interval time.Duration) *RuntimeCollector {
+	return &RuntimeCollector{interval: interval}
+}
+
+func (r *RuntimeCollector) Start() {
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		core.Supervise("runtime_metrics", r.stop, func() {
+			ticker := time.NewTicker(r.interval)
+			defer ticker.Stop()
+			for {
+				r.Collect()
+				select {
+				case <-r.stop:
+					return
+				case <-ticker.C:
+				}
+			}
+		})
+	}()
+}
+
+func (r *RuntimeCollector) Stop() {
+	if r.stop != nil {
+		close(r.stop)
+		<-r.done
+		r.stop = nil
+	}
+}
+
+// Collect takes one sample. ReadMemStats briefly stops the world, which is
+// why collection is opt-in and periodic.
+func (r *RuntimeCollector) Collect() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	core.SetGauge("runtime.goroutines", int64(runtime.NumGoroutine()))
+	core.SetGauge("runtime.heap_alloc_bytes", int64(m.HeapAlloc))
+	core.SetGauge("runtime.heap_inuse_bytes", int64(m.HeapInuse))
+	core.SetGauge("runtime.heap_objects", int64(m.HeapObjects))
+	core.SetGauge("runtime.sys_bytes", int64(m.Sys))
+	core.SetGauge("runtime.total_alloc_bytes", int64(m.TotalAlloc))
+	core.SetGauge("runtime.gc_cycles", int64(m.NumGC))
+
+	// PauseNs is a ring of the last 256 pauses; older ones are lost if
+	// more than that happened since the previous sample
+	first := r.lastGC
+	if m.NumGC-first > uint32(len(m.PauseNs)) {
+		first = m.NumGC - uint32(len(m.PauseNs))
+	}
+	for gc := first; gc < m.NumGC; gc++ {
+		pause := m.PauseNs[gc%uint32(len(m.PauseNs))]
+		core.RecordValue("runtime.gc_pause_us", float64(pause)/1e3)
+	}
+	r.lastGC = m.NumGC
+
+	if n, ok := openFDs(); ok {
+		core.SetGauge("runtime.open_fds", int64(n))
+	}
+}
+
+// openFDs counts this process's open file descriptors where the OS lists
+// them as a directory.
+func openFDs() (int, bool) {
+	var dir string
+	switch runtime.GOOS {
+	case "linux":
+		dir = "/proc/self/fd"
+	case "darwin", "freebsd":
+		dir = "/dev/fd"
+	default:
+		return 0, false
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, false
+	}
+	// Reading the directory holds one descriptor open itself
+	return len(entries) - 1, true
+}
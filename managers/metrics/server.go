@@ -0,0 +1,58 @@
+// managers/metrics/server.go
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+type Server struct {
+	address string
+	server  *http.Server
+	logger  core.Logger
+}
+
+var instance *Server
+
+func Get() *Server {
+	return instance
+}
+
+func NewServer(address, path string) *Server {
+	if path == "" {
+		path = "/metrics"
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(path, core.MetricsHandler())
+
+	return &Server{
+		address: address,
+		server:  &http.Server{Handler: mux},
+		logger:  core.GetLogger("metrics"),
+	}
+}
+
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.address)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Metrics server failed: %v", err)
+		}
+	}()
+
+	s.logger.Info("Serving metrics on %s", ln.Addr())
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
@@ -0,0 +1,240 @@
+// managers/metrics/statsd.go
+package metrics
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// maxPacket keeps datagrams under a typical MTU.
+const maxPacket = 1432
+
+// StatsD pushes the core metrics to a StatsD or DogStatsD agent. Counters
+// are sent as the increase since the previous flush; gauges and histogram
+// summaries (avg, min, max and quantiles) as gauges; histogram counts as
+// counters. DogStatsD receives labels as tags, plain StatsD has them
+// appended to the metric name in label name order.
+type StatsD struct {
+	conn      net.Conn
+	prefix    string
+	dogstatsd bool
+	tags      string
+	interval  time.Duration
+	logger    core.Logger
+
+	mu   sync.Mutex
+	last map[string]float64
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+var statsd *StatsD
+
+func GetStatsD() *StatsD {
+	return statsd
+}
+
+// NewStatsD dials address, which is host:port for UDP or unix:///path for
+// a Unix datagram socket.
+func NewStatsD(address, prefix string, dogstatsd bool, tags map[string]string, interval time.Duration) (*StatsD, error) {
+	network := "udp"
+	if path, ok := strings.CutPrefix(address, "unix://"); ok {
+		network, address = "unixgram", path
+	}
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, fmt.Errorf("dialing statsd at %s: %w", address, err)
+	}
+
+	if prefix != "" && !strings.HasSuffix(prefix, ".") {
+		prefix += "."
+	}
+	return &StatsD{
+		conn:      conn,
+		prefix:    prefix,
+		dogstatsd: dogstatsd,
+		tags:      formatTags(tags),
+		interval:  interval,
+		logger:    core.GetLogger("metrics"),
+		last:      make(map[string]float64),
+	}, nil
+}
+
+func (s *StatsD) Start() {
+	s.stop = make(chan struct{})
+	s.done = make(chan struct{})
+	go func() {
+		defer close(s.done)
+		core.Supervise("statsd", s.stop, func() {
+			ticker := time.NewTicker(s.interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-s.stop:
+					return
+				case <-ticker.C:
+					if err := s.Flush(); err != nil {
+						s.logger.Warn("Pushing metrics to statsd: %v", err)
+					}
+				}
+			}
+		})
+	}()
+}
+
+// Stop ends the flush loop, pushes a final flush and closes the socket.
+func (s *StatsD) Stop() error {
+	if s.stop != nil {
+		close(s.stop)
+		<-s.done
+		s.stop = nil
+	}
+	err := s.Flush()
+	if closeErr := s.conn.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// Flush sends every metric once. A failed write is reported after the
+// rest have been attempted.
+func (s *StatsD) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := core.GetMetrics()
+	keys := make([]string, 0, len(snapshot))
+	for key := range snapshot {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var (
+		packet   []byte
+		firstErr error
+		sent     int
+	)
+	send := func() {
+		if len(packet) == 0 {
+			return
+		}
+		if _, err := s.conn.Write(packet); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		packet = packet[:0]
+	}
+
+	for _, key := range keys {
+		value, ok := toFloat(snapshot[key])
+		if !ok {
+			continue
+		}
+		kind, series, _ := strings.Cut(key, ".")
+		name, labels := splitSeries(series)
+
+		metricType := "g"
+		if kind == "counter" || kind == "histogram" && strings.HasSuffix(name, ".count") {
+			metricType = "c"
+			delta := value - s.last[key]
+			if delta < 0 {
+				// The counter was reset; everything it holds is new
+				delta = value
+			}
+			s.last[key] = value
+			if delta == 0 {
+				continue
+			}
+			value = delta
+		}
+
+		line := s.line(name, labels, value, metricType)
+		if len(packet) > 0 && len(packet)+1+len(line) > maxPacket {
+			send()
+		}
+		if len(packet) > 0 {
+			packet = append(packet, '\n')
+		}
+		packet = append(packet, line...)
+		sent++
+	}
+	send()
+
+	core.SetGauge("metrics.statsd.lines", int64(sent))
+	if firstErr != nil {
+		core.IncrCounter("metrics.statsd.errors")
+	}
+	return firstErr
+}
+
+func (s *StatsD) line(name string, labels map[string]string, value float64, metricType string) string {
+	var b strings.Builder
+	b.WriteString(s.prefix)
+	b.WriteString(sanitizeStatsD(name))
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	if !s.dogstatsd {
+		for _, k := range keys {
+			b.WriteByte('.')
+			b.WriteString(sanitizeStatsD(labels[k]))
+		}
+	}
+
+	b.WriteByte(':')
+	b.WriteString(strconv.FormatFloat(value, 'f', -1, 64))
+	b.WriteByte('|')
+	b.WriteString(metricType)
+
+	if s.dogstatsd && (len(keys) > 0 || s.tags != "") {
+		b.WriteString("|#")
+		b.WriteString(s.tags)
+		for i, k := range keys {
+			if i > 0 || s.tags != "" {
+				b.WriteByte(',')
+			}
+			b.WriteString(sanitizeStatsD(k))
+			b.WriteByte(':')
+			b.WriteString(sanitizeStatsD(labels[k]))
+		}
+	}
+	return b.String()
+}
+
+func formatTags(tags map[string]string) string {
+	parts := make([]string, 0, len(tags))
+	for k, v := range tags {
+		parts = append(parts, sanitizeStatsD(k)+":"+sanitizeStatsD(v))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ",")
+}
+
+var statsdEscaper = strings.NewReplacer(":", "_", "|", "_", "@", "_", "#", "_", ",", "_", "\n", "_", " ", "_")
+
+func sanitizeStatsD(s string) string {
+	return statsdEscaper.Replace(s)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch val := v.(type) {
+	case int64:
+		return float64(val), true
+	case int:
+		return float64(val), true
+	case float64:
+		return val, true
+	}
+	return 0, false
+}
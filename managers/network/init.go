@@ -3,10 +3,14 @@ package network
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"time"
 
+	"github.com/polkadot-go/helper"
 	"github.com/polkadot-go/helper/core"
 	"github.com/polkadot-go/helper/core/config"
-	"github.com/polkadot-go/helper/data/mysql"
+	"github.com/polkadot-go/helper/managers/admin"
 )
 
 type networkComponent struct{}
@@ -15,24 +19,46 @@ func (c *networkComponent) Name() string {
 	return "network_manager"
 }
 
+func (c *networkComponent) ConfigSections() []string {
+	return []string{"network"}
+}
+
 func (c *networkComponent) Dependencies() []string {
-	return []string{"config", "logger", "mysql"}
+	return []string{"config", "logger", "scheduler"}
 }
 
 func (c *networkComponent) Init() error {
 	cfg := config.Get()
 
-	mysqlStore := mysql.Get()
-	instance = New(mysqlStore)
-
 	interval := cfg.GetDuration("network", "check_interval")
-	if interval > 0 {
-		instance.interval = interval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	timeout := cfg.GetDuration("network", "timeout")
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	retries := cfg.GetInt("network", "max_retries")
+	if retries < 0 {
+		retries = 0
+	}
+	raw, _ := cfg.Get("network", "targets").([]interface{})
+	targets, err := parseTargets(raw, Target{
+		Interval:   interval,
+		Timeout:    timeout,
+		MaxRetries: retries,
+		Backoff:    cfg.GetDuration("network", "retry_backoff"),
+	})
+	if err != nil {
+		return err
 	}
 
-	instance.Start()
+	instance = New(targets)
+	if err := instance.Start(); err != nil {
+		return err
+	}
 
-	core.RegisterHealthCheck("network_manager", instance)
+	core.RegisterHealthCheckKind("network_manager", instance, core.LivenessCheck|core.ReadinessCheck)
 	return nil
 }
 
@@ -43,24 +69,58 @@ func (c *networkComponent) Shutdown(ctx context.Context) error {
 	return nil
 }
 
+func handleProbes(w http.ResponseWriter, r *http.Request) {
+	n := Get()
+	if n == nil {
+		admin.WriteJSON(w, http.StatusServiceUnavailable, map[string]string{"error": "network manager is not running"})
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, n.Status())
+}
+
+func validateTargets(v interface{}) error {
+	raw, ok := v.([]interface{})
+	if !ok {
+		return fmt.Errorf("must be a list")
+	}
+	_, err := parseTargets(raw, Target{})
+	return err
+}
+
 func init() {
 	config.Register("network", config.Schema{
 		"check_interval": config.Field{
 			Default:     "30s",
 			Required:    false,
-			Description: "Network check interval",
+			Description: "Default interval between probes of each target",
 		},
 		"timeout": config.Field{
 			Default:     "10s",
 			Required:    false,
-			Description: "Network check timeout",
+			Description: "Default timeout of each probe",
+		},
+		"targets": config.Field{
+			Default:     []interface{}{},
+			Required:    false,
+			Description: "Probe targets: objects with name, type (tcp, http, ws, dns), address, and optional expect_status, interval, timeout, max_retries, backoff and critical",
+			Validator:   validateTargets,
 		},
 		"max_retries": config.Field{
 			Default:     3,
 			Required:    false,
-			Description: "Maximum retry attempts",
+			Description: "Times a failed probe is retried before the check counts as failed",
+		},
+		"retry_backoff": config.Field{
+			Default:     "1s",
+			Required:    false,
+			Description: "Wait before the first probe retry, doubled per retry",
 		},
 	})
+}
+
+// Register adds the network probe component and its admin route to app.
+func Register(app *helper.App) {
+	app.RegisterComponent(&networkComponent{})
 
-	core.Register(&networkComponent{})
+	admin.HandleFunc("GET /network/probes", handleProbes)
 }
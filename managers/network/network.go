@@ -3,90 +3,77 @@ package network
 
 import (
 	"context"
-	"sync"
-	"time"
+	"sync/atomic"
 
 	"github.com/polkadot-go/helper/core"
-	"github.com/polkadot-go/helper/data"
+	"github.com/polkadot-go/helper/managers/scheduler"
 )
 
+// NetworkManager probes the targets in network.targets, each on a
+// scheduler job of its own, and reports each as a health check named
+// network:<target>.
 type NetworkManager struct {
-	store    data.SQLStore
-	logger   *core.Logger
-	stopCh   chan struct{}
-	wg       sync.WaitGroup
-	interval time.Duration
+	logger  core.Logger
+	probes  []*probe
+	running atomic.Bool
 }
 
+const checkJob = "network_check"
+
 var instance *NetworkManager
 
 func Get() *NetworkManager {
 	return instance
 }
 
-func New(store data.SQLStore) *NetworkManager {
-	return &NetworkManager{
-		store:    store,
-		logger:   core.GetLogger("network"),
-		stopCh:   make(chan struct{}),
-		interval: 30 * time.Second,
+func New(targets []Target) *NetworkManager {
+	n := &NetworkManager{logger: core.GetLogger("network")}
+	for _, t := range targets {
+		n.probes = append(n.probes, newProbe(t))
 	}
+	return n
 }
 
-func (n *NetworkManager) Start() {
-	n.wg.Add(1)
-	go n.monitor()
-	n.logger.Info("Network manager started")
+func (n *NetworkManager) Start() error {
+	for i, p := range n.probes {
+		// Each attempt is bounded by the target's timeout, so runs are not
+		opts := scheduler.JobOptions{Interval: p.target.Interval, RunOnStart: true}
+		if err := scheduler.Get().Register(p.jobName(), opts, p.run); err != nil {
+			for _, started := range n.probes[:i] {
+				scheduler.Get().Remove(started.jobName())
+				core.UnregisterHealthCheck(started.healthName())
+			}
+			return err
+		}
+		core.RegisterHealthCheck(p.healthName(), p)
+	}
+	n.running.Store(true)
+	n.logger.Info("Network manager started with %d probe targets", len(n.probes))
+	return nil
 }
 
 func (n *NetworkManager) Stop() {
-	close(n.stopCh)
-	n.wg.Wait()
-	n.logger.Info("Network manager stopped")
-}
-
-func (n *NetworkManager) monitor() {
-	defer n.wg.Done()
-	ticker := time.NewTicker(n.interval)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			n.checkNetwork()
-		case <-n.stopCh:
-			return
-		}
+	for _, p := range n.probes {
+		scheduler.Get().Remove(p.jobName())
+		core.UnregisterHealthCheck(p.healthName())
 	}
+	n.running.Store(false)
+	n.logger.Info("Network manager stopped")
 }
 
-func (n *NetworkManager) checkNetwork() {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	start := time.Now()
-
-	// Example network check - verify database connection
-	rows, err := n.store.Query(ctx, "SELECT 1")
-	if err != nil {
-		n.logger.Error("Network check failed: %v", err)
-		core.IncrCounter("network.check.failed")
-	} else {
-		rows.Close()
+// Status returns the last probe outcome of every target.
+func (n *NetworkManager) Status() []ProbeStatus {
+	statuses := make([]ProbeStatus, 0, len(n.probes))
+	for _, p := range n.probes {
+		statuses = append(statuses, p.status())
 	}
-
-	core.RecordDuration("network.check", start)
-	core.IncrCounter("network.checks")
-
-	n.logger.Debug("Network check completed")
+	return statuses
 }
 
 func (n *NetworkManager) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
-	// Check if monitoring is running
-	select {
-	case <-n.stopCh:
+	// Targets report on their own; this only checks that probing runs
+	if !n.running.Load() {
 		return core.HealthUnhealthy, nil
-	default:
-		return core.HealthHealthy, nil
 	}
+	return core.HealthHealthy, nil
 }
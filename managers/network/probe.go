@@ -0,0 +1,376 @@
+// managers/network/probe.go
+package network
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/resilience"
+)
+
+// Target is a probe target from network.targets.
+type Target struct {
+	Name string `json:"name"`
+	// Type is tcp, http, ws or dns
+	Type string `json:"type"`
+	// Address is host:port for tcp, a URL for http and ws, and a host name
+	// for dns
+	Address string `json:"address"`
+	// ExpectStatus is the HTTP status an http probe expects; zero accepts
+	// any 2xx
+	ExpectStatus int `json:"expect_status,omitempty"`
+	// Interval, Timeout and MaxRetries default to network.check_interval,
+	// network.timeout and network.max_retries. Timeout bounds each attempt.
+	Interval   time.Duration `json:"interval"`
+	Timeout    time.Duration `json:"timeout"`
+	MaxRetries int           `json:"max_retries"`
+	// Backoff is the wait before the first retry, doubled per retry
+	Backoff time.Duration `json:"backoff"`
+	// Critical targets report unhealthy when down, others degraded
+	Critical bool `json:"critical,omitempty"`
+}
+
+// ProbeStatus is the outcome of a target's last probe.
+type ProbeStatus struct {
+	Target    Target        `json:"target"`
+	Status    string        `json:"status"`
+	LastCheck time.Time     `json:"last_check,omitzero"`
+	Latency   time.Duration `json:"latency"`
+	Error     string        `json:"error,omitempty"`
+	// ConsecutiveFailures counts checks that failed after all retries
+	// since the last success
+	ConsecutiveFailures int `json:"consecutive_failures"`
+}
+
+type probe struct {
+	target Target
+	labels map[string]string
+
+	mu          sync.Mutex
+	checked     bool
+	lastCheck   time.Time
+	latency     time.Duration
+	lastErr     error
+	consecutive int
+}
+
+func newProbe(target Target) *probe {
+	return &probe{
+		target: target,
+		labels: map[string]string{"target": target.Name, "type": target.Type},
+	}
+}
+
+func (p *probe) jobName() string {
+	return checkJob + ":" + p.target.Name
+}
+
+func (p *probe) healthName() string {
+	return "network:" + p.target.Name
+}
+
+// run checks the target, retrying failed probes with backoff, and records
+// the outcome. Only a check that fails every attempt counts as failed.
+func (p *probe) run(ctx context.Context) error {
+	start := time.Now()
+	var latency time.Duration
+	err := resilience.Retry(ctx, resilience.RetryPolicy{
+		MaxAttempts: p.target.MaxRetries + 1,
+		Backoff:     p.target.Backoff,
+		Jitter:      resilience.EqualJitter,
+		// An attempt that timed out is retried; the run being cancelled is
+		// not
+		Retryable: func(error) bool { return ctx.Err() == nil },
+		OnRetry: func(int, error, time.Duration) {
+			core.IncrCounterWithLabels("network.probe_retries", p.labels)
+		},
+	}, func(ctx context.Context) error {
+		ctx, cancel := context.WithTimeout(ctx, p.target.Timeout)
+		defer cancel()
+
+		attempt := time.Now()
+		err := probeFuncs[p.target.Type](ctx, p.target)
+		latency = time.Since(attempt)
+		core.RecordDurationWithLabels("network.probe", p.labels, attempt)
+		return err
+	})
+
+	core.IncrCounterWithLabels("network.probes", p.labels)
+	if err != nil {
+		core.IncrCounterWithLabels("network.probe_failures", p.labels)
+	}
+
+	p.mu.Lock()
+	p.checked = true
+	p.lastCheck = start
+	p.latency = latency
+	p.lastErr = err
+	if err != nil {
+		p.consecutive++
+	} else {
+		p.consecutive = 0
+	}
+	consecutive := p.consecutive
+	p.mu.Unlock()
+
+	core.SetGaugeWithLabels("network.probe_consecutive_failures", p.labels, int64(consecutive))
+	return err
+}
+
+func (p *probe) status() ProbeStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	s := ProbeStatus{
+		Target:              p.target,
+		Status:              core.HealthUnknown.String(),
+		LastCheck:           p.lastCheck,
+		Latency:             p.latency,
+		ConsecutiveFailures: p.consecutive,
+	}
+	if !p.checked {
+		return s
+	}
+	s.Status = core.HealthHealthy.String()
+	if p.lastErr != nil {
+		s.Status = p.failedStatus().String()
+		s.Error = p.lastErr.Error()
+	}
+	return s
+}
+
+func (p *probe) failedStatus() core.HealthStatus {
+	if p.target.Critical {
+		return core.HealthUnhealthy
+	}
+	return core.HealthDegraded
+}
+
+func (p *probe) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.checked {
+		// Only critical targets hold readiness back until their first check
+		if p.target.Critical {
+			return core.HealthUnknown, nil
+		}
+		return core.HealthDegraded, fmt.Errorf("not probed yet")
+	}
+	if p.lastErr != nil {
+		return p.failedStatus(), fmt.Errorf("%d consecutive failures: %w", p.consecutive, p.lastErr)
+	}
+	return core.HealthHealthy, nil
+}
+
+var probeFuncs = map[string]func(ctx context.Context, t Target) error{
+	"tcp":  probeTCP,
+	"http": probeHTTP,
+	"ws":   probeWebSocket,
+	"dns":  probeDNS,
+}
+
+func probeTCP(ctx context.Context, t Target) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", t.Address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+func probeHTTP(ctx context.Context, t Target) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, t.Address, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if t.ExpectStatus != 0 && resp.StatusCode != t.ExpectStatus {
+		return fmt.Errorf("status %d, expected %d", resp.StatusCode, t.ExpectStatus)
+	}
+	if t.ExpectStatus == 0 && (resp.StatusCode < 200 || resp.StatusCode > 299) {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// probeWebSocket performs an RFC 6455 opening handshake and closes the
+// connection without exchanging messages.
+func probeWebSocket(ctx context.Context, t Target) error {
+	u, err := url.Parse(t.Address)
+	if err != nil {
+		return err
+	}
+	host := u.Host
+	if u.Port() == "" {
+		if u.Scheme == "wss" {
+			host = net.JoinHostPort(u.Hostname(), "443")
+		} else {
+			host = net.JoinHostPort(u.Hostname(), "80")
+		}
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	if u.Scheme == "wss" {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: u.Hostname()})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			return err
+		}
+		conn = tlsConn
+	}
+
+	nonce := make([]byte, 16)
+	rand.Read(nonce)
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req := &http.Request{
+		Method: http.MethodGet,
+		URL:    &url.URL{Path: u.EscapedPath(), RawQuery: u.RawQuery},
+		Host:   u.Host,
+		Header: http.Header{
+			"Upgrade":               {"websocket"},
+			"Connection":            {"Upgrade"},
+			"Sec-Websocket-Key":     {key},
+			"Sec-Websocket-Version": {"13"},
+		},
+	}
+	if req.URL.Path == "" {
+		req.URL.Path = "/"
+	}
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		return fmt.Errorf("handshake status %d", resp.StatusCode)
+	}
+	sum := sha1.Sum([]byte(key + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+	if resp.Header.Get("Sec-WebSocket-Accept") != base64.StdEncoding.EncodeToString(sum[:]) {
+		return fmt.Errorf("invalid Sec-WebSocket-Accept")
+	}
+	return nil
+}
+
+func probeDNS(ctx context.Context, t Target) error {
+	addrs, err := net.DefaultResolver.LookupHost(ctx, t.Address)
+	if err != nil {
+		return err
+	}
+	if len(addrs) == 0 {
+		return fmt.Errorf("no addresses for %s", t.Address)
+	}
+	return nil
+}
+
+// parseTargets reads network.targets, filling in settings left out from
+// defaults.
+func parseTargets(raw []interface{}, defaults Target) ([]Target, error) {
+	seen := make(map[string]bool)
+	targets := make([]Target, 0, len(raw))
+	for i, item := range raw {
+		fields, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("network.targets[%d] must be an object", i)
+		}
+		t := Target{
+			Interval:   defaults.Interval,
+			Timeout:    defaults.Timeout,
+			MaxRetries: defaults.MaxRetries,
+			Backoff:    defaults.Backoff,
+		}
+		t.Name, _ = fields["name"].(string)
+		t.Type, _ = fields["type"].(string)
+		t.Address, _ = fields["address"].(string)
+		t.Critical, _ = fields["critical"].(bool)
+		if status, ok := fields["expect_status"].(float64); ok {
+			t.ExpectStatus = int(status)
+		}
+		if retries, ok := fields["max_retries"].(float64); ok {
+			if retries < 0 {
+				return nil, fmt.Errorf("network.targets[%d]: max_retries must not be negative", i)
+			}
+			t.MaxRetries = int(retries)
+		}
+
+		if t.Name == "" {
+			return nil, fmt.Errorf("network.targets[%d] needs a name", i)
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("network.targets: duplicate target %s", t.Name)
+		}
+		seen[t.Name] = true
+		if _, ok := probeFuncs[t.Type]; !ok {
+			return nil, fmt.Errorf("network.targets.%s: type must be tcp, http, ws or dns", t.Name)
+		}
+		if t.Address == "" {
+			return nil, fmt.Errorf("network.targets.%s needs an address", t.Name)
+		}
+		switch t.Type {
+		case "http":
+			if !strings.HasPrefix(t.Address, "http://") && !strings.HasPrefix(t.Address, "https://") {
+				return nil, fmt.Errorf("network.targets.%s: address must be an http(s) URL", t.Name)
+			}
+		case "ws":
+			if !strings.HasPrefix(t.Address, "ws://") && !strings.HasPrefix(t.Address, "wss://") {
+				return nil, fmt.Errorf("network.targets.%s: address must be a ws(s) URL", t.Name)
+			}
+		case "tcp":
+			if _, _, err := net.SplitHostPort(t.Address); err != nil {
+				return nil, fmt.Errorf("network.targets.%s: %v", t.Name, err)
+			}
+		}
+
+		for _, key := range []string{"interval", "timeout", "backoff"} {
+			s, ok := fields[key].(string)
+			if !ok {
+				continue
+			}
+			d, err := time.ParseDuration(s)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("network.targets.%s: invalid %s %q", t.Name, key, s)
+			}
+			switch key {
+			case "interval":
+				t.Interval = d
+			case "timeout":
+				t.Timeout = d
+			case "backoff":
+				t.Backoff = d
+			}
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
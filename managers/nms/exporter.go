@@ -0,0 +1,341 @@
+// managers/nms/exporter.go
+package nms
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+)
+
+// Exporter serves health and metrics over a line-based TCP protocol for
+// NOC tooling that polls SNMP-style OID tables but cannot scrape
+// Prometheus. Commands, one per line:
+//
+//	GET <oid>      one value
+//	GETNEXT <oid>  the first value after oid, in OID order
+//	WALK [<oid>]   every value under oid, terminated by a "." line
+//	QUIT
+//
+// Values are answered as "<oid> = <TYPE>: <value>".
+//
+// Table layout under the base OID:
+//
+//	.1.1.<i>  health check name
+//	.1.2.<i>  health status, as hrDeviceStatus: unknown(1) running(2)
+//	          warning(3) down(5)
+//	.1.3.<i>  health error text
+//	.2.1.<i>  metric name
+//	.2.2.<i>  metric value
+type Exporter struct {
+	address  string
+	base     []int
+	listener net.Listener
+	logger   core.Logger
+	wg       sync.WaitGroup
+	mu       sync.Mutex
+	conns    map[net.Conn]struct{}
+}
+
+type variable struct {
+	oid   []int
+	kind  string
+	value string
+}
+
+var instance *Exporter
+
+func Get() *Exporter {
+	return instance
+}
+
+func NewExporter(address, baseOID string) (*Exporter, error) {
+	base, err := parseOID(baseOID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base OID: %w", err)
+	}
+	return &Exporter{
+		address: address,
+		base:    base,
+		logger:  core.GetLogger("nms"),
+		conns:   make(map[net.Conn]struct{}),
+	}, nil
+}
+
+func (e *Exporter) Start() error {
+	ln, err := net.Listen("tcp", e.address)
+	if err != nil {
+		return err
+	}
+	e.listener = ln
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				if !errors.Is(err, net.ErrClosed) {
+					e.logger.Error("Accepting NMS connection: %v", err)
+				}
+				return
+			}
+			e.wg.Add(1)
+			go func() {
+				defer e.wg.Done()
+				e.serve(conn)
+			}()
+		}
+	}()
+
+	e.logger.Info("NMS exporter listening on %s", ln.Addr())
+	return nil
+}
+
+func (e *Exporter) Stop(ctx context.Context) error {
+	if e.listener == nil {
+		return nil
+	}
+	e.listener.Close()
+
+	e.mu.Lock()
+	for conn := range e.conns {
+		conn.Close()
+	}
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *Exporter) serve(conn net.Conn) {
+	e.mu.Lock()
+	e.conns[conn] = struct{}{}
+	e.mu.Unlock()
+	defer func() {
+		e.mu.Lock()
+		delete(e.conns, conn)
+		e.mu.Unlock()
+		conn.Close()
+	}()
+
+	reader := bufio.NewScanner(conn)
+	writer := bufio.NewWriter(conn)
+	for {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Minute))
+		if !reader.Scan() {
+			return
+		}
+
+		fields := strings.Fields(reader.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		core.IncrCounterWithLabels("nms.requests", map[string]string{"command": strings.ToUpper(fields[0])})
+
+		switch strings.ToUpper(fields[0]) {
+		case "GET", "GETNEXT":
+			if len(fields) != 2 {
+				fmt.Fprintf(writer, "ERROR usage: %s <oid>\n", strings.ToUpper(fields[0]))
+				break
+			}
+			oid, err := parseOID(fields[1])
+			if err != nil {
+				fmt.Fprintf(writer, "ERROR %v\n", err)
+				break
+			}
+			vars := e.snapshot()
+			if strings.EqualFold(fields[0], "GET") {
+				writeVar(writer, lookup(vars, oid), oid)
+			} else {
+				writeVar(writer, next(vars, oid), oid)
+			}
+		case "WALK":
+			prefix := e.base
+			if len(fields) > 1 {
+				oid, err := parseOID(fields[1])
+				if err != nil {
+					fmt.Fprintf(writer, "ERROR %v\n", err)
+					break
+				}
+				prefix = oid
+			}
+			for _, v := range e.snapshot() {
+				if hasPrefix(v.oid, prefix) {
+					writeVar(writer, &v, v.oid)
+				}
+			}
+			writer.WriteString(".\n")
+		case "QUIT":
+			writer.Flush()
+			return
+		default:
+			fmt.Fprintf(writer, "ERROR unknown command %s\n", fields[0])
+		}
+		writer.Flush()
+	}
+}
+
+// snapshot builds the OID table from current health and metrics.
+func (e *Exporter) snapshot() []variable {
+	var results map[string]core.HealthResult
+	if monitor := core.GetHealthMonitor(); monitor != nil {
+		results = monitor.LastResults()
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		results = core.CheckHealth(ctx)
+		cancel()
+	}
+
+	var vars []variable
+	add := func(suffix []int, kind, value string) {
+		oid := append(append([]int{}, e.base...), suffix...)
+		vars = append(vars, variable{oid: oid, kind: kind, value: value})
+	}
+
+	names := sortedNames(results)
+	for i, name := range names {
+		result := results[name]
+		errText := ""
+		if result.Error != nil {
+			errText = result.Error.Error()
+		}
+		add([]int{1, 1, i + 1}, "STRING", name)
+		add([]int{1, 2, i + 1}, "INTEGER", strconv.Itoa(deviceStatus(result.Status)))
+		add([]int{1, 3, i + 1}, "STRING", errText)
+	}
+
+	metrics := core.GetMetrics()
+	metricNames := sortedNames(metrics)
+	for i, name := range metricNames {
+		kind, value := formatValue(name, metrics[name])
+		add([]int{2, 1, i + 1}, "STRING", name)
+		add([]int{2, 2, i + 1}, kind, value)
+	}
+
+	sort.Slice(vars, func(i, j int) bool { return compareOID(vars[i].oid, vars[j].oid) < 0 })
+	return vars
+}
+
+// deviceStatus maps health onto HOST-RESOURCES-MIB hrDeviceStatus.
+func deviceStatus(status core.HealthStatus) int {
+	switch status {
+	case core.HealthHealthy:
+		return 2
+	case core.HealthDegraded:
+		return 3
+	case core.HealthUnhealthy:
+		return 5
+	}
+	return 1
+}
+
+// formatValue types counters as Counter64 and other integers as INTEGER.
+// SNMP has no float type, so fractional values are sent as strings.
+func formatValue(name string, v interface{}) (string, string) {
+	switch val := v.(type) {
+	case int64:
+		if strings.HasPrefix(name, "counter.") {
+			return "Counter64", strconv.FormatInt(val, 10)
+		}
+		return "INTEGER", strconv.FormatInt(val, 10)
+	case int:
+		return "INTEGER", strconv.Itoa(val)
+	case float64:
+		return "STRING", strconv.FormatFloat(val, 'f', -1, 64)
+	}
+	return "STRING", fmt.Sprintf("%v", v)
+}
+
+func writeVar(w *bufio.Writer, v *variable, requested []int) {
+	if v == nil {
+		fmt.Fprintf(w, "%s = NoSuchObject\n", formatOID(requested))
+		return
+	}
+	fmt.Fprintf(w, "%s = %s: %s\n", formatOID(v.oid), v.kind, strings.ReplaceAll(v.value, "\n", " "))
+}
+
+func lookup(vars []variable, oid []int) *variable {
+	for i := range vars {
+		if compareOID(vars[i].oid, oid) == 0 {
+			return &vars[i]
+		}
+	}
+	return nil
+}
+
+func next(vars []variable, oid []int) *variable {
+	for i := range vars {
+		if compareOID(vars[i].oid, oid) > 0 {
+			return &vars[i]
+		}
+	}
+	return nil
+}
+
+func parseOID(s string) ([]int, error) {
+	s = strings.TrimPrefix(s, ".")
+	if s == "" {
+		return nil, fmt.Errorf("empty OID")
+	}
+	parts := strings.Split(s, ".")
+	oid := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid OID %q", s)
+		}
+		oid[i] = n
+	}
+	return oid, nil
+}
+
+func formatOID(oid []int) string {
+	parts := make([]string, len(oid))
+	for i, n := range oid {
+		parts[i] = strconv.Itoa(n)
+	}
+	return "." + strings.Join(parts, ".")
+}
+
+func compareOID(a, b []int) int {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			if a[i] < b[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+	return len(a) - len(b)
+}
+
+func hasPrefix(oid, prefix []int) bool {
+	return len(oid) >= len(prefix) && compareOID(oid[:len(prefix)], prefix) == 0
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,62 @@
+// managers/nms/init.go
+package nms
+
+import (
+	"context"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+)
+
+type nmsComponent struct{}
+
+func (c *nmsComponent) Name() string {
+	return "nms"
+}
+
+func (c *nmsComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *nmsComponent) Init() error {
+	cfg := config.Get()
+
+	address := cfg.GetString("nms", "address")
+	if address == "" {
+		return nil
+	}
+
+	exporter, err := NewExporter(address, cfg.GetString("nms", "base_oid"))
+	if err != nil {
+		return err
+	}
+	instance = exporter
+	return instance.Start()
+}
+
+func (c *nmsComponent) Shutdown(ctx context.Context) error {
+	if instance != nil {
+		return instance.Stop(ctx)
+	}
+	return nil
+}
+
+func init() {
+	config.Register("nms", config.Schema{
+		"address": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Bind address for the NMS polling exporter (empty disables it)",
+		},
+		"base_oid": config.Field{
+			Default:     "1.3.6.1.4.1.99999.1",
+			Required:    false,
+			Description: "OID the health and metric tables are rooted at; set it to your enterprise arc",
+		},
+	})
+
+	core.Register(&nmsComponent{})
+	core.RegisterPreflight("nms", "port_free", func(ctx context.Context) error {
+		return core.CheckPortFree(config.Get().GetString("nms", "address"))
+	})
+}
@@ -0,0 +1,157 @@
+// managers/peer/init.go
+package peer
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/managers/admin"
+)
+
+type peerComponent struct {
+	server *http.Server
+}
+
+func (c *peerComponent) Name() string {
+	return "peer"
+}
+
+func (c *peerComponent) Dependencies() []string {
+	return []string{"config", "logger", "keys", "admin"}
+}
+
+func (c *peerComponent) Init() error {
+	cfg := config.Get()
+
+	keyName := cfg.GetString("peer", "key")
+	if keyName == "" {
+		return nil
+	}
+
+	raw, _ := cfg.Get("peer", "trusted_keys").(map[string]interface{})
+	trusted, err := ParseTrusted(raw)
+	if err != nil {
+		return err
+	}
+
+	name := cfg.GetString("peer", "name")
+	if name == "" {
+		name, _ = os.Hostname()
+	}
+	channel := New(keyName, name, trusted)
+	pub, err := channel.PublicKey()
+	if err != nil {
+		return fmt.Errorf("peer.key: %w", err)
+	}
+	// Fail now rather than on the first handshake if the key cannot sign
+	if _, err := channel.certificate(); err != nil {
+		return fmt.Errorf("peer.key: %w", err)
+	}
+	instance = channel
+
+	logger := core.GetLogger("peer")
+	if address := cfg.GetString("peer", "address"); address != "" {
+		server := admin.Get()
+		if server == nil {
+			return fmt.Errorf("peer.address needs the admin server enabled")
+		}
+		ln, err := net.Listen("tcp", address)
+		if err != nil {
+			return err
+		}
+		c.server = &http.Server{
+			Handler:           server.Handler(),
+			TLSConfig:         channel.ServerConfig(),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			if err := c.server.ServeTLS(ln, "", ""); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("Peer listener failed: %v", err)
+			}
+		}()
+		logger.Info("Peer listener on %s", ln.Addr())
+	}
+
+	logger.Info("Peer identity %s, trusting %d peers", hex.EncodeToString(pub), len(channel.TrustedPeers()))
+	return nil
+}
+
+func (c *peerComponent) Shutdown(ctx context.Context) error {
+	instance = nil
+	if c.server == nil {
+		return nil
+	}
+	err := c.server.Shutdown(ctx)
+	c.server = nil
+	return err
+}
+
+// handleIdentity serves this helper's peer public key, for adding it to
+// other helpers' peer.trusted_keys.
+func handleIdentity(w http.ResponseWriter, r *http.Request) {
+	channel := Get()
+	if channel == nil {
+		admin.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "no peer key configured"})
+		return
+	}
+	pub, err := channel.PublicKey()
+	if err != nil {
+		admin.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	admin.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"name":       channel.commonName,
+		"public_key": hex.EncodeToString(pub),
+		"trusted":    channel.TrustedPeers(),
+	})
+}
+
+func init() {
+	config.Register("peer", config.Schema{
+		"key": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Name of the ed25519 key in keys.private_keys that identifies this helper to its peers (empty disables the peer channel)",
+		},
+		"name": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Name put in this helper's peer certificate (defaults to the hostname)",
+		},
+		"address": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Address of the mutual TLS listener serving the admin endpoints to trusted peers (empty disables it)",
+		},
+		"trusted_keys": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Trusted peers by name, each a hex ed25519 public key or a list of keys while the peer rotates",
+		},
+	})
+
+	config.OnReload("peer", func(old, new map[string]interface{}) {
+		channel := Get()
+		if channel == nil {
+			return
+		}
+		raw, _ := new["trusted_keys"].(map[string]interface{})
+		trusted, err := ParseTrusted(raw)
+		if err != nil {
+			core.GetLogger("peer").Error("Keeping previous trusted peers: %v", err)
+			return
+		}
+		channel.SetTrusted(trusted)
+	})
+	core.Register(&peerComponent{})
+
+	admin.HandleFunc("GET /peer/identity", handleIdentity)
+}
@@ -0,0 +1,237 @@
+// managers/peer/peer.go
+package peer
+
+import (
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/managers/keys"
+)
+
+const (
+	// certValidity is how long generated certificates are valid; they are
+	// regenerated after half of it
+	certValidity = 24 * time.Hour
+)
+
+// Channel authenticates helpers to each other over mutual TLS. Each helper
+// presents a self-signed certificate for its identity key from the keyring
+// and accepts only peers whose public keys it trusts, so no CA is needed.
+// The identity key is looked up on every handshake, so a rotated key is
+// used as soon as the keyring has it.
+type Channel struct {
+	keyName    string
+	commonName string
+	logger     core.Logger
+
+	mu      sync.RWMutex
+	trusted map[string]string
+	cert    *tls.Certificate
+	certKey string
+	certAt  time.Time
+}
+
+var instance *Channel
+
+// Get returns the channel, or nil when no identity key is configured.
+func Get() *Channel {
+	return instance
+}
+
+// New returns a channel presenting the keyring key keyName. trusted maps
+// hex public keys to peer names.
+func New(keyName, commonName string, trusted map[string]string) *Channel {
+	return &Channel{
+		keyName:    keyName,
+		commonName: commonName,
+		logger:     core.GetLogger("peer"),
+		trusted:    trusted,
+	}
+}
+
+// SetTrusted replaces the trusted peer keys; connections already
+// established are not affected.
+func (c *Channel) SetTrusted(trusted map[string]string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trusted = trusted
+}
+
+// TrustedPeers returns the names of the trusted peers.
+func (c *Channel) TrustedPeers() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, name := range c.trusted {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (c *Channel) PublicKey() ([]byte, error) {
+	signer, err := c.signer()
+	if err != nil {
+		return nil, err
+	}
+	return signer.PublicKey(), nil
+}
+
+// ServerConfig requires clients to present a trusted key.
+func (c *Channel) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS13,
+		ClientAuth: tls.RequireAnyClientCert,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return c.certificate()
+		},
+		VerifyPeerCertificate: c.verify,
+	}
+}
+
+// ClientConfig presents this helper's key and accepts only trusted
+// servers. Chain verification is skipped because the certificates are
+// self-signed; verify pins the key instead.
+func (c *Channel) ClientConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS13,
+		InsecureSkipVerify: true,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.certificate()
+		},
+		VerifyPeerCertificate: c.verify,
+	}
+}
+
+// HTTPClient returns a client for https requests to trusted peers. Plain
+// http requests are sent as usual.
+func (c *Channel) HTTPClient(timeout time.Duration) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = c.ClientConfig()
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// PeerName returns the trusted peer that sent r over the channel.
+func (c *Channel) PeerName(r *http.Request) (string, bool) {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return "", false
+	}
+	pub, ok := r.TLS.PeerCertificates[0].PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return "", false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	name, ok := c.trusted[hex.EncodeToString(pub)]
+	return name, ok
+}
+
+func (c *Channel) signer() (keys.Signer, error) {
+	keyring := keys.Get()
+	if keyring == nil {
+		return nil, fmt.Errorf("keyring is not loaded")
+	}
+	return keyring.Signer(c.keyName)
+}
+
+// certificate returns the certificate for the current identity key,
+// generating a new one when the key changed or the old one is half way to
+// expiry.
+func (c *Channel) certificate() (*tls.Certificate, error) {
+	signer, err := c.signer()
+	if err != nil {
+		return nil, err
+	}
+	key := hex.EncodeToString(signer.PublicKey())
+
+	c.mu.RLock()
+	cert := c.cert
+	fresh := c.certKey == key && time.Since(c.certAt) < certValidity/2
+	c.mu.RUnlock()
+	if cert != nil && fresh {
+		return cert, nil
+	}
+
+	generated, err := keys.Certificate(signer, c.commonName, certValidity)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	if c.certKey != "" && c.certKey != key {
+		c.logger.Info("Peer identity key rotated to %s", key)
+	}
+	c.cert, c.certKey, c.certAt = &generated, key, time.Now()
+	c.mu.Unlock()
+	return &generated, nil
+}
+
+func (c *Channel) verify(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return c.reject("no certificate presented")
+	}
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return c.reject("invalid certificate: %v", err)
+	}
+	pub, ok := cert.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return c.reject("certificate key is not ed25519")
+	}
+	key := hex.EncodeToString(pub)
+
+	c.mu.RLock()
+	_, trusted := c.trusted[key]
+	c.mu.RUnlock()
+	if !trusted {
+		return c.reject("untrusted peer key %s", key)
+	}
+	core.IncrCounterWithLabels("peer.handshakes", map[string]string{"result": "accepted"})
+	return nil
+}
+
+func (c *Channel) reject(format string, args ...interface{}) error {
+	core.IncrCounterWithLabels("peer.handshakes", map[string]string{"result": "rejected"})
+	err := fmt.Errorf(format, args...)
+	c.logger.Warn("Rejected peer: %v", err)
+	return err
+}
+
+// ParseTrusted reads peer.trusted_keys: peer names mapped to a hex ed25519
+// public key, or a list of them while a peer rotates its key.
+func ParseTrusted(raw map[string]interface{}) (map[string]string, error) {
+	trusted := make(map[string]string)
+	for name, v := range raw {
+		var values []interface{}
+		switch val := v.(type) {
+		case string:
+			values = []interface{}{val}
+		case []interface{}:
+			values = val
+		default:
+			return nil, fmt.Errorf("peer.trusted_keys.%s must be a key or a list of keys", name)
+		}
+		for _, item := range values {
+			s, _ := item.(string)
+			key, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(s), "0x"))
+			if err != nil || len(key) != ed25519.PublicKeySize {
+				return nil, fmt.Errorf("peer.trusted_keys.%s: %q is not a hex ed25519 public key", name, s)
+			}
+			trusted[hex.EncodeToString(key)] = name
+		}
+	}
+	return trusted, nil
+}
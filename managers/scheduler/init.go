@@ -0,0 +1,86 @@
+// managers/scheduler/init.go
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/managers/admin"
+	"github.com/polkadot-go/helper/managers/maintenance"
+)
+
+type schedulerComponent struct{}
+
+func (c *schedulerComponent) Name() string {
+	return "scheduler"
+}
+
+func (c *schedulerComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *schedulerComponent) Init() error {
+	raw, _ := config.Get().Get("scheduler", "schedules").(map[string]interface{})
+	overrides := make(map[string]string, len(raw))
+	for name, v := range raw {
+		spec, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("scheduler.schedules.%s must be a string", name)
+		}
+		if spec != "off" {
+			if _, err := ParseSchedule(spec); err != nil {
+				return fmt.Errorf("scheduler.schedules.%s: %w", name, err)
+			}
+		}
+		overrides[name] = spec
+	}
+	instance.SetOverrides(overrides)
+
+	instance.Start()
+	core.RegisterHealthCheck("scheduler", instance)
+	return nil
+}
+
+func (c *schedulerComponent) Shutdown(ctx context.Context) error {
+	return instance.Stop(ctx)
+}
+
+func handleJobs(w http.ResponseWriter, r *http.Request) {
+	admin.WriteJSON(w, http.StatusOK, instance.Status())
+}
+
+func handleRunJob(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	if err := instance.RunNow(name); err != nil {
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, ErrUnknownJob):
+			status = http.StatusNotFound
+		case errors.Is(err, maintenance.ErrOutsideWindow):
+			status = http.StatusConflict
+		}
+		admin.WriteJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	core.LoggerFromContext(r.Context()).Info("Job %s triggered from %s", name, r.RemoteAddr)
+	admin.WriteJSON(w, http.StatusAccepted, map[string]string{"job": name})
+}
+
+func init() {
+	config.Register("scheduler", config.Schema{
+		"schedules": config.Field{
+			Default:     map[string]interface{}{},
+			Required:    false,
+			Description: "Schedule overrides by job name: a cron expression, \"@every <duration>\", or \"off\" to disable the job",
+		},
+	})
+
+	core.Register(&schedulerComponent{})
+
+	admin.HandleFunc("GET /scheduler/jobs", handleJobs)
+	admin.HandleFunc("POST /scheduler/jobs/{name}/run", handleRunJob)
+}
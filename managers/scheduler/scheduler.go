@@ -0,0 +1,382 @@
+// managers/scheduler/scheduler.go
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/managers/maintenance"
+)
+
+var (
+	ErrDuplicateJob = errors.New("job already registered")
+	ErrUnknownJob   = errors.New("unknown job")
+)
+
+// Job is the work a scheduled job does. ctx ends at the job's timeout or
+// when shutdown gives up waiting for it.
+type Job func(ctx context.Context) error
+
+// JobOptions configures a job. Exactly one of Schedule and Interval is
+// required.
+type JobOptions struct {
+	// Schedule is a cron expression or descriptor; see ParseSchedule
+	Schedule string
+	Interval time.Duration
+	// Timeout bounds each run; zero leaves runs unbounded
+	Timeout time.Duration
+	// AllowOverlap starts a run even while the previous one is still going;
+	// by default such a run is skipped
+	AllowOverlap bool
+	// RunOnStart also runs the job as soon as the scheduler starts
+	RunOnStart bool
+}
+
+// JobStatus is a job's schedule and the outcome of its last run.
+type JobStatus struct {
+	Name         string        `json:"name"`
+	Schedule     string        `json:"schedule"`
+	Next         time.Time     `json:"next,omitempty"`
+	Running      int           `json:"running"`
+	Runs         int64         `json:"runs"`
+	Failures     int64         `json:"failures"`
+	Skipped      int64         `json:"skipped"`
+	LastStart    time.Time     `json:"last_start,omitempty"`
+	LastDuration time.Duration `json:"last_duration,omitempty"`
+	LastError    string        `json:"last_error,omitempty"`
+}
+
+type job struct {
+	name     string
+	schedule Schedule
+	opts     JobOptions
+	fn       Job
+	labels   map[string]string
+	stop     chan struct{}
+	done     chan struct{}
+
+	mu     sync.Mutex
+	status JobStatus
+}
+
+// Scheduler runs registered jobs on their schedules. Jobs may be
+// registered before Start; they begin running once it is called.
+type Scheduler struct {
+	mu        sync.Mutex
+	jobs      map[string]*job
+	overrides map[string]string
+	started   bool
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+	logger  core.Logger
+}
+
+var instance = New()
+
+// Get returns the scheduler the scheduler component starts. Components
+// register their jobs on it from their own Init.
+func Get() *Scheduler {
+	return instance
+}
+
+func New() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		jobs:   make(map[string]*job),
+		ctx:    ctx,
+		cancel: cancel,
+		logger: core.GetLogger("scheduler"),
+	}
+}
+
+// Register adds a named job. A schedule set for the name in
+// SetOverrides takes precedence over opts.
+func (s *Scheduler) Register(name string, opts JobOptions, fn Job) error {
+	spec := opts.Schedule
+	if opts.Interval > 0 {
+		if spec != "" {
+			return fmt.Errorf("job %s: set Schedule or Interval, not both", name)
+		}
+		spec = "@every " + opts.Interval.String()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateJob, name)
+	}
+	if override, ok := s.overrides[name]; ok {
+		spec = override
+	}
+
+	j := &job{
+		name:   name,
+		opts:   opts,
+		fn:     fn,
+		labels: map[string]string{"job": name},
+		status: JobStatus{Name: name, Schedule: spec},
+	}
+	if spec != "off" {
+		schedule, err := ParseSchedule(spec)
+		if err != nil {
+			return fmt.Errorf("job %s: %w", name, err)
+		}
+		j.schedule = schedule
+	}
+
+	s.jobs[name] = j
+	if s.started {
+		s.startJob(j)
+	}
+	return nil
+}
+
+// Remove stops scheduling a job. A run in progress is left to finish.
+func (s *Scheduler) Remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if j, ok := s.jobs[name]; ok {
+		stopJob(j)
+		delete(s.jobs, name)
+	}
+}
+
+// SetOverrides replaces the schedules of jobs by name, "off" disabling a
+// job. It applies to jobs registered afterwards.
+func (s *Scheduler) SetOverrides(overrides map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.overrides = overrides
+}
+
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	if s.ctx.Err() != nil {
+		// A previous Stop gave up on its jobs
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	s.started = true
+	for _, j := range s.jobs {
+		s.startJob(j)
+	}
+	s.logger.Info("Scheduler started with %d jobs", len(s.jobs))
+}
+
+// Stop stops scheduling and waits for running jobs to finish. Once ctx is
+// done the jobs' contexts are cancelled and Stop returns without them.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.mu.Lock()
+	for _, j := range s.jobs {
+		stopJob(j)
+	}
+	s.started = false
+	cancel := s.cancel
+	s.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		s.running.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return fmt.Errorf("jobs still running at shutdown: %w", ctx.Err())
+	}
+}
+
+// RunNow starts a job immediately, outside its schedule, subject to its
+// overlap setting and maintenance windows.
+func (s *Scheduler) RunNow(name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	return s.trigger(j)
+}
+
+// Status lists every job by name.
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.Lock()
+	jobs := make([]*job, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		jobs = append(jobs, j)
+	}
+	s.mu.Unlock()
+
+	result := make([]JobStatus, 0, len(jobs))
+	for _, j := range jobs {
+		j.mu.Lock()
+		result = append(result, j.status)
+		j.mu.Unlock()
+	}
+	sort.Slice(result, func(i, k int) bool { return result[i].Name < result[k].Name })
+	return result
+}
+
+// HealthCheck reports degraded while any job's last run failed.
+func (s *Scheduler) HealthCheck(ctx context.Context) (core.HealthStatus, error) {
+	var failing []string
+	for _, st := range s.Status() {
+		if st.LastError != "" {
+			failing = append(failing, st.Name)
+		}
+	}
+	if len(failing) > 0 {
+		return core.HealthDegraded, fmt.Errorf("last run failed: %s", strings.Join(failing, ", "))
+	}
+	return core.HealthHealthy, nil
+}
+
+// startJob must be called with s.mu held.
+func (s *Scheduler) startJob(j *job) {
+	if j.schedule == nil {
+		return
+	}
+	j.stop = make(chan struct{})
+	j.done = make(chan struct{})
+	go s.loop(j, j.stop, j.done)
+}
+
+// stopJob must be called with s.mu held.
+func stopJob(j *job) {
+	if j.stop != nil {
+		close(j.stop)
+		<-j.done
+		j.stop = nil
+	}
+}
+
+func (s *Scheduler) loop(j *job, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	if j.opts.RunOnStart {
+		s.trigger(j)
+	}
+
+	for {
+		next := j.schedule.Next(time.Now())
+		j.mu.Lock()
+		j.status.Next = next
+		j.mu.Unlock()
+		if next.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+			s.trigger(j)
+		}
+	}
+}
+
+// trigger starts a run unless maintenance windows, checked with the job's
+// name as the operation, forbid it.
+func (s *Scheduler) trigger(j *job) error {
+	if err := maintenance.Get().Check(j.name); err != nil {
+		j.mu.Lock()
+		j.status.Skipped++
+		j.mu.Unlock()
+		core.IncrCounterWithLabels("scheduler.skipped", j.labels)
+		s.logger.Info("Skipping %s: %v", j.name, err)
+		return err
+	}
+
+	j.mu.Lock()
+	if j.status.Running > 0 && !j.opts.AllowOverlap {
+		j.status.Skipped++
+		j.mu.Unlock()
+		core.IncrCounterWithLabels("scheduler.skipped", j.labels)
+		s.logger.Warn("Skipping %s: previous run still in progress", j.name)
+		return nil
+	}
+	j.status.Running++
+	running := j.status.Running
+	j.mu.Unlock()
+	core.SetGaugeWithLabels("scheduler.running", j.labels, int64(running))
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		s.run(j)
+	}()
+	return nil
+}
+
+func (s *Scheduler) run(j *job) {
+	s.mu.Lock()
+	ctx := s.ctx
+	s.mu.Unlock()
+	if j.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.opts.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	j.mu.Lock()
+	j.status.LastStart = start
+	j.mu.Unlock()
+
+	err := s.runJob(ctx, j)
+	elapsed := time.Since(start)
+
+	j.mu.Lock()
+	j.status.Running--
+	j.status.Runs++
+	j.status.LastDuration = elapsed
+	j.status.LastError = ""
+	if err != nil {
+		j.status.Failures++
+		j.status.LastError = err.Error()
+	}
+	running := j.status.Running
+	j.mu.Unlock()
+
+	core.SetGaugeWithLabels("scheduler.running", j.labels, int64(running))
+	core.RecordDurationWithLabels("scheduler.duration", j.labels, start)
+	if err != nil {
+		core.IncrCounterWithLabels("scheduler.failures", j.labels)
+		s.logger.Error("Job %s failed after %s: %v", j.name, elapsed.Round(time.Millisecond), err)
+		return
+	}
+	core.IncrCounterWithLabels("scheduler.runs", j.labels)
+	core.SetGaugeWithLabels("scheduler.last_success", j.labels, time.Now().Unix())
+}
+
+// runJob calls the job, turning a panic into an error so that one bad job
+// cannot take the process down.
+func (s *Scheduler) runJob(ctx context.Context, j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Job %s panicked: %v\n%s", j.name, r, debug.Stack())
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.fn(ctx)
+}
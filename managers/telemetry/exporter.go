@@ -0,0 +1,113 @@
+// managers/telemetry/exporter.go
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/data"
+)
+
+type Snapshot struct {
+	RunID      string                  `json:"run_id"`
+	StartedAt  time.Time               `json:"started_at"`
+	FinishedAt time.Time               `json:"finished_at"`
+	Metrics    map[string]interface{}  `json:"metrics"`
+	Health     map[string]HealthRecord `json:"health"`
+}
+
+type HealthRecord struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Exporter struct {
+	file      string
+	store     data.Store
+	keyPrefix string
+	logger    core.Logger
+}
+
+func NewExporter(file string, store data.Store, keyPrefix string) *Exporter {
+	return &Exporter{
+		file:      file,
+		store:     store,
+		keyPrefix: keyPrefix,
+		logger:    core.GetLogger("telemetry"),
+	}
+}
+
+func TakeSnapshot(ctx context.Context) Snapshot {
+	var results map[string]core.HealthResult
+	if monitor := core.GetHealthMonitor(); monitor != nil {
+		results = monitor.LastResults()
+	} else {
+		results = core.CheckHealth(ctx)
+	}
+
+	health := make(map[string]HealthRecord, len(results))
+	for name, result := range results {
+		record := HealthRecord{Status: result.Status.String()}
+		if result.Error != nil {
+			record.Error = result.Error.Error()
+		}
+		health[name] = record
+	}
+
+	return Snapshot{
+		RunID:      core.RunID(),
+		StartedAt:  core.StartedAt(),
+		FinishedAt: time.Now(),
+		Metrics:    core.GetMetrics(),
+		Health:     health,
+	}
+}
+
+// Export writes the final snapshot to every configured destination,
+// attempting all of them before reporting the first failure.
+func (e *Exporter) Export(ctx context.Context) error {
+	payload, err := json.MarshalIndent(TakeSnapshot(ctx), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding telemetry snapshot: %w", err)
+	}
+
+	var firstErr error
+	if e.file != "" {
+		if err := e.writeFile(payload); err != nil {
+			e.logger.Error("Writing telemetry file: %v", err)
+			firstErr = err
+		}
+	}
+
+	if e.store != nil {
+		key := e.keyPrefix + core.RunID()
+		if err := e.store.Set(ctx, key, string(payload)); err != nil {
+			e.logger.Error("Writing telemetry to store: %v", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+		} else {
+			e.logger.Info("Telemetry snapshot stored under %s", key)
+		}
+	}
+
+	return firstErr
+}
+
+func (e *Exporter) writeFile(payload []byte) error {
+	path := strings.ReplaceAll(e.file, "{run_id}", core.RunID())
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, payload, 0644); err != nil {
+		return err
+	}
+	e.logger.Info("Telemetry snapshot written to %s", path)
+	return nil
+}
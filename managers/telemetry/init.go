@@ -0,0 +1,118 @@
+// managers/telemetry/init.go
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/polkadot-go/helper"
+	"github.com/polkadot-go/helper/core"
+	"github.com/polkadot-go/helper/core/config"
+	"github.com/polkadot-go/helper/data"
+)
+
+// settings is the telemetry section. It is bound rather than read once, so
+// a reload before shutdown changes where the snapshot goes.
+type settings struct {
+	ExportOnShutdown bool   `config:"export_on_shutdown"`
+	File             string `config:"file"`
+	Store            string `config:"store"`
+	KeyPrefix        string `config:"key_prefix"`
+}
+
+type telemetryComponent struct {
+	settings *config.Bound[settings]
+}
+
+func (c *telemetryComponent) Name() string {
+	return "telemetry"
+}
+
+func (c *telemetryComponent) Dependencies() []string {
+	return []string{"config", "logger"}
+}
+
+func (c *telemetryComponent) Init() error {
+	// Bound once; a restart keeps the binding and the hook
+	if c.settings != nil {
+		return c.settings.Load().check()
+	}
+	bound, err := config.Bind[settings]("telemetry")
+	if err != nil {
+		return err
+	}
+	if err := bound.Load().check(); err != nil {
+		return err
+	}
+	c.settings = bound
+
+	// Pre-shutdown hooks run while every component is still up, so the
+	// store can be resolved then even if it initializes after this one.
+	core.RegisterPreShutdownHook(c.export)
+	return nil
+}
+
+func (s *settings) check() error {
+	if s.ExportOnShutdown && s.File == "" && s.Store == "" {
+		return fmt.Errorf("telemetry export enabled without a file or store destination")
+	}
+	return nil
+}
+
+func (c *telemetryComponent) export(ctx context.Context) error {
+	s := c.settings.Load()
+	if !s.ExportOnShutdown {
+		return nil
+	}
+
+	exporter := NewExporter(s.File, nil, s.KeyPrefix)
+	if err := s.check(); err != nil {
+		exporter.logger.Error("Skipping telemetry export: %v", err)
+		return nil
+	}
+	if s.Store != "" {
+		provider, ok := core.GetComponent(s.Store).(data.StoreProvider)
+		if !ok || provider.Store() == nil {
+			exporter.logger.Error("Telemetry store %s is not available", s.Store)
+		} else {
+			exporter.store = provider.Store()
+		}
+	}
+	// A failed export must not block the rest of shutdown
+	exporter.Export(ctx)
+	return nil
+}
+
+func (c *telemetryComponent) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func init() {
+	config.Register("telemetry", config.Schema{
+		"export_on_shutdown": config.Field{
+			Default:     false,
+			Required:    false,
+			Description: "Write a final metrics and health snapshot on graceful shutdown",
+		},
+		"file": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Snapshot file path; {run_id} is replaced with the run identifier",
+		},
+		"store": config.Field{
+			Default:     "",
+			Required:    false,
+			Description: "Component name of the data store to write snapshots to (e.g. mysql)",
+		},
+		"key_prefix": config.Field{
+			Default:     "telemetry/",
+			Required:    false,
+			Description: "Key prefix for snapshots written to the store",
+		},
+	})
+}
+
+// Register adds the telemetry export component to app.
+func Register(app *helper.App) {
+	app.RegisterComponent(&telemetryComponent{})
+}